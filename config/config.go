@@ -7,11 +7,16 @@ import (
 )
 
 type Config struct {
-	HTTPServer `yaml:"httpServer"`
-	Postgres   `yaml:"postgres"`
-	JWT        `yaml:"jwt"`
-	Logger     `yaml:"logger"`
-	TxManager  `yaml:"txManager"`
+	HTTPServer    `yaml:"httpServer"`
+	Postgres      `yaml:"postgres"`
+	JWT           `yaml:"jwt"`
+	Auth          `yaml:"auth"`
+	Password      `yaml:"password"`
+	LoginThrottle `yaml:"loginThrottle"`
+	Shop          `yaml:"shop"`
+	Idempotency   `yaml:"idempotency"`
+	Logger        `yaml:"logger"`
+	TxManager     `yaml:"txManager"`
 }
 
 type HTTPServer struct {
@@ -22,9 +27,81 @@ type Postgres struct {
 	DSN string `yaml:"dsn" env:"DSN" env-required:"true"`
 }
 
+// Если заданы ключи Keys, токены подписываются ключом SigningKeyID (RS256 или EdDSA),
+// а открытые ключи публикуются в /.well-known/jwks.json. Иначе используется HS256 с секретом Secret.
 type JWT struct {
-	Secret string `yaml:"secret" env:"JWT_SECRET" env-required:"true"`
+	Secret string `yaml:"secret" env:"JWT_SECRET"`
 	TTLMin int    `yaml:"ttlMin" env:"JWT_TTL_MINUTES" env-required:"true"`
+
+	SigningKeyID string   `yaml:"signingKeyId" env:"JWT_SIGNING_KEY_ID"`
+	Keys         []JWTKey `yaml:"keys"`
+
+	RefreshTTLMin   int `yaml:"refreshTtlMin" env:"JWT_REFRESH_TTL_MINUTES" env-default:"43200"`
+	DenylistSyncSec int `yaml:"denylistSyncSec" env:"JWT_DENYLIST_SYNC_SECONDS" env-default:"10"`
+}
+
+// Ключ, оставленный только для проверки подписи после ротации, задается одним открытым ключом.
+type JWTKey struct {
+	ID             string `yaml:"id"`
+	Algorithm      string `yaml:"algorithm"`
+	PrivateKeyFile string `yaml:"privateKeyFile"`
+	PublicKeyFile  string `yaml:"publicKeyFile"`
+}
+
+type Auth struct {
+	AutoRegister bool `yaml:"autoRegister" env:"AUTH_AUTO_REGISTER" env-default:"true"`
+	// Пользователь, который станет администратором при старте, если администраторов еще нет.
+	BootstrapAdmin string `yaml:"bootstrapAdmin" env:"AUTH_BOOTSTRAP_ADMIN"`
+	// Название сервиса, которое приложение-аутентификатор показывает рядом с кодом.
+	TOTPIssuer string `yaml:"totpIssuer" env:"AUTH_TOTP_ISSUER" env-default:"Merch Store"`
+	// Казначейский счет для остатка монет деактивированных пользователей. Пустое значение отключает перевод.
+	TreasuryUsername string `yaml:"treasuryUsername" env:"AUTH_TREASURY_USERNAME"`
+	// Имена, которые нельзя занять при регистрации, без учета регистра.
+	ReservedUsernames []string `yaml:"reservedUsernames" env:"AUTH_RESERVED_USERNAMES" env-separator:","`
+}
+
+// Защита /api/auth от перебора паролей, см. auth.LoginThrottleConfig.
+type LoginThrottle struct {
+	FreeAttempts       int `yaml:"freeAttempts" env:"LOGIN_FREE_ATTEMPTS" env-default:"3"`
+	LockoutThreshold   int `yaml:"lockoutThreshold" env:"LOGIN_LOCKOUT_THRESHOLD" env-default:"10"`
+	IPFreeAttempts     int `yaml:"ipFreeAttempts" env:"LOGIN_IP_FREE_ATTEMPTS" env-default:"20"`
+	IPLockoutThreshold int `yaml:"ipLockoutThreshold" env:"LOGIN_IP_LOCKOUT_THRESHOLD" env-default:"200"`
+	BackoffBaseMs      int `yaml:"backoffBaseMs" env:"LOGIN_BACKOFF_BASE_MS" env-default:"1000"`
+	MaxBackoffSec      int `yaml:"maxBackoffSec" env:"LOGIN_MAX_BACKOFF_SECONDS" env-default:"60"`
+	LockoutMin         int `yaml:"lockoutMin" env:"LOGIN_LOCKOUT_MINUTES" env-default:"15"`
+	FailureWindowMin   int `yaml:"failureWindowMin" env:"LOGIN_FAILURE_WINDOW_MINUTES" env-default:"60"`
+}
+
+type Shop struct {
+	// Максимальное количество единиц товара в одной покупке.
+	MaxPurchaseQuantity int `yaml:"maxPurchaseQuantity" env:"SHOP_MAX_PURCHASE_QUANTITY" env-default:"100"`
+	// Сколько часов после покупки можно подать заявку на возврат.
+	RefundWindowHours int `yaml:"refundWindowHours" env:"SHOP_REFUND_WINDOW_HOURS" env-default:"336"`
+	// Товары с остатком не больше порога показываются администраторам в отчете о заканчивающихся товарах.
+	LowStockThreshold int `yaml:"lowStockThreshold" env:"SHOP_LOW_STOCK_THRESHOLD" env-default:"5"`
+}
+
+type Idempotency struct {
+	// Сколько хранится ответ на запрос с Idempotency-Key. После этого ключ можно использовать снова.
+	KeyTTLMin int `yaml:"keyTtlMin" env:"IDEMPOTENCY_KEY_TTL_MINUTES" env-default:"1440"`
+}
+
+// Bcrypt-хеши, созданные до перехода на argon2id, проверяются с глобальной солью BcryptPepper,
+// поэтому ее нельзя менять, пока в БД остаются такие хеши.
+type Password struct {
+	Algorithm         string `yaml:"algorithm" env:"PASSWORD_ALGORITHM" env-default:"argon2id"`
+	Argon2MemoryKiB   uint32 `yaml:"argon2MemoryKiB" env:"PASSWORD_ARGON2_MEMORY_KIB" env-default:"65536"`
+	Argon2Iterations  uint32 `yaml:"argon2Iterations" env:"PASSWORD_ARGON2_ITERATIONS" env-default:"3"`
+	Argon2Parallelism uint8  `yaml:"argon2Parallelism" env:"PASSWORD_ARGON2_PARALLELISM" env-default:"2"`
+	BcryptCost        int    `yaml:"bcryptCost" env:"PASSWORD_BCRYPT_COST" env-default:"10"`
+	BcryptPepper      string `yaml:"bcryptPepper" env:"PASSWORD_BCRYPT_PEPPER" env-default:"1234567890"`
+	// Требования к паролю при его смене и сбросе.
+	PolicyMinLength        int  `yaml:"policyMinLength" env:"PASSWORD_POLICY_MIN_LENGTH" env-default:"8"`
+	PolicyMaxLength        int  `yaml:"policyMaxLength" env:"PASSWORD_POLICY_MAX_LENGTH" env-default:"128"`
+	PolicyRequireMixedCase bool `yaml:"policyRequireMixedCase" env:"PASSWORD_POLICY_REQUIRE_MIXED_CASE" env-default:"false"`
+	PolicyRequireDigit     bool `yaml:"policyRequireDigit" env:"PASSWORD_POLICY_REQUIRE_DIGIT" env-default:"true"`
+	PolicyRequireSpecial   bool `yaml:"policyRequireSpecial" env:"PASSWORD_POLICY_REQUIRE_SPECIAL" env-default:"false"`
+	ResetTTLMin            int  `yaml:"resetTTLMin" env:"PASSWORD_RESET_TTL_MINUTES" env-default:"60"`
 }
 
 type Logger struct {
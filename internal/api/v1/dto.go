@@ -3,31 +3,316 @@
 // Code generated by github.com/oapi-codegen/oapi-codegen/v2 version v2.4.1 DO NOT EDIT.
 package v1
 
+import (
+	"time"
+)
+
 const (
+	ApiKeyAuthScopes = "ApiKeyAuth.Scopes"
 	BearerAuthScopes = "BearerAuth.Scopes"
 )
 
+// Defines values for AuthRequestScopes.
+const (
+	AuthRequestScopesAdmin     AuthRequestScopes = "admin"
+	AuthRequestScopesCoinssend AuthRequestScopes = "coins:send"
+	AuthRequestScopesInforead  AuthRequestScopes = "info:read"
+	AuthRequestScopesShopbuy   AuthRequestScopes = "shop:buy"
+)
+
+// Defines values for CreateApiKeyRequestScopes.
+const (
+	CreateApiKeyRequestScopesAdmin     CreateApiKeyRequestScopes = "admin"
+	CreateApiKeyRequestScopesCoinssend CreateApiKeyRequestScopes = "coins:send"
+	CreateApiKeyRequestScopesInforead  CreateApiKeyRequestScopes = "info:read"
+	CreateApiKeyRequestScopesShopbuy   CreateApiKeyRequestScopes = "shop:buy"
+)
+
+// Defines values for HistoryEntryDirection.
+const (
+	HistoryEntryDirectionIncoming HistoryEntryDirection = "incoming"
+	HistoryEntryDirectionOutgoing HistoryEntryDirection = "outgoing"
+)
+
+// Defines values for HistoryEntryType.
+const (
+	HistoryEntryTypePurchase HistoryEntryType = "purchase"
+	HistoryEntryTypeRefund   HistoryEntryType = "refund"
+	HistoryEntryTypeTransfer HistoryEntryType = "transfer"
+)
+
+// Defines values for OperationType.
+const (
+	OperationTypePurchase OperationType = "purchase"
+	OperationTypeRefund   OperationType = "refund"
+	OperationTypeTransfer OperationType = "transfer"
+)
+
+// Defines values for ProductAuditEntryAction.
+const (
+	ProductAuditEntryActionCreate ProductAuditEntryAction = "create"
+	ProductAuditEntryActionRetire ProductAuditEntryAction = "retire"
+	ProductAuditEntryActionUpdate ProductAuditEntryAction = "update"
+)
+
+// Defines values for RefundStatus.
+const (
+	RefundStatusApproved  RefundStatus = "approved"
+	RefundStatusRejected  RefundStatus = "rejected"
+	RefundStatusRequested RefundStatus = "requested"
+)
+
+// Defines values for SetUserRoleRequestRole.
+const (
+	SetUserRoleRequestRoleAdmin SetUserRoleRequestRole = "admin"
+	SetUserRoleRequestRoleUser  SetUserRoleRequestRole = "user"
+)
+
+// AddCartItemRequest defines model for AddCartItemRequest.
+type AddCartItemRequest struct {
+	// Item Название товара.
+	Item string `json:"item"`
+
+	// Quantity Сколько единиц товара добавить в корзину. По умолчанию 1.
+	Quantity *int `json:"quantity,omitempty"`
+
+	// Variant Название варианта товара, например размер. Без него в корзину кладется сам товар.
+	Variant *string `json:"variant,omitempty"`
+}
+
+// ApiKey defines model for ApiKey.
+type ApiKey struct {
+	// CreatedAt Время выпуска ключа.
+	CreatedAt time.Time `json:"createdAt"`
+
+	// Id Идентификатор ключа.
+	Id int `json:"id"`
+
+	// Key Значение ключа. Возвращается только при выпуске, сохранить его нужно сразу.
+	Key *string `json:"key,omitempty"`
+
+	// LastUsedAt Время последнего использования ключа с точностью до минуты.
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+
+	// Name Название ключа.
+	Name string `json:"name"`
+
+	// Prefix Начало ключа, по которому его можно узнать.
+	Prefix string `json:"prefix"`
+
+	// RevokedAt Время отзыва ключа.
+	RevokedAt *time.Time `json:"revokedAt,omitempty"`
+
+	// Scopes Области доступа ключа.
+	Scopes []string `json:"scopes"`
+}
+
 // AuthRequest defines model for AuthRequest.
 type AuthRequest struct {
+	// OtpCode Код из приложения-аутентификатора или код восстановления. Обязателен, если у пользователя включена двухфакторная аутентификация.
+	OtpCode *string `json:"otpCode,omitempty"`
+
 	// Password Пароль для аутентификации.
 	Password string `json:"password"`
 
-	// Username Имя пользователя для аутентификации.
+	// Scopes Области доступа выдаваемого токена. Если не указаны, выдаются все области, доступные роли пользователя.
+	Scopes *[]AuthRequestScopes `json:"scopes,omitempty"`
+
+	// Username Имя пользователя для аутентификации. Сравнивается без учета регистра и формы записи Unicode.
 	Username string `json:"username"`
 }
 
+// AuthRequestScopes defines model for AuthRequest.Scopes.
+type AuthRequestScopes string
+
 // AuthResponse defines model for AuthResponse.
 type AuthResponse struct {
+	// RefreshToken Непрозрачный одноразовый токен для получения новой пары токенов.
+	RefreshToken *string `json:"refreshToken,omitempty"`
+
 	// Token JWT-токен для доступа к защищенным ресурсам.
 	Token *string `json:"token,omitempty"`
 }
 
+// CartItem defines model for CartItem.
+type CartItem struct {
+	// Name Название товара.
+	Name string `json:"name"`
+
+	// Price Текущая цена одной единицы товара: цена варианта, если она у него задана, иначе цена товара.
+	Price int `json:"price"`
+
+	// Quantity Количество единиц товара.
+	Quantity int `json:"quantity"`
+
+	// TotalPrice Стоимость строки: цена, умноженная на количество.
+	TotalPrice int `json:"totalPrice"`
+
+	// Variant Вариант товара. Не указывается, если в корзине сам товар.
+	Variant *string `json:"variant,omitempty"`
+}
+
+// CartResponse defines model for CartResponse.
+type CartResponse struct {
+	Items []CartItem `json:"items"`
+
+	// TotalPrice Стоимость всей корзины.
+	TotalPrice int `json:"totalPrice"`
+}
+
+// ChangePasswordRequest defines model for ChangePasswordRequest.
+type ChangePasswordRequest struct {
+	// NewPassword Новый пароль, должен соответствовать политике паролей.
+	NewPassword string `json:"newPassword"`
+
+	// OldPassword Текущий пароль пользователя.
+	OldPassword string `json:"oldPassword"`
+}
+
+// CheckoutErrorResponse defines model for CheckoutErrorResponse.
+type CheckoutErrorResponse struct {
+	// Errors Сообщение об ошибке, описывающее проблему.
+	Errors *string `json:"errors,omitempty"`
+
+	// Lines Строки корзины, которые нельзя купить.
+	Lines *[]CheckoutLineError `json:"lines,omitempty"`
+}
+
+// CheckoutLineError defines model for CheckoutLineError.
+type CheckoutLineError struct {
+	// Errors Причина, по которой товар нельзя купить.
+	Errors string `json:"errors"`
+
+	// Item Название товара.
+	Item string `json:"item"`
+
+	// Variant Вариант товара. Не указывается, если в корзине сам товар.
+	Variant *string `json:"variant,omitempty"`
+}
+
+// ConfirmTotpRequest defines model for ConfirmTotpRequest.
+type ConfirmTotpRequest struct {
+	// Code Текущий код из приложения-аутентификатора.
+	Code string `json:"code"`
+
+	// Threshold Переводы и покупки дороже этой суммы будут требовать код. По умолчанию 0, то есть код нужен для всех операций.
+	Threshold *int `json:"threshold,omitempty"`
+}
+
+// CreateApiKeyRequest defines model for CreateApiKeyRequest.
+type CreateApiKeyRequest struct {
+	// Name Название ключа, например имя бота или окружения.
+	Name string `json:"name"`
+
+	// Scopes Области доступа ключа. Если не указаны, выдаются все области, доступные роли сервисного аккаунта.
+	Scopes *[]CreateApiKeyRequestScopes `json:"scopes,omitempty"`
+}
+
+// CreateApiKeyRequestScopes defines model for CreateApiKeyRequest.Scopes.
+type CreateApiKeyRequestScopes string
+
+// CreateProductRequest defines model for CreateProductRequest.
+type CreateProductRequest struct {
+	// Description Описание товара.
+	Description *string `json:"description,omitempty"`
+
+	// Name Уникальное название товара.
+	Name string `json:"name"`
+
+	// Price Цена за единицу.
+	Price int `json:"price"`
+
+	// Stock Начальный остаток на складе. Если не указан, товар создается без остатка.
+	Stock *int `json:"stock,omitempty"`
+}
+
+// CreateProductVariantRequest defines model for CreateProductVariantRequest.
+type CreateProductVariantRequest struct {
+	// Name Название варианта, например размер. Уникально в пределах товара.
+	Name string `json:"name"`
+
+	// Price Цена варианта. Если не указана, действует цена товара.
+	Price *int `json:"price,omitempty"`
+
+	// Sku Уникальный артикул варианта.
+	Sku string `json:"sku"`
+
+	// Stock Собственный остаток варианта. Если не указан, вариант продается из остатка товара.
+	Stock *int `json:"stock,omitempty"`
+}
+
+// CreateServiceAccountRequest defines model for CreateServiceAccountRequest.
+type CreateServiceAccountRequest struct {
+	// Username Имя сервисного аккаунта.
+	Username string `json:"username"`
+}
+
+// DeactivateUserRequest defines model for DeactivateUserRequest.
+type DeactivateUserRequest struct {
+	// SweepBalance Перевести остаток монет на казначейский счет.
+	SweepBalance *bool `json:"sweepBalance,omitempty"`
+}
+
+// DeactivateUserResponse defines model for DeactivateUserResponse.
+type DeactivateUserResponse struct {
+	// SweptAmount Сумма, переведенная на казначейский счет.
+	SweptAmount int `json:"sweptAmount"`
+}
+
 // ErrorResponse defines model for ErrorResponse.
 type ErrorResponse struct {
 	// Errors Сообщение об ошибке, описывающее проблему.
 	Errors *string `json:"errors,omitempty"`
 }
 
+// HistoryEntry defines model for HistoryEntry.
+type HistoryEntry struct {
+	// Amount Сумма операции в монетах.
+	Amount int `json:"amount"`
+
+	// Counterparty Второй участник перевода. Указывается только для переводов.
+	Counterparty *string `json:"counterparty,omitempty"`
+
+	// CreatedAt Время операции.
+	CreatedAt time.Time `json:"createdAt"`
+
+	// Direction Направление движения монет.
+	Direction HistoryEntryDirection `json:"direction"`
+
+	// Id Идентификатор операции.
+	Id int `json:"id"`
+
+	// Item Название товара. Указывается для покупок и возвратов.
+	Item *string `json:"item,omitempty"`
+
+	// Quantity Количество единиц товара. Указывается для покупок и возвратов.
+	Quantity *int `json:"quantity,omitempty"`
+
+	// RefundStatus Шаг возврата. Указывается только для возвратов.
+	RefundStatus *RefundStatus `json:"refundStatus,omitempty"`
+
+	// Type Тип операции.
+	Type HistoryEntryType `json:"type"`
+}
+
+// HistoryEntryDirection Направление движения монет.
+type HistoryEntryDirection string
+
+// HistoryEntryType Тип операции.
+type HistoryEntryType string
+
+// HistoryResponse defines model for HistoryResponse.
+type HistoryResponse struct {
+	// Items Операции текущей страницы от новых к старым.
+	Items []HistoryEntry `json:"items"`
+
+	// Limit Размер страницы.
+	Limit int `json:"limit"`
+
+	// NextCursor Курсор следующей страницы. Не указывается на последней странице.
+	NextCursor *string `json:"nextCursor,omitempty"`
+}
+
 // InfoResponse defines model for InfoResponse.
 type InfoResponse struct {
 	CoinHistory *struct {
@@ -53,9 +338,267 @@ type InfoResponse struct {
 		// Quantity Количество предметов.
 		Quantity *int `json:"quantity,omitempty"`
 
-		// Type Тип предмета.
+		// Type Тип предмета. Для купленного варианта вариант указывается в скобках, например hoody (XL).
 		Type *string `json:"type,omitempty"`
 	} `json:"inventory,omitempty"`
+
+	// LastOperationId Идентификатор последней операции счета, попавшей в снимок. 0, если операций нет.
+	LastOperationId *int `json:"lastOperationId,omitempty"`
+
+	// SnapshotAt Момент снимка, из которого прочитаны баланс, инвентарь и история.
+	SnapshotAt *time.Time `json:"snapshotAt,omitempty"`
+}
+
+// JWK defines model for JWK.
+type JWK struct {
+	// Alg Алгоритм подписи.
+	Alg string `json:"alg"`
+
+	// Crv Кривая ключа типа OKP.
+	Crv *string `json:"crv,omitempty"`
+
+	// E Экспонента RSA-ключа в base64url.
+	E *string `json:"e,omitempty"`
+
+	// Kid Идентификатор ключа, совпадает с заголовком kid токена.
+	Kid string `json:"kid"`
+
+	// Kty Тип ключа.
+	Kty string `json:"kty"`
+
+	// N Модуль RSA-ключа в base64url.
+	N *string `json:"n,omitempty"`
+
+	// Use Назначение ключа.
+	Use string `json:"use"`
+
+	// X Открытый ключ типа OKP в base64url.
+	X *string `json:"x,omitempty"`
+}
+
+// JWKSet defines model for JWKSet.
+type JWKSet struct {
+	// Keys Открытые ключи для проверки подписи access-токенов.
+	Keys []JWK `json:"keys"`
+}
+
+// LogoutRequest defines model for LogoutRequest.
+type LogoutRequest struct {
+	// RefreshToken Refresh-токен завершаемой сессии. Если передан, отзывается вместе со всей цепочкой ротации.
+	RefreshToken *string `json:"refreshToken,omitempty"`
+}
+
+// Operation defines model for Operation.
+type Operation struct {
+	// Amount Сумма перевода или возврата в монетах.
+	Amount *int `json:"amount,omitempty"`
+
+	// CreatedAt Время операции.
+	CreatedAt time.Time `json:"createdAt"`
+
+	// Id Идентификатор операции.
+	Id int `json:"id"`
+
+	// Item Название товара. Указывается для покупок и возвратов.
+	Item *string `json:"item,omitempty"`
+
+	// Quantity Количество единиц товара. Указывается для покупок и возвратов.
+	Quantity *int `json:"quantity,omitempty"`
+
+	// Recipient Получатель перевода.
+	Recipient *string `json:"recipient,omitempty"`
+
+	// RefundStatus Шаг возврата. Указывается только для возвратов.
+	RefundStatus *RefundStatus `json:"refundStatus,omitempty"`
+
+	// TotalPrice Итоговая сумма покупки.
+	TotalPrice *int `json:"totalPrice,omitempty"`
+
+	// Type Тип операции.
+	Type OperationType `json:"type"`
+
+	// User Инициатор операции: покупатель или отправитель перевода.
+	User string `json:"user"`
+}
+
+// OperationType Тип операции.
+type OperationType string
+
+// PasswordResetResponse defines model for PasswordResetResponse.
+type PasswordResetResponse struct {
+	// ExpiresAt Время, до которого токен действителен.
+	ExpiresAt time.Time `json:"expiresAt"`
+
+	// ResetToken Одноразовый токен сброса пароля. Показывается только один раз.
+	ResetToken string `json:"resetToken"`
+}
+
+// Product defines model for Product.
+type Product struct {
+	// Description Описание товара.
+	Description string `json:"description"`
+
+	// Name Название товара.
+	Name string `json:"name"`
+
+	// Price Цена за единицу.
+	Price int `json:"price"`
+
+	// Stock Сколько единиц осталось на складе.
+	Stock int `json:"stock"`
+
+	// Variants Варианты товара. Возвращаются в карточке отдельного товара, а в отчете о заканчивающихся товарах — только заканчивающиеся варианты.
+	Variants *[]ProductVariant `json:"variants,omitempty"`
+}
+
+// ProductAuditEntry defines model for ProductAuditEntry.
+type ProductAuditEntry struct {
+	// Action Тип изменения.
+	Action ProductAuditEntryAction `json:"action"`
+
+	// ChangedAt Время изменения.
+	ChangedAt time.Time `json:"changedAt"`
+
+	// ChangedBy Администратор, который внес изменение. Пусто, если пользователь удален.
+	ChangedBy string `json:"changedBy"`
+
+	// Field Измененное поле. Не указывается при снятии с продажи.
+	Field *string `json:"field,omitempty"`
+
+	// NewValue Новое значение поля.
+	NewValue *string `json:"newValue,omitempty"`
+
+	// OldValue Прежнее значение поля. Не указывается при создании товара.
+	OldValue *string `json:"oldValue,omitempty"`
+}
+
+// ProductAuditEntryAction Тип изменения.
+type ProductAuditEntryAction string
+
+// ProductListResponse defines model for ProductListResponse.
+type ProductListResponse struct {
+	// Items Товары текущей страницы.
+	Items []Product `json:"items"`
+
+	// Limit Размер страницы.
+	Limit int `json:"limit"`
+
+	// Offset Сколько товаров пропущено от начала выборки.
+	Offset int `json:"offset"`
+
+	// Total Сколько всего товаров подходит под фильтр.
+	Total int `json:"total"`
+}
+
+// ProductVariant defines model for ProductVariant.
+type ProductVariant struct {
+	// Name Название варианта.
+	Name string `json:"name"`
+
+	// Price Цена варианта за единицу.
+	Price int `json:"price"`
+
+	// Sku Артикул варианта.
+	Sku string `json:"sku"`
+
+	// Stock Сколько единиц варианта осталось на складе.
+	Stock int `json:"stock"`
+}
+
+// RecoveryCodesResponse defines model for RecoveryCodesResponse.
+type RecoveryCodesResponse struct {
+	// RecoveryCodes Одноразовые коды восстановления на случай потери устройства. Показываются только один раз.
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+// RefreshRequest defines model for RefreshRequest.
+type RefreshRequest struct {
+	// RefreshToken Refresh-токен, полученный при аутентификации или предыдущем обновлении.
+	RefreshToken string `json:"refreshToken"`
+}
+
+// Refund defines model for Refund.
+type Refund struct {
+	// Amount Сумма возврата, пропорциональная количеству единиц.
+	Amount int `json:"amount"`
+
+	// CreatedAt Время подачи заявки.
+	CreatedAt time.Time `json:"createdAt"`
+
+	// DecidedAt Время решения администратора.
+	DecidedAt *time.Time `json:"decidedAt,omitempty"`
+
+	// Id Идентификатор заявки.
+	Id int `json:"id"`
+
+	// Item Название товара.
+	Item string `json:"item"`
+
+	// PurchaseId Идентификатор покупки.
+	PurchaseId int `json:"purchaseId"`
+
+	// Quantity Количество возвращаемых единиц.
+	Quantity int `json:"quantity"`
+
+	// Status Статус заявки.
+	Status RefundStatus `json:"status"`
+
+	// Username Покупатель.
+	Username string `json:"username"`
+}
+
+// RefundStatus Статус заявки.
+type RefundStatus string
+
+// RefundRequest defines model for RefundRequest.
+type RefundRequest struct {
+	// PurchaseId Идентификатор покупки.
+	PurchaseId int `json:"purchaseId"`
+
+	// Quantity Сколько единиц вернуть. По умолчанию все еще не возвращенные единицы.
+	Quantity *int `json:"quantity,omitempty"`
+}
+
+// RefundablePurchase defines model for RefundablePurchase.
+type RefundablePurchase struct {
+	// Id Идентификатор покупки.
+	Id int `json:"id"`
+
+	// Item Название товара.
+	Item string `json:"item"`
+
+	// PurchasedAt Время покупки.
+	PurchasedAt time.Time `json:"purchasedAt"`
+
+	// Quantity Сколько единиц было куплено.
+	Quantity int `json:"quantity"`
+
+	// RefundableQuantity Сколько единиц еще можно вернуть.
+	RefundableQuantity int `json:"refundableQuantity"`
+
+	// RefundableUntil До какого момента можно подать заявку на возврат.
+	RefundableUntil time.Time `json:"refundableUntil"`
+
+	// TotalPrice Сумма покупки.
+	TotalPrice int `json:"totalPrice"`
+}
+
+// RestockRequest defines model for RestockRequest.
+type RestockRequest struct {
+	// Quantity Сколько единиц добавить на склад.
+	Quantity int `json:"quantity"`
+
+	// Variant Вариант с собственным остатком, который нужно пополнить. Для варианта без собственного остатка пополняется остаток товара.
+	Variant *string `json:"variant,omitempty"`
+}
+
+// ResetPasswordRequest defines model for ResetPasswordRequest.
+type ResetPasswordRequest struct {
+	// NewPassword Новый пароль, должен соответствовать политике паролей.
+	NewPassword string `json:"newPassword"`
+
+	// ResetToken Токен сброса пароля, выданный администратором.
+	ResetToken string `json:"resetToken"`
 }
 
 // SendCoinRequest defines model for SendCoinRequest.
@@ -63,12 +606,108 @@ type SendCoinRequest struct {
 	// Amount Количество монет, которые необходимо отправить.
 	Amount int `json:"amount"`
 
-	// ToUser Имя пользователя, которому нужно отправить монеты.
+	// ToUser Имя пользователя, которому нужно отправить монеты. Регистр не важен.
 	ToUser string `json:"toUser"`
 }
 
+// SetTotpThresholdRequest defines model for SetTotpThresholdRequest.
+type SetTotpThresholdRequest struct {
+	// Code Код из приложения-аутентификатора или код восстановления.
+	Code string `json:"code"`
+
+	// Threshold Переводы и покупки дороже этой суммы будут требовать код.
+	Threshold int `json:"threshold"`
+}
+
+// SetUserRoleRequest defines model for SetUserRoleRequest.
+type SetUserRoleRequest struct {
+	// Role Новая роль пользователя.
+	Role SetUserRoleRequestRole `json:"role"`
+}
+
+// SetUserRoleRequestRole Новая роль пользователя.
+type SetUserRoleRequestRole string
+
+// TotpCodeRequest defines model for TotpCodeRequest.
+type TotpCodeRequest struct {
+	// Code Код из приложения-аутентификатора или код восстановления.
+	Code string `json:"code"`
+}
+
+// TotpEnrollmentResponse defines model for TotpEnrollmentResponse.
+type TotpEnrollmentResponse struct {
+	// OtpauthUri Ссылка otpauth:// для QR-кода.
+	OtpauthUri string `json:"otpauthUri"`
+
+	// Secret Секрет для ручного ввода в приложение-аутентификатор.
+	Secret string `json:"secret"`
+}
+
+// UpdateProductRequest defines model for UpdateProductRequest.
+type UpdateProductRequest struct {
+	// Description Новое описание товара.
+	Description *string `json:"description,omitempty"`
+
+	// Price Новая цена за единицу. Действует для следующих покупок.
+	Price *int `json:"price,omitempty"`
+}
+
+// PatchApiAdminProductsItemJSONRequestBody defines body for PatchApiAdminProductsItem for application/json ContentType.
+type PatchApiAdminProductsItemJSONRequestBody = UpdateProductRequest
+
+// PostApiAdminProductsJSONRequestBody defines body for PostApiAdminProducts for application/json ContentType.
+type PostApiAdminProductsJSONRequestBody = CreateProductRequest
+
+// PostApiAdminProductsItemRestockJSONRequestBody defines body for PostApiAdminProductsItemRestock for application/json ContentType.
+type PostApiAdminProductsItemRestockJSONRequestBody = RestockRequest
+
+// PostApiAdminProductsItemVariantsJSONRequestBody defines body for PostApiAdminProductsItemVariants for application/json ContentType.
+type PostApiAdminProductsItemVariantsJSONRequestBody = CreateProductVariantRequest
+
+// PostApiAdminServiceAccountsJSONRequestBody defines body for PostApiAdminServiceAccounts for application/json ContentType.
+type PostApiAdminServiceAccountsJSONRequestBody = CreateServiceAccountRequest
+
+// PostApiAdminServiceAccountsUsernameApiKeysJSONRequestBody defines body for PostApiAdminServiceAccountsUsernameApiKeys for application/json ContentType.
+type PostApiAdminServiceAccountsUsernameApiKeysJSONRequestBody = CreateApiKeyRequest
+
+// PostApiAdminUsersUsernameDeactivateJSONRequestBody defines body for PostApiAdminUsersUsernameDeactivate for application/json ContentType.
+type PostApiAdminUsersUsernameDeactivateJSONRequestBody = DeactivateUserRequest
+
+// PostApiAuth2faConfirmJSONRequestBody defines body for PostApiAuth2faConfirm for application/json ContentType.
+type PostApiAuth2faConfirmJSONRequestBody = ConfirmTotpRequest
+
+// PostApiAuth2faDisableJSONRequestBody defines body for PostApiAuth2faDisable for application/json ContentType.
+type PostApiAuth2faDisableJSONRequestBody = TotpCodeRequest
+
 // PostApiAuthJSONRequestBody defines body for PostApiAuth for application/json ContentType.
 type PostApiAuthJSONRequestBody = AuthRequest
 
+// PostApiAuthLogoutJSONRequestBody defines body for PostApiAuthLogout for application/json ContentType.
+type PostApiAuthLogoutJSONRequestBody = LogoutRequest
+
+// PostApiAuthPasswordJSONRequestBody defines body for PostApiAuthPassword for application/json ContentType.
+type PostApiAuthPasswordJSONRequestBody = ChangePasswordRequest
+
+// PostApiAuthPasswordResetJSONRequestBody defines body for PostApiAuthPasswordReset for application/json ContentType.
+type PostApiAuthPasswordResetJSONRequestBody = ResetPasswordRequest
+
+// PostApiAuthRefreshJSONRequestBody defines body for PostApiAuthRefresh for application/json ContentType.
+type PostApiAuthRefreshJSONRequestBody = RefreshRequest
+
+// PostApiCartItemsJSONRequestBody defines body for PostApiCartItems for application/json ContentType.
+type PostApiCartItemsJSONRequestBody = AddCartItemRequest
+
+// PostApiRefundsJSONRequestBody defines body for PostApiRefunds for application/json ContentType.
+type PostApiRefundsJSONRequestBody = RefundRequest
+
+// PostApiRegisterJSONRequestBody defines body for PostApiRegister for application/json ContentType.
+type PostApiRegisterJSONRequestBody = AuthRequest
+
 // PostApiSendCoinJSONRequestBody defines body for PostApiSendCoin for application/json ContentType.
 type PostApiSendCoinJSONRequestBody = SendCoinRequest
+
+// PutApiAdminUsersUsernameRoleJSONRequestBody defines body for PutApiAdminUsersUsernameRole for application/json ContentType.
+type PutApiAdminUsersUsernameRoleJSONRequestBody = SetUserRoleRequest
+
+// PutApiAuth2faThresholdJSONRequestBody defines body for PutApiAuth2faThreshold for application/json ContentType.
+type PutApiAuth2faThresholdJSONRequestBody = SetTotpThresholdRequest
@@ -34,6 +34,10 @@ func (a *App) Run() {
 
 	e := echo.New()
 	httpServer := httpserver.New(a.provider.Handler(ctx, e), a.provider.Config().HTTPServer.Port)
+
+	if err := a.provider.Usecases(ctx).BootstrapAdmin(ctx, a.provider.Config().Auth.BootstrapAdmin); err != nil {
+		log.Warnf("failed to bootstrap admin: %v", err)
+	}
 	a.provider.Closer().Add(func() error {
 		log.Info("stopping http server gracefully...")
 
@@ -12,9 +12,15 @@ import (
 	"github.com/resueman/merch-store/internal/delivery/middleware"
 	"github.com/resueman/merch-store/internal/repo"
 	"github.com/resueman/merch-store/internal/usecase"
+	"github.com/resueman/merch-store/internal/usecase/auth"
+	"github.com/resueman/merch-store/internal/usecase/idempotency"
+	"github.com/resueman/merch-store/internal/usecase/operation"
+	"github.com/resueman/merch-store/internal/usecase/product"
+	"github.com/resueman/merch-store/internal/usecase/refund"
 	"github.com/resueman/merch-store/pkg/closer"
 	"github.com/resueman/merch-store/pkg/db"
 	"github.com/resueman/merch-store/pkg/db/postgres"
+	"github.com/resueman/merch-store/pkg/jwtkeys"
 	"github.com/resueman/merch-store/pkg/password"
 )
 
@@ -27,7 +33,8 @@ type serviceProvider struct {
 
 	dbClient        db.Client
 	txManager       db.TxManager
-	passwordManager *password.BcryptManager
+	passwordManager *password.Manager
+	jwtKeySet       *jwtkeys.KeySet
 	repositories    *repo.Repositories
 	usecases        *usecase.Usecase
 	authMiddleware  *middleware.AuthMiddleware
@@ -108,21 +115,118 @@ func (p *serviceProvider) Repositories(ctx context.Context) *repo.Repositories {
 	return p.repositories
 }
 
-func (p *serviceProvider) PasswordManager() *password.BcryptManager {
+func (p *serviceProvider) PasswordManager() *password.Manager {
 	if p.passwordManager == nil {
-		salt := "1234567890" // надо генерировать для каждого пользователя, но пока так, переделаю, если успею
-		p.passwordManager = password.NewPasswordManager(salt)
+		cfg := p.Config().Password
+		argon2Hasher := password.NewArgon2Hasher(password.Argon2Params{
+			Memory:      cfg.Argon2MemoryKiB,
+			Iterations:  cfg.Argon2Iterations,
+			Parallelism: cfg.Argon2Parallelism,
+			SaltLength:  password.DefaultArgon2Params.SaltLength,
+			KeyLength:   password.DefaultArgon2Params.KeyLength,
+		})
+		bcryptHasher := password.NewBcryptHasher(cfg.BcryptCost, cfg.BcryptPepper)
+
+		switch cfg.Algorithm {
+		case "argon2id":
+			p.passwordManager = password.NewPasswordManager(argon2Hasher, bcryptHasher)
+		case "bcrypt":
+			p.passwordManager = password.NewPasswordManager(bcryptHasher, argon2Hasher)
+		default:
+			log.Fatalf("unknown password hashing algorithm: %s", cfg.Algorithm)
+		}
 	}
 
 	return p.passwordManager
 }
 
+// Возвращает nil, если ключи не заданы: тогда токены подписываются секретом HS256.
+func (p *serviceProvider) JWTKeySet() *jwtkeys.KeySet {
+	if p.jwtKeySet == nil {
+		cfg := p.Config().JWT
+		if len(cfg.Keys) == 0 {
+			if cfg.Secret == "" {
+				log.Fatal("either jwt.secret or jwt.keys must be configured")
+			}
+
+			return nil
+		}
+
+		files := make([]jwtkeys.KeyFile, 0, len(cfg.Keys))
+		for _, k := range cfg.Keys {
+			files = append(files, jwtkeys.KeyFile{
+				ID:             k.ID,
+				Algorithm:      k.Algorithm,
+				PrivateKeyFile: k.PrivateKeyFile,
+				PublicKeyFile:  k.PublicKeyFile,
+			})
+		}
+
+		keySet, err := jwtkeys.LoadKeySet(cfg.SigningKeyID, files)
+		if err != nil {
+			log.Fatalf("failed to load jwt keys: %v", err)
+		}
+
+		p.jwtKeySet = keySet
+	}
+
+	return p.jwtKeySet
+}
+
 func (p *serviceProvider) Usecases(ctx context.Context) *usecase.Usecase {
 	if p.usecases == nil {
-		secret := p.Config().JWT.Secret
-		ttl := time.Duration(p.Config().JWT.TTLMin) * time.Minute
-
-		p.usecases = usecase.NewUsecase(p.Repositories(ctx), p.TxManager(ctx), p.PasswordManager(), secret, ttl)
+		authConfig := auth.Config{
+			SecretKey:            p.Config().JWT.Secret,
+			AccessTokenTTL:       time.Duration(p.Config().JWT.TTLMin) * time.Minute,
+			RefreshTokenTTL:      time.Duration(p.Config().JWT.RefreshTTLMin) * time.Minute,
+			DenylistSyncInterval: time.Duration(p.Config().JWT.DenylistSyncSec) * time.Second,
+			AutoRegister:         p.Config().Auth.AutoRegister,
+			LoginThrottle: auth.LoginThrottleConfig{
+				FreeAttempts:       p.Config().LoginThrottle.FreeAttempts,
+				LockoutThreshold:   p.Config().LoginThrottle.LockoutThreshold,
+				IPFreeAttempts:     p.Config().LoginThrottle.IPFreeAttempts,
+				IPLockoutThreshold: p.Config().LoginThrottle.IPLockoutThreshold,
+				BackoffBase:        time.Duration(p.Config().LoginThrottle.BackoffBaseMs) * time.Millisecond,
+				MaxBackoff:         time.Duration(p.Config().LoginThrottle.MaxBackoffSec) * time.Second,
+				LockoutDuration:    time.Duration(p.Config().LoginThrottle.LockoutMin) * time.Minute,
+				FailureWindow:      time.Duration(p.Config().LoginThrottle.FailureWindowMin) * time.Minute,
+			},
+			PasswordPolicy: password.Policy{
+				MinLength:        p.Config().Password.PolicyMinLength,
+				MaxLength:        p.Config().Password.PolicyMaxLength,
+				RequireMixedCase: p.Config().Password.PolicyRequireMixedCase,
+				RequireDigit:     p.Config().Password.PolicyRequireDigit,
+				RequireSpecial:   p.Config().Password.PolicyRequireSpecial,
+			},
+			PasswordResetTTL:  time.Duration(p.Config().Password.ResetTTLMin) * time.Minute,
+			TOTPIssuer:        p.Config().Auth.TOTPIssuer,
+			TreasuryUsername:  p.Config().Auth.TreasuryUsername,
+			ReservedUsernames: p.Config().Auth.ReservedUsernames,
+		}
+
+		// nil-указатель нельзя класть в интерфейс, иначе usecase не заметит отсутствие ключей.
+		if keySet := p.JWTKeySet(); keySet != nil {
+			authConfig.KeySet = keySet
+		}
+
+		operationConfig := operation.Config{
+			MaxPurchaseQuantity: p.Config().Shop.MaxPurchaseQuantity,
+		}
+
+		productConfig := product.Config{
+			LowStockThreshold: p.Config().Shop.LowStockThreshold,
+		}
+
+		refundConfig := refund.Config{
+			Window: time.Duration(p.Config().Shop.RefundWindowHours) * time.Hour,
+		}
+
+		idempotencyConfig := idempotency.Config{
+			KeyTTL: time.Duration(p.Config().Idempotency.KeyTTLMin) * time.Minute,
+		}
+
+		p.usecases = usecase.NewUsecase(p.Repositories(ctx), p.TxManager(ctx), p.PasswordManager(),
+			authConfig, operationConfig, productConfig, refundConfig, idempotencyConfig)
 	}
 
 	return p.usecases
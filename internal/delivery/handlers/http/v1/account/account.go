@@ -3,11 +3,14 @@ package account
 
 import (
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/labstack/echo"
 	"github.com/resueman/merch-store/internal/delivery/ctxkey"
 	"github.com/resueman/merch-store/internal/delivery/handlers/http/v1/converter"
 	"github.com/resueman/merch-store/internal/delivery/handlers/http/v1/response"
+	"github.com/resueman/merch-store/internal/delivery/middleware"
 	"github.com/resueman/merch-store/internal/model"
 	"github.com/resueman/merch-store/internal/usecase"
 )
@@ -19,7 +22,8 @@ type AccountHandler struct {
 func NewAccountHandler(e *echo.Echo, usecase usecase.Account, m ...echo.MiddlewareFunc) *AccountHandler {
 	h := &AccountHandler{accountUsecase: usecase}
 
-	e.GET("api/info", h.GetInfo, m...)
+	e.GET("api/info", h.GetInfo, middleware.WithScopes(m, model.ScopeInfoRead)...)
+	e.GET("api/history", h.GetHistory, middleware.WithScopes(m, model.ScopeInfoRead)...)
 
 	return h
 }
@@ -46,3 +50,64 @@ func (h *AccountHandler) GetInfo(c echo.Context) error {
 
 	return response.SendOk(c, dto)
 }
+
+// Разбирает необязательный параметр запроса с датой в формате RFC 3339. nil означает, что параметр не передан.
+func queryTime(c echo.Context, name string) (*time.Time, bool) {
+	value := c.QueryParam(name)
+	if value == "" {
+		return nil, true
+	}
+
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, false
+	}
+
+	return &parsed, true
+}
+
+// (GET /api/history): постраничная история операций с фильтрами по типу, направлению,
+// второму участнику перевода и периоду.
+func (h *AccountHandler) GetHistory(c echo.Context) error {
+	ctx := c.Request().Context()
+	claims, ok := ctx.Value(ctxkey.ClaimsKey).(model.Claims)
+	if !ok {
+		return response.SendHandlerError(c, http.StatusUnauthorized, response.ErrInvalidClaimsMessage)
+	}
+
+	filter := model.HistoryFilter{
+		Type:         model.HistoryOperationType(c.QueryParam("type")),
+		Direction:    model.HistoryDirection(c.QueryParam("direction")),
+		Counterparty: c.QueryParam("counterparty"),
+		Cursor:       c.QueryParam("cursor"),
+	}
+
+	if filter.From, ok = queryTime(c, "from"); !ok {
+		return response.SendHandlerError(c, http.StatusBadRequest, "from must be an RFC 3339 date-time")
+	}
+
+	if filter.To, ok = queryTime(c, "to"); !ok {
+		return response.SendHandlerError(c, http.StatusBadRequest, "to must be an RFC 3339 date-time")
+	}
+
+	if limit := c.QueryParam("limit"); limit != "" {
+		parsed, err := strconv.Atoi(limit)
+		if err != nil {
+			return response.SendHandlerError(c, http.StatusBadRequest, "limit must be an integer")
+		}
+
+		// Явно переданный ноль не должен превращаться в размер страницы по умолчанию.
+		if parsed <= 0 {
+			return response.SendHandlerError(c, http.StatusBadRequest, response.ErrInvalidPaginationMessage)
+		}
+
+		filter.Limit = parsed
+	}
+
+	page, err := h.accountUsecase.GetHistory(ctx, claims, filter)
+	if err != nil {
+		return response.SendUsecaseError(c, err)
+	}
+
+	return response.SendOk(c, converter.ConvertHistoryPage(page))
+}
@@ -6,12 +6,14 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/labstack/echo"
 	v1 "github.com/resueman/merch-store/internal/api/v1"
 	"github.com/resueman/merch-store/internal/delivery/ctxkey"
 	"github.com/resueman/merch-store/internal/delivery/handlers/http/v1/converter"
 	"github.com/resueman/merch-store/internal/model"
+	"github.com/resueman/merch-store/internal/usecase/apperrors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -26,6 +28,13 @@ func (m *MockAccountUsecase) GetInfo(ctx context.Context, claims model.Claims) (
 	return &info, err
 }
 
+func (m *MockAccountUsecase) GetHistory(ctx context.Context, claims model.Claims, filter model.HistoryFilter) (
+	model.HistoryPage, error,
+) {
+	args := m.Called(ctx, claims, filter)
+	return args.Get(0).(model.HistoryPage), args.Error(1)
+}
+
 func TestGetInfo(t *testing.T) {
 	e := echo.New()
 	mockUsecase := &MockAccountUsecase{}
@@ -34,7 +43,9 @@ func TestGetInfo(t *testing.T) {
 	t.Run("successful get info", func(t *testing.T) {
 		claims := model.Claims{UserID: 123}
 		info := model.AccountInfo{
-			Balance: 100,
+			SnapshotAt:      time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC),
+			LastOperationID: 42,
+			Balance:         100,
 			Inventory: []model.Inventory{
 				{Name: "pen", Quantity: 10},
 				{Name: "book", Quantity: 5},
@@ -67,6 +78,7 @@ func TestGetInfo(t *testing.T) {
 		err = json.NewDecoder(rec.Body).Decode(&response)
 		assert.NoError(t, err)
 		assert.Equal(t, converter.ConvertAccountInfoToInfoResponse(&info), response)
+		assert.Equal(t, 42, *response.LastOperationId)
 	})
 
 	t.Run("unauthorized", func(t *testing.T) {
@@ -79,3 +91,102 @@ func TestGetInfo(t *testing.T) {
 		assert.Equal(t, http.StatusUnauthorized, rec.Code)
 	})
 }
+
+func TestGetHistory(t *testing.T) {
+	claims := model.Claims{UserID: 123}
+
+	newContext := func(e *echo.Echo, target string) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetRequest(c.Request().WithContext(context.WithValue(c.Request().Context(), ctxkey.ClaimsKey, claims)))
+
+		return c, rec
+	}
+
+	t.Run("passes filters and returns the page", func(t *testing.T) {
+		e := echo.New()
+		mockUsecase := &MockAccountUsecase{}
+		handler := NewAccountHandler(e, mockUsecase)
+
+		from := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+		filter := model.HistoryFilter{
+			Type:         model.HistoryTransfer,
+			Direction:    model.HistoryIncoming,
+			Counterparty: "bob",
+			From:         &from,
+			Cursor:       "abc",
+			Limit:        2,
+		}
+		page := model.HistoryPage{
+			Items: []model.HistoryEntry{
+				{ID: 7, Type: model.HistoryTransfer, Direction: model.HistoryIncoming, Amount: 50,
+					Counterparty: "bob", CreatedAt: from.Add(time.Hour)},
+				{ID: 5, Type: model.HistoryPurchase, Direction: model.HistoryOutgoing, Amount: 80,
+					ItemName: "cup", Quantity: 4, CreatedAt: from.Add(time.Minute)},
+			},
+			NextCursor: "next",
+			Limit:      2,
+		}
+		mockUsecase.On("GetHistory", mock.Anything, claims, filter).Return(page, nil)
+
+		c, rec := newContext(e, "/api/history?type=transfer&direction=incoming&counterparty=bob"+
+			"&from=2025-03-01T00:00:00Z&cursor=abc&limit=2")
+
+		assert.NoError(t, handler.GetHistory(c))
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		var response v1.HistoryResponse
+		assert.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+		assert.Equal(t, converter.ConvertHistoryPage(page), response)
+		assert.Equal(t, "bob", *response.Items[0].Counterparty)
+		assert.Nil(t, response.Items[0].Item)
+		assert.Equal(t, "cup", *response.Items[1].Item)
+		assert.Nil(t, response.Items[1].Counterparty)
+		assert.Equal(t, "next", *response.NextCursor)
+	})
+
+	t.Run("invalid query parameters", func(t *testing.T) {
+		for _, target := range []string{
+			"/api/history?from=yesterday",
+			"/api/history?to=2025-03-01",
+			"/api/history?limit=ten",
+			"/api/history?limit=0",
+		} {
+			e := echo.New()
+			mockUsecase := &MockAccountUsecase{}
+			handler := NewAccountHandler(e, mockUsecase)
+
+			c, rec := newContext(e, target)
+
+			assert.NoError(t, handler.GetHistory(c))
+			assert.Equal(t, http.StatusBadRequest, rec.Code, target)
+			mockUsecase.AssertNotCalled(t, "GetHistory", mock.Anything, mock.Anything, mock.Anything)
+		}
+	})
+
+	t.Run("usecase validation error", func(t *testing.T) {
+		e := echo.New()
+		mockUsecase := &MockAccountUsecase{}
+		handler := NewAccountHandler(e, mockUsecase)
+
+		mockUsecase.On("GetHistory", mock.Anything, claims, mock.Anything).
+			Return(model.HistoryPage{}, apperrors.ErrInvalidHistoryCursor)
+
+		c, rec := newContext(e, "/api/history?cursor=broken")
+
+		assert.NoError(t, handler.GetHistory(c))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("unauthorized", func(t *testing.T) {
+		e := echo.New()
+		handler := NewAccountHandler(e, &MockAccountUsecase{})
+
+		req := httptest.NewRequest(http.MethodGet, "/api/history", nil)
+		rec := httptest.NewRecorder()
+
+		assert.NoError(t, handler.GetHistory(e.NewContext(req, rec)))
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+}
@@ -0,0 +1,460 @@
+//nolint:wrapcheck
+package admin
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo"
+	dto "github.com/resueman/merch-store/internal/api/v1"
+	"github.com/resueman/merch-store/internal/delivery/ctxkey"
+	"github.com/resueman/merch-store/internal/delivery/handlers/http/v1/converter"
+	"github.com/resueman/merch-store/internal/delivery/handlers/http/v1/response"
+	"github.com/resueman/merch-store/internal/delivery/middleware"
+	"github.com/resueman/merch-store/internal/model"
+	"github.com/resueman/merch-store/internal/usecase"
+)
+
+type AdminHandler struct {
+	authService    usecase.Auth
+	refundService  usecase.Refund
+	productService usecase.Product
+}
+
+// Все маршруты обработчика доступны только администраторам,
+// поэтому в m должны входить AuthMiddleware и проверка роли.
+func NewAdminHandler(e *echo.Echo, authService usecase.Auth, refundService usecase.Refund,
+	productService usecase.Product, idempotency echo.MiddlewareFunc, m ...echo.MiddlewareFunc) *AdminHandler {
+	h := &AdminHandler{authService: authService, refundService: refundService, productService: productService}
+
+	admin := middleware.WithScopes(m, model.ScopeAdmin)
+	write := middleware.WithIdempotency(admin, idempotency)
+
+	e.PUT("/api/admin/users/:username/role", h.SetUserRole, write...)
+	e.DELETE("/api/admin/users/:username/lockout", h.UnlockUser, write...)
+	// Без Idempotency-Key: ответ содержит токен сброса пароля, его нельзя хранить в БД.
+	e.POST("/api/admin/users/:username/password-reset", h.CreatePasswordReset, admin...)
+	e.POST("/api/admin/users/:username/deactivate", h.DeactivateUser, write...)
+	e.POST("/api/admin/users/:username/reactivate", h.ReactivateUser, write...)
+	e.POST("/api/admin/service-accounts", h.CreateServiceAccount, write...)
+	// Без Idempotency-Key: ответ содержит сам API-ключ, его нельзя хранить в БД.
+	e.POST("/api/admin/service-accounts/:username/api-keys", h.CreateAPIKey, admin...)
+	e.GET("/api/admin/service-accounts/:username/api-keys", h.GetAPIKeys, admin...)
+	e.DELETE("/api/admin/api-keys/:id", h.RevokeAPIKey, write...)
+	e.GET("/api/admin/refunds", h.GetRefunds, admin...)
+	e.POST("/api/admin/refunds/:id/approve", h.ApproveRefund, write...)
+	e.POST("/api/admin/refunds/:id/reject", h.RejectRefund, write...)
+	e.POST("/api/admin/products", h.CreateProduct, write...)
+	e.PATCH("/api/admin/products/:item", h.UpdateProduct, write...)
+	e.DELETE("/api/admin/products/:item", h.RetireProduct, write...)
+	e.GET("/api/admin/products/:item/audit", h.GetProductAudit, admin...)
+	e.POST("/api/admin/products/:item/variants", h.CreateProductVariant, write...)
+	e.POST("/api/admin/products/:item/restock", h.Restock, write...)
+	e.GET("/api/admin/products/low-stock", h.GetLowStockProducts, admin...)
+
+	return h
+}
+
+// (PUT /api/admin/users/{username}/role): назначение роли пользователю.
+func (h *AdminHandler) SetUserRole(c echo.Context) error {
+	ctx := c.Request().Context()
+	claims, ok := ctx.Value(ctxkey.ClaimsKey).(model.Claims)
+	if !ok {
+		return response.SendHandlerError(c, http.StatusUnauthorized, response.ErrInvalidClaimsMessage)
+	}
+
+	username := c.Param("username")
+	if username == "" {
+		return response.SendHandlerError(c, http.StatusBadRequest, "username is required")
+	}
+
+	var input dto.SetUserRoleRequest
+	if err := c.Bind(&input); err != nil {
+		return response.SendHandlerError(c, http.StatusBadRequest, response.ErrBindingMessage)
+	}
+
+	if input.Role == "" {
+		return response.SendHandlerError(c, http.StatusBadRequest, "role is required;")
+	}
+
+	roleInput := model.SetUserRoleInput{Username: username, Role: model.Role(input.Role)}
+	if err := h.authService.SetUserRole(ctx, claims, roleInput); err != nil {
+		return response.SendUsecaseError(c, err)
+	}
+
+	return response.SendNoContent(c)
+}
+
+// (DELETE /api/admin/users/{username}/lockout): снятие блокировки входа после неудачных попыток.
+func (h *AdminHandler) UnlockUser(c echo.Context) error {
+	username := c.Param("username")
+	if username == "" {
+		return response.SendHandlerError(c, http.StatusBadRequest, "username is required")
+	}
+
+	if err := h.authService.UnlockUser(c.Request().Context(), username); err != nil {
+		return response.SendUsecaseError(c, err)
+	}
+
+	return response.SendNoContent(c)
+}
+
+// (POST /api/admin/users/{username}/password-reset): выдача одноразового токена сброса пароля.
+// Токен показывается только в этом ответе, администратор передает его пользователю.
+func (h *AdminHandler) CreatePasswordReset(c echo.Context) error {
+	ctx := c.Request().Context()
+	claims, ok := ctx.Value(ctxkey.ClaimsKey).(model.Claims)
+	if !ok {
+		return response.SendHandlerError(c, http.StatusUnauthorized, response.ErrInvalidClaimsMessage)
+	}
+
+	username := c.Param("username")
+	if username == "" {
+		return response.SendHandlerError(c, http.StatusBadRequest, "username is required")
+	}
+
+	reset, err := h.authService.CreatePasswordReset(ctx, claims, username)
+	if err != nil {
+		return response.SendUsecaseError(c, err)
+	}
+
+	dto := dto.PasswordResetResponse{ResetToken: reset.Token, ExpiresAt: reset.ExpiresAt}
+
+	return response.SendOk(c, dto)
+}
+
+// (POST /api/admin/users/{username}/deactivate): деактивация пользователя, например при увольнении.
+// Тело запроса необязательно, по флагу sweepBalance остаток монет переводится на казначейский счет.
+func (h *AdminHandler) DeactivateUser(c echo.Context) error {
+	ctx := c.Request().Context()
+	claims, ok := ctx.Value(ctxkey.ClaimsKey).(model.Claims)
+	if !ok {
+		return response.SendHandlerError(c, http.StatusUnauthorized, response.ErrInvalidClaimsMessage)
+	}
+
+	username := c.Param("username")
+	if username == "" {
+		return response.SendHandlerError(c, http.StatusBadRequest, "username is required")
+	}
+
+	var input dto.DeactivateUserRequest
+	if c.Request().ContentLength != 0 {
+		if err := c.Bind(&input); err != nil {
+			return response.SendHandlerError(c, http.StatusBadRequest, response.ErrBindingMessage)
+		}
+	}
+
+	deactivateInput := converter.ConvertDeactivateUserRequestToInput(username, &input)
+
+	swept, err := h.authService.DeactivateUser(ctx, claims, deactivateInput)
+	if err != nil {
+		return response.SendUsecaseError(c, err)
+	}
+
+	return response.SendOk(c, dto.DeactivateUserResponse{SweptAmount: swept})
+}
+
+// (POST /api/admin/users/{username}/reactivate): возврат доступа деактивированному пользователю.
+func (h *AdminHandler) ReactivateUser(c echo.Context) error {
+	username := c.Param("username")
+	if username == "" {
+		return response.SendHandlerError(c, http.StatusBadRequest, "username is required")
+	}
+
+	if err := h.authService.ReactivateUser(c.Request().Context(), username); err != nil {
+		return response.SendUsecaseError(c, err)
+	}
+
+	return response.SendNoContent(c)
+}
+
+// (POST /api/admin/service-accounts): создание сервисного аккаунта для бота.
+func (h *AdminHandler) CreateServiceAccount(c echo.Context) error {
+	var input dto.CreateServiceAccountRequest
+	if err := c.Bind(&input); err != nil {
+		return response.SendHandlerError(c, http.StatusBadRequest, response.ErrBindingMessage)
+	}
+
+	if input.Username == "" {
+		return response.SendHandlerError(c, http.StatusBadRequest, "username is required")
+	}
+
+	if err := h.authService.CreateServiceAccount(c.Request().Context(), input.Username); err != nil {
+		return response.SendUsecaseError(c, err)
+	}
+
+	return response.SendNoContent(c)
+}
+
+// (POST /api/admin/service-accounts/{username}/api-keys): выпуск API-ключа сервисного аккаунта.
+// Значение ключа показывается только в этом ответе.
+func (h *AdminHandler) CreateAPIKey(c echo.Context) error {
+	ctx := c.Request().Context()
+	claims, ok := ctx.Value(ctxkey.ClaimsKey).(model.Claims)
+	if !ok {
+		return response.SendHandlerError(c, http.StatusUnauthorized, response.ErrInvalidClaimsMessage)
+	}
+
+	username := c.Param("username")
+	if username == "" {
+		return response.SendHandlerError(c, http.StatusBadRequest, "username is required")
+	}
+
+	var input dto.CreateApiKeyRequest
+	if err := c.Bind(&input); err != nil {
+		return response.SendHandlerError(c, http.StatusBadRequest, response.ErrBindingMessage)
+	}
+
+	if input.Name == "" {
+		return response.SendHandlerError(c, http.StatusBadRequest, "name is required")
+	}
+
+	key, err := h.authService.CreateAPIKey(ctx, claims, converter.ConvertCreateAPIKeyRequestToInput(username, &input))
+	if err != nil {
+		return response.SendUsecaseError(c, err)
+	}
+
+	return response.SendOk(c, converter.ConvertAPIKey(key))
+}
+
+// (GET /api/admin/service-accounts/{username}/api-keys): список ключей сервисного аккаунта.
+func (h *AdminHandler) GetAPIKeys(c echo.Context) error {
+	username := c.Param("username")
+	if username == "" {
+		return response.SendHandlerError(c, http.StatusBadRequest, "username is required")
+	}
+
+	keys, err := h.authService.GetAPIKeys(c.Request().Context(), username)
+	if err != nil {
+		return response.SendUsecaseError(c, err)
+	}
+
+	dto := make([]dto.ApiKey, 0, len(keys))
+	for _, k := range keys {
+		dto = append(dto, converter.ConvertAPIKey(k))
+	}
+
+	return response.SendOk(c, dto)
+}
+
+// (DELETE /api/admin/api-keys/{id}): отзыв API-ключа. Отозванный ключ сразу перестает приниматься.
+func (h *AdminHandler) RevokeAPIKey(c echo.Context) error {
+	keyID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || keyID <= 0 {
+		return response.SendHandlerError(c, http.StatusBadRequest, "invalid api key id")
+	}
+
+	if err = h.authService.RevokeAPIKey(c.Request().Context(), keyID); err != nil {
+		return response.SendUsecaseError(c, err)
+	}
+
+	return response.SendNoContent(c)
+}
+
+// (GET /api/admin/refunds): заявки на возврат с заданным статусом, по умолчанию ожидающие решения.
+func (h *AdminHandler) GetRefunds(c echo.Context) error {
+	status := model.RefundRequested
+	if param := c.QueryParam("status"); param != "" {
+		status = model.RefundStatus(param)
+	}
+
+	refunds, err := h.refundService.GetRefundsByStatus(c.Request().Context(), status)
+	if err != nil {
+		return response.SendUsecaseError(c, err)
+	}
+
+	return response.SendOk(c, converter.ConvertRefunds(refunds))
+}
+
+// (POST /api/admin/refunds/{id}/approve): одобрение заявки, сумма возврата начисляется покупателю.
+func (h *AdminHandler) ApproveRefund(c echo.Context) error {
+	return h.decideRefund(c, h.refundService.ApproveRefund)
+}
+
+// (POST /api/admin/refunds/{id}/reject): отказ в возврате.
+func (h *AdminHandler) RejectRefund(c echo.Context) error {
+	return h.decideRefund(c, h.refundService.RejectRefund)
+}
+
+func (h *AdminHandler) decideRefund(c echo.Context,
+	decide func(ctx context.Context, claims model.Claims, refundID int) (model.Refund, error),
+) error {
+	ctx := c.Request().Context()
+	claims, ok := ctx.Value(ctxkey.ClaimsKey).(model.Claims)
+	if !ok {
+		return response.SendHandlerError(c, http.StatusUnauthorized, response.ErrInvalidClaimsMessage)
+	}
+
+	refundID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || refundID <= 0 {
+		return response.SendHandlerError(c, http.StatusBadRequest, "invalid refund id")
+	}
+
+	refund, err := decide(ctx, claims, refundID)
+	if err != nil {
+		return response.SendUsecaseError(c, err)
+	}
+
+	return response.SendOk(c, converter.ConvertRefund(refund))
+}
+
+// (POST /api/admin/products/{item}/variants): добавление варианта товара, например размера.
+func (h *AdminHandler) CreateProductVariant(c echo.Context) error {
+	ctx := c.Request().Context()
+	claims, ok := ctx.Value(ctxkey.ClaimsKey).(model.Claims)
+	if !ok {
+		return response.SendHandlerError(c, http.StatusUnauthorized, response.ErrInvalidClaimsMessage)
+	}
+
+	item := c.Param("item")
+	if item == "" {
+		return response.SendHandlerError(c, http.StatusBadRequest, "item is required")
+	}
+
+	var input dto.CreateProductVariantRequest
+	if err := c.Bind(&input); err != nil {
+		return response.SendHandlerError(c, http.StatusBadRequest, response.ErrBindingMessage)
+	}
+
+	variant, err := h.productService.CreateProductVariant(ctx, claims, item, model.CreateProductVariantInput{
+		Name:  input.Name,
+		SKU:   input.Sku,
+		Price: input.Price,
+		Stock: input.Stock,
+	})
+	if err != nil {
+		return response.SendUsecaseError(c, err)
+	}
+
+	return response.SendOk(c, converter.ConvertProductVariant(variant))
+}
+
+// (POST /api/admin/products/{item}/restock): пополнение склада.
+func (h *AdminHandler) Restock(c echo.Context) error {
+	ctx := c.Request().Context()
+	claims, ok := ctx.Value(ctxkey.ClaimsKey).(model.Claims)
+	if !ok {
+		return response.SendHandlerError(c, http.StatusUnauthorized, response.ErrInvalidClaimsMessage)
+	}
+
+	item := c.Param("item")
+	if item == "" {
+		return response.SendHandlerError(c, http.StatusBadRequest, "item is required")
+	}
+
+	var input dto.RestockRequest
+	if err := c.Bind(&input); err != nil {
+		return response.SendHandlerError(c, http.StatusBadRequest, response.ErrBindingMessage)
+	}
+
+	if input.Quantity <= 0 {
+		return response.SendHandlerError(c, http.StatusBadRequest, response.ErrInvalidQuantityMessage)
+	}
+
+	variant := ""
+	if input.Variant != nil {
+		variant = *input.Variant
+	}
+
+	product, err := h.productService.Restock(ctx, claims, item, variant, input.Quantity)
+	if err != nil {
+		return response.SendUsecaseError(c, err)
+	}
+
+	return response.SendOk(c, converter.ConvertProduct(product))
+}
+
+// (GET /api/admin/products/low-stock): товары, остаток которых не больше порога shop.lowStockThreshold.
+func (h *AdminHandler) GetLowStockProducts(c echo.Context) error {
+	products, err := h.productService.GetLowStockProducts(c.Request().Context())
+	if err != nil {
+		return response.SendUsecaseError(c, err)
+	}
+
+	return response.SendOk(c, converter.ConvertProducts(products))
+}
+
+// (POST /api/admin/products): добавление товара в каталог.
+func (h *AdminHandler) CreateProduct(c echo.Context) error {
+	ctx := c.Request().Context()
+	claims, ok := ctx.Value(ctxkey.ClaimsKey).(model.Claims)
+	if !ok {
+		return response.SendHandlerError(c, http.StatusUnauthorized, response.ErrInvalidClaimsMessage)
+	}
+
+	var input dto.CreateProductRequest
+	if err := c.Bind(&input); err != nil {
+		return response.SendHandlerError(c, http.StatusBadRequest, response.ErrBindingMessage)
+	}
+
+	product, err := h.productService.CreateProduct(ctx, claims, converter.ConvertCreateProductRequest(input))
+	if err != nil {
+		return response.SendUsecaseError(c, err)
+	}
+
+	return response.SendOk(c, converter.ConvertProduct(product))
+}
+
+// (PATCH /api/admin/products/{item}): изменение цены и описания товара.
+func (h *AdminHandler) UpdateProduct(c echo.Context) error {
+	ctx := c.Request().Context()
+	claims, ok := ctx.Value(ctxkey.ClaimsKey).(model.Claims)
+	if !ok {
+		return response.SendHandlerError(c, http.StatusUnauthorized, response.ErrInvalidClaimsMessage)
+	}
+
+	item := c.Param("item")
+	if item == "" {
+		return response.SendHandlerError(c, http.StatusBadRequest, "item is required")
+	}
+
+	var input dto.UpdateProductRequest
+	if err := c.Bind(&input); err != nil {
+		return response.SendHandlerError(c, http.StatusBadRequest, response.ErrBindingMessage)
+	}
+
+	if input.Price == nil && input.Description == nil {
+		return response.SendHandlerError(c, http.StatusBadRequest, "price or description is required")
+	}
+
+	product, err := h.productService.UpdateProduct(ctx, claims, item, model.UpdateProductInput{
+		Price:       input.Price,
+		Description: input.Description,
+	})
+	if err != nil {
+		return response.SendUsecaseError(c, err)
+	}
+
+	return response.SendOk(c, converter.ConvertProduct(product))
+}
+
+// (DELETE /api/admin/products/{item}): снятие товара с продажи.
+func (h *AdminHandler) RetireProduct(c echo.Context) error {
+	ctx := c.Request().Context()
+	claims, ok := ctx.Value(ctxkey.ClaimsKey).(model.Claims)
+	if !ok {
+		return response.SendHandlerError(c, http.StatusUnauthorized, response.ErrInvalidClaimsMessage)
+	}
+
+	item := c.Param("item")
+	if item == "" {
+		return response.SendHandlerError(c, http.StatusBadRequest, "item is required")
+	}
+
+	if err := h.productService.RetireProduct(ctx, claims, item); err != nil {
+		return response.SendUsecaseError(c, err)
+	}
+
+	return response.SendNoContent(c)
+}
+
+// (GET /api/admin/products/{item}/audit): журнал изменений товара, сначала новые записи.
+func (h *AdminHandler) GetProductAudit(c echo.Context) error {
+	entries, err := h.productService.GetProductAudit(c.Request().Context(), c.Param("item"))
+	if err != nil {
+		return response.SendUsecaseError(c, err)
+	}
+
+	return response.SendOk(c, converter.ConvertProductAudit(entries))
+}
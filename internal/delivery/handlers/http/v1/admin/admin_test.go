@@ -0,0 +1,722 @@
+package admin
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo"
+	"github.com/resueman/merch-store/internal/delivery/ctxkey"
+	"github.com/resueman/merch-store/internal/delivery/handlers/http/v1/response"
+	"github.com/resueman/merch-store/internal/model"
+	"github.com/resueman/merch-store/internal/usecase"
+	"github.com/resueman/merch-store/internal/usecase/apperrors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// Обработчику нужна только часть методов usecase.Auth, остальные не вызываются.
+type MockAuthService struct {
+	usecase.Auth
+	mock.Mock
+}
+
+// Аналогично, из usecase.Refund нужны только методы, которые вызывает обработчик.
+type MockRefundService struct {
+	usecase.Refund
+	mock.Mock
+}
+
+func (m *MockRefundService) ApproveRefund(ctx context.Context, claims model.Claims, refundID int) (
+	model.Refund, error,
+) {
+	args := m.Called(ctx, claims, refundID)
+	return args.Get(0).(model.Refund), args.Error(1)
+}
+
+func (m *MockRefundService) GetRefundsByStatus(ctx context.Context, status model.RefundStatus) ([]model.Refund, error) {
+	args := m.Called(ctx, status)
+	return args.Get(0).([]model.Refund), args.Error(1)
+}
+
+type MockProductService struct {
+	usecase.Product
+	mock.Mock
+}
+
+func (m *MockProductService) Restock(ctx context.Context, claims model.Claims, itemName string, variant string,
+	quantity int,
+) (model.Product, error) {
+	args := m.Called(ctx, claims, itemName, variant, quantity)
+	return args.Get(0).(model.Product), args.Error(1)
+}
+
+func (m *MockProductService) CreateProduct(ctx context.Context, claims model.Claims,
+	input model.CreateProductInput,
+) (model.Product, error) {
+	args := m.Called(ctx, claims, input)
+	return args.Get(0).(model.Product), args.Error(1)
+}
+
+func (m *MockProductService) UpdateProduct(ctx context.Context, claims model.Claims, name string,
+	input model.UpdateProductInput,
+) (model.Product, error) {
+	args := m.Called(ctx, claims, name, input)
+	return args.Get(0).(model.Product), args.Error(1)
+}
+
+func (m *MockProductService) RetireProduct(ctx context.Context, claims model.Claims, name string) error {
+	args := m.Called(ctx, claims, name)
+	return args.Error(0)
+}
+
+func (m *MockProductService) CreateProductVariant(ctx context.Context, claims model.Claims, itemName string,
+	input model.CreateProductVariantInput,
+) (model.ProductVariant, error) {
+	args := m.Called(ctx, claims, itemName, input)
+	return args.Get(0).(model.ProductVariant), args.Error(1)
+}
+
+func (m *MockAuthService) SetUserRole(ctx context.Context, claims model.Claims, input model.SetUserRoleInput) error {
+	args := m.Called(ctx, claims, input)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) UnlockUser(ctx context.Context, username string) error {
+	args := m.Called(ctx, username)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) DeactivateUser(ctx context.Context, claims model.Claims,
+	input model.DeactivateUserInput,
+) (int, error) {
+	args := m.Called(ctx, claims, input)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockAuthService) ReactivateUser(ctx context.Context, username string) error {
+	args := m.Called(ctx, username)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) CreatePasswordReset(ctx context.Context, claims model.Claims,
+	username string,
+) (model.PasswordResetToken, error) {
+	args := m.Called(ctx, claims, username)
+	return args.Get(0).(model.PasswordResetToken), args.Error(1)
+}
+
+func (m *MockAuthService) CreateServiceAccount(ctx context.Context, username string) error {
+	args := m.Called(ctx, username)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) CreateAPIKey(ctx context.Context, claims model.Claims,
+	input model.CreateAPIKeyInput,
+) (model.APIKey, error) {
+	args := m.Called(ctx, claims, input)
+	return args.Get(0).(model.APIKey), args.Error(1)
+}
+
+func (m *MockAuthService) RevokeAPIKey(ctx context.Context, keyID int) error {
+	args := m.Called(ctx, keyID)
+	return args.Error(0)
+}
+
+func newAdminContext(e *echo.Echo, method, target, body, username string) (echo.Context, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req = req.WithContext(context.WithValue(req.Context(), ctxkey.ClaimsKey, model.Claims{UserID: 1, Role: model.RoleAdmin}))
+	rec := httptest.NewRecorder()
+
+	ctx := e.NewContext(req, rec)
+	ctx.SetParamNames("username")
+	ctx.SetParamValues(username)
+
+	return ctx, rec
+}
+
+func TestNewAdminHandler_Idempotency(t *testing.T) {
+	e := echo.New()
+	mockAuthService := new(MockAuthService)
+	mockProductService := new(MockProductService)
+	replayed := func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			return c.NoContent(http.StatusNotModified)
+		}
+	}
+	NewAdminHandler(e, mockAuthService, nil, mockProductService, replayed)
+
+	serve := func(target string) *httptest.ResponseRecorder {
+		claims := model.Claims{UserID: 1, Role: model.RoleAdmin, Scopes: []model.Scope{model.ScopeAdmin}}
+		req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(""))
+		req = req.WithContext(context.WithValue(req.Context(), ctxkey.ClaimsKey, claims))
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	t.Run("Restock accepts Idempotency-Key", func(t *testing.T) {
+		rec := serve("/api/admin/products/pink-hoody/restock")
+
+		assert.Equal(t, http.StatusNotModified, rec.Code)
+		mockProductService.AssertNotCalled(t, "Restock")
+	})
+
+	t.Run("Password reset does not store the token", func(t *testing.T) {
+		mockAuthService.
+			On("CreatePasswordReset", mock.Anything, mock.Anything, "bob").
+			Return(model.PasswordResetToken{Token: "reset"}, nil)
+
+		rec := serve("/api/admin/users/bob/password-reset")
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"resetToken":"reset"`)
+	})
+}
+
+func TestSetUserRole(t *testing.T) {
+	e := echo.New()
+	mockAuthService := new(MockAuthService)
+	handler := NewAdminHandler(e, mockAuthService, nil, nil, nil)
+	admin := model.Claims{UserID: 1, Role: model.RoleAdmin}
+
+	t.Run("Successful role change", func(t *testing.T) {
+		mockAuthService.
+			On("SetUserRole", mock.Anything, admin, model.SetUserRoleInput{Username: "bob", Role: model.RoleAdmin}).
+			Return(nil)
+
+		ctx, rec := newAdminContext(e, http.MethodPut, "/api/admin/users/bob/role", `{"role":"admin"}`, "bob")
+
+		if assert.NoError(t, handler.SetUserRole(ctx)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+		}
+	})
+
+	t.Run("Invalid role", func(t *testing.T) {
+		mockAuthService.
+			On("SetUserRole", mock.Anything, admin, model.SetUserRoleInput{Username: "bob", Role: "root"}).
+			Return(apperrors.ErrInvalidRole)
+
+		ctx, rec := newAdminContext(e, http.MethodPut, "/api/admin/users/bob/role", `{"role":"root"}`, "bob")
+
+		if assert.NoError(t, handler.SetUserRole(ctx)) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.Contains(t, rec.Body.String(), response.ErrInvalidRoleMessage)
+		}
+	})
+
+	t.Run("Missing role", func(t *testing.T) {
+		ctx, rec := newAdminContext(e, http.MethodPut, "/api/admin/users/bob/role", `{}`, "bob")
+
+		if assert.NoError(t, handler.SetUserRole(ctx)) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.Contains(t, rec.Body.String(), "role is required;")
+		}
+	})
+}
+
+func TestUnlockUser(t *testing.T) {
+	e := echo.New()
+	mockAuthService := new(MockAuthService)
+	handler := NewAdminHandler(e, mockAuthService, nil, nil, nil)
+
+	t.Run("Successful unlock", func(t *testing.T) {
+		mockAuthService.On("UnlockUser", mock.Anything, "bob").Return(nil)
+
+		ctx, rec := newAdminContext(e, http.MethodDelete, "/api/admin/users/bob/lockout", "", "bob")
+
+		if assert.NoError(t, handler.UnlockUser(ctx)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+		}
+	})
+
+	t.Run("Missing username", func(t *testing.T) {
+		ctx, rec := newAdminContext(e, http.MethodDelete, "/api/admin/users//lockout", "", "")
+
+		if assert.NoError(t, handler.UnlockUser(ctx)) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+		}
+	})
+}
+
+func TestCreatePasswordReset(t *testing.T) {
+	e := echo.New()
+	mockAuthService := new(MockAuthService)
+	handler := NewAdminHandler(e, mockAuthService, nil, nil, nil)
+	admin := model.Claims{UserID: 1, Role: model.RoleAdmin}
+
+	t.Run("Successful reset token", func(t *testing.T) {
+		expiresAt := time.Date(2025, 3, 6, 12, 0, 0, 0, time.UTC)
+		mockAuthService.
+			On("CreatePasswordReset", mock.Anything, admin, "bob").
+			Return(model.PasswordResetToken{Token: "reset", ExpiresAt: expiresAt}, nil)
+
+		ctx, rec := newAdminContext(e, http.MethodPost, "/api/admin/users/bob/password-reset", "", "bob")
+
+		if assert.NoError(t, handler.CreatePasswordReset(ctx)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Contains(t, rec.Body.String(), `"resetToken":"reset"`)
+			assert.Contains(t, rec.Body.String(), `"expiresAt":"2025-03-06T12:00:00Z"`)
+		}
+	})
+
+	t.Run("Unknown user", func(t *testing.T) {
+		mockAuthService.
+			On("CreatePasswordReset", mock.Anything, admin, "ghost").
+			Return(model.PasswordResetToken{}, apperrors.ErrUserNotFound)
+
+		ctx, rec := newAdminContext(e, http.MethodPost, "/api/admin/users/ghost/password-reset", "", "ghost")
+
+		if assert.NoError(t, handler.CreatePasswordReset(ctx)) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.Contains(t, rec.Body.String(), response.ErrUserNotFoundMessage)
+		}
+	})
+}
+
+func TestDeactivateUser(t *testing.T) {
+	e := echo.New()
+	mockAuthService := new(MockAuthService)
+	handler := NewAdminHandler(e, mockAuthService, nil, nil, nil)
+	admin := model.Claims{UserID: 1, Role: model.RoleAdmin}
+
+	t.Run("Deactivation with sweep", func(t *testing.T) {
+		mockAuthService.
+			On("DeactivateUser", mock.Anything, admin, model.DeactivateUserInput{Username: "bob", SweepBalance: true}).
+			Return(750, nil)
+
+		body := `{"sweepBalance":true}`
+		ctx, rec := newAdminContext(e, http.MethodPost, "/api/admin/users/bob/deactivate", body, "bob")
+
+		if assert.NoError(t, handler.DeactivateUser(ctx)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Contains(t, rec.Body.String(), `"sweptAmount":750`)
+		}
+	})
+
+	t.Run("Deactivation without body", func(t *testing.T) {
+		mockAuthService.
+			On("DeactivateUser", mock.Anything, admin, model.DeactivateUserInput{Username: "carol"}).
+			Return(0, nil)
+
+		ctx, rec := newAdminContext(e, http.MethodPost, "/api/admin/users/carol/deactivate", "", "carol")
+
+		if assert.NoError(t, handler.DeactivateUser(ctx)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+		}
+	})
+
+	t.Run("Treasury not configured", func(t *testing.T) {
+		mockAuthService.
+			On("DeactivateUser", mock.Anything, admin, model.DeactivateUserInput{Username: "dave", SweepBalance: true}).
+			Return(0, apperrors.ErrTreasuryNotConfigured)
+
+		body := `{"sweepBalance":true}`
+		ctx, rec := newAdminContext(e, http.MethodPost, "/api/admin/users/dave/deactivate", body, "dave")
+
+		if assert.NoError(t, handler.DeactivateUser(ctx)) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.Contains(t, rec.Body.String(), response.ErrTreasuryNotConfiguredMessage)
+		}
+	})
+}
+
+func TestReactivateUser(t *testing.T) {
+	e := echo.New()
+	mockAuthService := new(MockAuthService)
+	handler := NewAdminHandler(e, mockAuthService, nil, nil, nil)
+
+	mockAuthService.On("ReactivateUser", mock.Anything, "bob").Return(nil)
+
+	ctx, rec := newAdminContext(e, http.MethodPost, "/api/admin/users/bob/reactivate", "", "bob")
+
+	if assert.NoError(t, handler.ReactivateUser(ctx)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+	}
+}
+
+func TestCreateServiceAccount(t *testing.T) {
+	e := echo.New()
+	mockAuthService := new(MockAuthService)
+	handler := NewAdminHandler(e, mockAuthService, nil, nil, nil)
+
+	t.Run("Successful creation", func(t *testing.T) {
+		mockAuthService.On("CreateServiceAccount", mock.Anything, "bot").Return(nil)
+
+		ctx, rec := newAdminContext(e, http.MethodPost, "/api/admin/service-accounts", `{"username":"bot"}`, "")
+
+		if assert.NoError(t, handler.CreateServiceAccount(ctx)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+		}
+	})
+
+	t.Run("Username taken", func(t *testing.T) {
+		mockAuthService.On("CreateServiceAccount", mock.Anything, "bob").Return(apperrors.ErrUserAlreadyExists)
+
+		ctx, rec := newAdminContext(e, http.MethodPost, "/api/admin/service-accounts", `{"username":"bob"}`, "")
+
+		if assert.NoError(t, handler.CreateServiceAccount(ctx)) {
+			assert.Equal(t, http.StatusConflict, rec.Code)
+		}
+	})
+}
+
+func TestCreateAPIKey(t *testing.T) {
+	e := echo.New()
+	mockAuthService := new(MockAuthService)
+	handler := NewAdminHandler(e, mockAuthService, nil, nil, nil)
+	admin := model.Claims{UserID: 1, Role: model.RoleAdmin}
+
+	t.Run("Key is returned once", func(t *testing.T) {
+		input := model.CreateAPIKeyInput{Username: "bot", Name: "ci", Scopes: []model.Scope{model.ScopeCoinsSend}}
+		key := model.APIKey{ID: 3, Name: "ci", Prefix: "msk_abcdefgh", Key: "msk_abcdefghsecret",
+			Scopes: []model.Scope{model.ScopeCoinsSend}}
+		mockAuthService.On("CreateAPIKey", mock.Anything, admin, input).Return(key, nil)
+
+		body := `{"name":"ci","scopes":["coins:send"]}`
+		ctx, rec := newAdminContext(e, http.MethodPost, "/api/admin/service-accounts/bot/api-keys", body, "bot")
+
+		if assert.NoError(t, handler.CreateAPIKey(ctx)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Contains(t, rec.Body.String(), `"key":"msk_abcdefghsecret"`)
+			assert.Contains(t, rec.Body.String(), `"scopes":["coins:send"]`)
+		}
+	})
+
+	t.Run("Not a service account", func(t *testing.T) {
+		input := model.CreateAPIKeyInput{Username: "bob", Name: "ci"}
+		mockAuthService.On("CreateAPIKey", mock.Anything, admin, input).
+			Return(model.APIKey{}, apperrors.ErrNotServiceAccount)
+
+		ctx, rec := newAdminContext(e, http.MethodPost, "/api/admin/service-accounts/bob/api-keys", `{"name":"ci"}`, "bob")
+
+		if assert.NoError(t, handler.CreateAPIKey(ctx)) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.Contains(t, rec.Body.String(), response.ErrNotServiceAccountMessage)
+		}
+	})
+
+	t.Run("Missing name", func(t *testing.T) {
+		ctx, rec := newAdminContext(e, http.MethodPost, "/api/admin/service-accounts/bot/api-keys", `{}`, "bot")
+
+		if assert.NoError(t, handler.CreateAPIKey(ctx)) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+		}
+	})
+}
+
+func TestRevokeAPIKey(t *testing.T) {
+	e := echo.New()
+	mockAuthService := new(MockAuthService)
+	handler := NewAdminHandler(e, mockAuthService, nil, nil, nil)
+
+	newRevokeContext := func(id string) (echo.Context, *httptest.ResponseRecorder) {
+		ctx, rec := newAdminContext(e, http.MethodDelete, "/api/admin/api-keys/"+id, "", "")
+		ctx.SetParamNames("id")
+		ctx.SetParamValues(id)
+
+		return ctx, rec
+	}
+
+	t.Run("Successful revoke", func(t *testing.T) {
+		mockAuthService.On("RevokeAPIKey", mock.Anything, 3).Return(nil)
+
+		ctx, rec := newRevokeContext("3")
+
+		if assert.NoError(t, handler.RevokeAPIKey(ctx)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+		}
+	})
+
+	t.Run("Unknown key", func(t *testing.T) {
+		mockAuthService.On("RevokeAPIKey", mock.Anything, 4).Return(apperrors.ErrAPIKeyNotFound)
+
+		ctx, rec := newRevokeContext("4")
+
+		if assert.NoError(t, handler.RevokeAPIKey(ctx)) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.Contains(t, rec.Body.String(), response.ErrAPIKeyNotFoundMessage)
+		}
+	})
+
+	t.Run("Invalid id", func(t *testing.T) {
+		ctx, rec := newRevokeContext("abc")
+
+		if assert.NoError(t, handler.RevokeAPIKey(ctx)) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+		}
+	})
+}
+
+func TestGetRefunds(t *testing.T) {
+	e := echo.New()
+	mockRefundService := new(MockRefundService)
+	handler := NewAdminHandler(e, nil, mockRefundService, nil, nil)
+
+	t.Run("Pending by default", func(t *testing.T) {
+		mockRefundService.On("GetRefundsByStatus", mock.Anything, model.RefundRequested).
+			Return([]model.Refund{{ID: 3, Status: model.RefundRequested}}, nil)
+
+		ctx, rec := newAdminContext(e, http.MethodGet, "/api/admin/refunds", "", "")
+
+		if assert.NoError(t, handler.GetRefunds(ctx)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Contains(t, rec.Body.String(), `"status":"requested"`)
+		}
+	})
+
+	t.Run("Unknown status", func(t *testing.T) {
+		mockRefundService.On("GetRefundsByStatus", mock.Anything, model.RefundStatus("pending")).
+			Return([]model.Refund(nil), apperrors.ErrInvalidRefundStatus)
+
+		ctx, rec := newAdminContext(e, http.MethodGet, "/api/admin/refunds?status=pending", "", "")
+
+		if assert.NoError(t, handler.GetRefunds(ctx)) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+		}
+	})
+}
+
+func TestApproveRefund(t *testing.T) {
+	e := echo.New()
+	mockRefundService := new(MockRefundService)
+	handler := NewAdminHandler(e, nil, mockRefundService, nil, nil)
+
+	newApproveContext := func(id string) (echo.Context, *httptest.ResponseRecorder) {
+		ctx, rec := newAdminContext(e, http.MethodPost, "/api/admin/refunds/"+id+"/approve", "", "")
+		ctx.SetParamNames("id")
+		ctx.SetParamValues(id)
+
+		return ctx, rec
+	}
+
+	t.Run("Successful approve", func(t *testing.T) {
+		mockRefundService.On("ApproveRefund", mock.Anything, mock.Anything, 3).
+			Return(model.Refund{ID: 3, Amount: 20, Status: model.RefundApproved}, nil)
+
+		ctx, rec := newApproveContext("3")
+
+		if assert.NoError(t, handler.ApproveRefund(ctx)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Contains(t, rec.Body.String(), `"status":"approved"`)
+		}
+	})
+
+	t.Run("Already decided", func(t *testing.T) {
+		mockRefundService.On("ApproveRefund", mock.Anything, mock.Anything, 4).
+			Return(model.Refund{}, apperrors.ErrRefundAlreadyDecided)
+
+		ctx, rec := newApproveContext("4")
+
+		if assert.NoError(t, handler.ApproveRefund(ctx)) {
+			assert.Equal(t, http.StatusConflict, rec.Code)
+			assert.Contains(t, rec.Body.String(), response.ErrRefundAlreadyDecidedMessage)
+		}
+	})
+
+	t.Run("Invalid id", func(t *testing.T) {
+		ctx, rec := newApproveContext("abc")
+
+		if assert.NoError(t, handler.ApproveRefund(ctx)) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+		}
+	})
+}
+
+func TestRestock(t *testing.T) {
+	e := echo.New()
+	mockProductService := new(MockProductService)
+	handler := NewAdminHandler(e, nil, nil, mockProductService, nil)
+
+	newRestockContext := func(item, body string) (echo.Context, *httptest.ResponseRecorder) {
+		ctx, rec := newAdminContext(e, http.MethodPost, "/api/admin/products/"+item+"/restock", body, "")
+		ctx.SetParamNames("item")
+		ctx.SetParamValues(item)
+
+		return ctx, rec
+	}
+
+	t.Run("Successful restock", func(t *testing.T) {
+		mockProductService.On("Restock", mock.Anything, mock.Anything, "pink-hoody", "", 20).
+			Return(model.Product{Name: "pink-hoody", Price: 500, Stock: 21}, nil)
+
+		ctx, rec := newRestockContext("pink-hoody", `{"quantity":20}`)
+
+		if assert.NoError(t, handler.Restock(ctx)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Contains(t, rec.Body.String(), `"stock":21`)
+		}
+	})
+
+	t.Run("Non-positive quantity", func(t *testing.T) {
+		ctx, rec := newRestockContext("pen", `{"quantity":0}`)
+
+		if assert.NoError(t, handler.Restock(ctx)) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.Contains(t, rec.Body.String(), response.ErrInvalidQuantityMessage)
+		}
+	})
+
+	t.Run("Unknown product", func(t *testing.T) {
+		mockProductService.On("Restock", mock.Anything, mock.Anything, "jujuju", "", 5).
+			Return(model.Product{}, apperrors.ErrProductNotFound)
+
+		ctx, rec := newRestockContext("jujuju", `{"quantity":5}`)
+
+		if assert.NoError(t, handler.Restock(ctx)) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.Contains(t, rec.Body.String(), response.ErrProductNotFoundMessage)
+		}
+	})
+}
+
+func TestCreateProduct(t *testing.T) {
+	e := echo.New()
+	mockProductService := new(MockProductService)
+	handler := NewAdminHandler(e, nil, nil, mockProductService, nil)
+
+	t.Run("Successful create", func(t *testing.T) {
+		mockProductService.On("CreateProduct", mock.Anything, mock.Anything, model.CreateProductInput{
+			Name: "mug", Price: 150, Description: "white mug", Stock: 10,
+		}).Return(model.Product{Name: "mug", Price: 150, Description: "white mug", Stock: 10}, nil)
+
+		ctx, rec := newAdminContext(e, http.MethodPost, "/api/admin/products",
+			`{"name":"mug","price":150,"description":"white mug","stock":10}`, "")
+
+		if assert.NoError(t, handler.CreateProduct(ctx)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Contains(t, rec.Body.String(), `"description":"white mug"`)
+		}
+	})
+
+	t.Run("Duplicate name", func(t *testing.T) {
+		mockProductService.On("CreateProduct", mock.Anything, mock.Anything, model.CreateProductInput{
+			Name: "cup", Price: 20,
+		}).Return(model.Product{}, apperrors.ErrProductAlreadyExists)
+
+		ctx, rec := newAdminContext(e, http.MethodPost, "/api/admin/products", `{"name":"cup","price":20}`, "")
+
+		if assert.NoError(t, handler.CreateProduct(ctx)) {
+			assert.Equal(t, http.StatusConflict, rec.Code)
+			assert.Contains(t, rec.Body.String(), response.ErrProductAlreadyExistsMessage)
+		}
+	})
+}
+
+func TestUpdateProduct(t *testing.T) {
+	e := echo.New()
+	mockProductService := new(MockProductService)
+	handler := NewAdminHandler(e, nil, nil, mockProductService, nil)
+
+	newUpdateContext := func(item, body string) (echo.Context, *httptest.ResponseRecorder) {
+		ctx, rec := newAdminContext(e, http.MethodPatch, "/api/admin/products/"+item, body, "")
+		ctx.SetParamNames("item")
+		ctx.SetParamValues(item)
+
+		return ctx, rec
+	}
+
+	t.Run("Successful update", func(t *testing.T) {
+		price := 600
+		mockProductService.On("UpdateProduct", mock.Anything, mock.Anything, "hoody",
+			model.UpdateProductInput{Price: &price}).
+			Return(model.Product{Name: "hoody", Price: 600}, nil)
+
+		ctx, rec := newUpdateContext("hoody", `{"price":600}`)
+
+		if assert.NoError(t, handler.UpdateProduct(ctx)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Contains(t, rec.Body.String(), `"price":600`)
+		}
+	})
+
+	t.Run("No fields", func(t *testing.T) {
+		ctx, rec := newUpdateContext("hoody", `{}`)
+
+		if assert.NoError(t, handler.UpdateProduct(ctx)) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+		}
+	})
+}
+
+func TestRetireProduct(t *testing.T) {
+	e := echo.New()
+	mockProductService := new(MockProductService)
+	handler := NewAdminHandler(e, nil, nil, mockProductService, nil)
+
+	newRetireContext := func(item string) (echo.Context, *httptest.ResponseRecorder) {
+		ctx, rec := newAdminContext(e, http.MethodDelete, "/api/admin/products/"+item, "", "")
+		ctx.SetParamNames("item")
+		ctx.SetParamValues(item)
+
+		return ctx, rec
+	}
+
+	t.Run("Successful retire", func(t *testing.T) {
+		mockProductService.On("RetireProduct", mock.Anything, mock.Anything, "umbrella").Return(nil)
+
+		ctx, rec := newRetireContext("umbrella")
+
+		if assert.NoError(t, handler.RetireProduct(ctx)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+		}
+	})
+
+	t.Run("Unknown product", func(t *testing.T) {
+		mockProductService.On("RetireProduct", mock.Anything, mock.Anything, "jujuju").
+			Return(apperrors.ErrProductNotFound)
+
+		ctx, rec := newRetireContext("jujuju")
+
+		if assert.NoError(t, handler.RetireProduct(ctx)) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.Contains(t, rec.Body.String(), response.ErrProductNotFoundMessage)
+		}
+	})
+}
+
+func TestCreateProductVariant(t *testing.T) {
+	e := echo.New()
+	mockProductService := new(MockProductService)
+	handler := NewAdminHandler(e, nil, nil, mockProductService, nil)
+
+	newVariantContext := func(item, body string) (echo.Context, *httptest.ResponseRecorder) {
+		ctx, rec := newAdminContext(e, http.MethodPost, "/api/admin/products/"+item+"/variants", body, "")
+		ctx.SetParamNames("item")
+		ctx.SetParamValues(item)
+
+		return ctx, rec
+	}
+
+	t.Run("Successful create", func(t *testing.T) {
+		stock := 5
+		mockProductService.On("CreateProductVariant", mock.Anything, mock.Anything, "hoody",
+			model.CreateProductVariantInput{Name: "XL", SKU: "HOODY-XL", Stock: &stock}).
+			Return(model.ProductVariant{Name: "XL", SKU: "HOODY-XL", Price: 300, Stock: 5}, nil)
+
+		ctx, rec := newVariantContext("hoody", `{"name":"XL","sku":"HOODY-XL","stock":5}`)
+
+		if assert.NoError(t, handler.CreateProductVariant(ctx)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Contains(t, rec.Body.String(), `"sku":"HOODY-XL"`)
+		}
+	})
+
+	t.Run("Duplicate sku", func(t *testing.T) {
+		mockProductService.On("CreateProductVariant", mock.Anything, mock.Anything, "t-shirt",
+			model.CreateProductVariantInput{Name: "XL", SKU: "HOODY-XL"}).
+			Return(model.ProductVariant{}, apperrors.ErrVariantAlreadyExists)
+
+		ctx, rec := newVariantContext("t-shirt", `{"name":"XL","sku":"HOODY-XL"}`)
+
+		if assert.NoError(t, handler.CreateProductVariant(ctx)) {
+			assert.Equal(t, http.StatusConflict, rec.Code)
+			assert.Contains(t, rec.Body.String(), response.ErrVariantAlreadyExistsMessage)
+		}
+	})
+}
@@ -7,7 +7,10 @@ import (
 
 	"github.com/labstack/echo"
 	dto "github.com/resueman/merch-store/internal/api/v1"
+	"github.com/resueman/merch-store/internal/delivery/ctxkey"
+	"github.com/resueman/merch-store/internal/delivery/handlers/http/v1/converter"
 	"github.com/resueman/merch-store/internal/delivery/handlers/http/v1/response"
+	"github.com/resueman/merch-store/internal/delivery/middleware"
 	"github.com/resueman/merch-store/internal/model"
 	"github.com/resueman/merch-store/internal/usecase"
 )
@@ -16,10 +19,28 @@ type AuthHandler struct {
 	authService usecase.Auth
 }
 
-func NewAuthHandler(e *echo.Echo, authService usecase.Auth) *AuthHandler {
+// Маршруты без аутентификации не принимают Idempotency-Key: ключи хранятся отдельно для каждого пользователя.
+// Не принимают его и маршруты, выдающие секреты (токены, секрет TOTP, коды восстановления),
+// чтобы их ответы не сохранялись в БД.
+func NewAuthHandler(e *echo.Echo, authService usecase.Auth, idempotency echo.MiddlewareFunc,
+	m ...echo.MiddlewareFunc) *AuthHandler {
 	h := &AuthHandler{authService: authService}
 
 	e.POST("/api/auth", h.Auth)
+	e.POST("/api/register", h.Register)
+	e.POST("/api/auth/refresh", h.Refresh)
+	// Выйти может любой действующий токен, независимо от областей доступа. Смена пароля и второго фактора
+	// доступна только токену со всеми пользовательскими областями: урезанный токен не должен менять защиту.
+	account := middleware.WithScopes(m, model.ScopeInfoRead, model.ScopeCoinsSend, model.ScopeShopBuy)
+	e.POST("/api/auth/logout", h.Logout, middleware.WithIdempotency(m, idempotency)...)
+	e.POST("/api/auth/logout/all", h.LogoutAll, middleware.WithIdempotency(m, idempotency)...)
+	e.POST("/api/auth/password", h.ChangePassword, account...)
+	e.POST("/api/auth/password/reset", h.ResetPassword)
+	e.POST("/api/auth/2fa/enroll", h.EnrollTOTP, account...)
+	e.POST("/api/auth/2fa/confirm", h.ConfirmTOTP, account...)
+	e.POST("/api/auth/2fa/disable", h.DisableTOTP, middleware.WithIdempotency(account, idempotency)...)
+	e.PUT("/api/auth/2fa/threshold", h.SetTOTPThreshold, middleware.WithIdempotency(account, idempotency)...)
+	e.GET("/.well-known/jwks.json", h.JWKS)
 
 	return h
 }
@@ -38,7 +59,7 @@ func (h *AuthHandler) validateAuthRequest(input *dto.AuthRequest) string {
 }
 
 // (POST /api/auth): аутентификация и получение JWT-токена.
-// При первой аутентификации пользователь создается автоматически.
+// Если включена автоматическая регистрация, при первой аутентификации пользователь создается автоматически.
 func (h *AuthHandler) Auth(ctx echo.Context) error {
 	var input dto.AuthRequest
 	if err := ctx.Bind(&input); err != nil {
@@ -49,14 +70,278 @@ func (h *AuthHandler) Auth(ctx echo.Context) error {
 		return response.SendHandlerError(ctx, http.StatusBadRequest, errMsg)
 	}
 
-	authInput := model.AuthRequestInput{Username: input.Username, Password: input.Password}
+	authInput := converter.ConvertAuthRequestToInput(&input)
+	// RealIP доверяет X-Forwarded-For и X-Real-IP, поэтому сервис должен стоять за прокси, который их перезаписывает.
+	authInput.ClientIP = ctx.RealIP()
 
-	token, err := h.authService.GenerateToken(ctx.Request().Context(), authInput)
+	tokens, err := h.authService.GenerateToken(ctx.Request().Context(), authInput)
 	if err != nil {
 		return response.SendUsecaseError(ctx, err)
 	}
 
-	dto := dto.AuthResponse{Token: &token}
+	dto := dto.AuthResponse{Token: &tokens.AccessToken, RefreshToken: &tokens.RefreshToken}
 
 	return response.SendOk(ctx, dto)
 }
+
+// (POST /api/register): регистрация нового пользователя и получение JWT-токена.
+func (h *AuthHandler) Register(ctx echo.Context) error {
+	var input dto.AuthRequest
+	if err := ctx.Bind(&input); err != nil {
+		return response.SendHandlerError(ctx, http.StatusBadRequest, response.ErrBindingMessage)
+	}
+
+	if errMsg := h.validateAuthRequest(&input); errMsg != "" {
+		return response.SendHandlerError(ctx, http.StatusBadRequest, errMsg)
+	}
+
+	authInput := converter.ConvertAuthRequestToInput(&input)
+
+	tokens, err := h.authService.Register(ctx.Request().Context(), authInput)
+	if err != nil {
+		return response.SendUsecaseError(ctx, err)
+	}
+
+	dto := dto.AuthResponse{Token: &tokens.AccessToken, RefreshToken: &tokens.RefreshToken}
+
+	return response.SendOk(ctx, dto)
+}
+
+// (POST /api/auth/refresh): обмен refresh-токена на новую пару токенов.
+func (h *AuthHandler) Refresh(ctx echo.Context) error {
+	var input dto.RefreshRequest
+	if err := ctx.Bind(&input); err != nil {
+		return response.SendHandlerError(ctx, http.StatusBadRequest, response.ErrBindingMessage)
+	}
+
+	if input.RefreshToken == "" {
+		return response.SendHandlerError(ctx, http.StatusBadRequest, "refreshToken is required;")
+	}
+
+	tokens, err := h.authService.RefreshTokens(ctx.Request().Context(), input.RefreshToken)
+	if err != nil {
+		return response.SendUsecaseError(ctx, err)
+	}
+
+	dto := dto.AuthResponse{Token: &tokens.AccessToken, RefreshToken: &tokens.RefreshToken}
+
+	return response.SendOk(ctx, dto)
+}
+
+// (POST /api/auth/logout): завершение текущей сессии.
+// Access-токен отзывается, а переданный refresh-токен становится недействительным вместе со всей цепочкой ротации.
+func (h *AuthHandler) Logout(c echo.Context) error {
+	ctx := c.Request().Context()
+	claims, ok := ctx.Value(ctxkey.ClaimsKey).(model.Claims)
+	if !ok {
+		return response.SendHandlerError(c, http.StatusUnauthorized, response.ErrInvalidClaimsMessage)
+	}
+
+	var input dto.LogoutRequest
+	if c.Request().ContentLength != 0 {
+		if err := c.Bind(&input); err != nil {
+			return response.SendHandlerError(c, http.StatusBadRequest, response.ErrBindingMessage)
+		}
+	}
+
+	refreshToken := ""
+	if input.RefreshToken != nil {
+		refreshToken = *input.RefreshToken
+	}
+
+	if err := h.authService.Logout(ctx, claims, refreshToken); err != nil {
+		return response.SendUsecaseError(c, err)
+	}
+
+	return response.SendNoContent(c)
+}
+
+// (POST /api/auth/logout/all): завершение всех сессий пользователя на всех устройствах.
+func (h *AuthHandler) LogoutAll(c echo.Context) error {
+	ctx := c.Request().Context()
+	claims, ok := ctx.Value(ctxkey.ClaimsKey).(model.Claims)
+	if !ok {
+		return response.SendHandlerError(c, http.StatusUnauthorized, response.ErrInvalidClaimsMessage)
+	}
+
+	if err := h.authService.LogoutAll(ctx, claims); err != nil {
+		return response.SendUsecaseError(c, err)
+	}
+
+	return response.SendNoContent(c)
+}
+
+// (POST /api/auth/password): смена пароля с проверкой старого.
+// Все сессии пользователя завершаются, вместо текущей выдается новая пара токенов.
+func (h *AuthHandler) ChangePassword(c echo.Context) error {
+	ctx := c.Request().Context()
+	claims, ok := ctx.Value(ctxkey.ClaimsKey).(model.Claims)
+	if !ok {
+		return response.SendHandlerError(c, http.StatusUnauthorized, response.ErrInvalidClaimsMessage)
+	}
+
+	var input dto.ChangePasswordRequest
+	if err := c.Bind(&input); err != nil {
+		return response.SendHandlerError(c, http.StatusBadRequest, response.ErrBindingMessage)
+	}
+
+	var errMsg strings.Builder
+	if input.OldPassword == "" {
+		errMsg.WriteString("oldPassword is required;")
+	}
+
+	if input.NewPassword == "" {
+		errMsg.WriteString("newPassword is required;")
+	}
+
+	if errMsg.Len() != 0 {
+		return response.SendHandlerError(c, http.StatusBadRequest, errMsg.String())
+	}
+
+	changeInput := model.ChangePasswordInput{OldPassword: input.OldPassword, NewPassword: input.NewPassword}
+
+	tokens, err := h.authService.ChangePassword(ctx, claims, changeInput)
+	if err != nil {
+		return response.SendUsecaseError(c, err)
+	}
+
+	dto := dto.AuthResponse{Token: &tokens.AccessToken, RefreshToken: &tokens.RefreshToken}
+
+	return response.SendOk(c, dto)
+}
+
+// (POST /api/auth/password/reset): установка нового пароля по токену сброса, выданному администратором.
+func (h *AuthHandler) ResetPassword(c echo.Context) error {
+	var input dto.ResetPasswordRequest
+	if err := c.Bind(&input); err != nil {
+		return response.SendHandlerError(c, http.StatusBadRequest, response.ErrBindingMessage)
+	}
+
+	var errMsg strings.Builder
+	if input.ResetToken == "" {
+		errMsg.WriteString("resetToken is required;")
+	}
+
+	if input.NewPassword == "" {
+		errMsg.WriteString("newPassword is required;")
+	}
+
+	if errMsg.Len() != 0 {
+		return response.SendHandlerError(c, http.StatusBadRequest, errMsg.String())
+	}
+
+	resetInput := model.ResetPasswordInput{ResetToken: input.ResetToken, NewPassword: input.NewPassword}
+	if err := h.authService.ResetPassword(c.Request().Context(), resetInput); err != nil {
+		return response.SendUsecaseError(c, err)
+	}
+
+	return response.SendNoContent(c)
+}
+
+// (POST /api/auth/2fa/enroll): начало подключения приложения-аутентификатора.
+// Второй фактор заработает только после подтверждения кодом через /api/auth/2fa/confirm.
+func (h *AuthHandler) EnrollTOTP(c echo.Context) error {
+	ctx := c.Request().Context()
+	claims, ok := ctx.Value(ctxkey.ClaimsKey).(model.Claims)
+	if !ok {
+		return response.SendHandlerError(c, http.StatusUnauthorized, response.ErrInvalidClaimsMessage)
+	}
+
+	enrollment, err := h.authService.EnrollTOTP(ctx, claims)
+	if err != nil {
+		return response.SendUsecaseError(c, err)
+	}
+
+	dto := dto.TotpEnrollmentResponse{Secret: enrollment.Secret, OtpauthUri: enrollment.URI}
+
+	return response.SendOk(c, dto)
+}
+
+// (POST /api/auth/2fa/confirm): включение второго фактора после проверки первого кода.
+// Коды восстановления показываются только в этом ответе.
+func (h *AuthHandler) ConfirmTOTP(c echo.Context) error {
+	ctx := c.Request().Context()
+	claims, ok := ctx.Value(ctxkey.ClaimsKey).(model.Claims)
+	if !ok {
+		return response.SendHandlerError(c, http.StatusUnauthorized, response.ErrInvalidClaimsMessage)
+	}
+
+	var input dto.ConfirmTotpRequest
+	if err := c.Bind(&input); err != nil {
+		return response.SendHandlerError(c, http.StatusBadRequest, response.ErrBindingMessage)
+	}
+
+	if input.Code == "" {
+		return response.SendHandlerError(c, http.StatusBadRequest, "code is required;")
+	}
+
+	confirmInput := model.ConfirmTOTPInput{Code: input.Code}
+	if input.Threshold != nil {
+		confirmInput.Threshold = *input.Threshold
+	}
+
+	codes, err := h.authService.ConfirmTOTP(ctx, claims, confirmInput)
+	if err != nil {
+		return response.SendUsecaseError(c, err)
+	}
+
+	return response.SendOk(c, dto.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// (POST /api/auth/2fa/disable): отключение второго фактора по коду из приложения или коду восстановления.
+func (h *AuthHandler) DisableTOTP(c echo.Context) error {
+	ctx := c.Request().Context()
+	claims, ok := ctx.Value(ctxkey.ClaimsKey).(model.Claims)
+	if !ok {
+		return response.SendHandlerError(c, http.StatusUnauthorized, response.ErrInvalidClaimsMessage)
+	}
+
+	var input dto.TotpCodeRequest
+	if err := c.Bind(&input); err != nil {
+		return response.SendHandlerError(c, http.StatusBadRequest, response.ErrBindingMessage)
+	}
+
+	if input.Code == "" {
+		return response.SendHandlerError(c, http.StatusBadRequest, "code is required;")
+	}
+
+	if err := h.authService.DisableTOTP(ctx, claims, input.Code); err != nil {
+		return response.SendUsecaseError(c, err)
+	}
+
+	return response.SendNoContent(c)
+}
+
+// (PUT /api/auth/2fa/threshold): изменение суммы, начиная с которой переводы и покупки требуют кода.
+func (h *AuthHandler) SetTOTPThreshold(c echo.Context) error {
+	ctx := c.Request().Context()
+	claims, ok := ctx.Value(ctxkey.ClaimsKey).(model.Claims)
+	if !ok {
+		return response.SendHandlerError(c, http.StatusUnauthorized, response.ErrInvalidClaimsMessage)
+	}
+
+	var input dto.SetTotpThresholdRequest
+	if err := c.Bind(&input); err != nil {
+		return response.SendHandlerError(c, http.StatusBadRequest, response.ErrBindingMessage)
+	}
+
+	if input.Code == "" {
+		return response.SendHandlerError(c, http.StatusBadRequest, "code is required;")
+	}
+
+	thresholdInput := model.SetTOTPThresholdInput{Code: input.Code, Threshold: input.Threshold}
+	if err := h.authService.SetTOTPThreshold(ctx, claims, thresholdInput); err != nil {
+		return response.SendUsecaseError(c, err)
+	}
+
+	return response.SendNoContent(c)
+}
+
+// (GET /.well-known/jwks.json): открытые ключи для проверки подписи access-токенов.
+// Ключи меняются только при ротации, поэтому ответ можно кешировать.
+func (h *AuthHandler) JWKS(c echo.Context) error {
+	jwks := converter.ConvertPublicKeysToJWKSet(h.authService.PublicKeys())
+	c.Response().Header().Set("Cache-Control", "public, max-age=300")
+
+	return response.SendOk(c, jwks)
+}
@@ -2,14 +2,17 @@ package auth
 
 import (
 	"context"
+	"crypto/ed25519"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
 
 	"github.com/labstack/echo"
+	"github.com/resueman/merch-store/internal/delivery/ctxkey"
 	"github.com/resueman/merch-store/internal/delivery/handlers/http/v1/response"
 	"github.com/resueman/merch-store/internal/model"
+	"github.com/resueman/merch-store/internal/usecase/apperrors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -18,9 +21,139 @@ type MockAuthService struct {
 	mock.Mock
 }
 
-func (m *MockAuthService) GenerateToken(ctx context.Context, input model.AuthRequestInput) (string, error) {
+func (m *MockAuthService) GenerateToken(ctx context.Context, input model.AuthRequestInput) (model.AuthTokens, error) {
 	args := m.Called(ctx, input)
-	return args.String(0), args.Error(1)
+	return args.Get(0).(model.AuthTokens), args.Error(1)
+}
+
+func (m *MockAuthService) Register(ctx context.Context, input model.AuthRequestInput) (model.AuthTokens, error) {
+	args := m.Called(ctx, input)
+	return args.Get(0).(model.AuthTokens), args.Error(1)
+}
+
+func (m *MockAuthService) SetUserRole(ctx context.Context, claims model.Claims, input model.SetUserRoleInput) error {
+	args := m.Called(ctx, claims, input)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) BootstrapAdmin(ctx context.Context, username string) error {
+	args := m.Called(ctx, username)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) PublicKeys() []model.PublicKey {
+	args := m.Called()
+	return args.Get(0).([]model.PublicKey)
+}
+
+func (m *MockAuthService) UnlockUser(ctx context.Context, username string) error {
+	args := m.Called(ctx, username)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) DeactivateUser(ctx context.Context, claims model.Claims,
+	input model.DeactivateUserInput,
+) (int, error) {
+	args := m.Called(ctx, claims, input)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockAuthService) ReactivateUser(ctx context.Context, username string) error {
+	args := m.Called(ctx, username)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) ChangePassword(ctx context.Context, claims model.Claims,
+	input model.ChangePasswordInput,
+) (model.AuthTokens, error) {
+	args := m.Called(ctx, claims, input)
+	return args.Get(0).(model.AuthTokens), args.Error(1)
+}
+
+func (m *MockAuthService) CreatePasswordReset(ctx context.Context, claims model.Claims,
+	username string,
+) (model.PasswordResetToken, error) {
+	args := m.Called(ctx, claims, username)
+	return args.Get(0).(model.PasswordResetToken), args.Error(1)
+}
+
+func (m *MockAuthService) ResetPassword(ctx context.Context, input model.ResetPasswordInput) error {
+	args := m.Called(ctx, input)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) CreateServiceAccount(ctx context.Context, username string) error {
+	args := m.Called(ctx, username)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) CreateAPIKey(ctx context.Context, claims model.Claims,
+	input model.CreateAPIKeyInput,
+) (model.APIKey, error) {
+	args := m.Called(ctx, claims, input)
+	return args.Get(0).(model.APIKey), args.Error(1)
+}
+
+func (m *MockAuthService) GetAPIKeys(ctx context.Context, username string) ([]model.APIKey, error) {
+	args := m.Called(ctx, username)
+	return args.Get(0).([]model.APIKey), args.Error(1)
+}
+
+func (m *MockAuthService) RevokeAPIKey(ctx context.Context, keyID int) error {
+	args := m.Called(ctx, keyID)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) AuthenticateAPIKey(ctx context.Context, key string) (model.Claims, error) {
+	args := m.Called(ctx, key)
+	return args.Get(0).(model.Claims), args.Error(1)
+}
+
+func (m *MockAuthService) EnrollTOTP(ctx context.Context, claims model.Claims) (model.TOTPEnrollment, error) {
+	args := m.Called(ctx, claims)
+	return args.Get(0).(model.TOTPEnrollment), args.Error(1)
+}
+
+func (m *MockAuthService) ConfirmTOTP(ctx context.Context, claims model.Claims,
+	input model.ConfirmTOTPInput,
+) ([]string, error) {
+	args := m.Called(ctx, claims, input)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockAuthService) DisableTOTP(ctx context.Context, claims model.Claims, code string) error {
+	args := m.Called(ctx, claims, code)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) SetTOTPThreshold(ctx context.Context, claims model.Claims, input model.SetTOTPThresholdInput) error {
+	args := m.Called(ctx, claims, input)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) VerifyOTP(ctx context.Context, userID int, amount int, code string) error {
+	args := m.Called(ctx, userID, amount, code)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) RecordOTPFailure(ctx context.Context, userID int) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) RefreshTokens(ctx context.Context, refreshToken string) (model.AuthTokens, error) {
+	args := m.Called(ctx, refreshToken)
+	return args.Get(0).(model.AuthTokens), args.Error(1)
+}
+
+func (m *MockAuthService) Logout(ctx context.Context, claims model.Claims, refreshToken string) error {
+	args := m.Called(ctx, claims, refreshToken)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) LogoutAll(ctx context.Context, claims model.Claims) error {
+	args := m.Called(ctx, claims)
+	return args.Error(0)
 }
 
 func (m *MockAuthService) ParseToken(ctx context.Context, token string) (model.Claims, error) {
@@ -31,12 +164,12 @@ func (m *MockAuthService) ParseToken(ctx context.Context, token string) (model.C
 func TestAuth(t *testing.T) {
 	e := echo.New()
 	mockAuthService := new(MockAuthService)
-	handler := NewAuthHandler(e, mockAuthService)
+	handler := NewAuthHandler(e, mockAuthService, nil)
 
 	t.Run("Successful authentication", func(t *testing.T) {
 		mockAuthService.
-			On("GenerateToken", mock.Anything, model.AuthRequestInput{Username: "user", Password: "pass"}).
-			Return("token", nil)
+			On("GenerateToken", mock.Anything, model.AuthRequestInput{Username: "user", Password: "pass", ClientIP: "192.0.2.1"}).
+			Return(model.AuthTokens{AccessToken: "token", RefreshToken: "refresh"}, nil)
 
 		req := httptest.NewRequest(http.MethodPost, "/api/auth", strings.NewReader(`{"username":"user","password":"pass"}`))
 		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
@@ -45,7 +178,8 @@ func TestAuth(t *testing.T) {
 
 		if assert.NoError(t, handler.Auth(ctx)) {
 			assert.Equal(t, http.StatusOK, rec.Code)
-			assert.Contains(t, rec.Body.String(), "token")
+			assert.Contains(t, rec.Body.String(), `"token":"token"`)
+			assert.Contains(t, rec.Body.String(), `"refreshToken":"refresh"`)
 		}
 	})
 
@@ -85,3 +219,325 @@ func TestAuth(t *testing.T) {
 		}
 	})
 }
+
+func TestRegister(t *testing.T) {
+	e := echo.New()
+	mockAuthService := new(MockAuthService)
+	handler := NewAuthHandler(e, mockAuthService, nil)
+
+	t.Run("Successful registration", func(t *testing.T) {
+		mockAuthService.
+			On("Register", mock.Anything, model.AuthRequestInput{Username: "new", Password: "pass"}).
+			Return(model.AuthTokens{AccessToken: "token", RefreshToken: "refresh"}, nil)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/register", strings.NewReader(`{"username":"new","password":"pass"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+
+		if assert.NoError(t, handler.Register(ctx)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Contains(t, rec.Body.String(), `"token":"token"`)
+			assert.Contains(t, rec.Body.String(), `"refreshToken":"refresh"`)
+		}
+	})
+
+	t.Run("User already exists", func(t *testing.T) {
+		mockAuthService.
+			On("Register", mock.Anything, model.AuthRequestInput{Username: "taken", Password: "pass"}).
+			Return(model.AuthTokens{}, apperrors.ErrUserAlreadyExists)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/register", strings.NewReader(`{"username":"taken","password":"pass"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+
+		if assert.NoError(t, handler.Register(ctx)) {
+			assert.Equal(t, http.StatusConflict, rec.Code)
+			assert.Contains(t, rec.Body.String(), response.ErrUserAlreadyExistsMessage)
+		}
+	})
+
+	t.Run("Missing password", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/register", strings.NewReader(`{"username":"new"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+
+		if assert.NoError(t, handler.Register(ctx)) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.Contains(t, rec.Body.String(), "password is required;")
+		}
+	})
+}
+
+func TestRefresh(t *testing.T) {
+	e := echo.New()
+	mockAuthService := new(MockAuthService)
+	handler := NewAuthHandler(e, mockAuthService, nil)
+
+	t.Run("Successful refresh", func(t *testing.T) {
+		mockAuthService.
+			On("RefreshTokens", mock.Anything, "old").
+			Return(model.AuthTokens{AccessToken: "access", RefreshToken: "new"}, nil)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/auth/refresh", strings.NewReader(`{"refreshToken":"old"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+
+		if assert.NoError(t, handler.Refresh(ctx)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Contains(t, rec.Body.String(), `"token":"access"`)
+			assert.Contains(t, rec.Body.String(), `"refreshToken":"new"`)
+		}
+	})
+
+	t.Run("Reused refresh token", func(t *testing.T) {
+		mockAuthService.
+			On("RefreshTokens", mock.Anything, "used").
+			Return(model.AuthTokens{}, apperrors.ErrRefreshTokenReused)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/auth/refresh", strings.NewReader(`{"refreshToken":"used"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+
+		if assert.NoError(t, handler.Refresh(ctx)) {
+			assert.Equal(t, http.StatusUnauthorized, rec.Code)
+			assert.Contains(t, rec.Body.String(), response.ErrRefreshTokenReusedMessage)
+		}
+	})
+
+	t.Run("Missing refresh token", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/auth/refresh", strings.NewReader(`{}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+
+		if assert.NoError(t, handler.Refresh(ctx)) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.Contains(t, rec.Body.String(), "refreshToken is required;")
+		}
+	})
+}
+
+func TestLogout(t *testing.T) {
+	e := echo.New()
+	mockAuthService := new(MockAuthService)
+	handler := NewAuthHandler(e, mockAuthService, nil)
+
+	claims := model.Claims{UserID: 1, TokenID: "jti"}
+
+	t.Run("Successful logout with refresh token", func(t *testing.T) {
+		mockAuthService.On("Logout", mock.Anything, claims, "refresh").Return(nil)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/auth/logout", strings.NewReader(`{"refreshToken":"refresh"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.SetRequest(req.WithContext(context.WithValue(req.Context(), ctxkey.ClaimsKey, claims)))
+
+		if assert.NoError(t, handler.Logout(ctx)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+		}
+	})
+
+	t.Run("Successful logout without body", func(t *testing.T) {
+		mockAuthService.On("Logout", mock.Anything, claims, "").Return(nil)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/auth/logout", nil)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.SetRequest(req.WithContext(context.WithValue(req.Context(), ctxkey.ClaimsKey, claims)))
+
+		if assert.NoError(t, handler.Logout(ctx)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+		}
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/auth/logout", nil)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+
+		if assert.NoError(t, handler.Logout(ctx)) {
+			assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		}
+	})
+}
+
+func TestChangePassword(t *testing.T) {
+	e := echo.New()
+	mockAuthService := new(MockAuthService)
+	handler := NewAuthHandler(e, mockAuthService, nil)
+
+	claims := model.Claims{UserID: 1, TokenID: "jti"}
+
+	t.Run("Successful password change", func(t *testing.T) {
+		mockAuthService.
+			On("ChangePassword", mock.Anything, claims, model.ChangePasswordInput{OldPassword: "old", NewPassword: "new-password1"}).
+			Return(model.AuthTokens{AccessToken: "token", RefreshToken: "refresh"}, nil)
+
+		body := `{"oldPassword":"old","newPassword":"new-password1"}`
+		req := httptest.NewRequest(http.MethodPost, "/api/auth/password", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.SetRequest(req.WithContext(context.WithValue(req.Context(), ctxkey.ClaimsKey, claims)))
+
+		if assert.NoError(t, handler.ChangePassword(ctx)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Contains(t, rec.Body.String(), `"token":"token"`)
+		}
+	})
+
+	t.Run("Weak password", func(t *testing.T) {
+		mockAuthService.
+			On("ChangePassword", mock.Anything, claims, model.ChangePasswordInput{OldPassword: "old", NewPassword: "weak"}).
+			Return(model.AuthTokens{}, apperrors.ErrWeakPassword)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/auth/password", strings.NewReader(`{"oldPassword":"old","newPassword":"weak"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.SetRequest(req.WithContext(context.WithValue(req.Context(), ctxkey.ClaimsKey, claims)))
+
+		if assert.NoError(t, handler.ChangePassword(ctx)) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.Contains(t, rec.Body.String(), response.ErrWeakPasswordMessage)
+		}
+	})
+
+	t.Run("Missing passwords", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/auth/password", strings.NewReader(`{}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.SetRequest(req.WithContext(context.WithValue(req.Context(), ctxkey.ClaimsKey, claims)))
+
+		if assert.NoError(t, handler.ChangePassword(ctx)) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.Contains(t, rec.Body.String(), "oldPassword is required;newPassword is required;")
+		}
+	})
+}
+
+func TestResetPassword(t *testing.T) {
+	e := echo.New()
+	mockAuthService := new(MockAuthService)
+	handler := NewAuthHandler(e, mockAuthService, nil)
+
+	t.Run("Successful reset", func(t *testing.T) {
+		mockAuthService.
+			On("ResetPassword", mock.Anything, model.ResetPasswordInput{ResetToken: "reset", NewPassword: "new-password1"}).
+			Return(nil)
+
+		body := `{"resetToken":"reset","newPassword":"new-password1"}`
+		req := httptest.NewRequest(http.MethodPost, "/api/auth/password/reset", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+
+		if assert.NoError(t, handler.ResetPassword(ctx)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+		}
+	})
+
+	t.Run("Expired token", func(t *testing.T) {
+		mockAuthService.
+			On("ResetPassword", mock.Anything, model.ResetPasswordInput{ResetToken: "expired", NewPassword: "new-password1"}).
+			Return(apperrors.ErrResetTokenExpired)
+
+		body := `{"resetToken":"expired","newPassword":"new-password1"}`
+		req := httptest.NewRequest(http.MethodPost, "/api/auth/password/reset", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+
+		if assert.NoError(t, handler.ResetPassword(ctx)) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.Contains(t, rec.Body.String(), response.ErrResetTokenExpiredMessage)
+		}
+	})
+}
+
+func TestConfirmTOTP(t *testing.T) {
+	e := echo.New()
+	mockAuthService := new(MockAuthService)
+	handler := NewAuthHandler(e, mockAuthService, nil)
+
+	claims := model.Claims{UserID: 1, TokenID: "jti"}
+
+	t.Run("Successful confirmation", func(t *testing.T) {
+		mockAuthService.
+			On("ConfirmTOTP", mock.Anything, claims, model.ConfirmTOTPInput{Code: "123456", Threshold: 100}).
+			Return([]string{"ABCD-EFGH"}, nil)
+
+		body := `{"code":"123456","threshold":100}`
+		req := httptest.NewRequest(http.MethodPost, "/api/auth/2fa/confirm", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.SetRequest(req.WithContext(context.WithValue(req.Context(), ctxkey.ClaimsKey, claims)))
+
+		if assert.NoError(t, handler.ConfirmTOTP(ctx)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Contains(t, rec.Body.String(), `"recoveryCodes":["ABCD-EFGH"]`)
+		}
+	})
+
+	t.Run("Invalid code", func(t *testing.T) {
+		mockAuthService.
+			On("ConfirmTOTP", mock.Anything, claims, model.ConfirmTOTPInput{Code: "000000"}).
+			Return([]string(nil), apperrors.ErrInvalidOTP)
+
+		req := httptest.NewRequest(http.MethodPost, "/api/auth/2fa/confirm", strings.NewReader(`{"code":"000000"}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.SetRequest(req.WithContext(context.WithValue(req.Context(), ctxkey.ClaimsKey, claims)))
+
+		if assert.NoError(t, handler.ConfirmTOTP(ctx)) {
+			assert.Equal(t, http.StatusUnauthorized, rec.Code)
+			assert.Contains(t, rec.Body.String(), response.ErrInvalidOTPMessage)
+		}
+	})
+
+	t.Run("Missing code", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/auth/2fa/confirm", strings.NewReader(`{}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.SetRequest(req.WithContext(context.WithValue(req.Context(), ctxkey.ClaimsKey, claims)))
+
+		if assert.NoError(t, handler.ConfirmTOTP(ctx)) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.Contains(t, rec.Body.String(), "code is required;")
+		}
+	})
+}
+
+func TestJWKS(t *testing.T) {
+	e := echo.New()
+	mockAuthService := new(MockAuthService)
+	handler := NewAuthHandler(e, mockAuthService, nil)
+
+	public, _, err := ed25519.GenerateKey(nil)
+	assert.NoError(t, err)
+
+	mockAuthService.
+		On("PublicKeys").
+		Return([]model.PublicKey{{ID: "2025-03", Algorithm: "EdDSA", Key: public}})
+
+	req := httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+
+	if assert.NoError(t, handler.JWKS(ctx)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"kid":"2025-03"`)
+		assert.Contains(t, rec.Body.String(), `"kty":"OKP"`)
+		assert.Contains(t, rec.Body.String(), `"crv":"Ed25519"`)
+	}
+}
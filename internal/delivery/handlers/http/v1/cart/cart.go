@@ -0,0 +1,150 @@
+//nolint:wrapcheck
+package cart
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo"
+	dto "github.com/resueman/merch-store/internal/api/v1"
+	"github.com/resueman/merch-store/internal/delivery/ctxkey"
+	"github.com/resueman/merch-store/internal/delivery/handlers/http/v1/converter"
+	"github.com/resueman/merch-store/internal/delivery/handlers/http/v1/response"
+	"github.com/resueman/merch-store/internal/delivery/middleware"
+	"github.com/resueman/merch-store/internal/model"
+	"github.com/resueman/merch-store/internal/usecase"
+)
+
+// Код второго фактора для заказов дороже порога, заданного пользователем.
+const otpCodeHeader = "X-OTP-Code"
+
+type CartHandler struct {
+	cartUsecase usecase.Cart
+}
+
+func NewCartHandler(e *echo.Echo, usecase usecase.Cart, idempotency echo.MiddlewareFunc,
+	m ...echo.MiddlewareFunc) *CartHandler {
+	h := &CartHandler{cartUsecase: usecase}
+
+	shop := middleware.WithScopes(m, model.ScopeShopBuy)
+	write := middleware.WithIdempotency(shop, idempotency)
+
+	e.GET("api/cart", h.GetCart, shop...)
+	e.POST("api/cart/items", h.AddCartItem, write...)
+	e.DELETE("api/cart/items/:item", h.RemoveCartItem, write...)
+	e.POST("api/checkout", h.Checkout, write...)
+
+	return h
+}
+
+// (GET /api/cart): получить содержимое корзины по текущим ценам.
+func (h *CartHandler) GetCart(c echo.Context) error {
+	ctx := c.Request().Context()
+	claimsValue := ctx.Value(ctxkey.ClaimsKey)
+	if claimsValue == nil {
+		return response.SendHandlerError(c, http.StatusUnauthorized, response.ErrInvalidClaimsMessage)
+	}
+
+	claims, ok := claimsValue.(model.Claims)
+	if !ok {
+		return response.SendHandlerError(c, http.StatusUnauthorized, response.ErrInvalidClaimsMessage)
+	}
+
+	cart, err := h.cartUsecase.GetCart(ctx, claims)
+	if err != nil {
+		return response.SendUsecaseError(c, err)
+	}
+
+	return response.SendOk(c, converter.ConvertCartToCartResponse(cart))
+}
+
+func (h *CartHandler) validateAddCartItemRequest(input *dto.AddCartItemRequest) string {
+	var errMsg strings.Builder
+	if input.Item == "" {
+		errMsg.WriteString("item is required;")
+	}
+
+	if input.Quantity != nil && *input.Quantity <= 0 {
+		errMsg.WriteString("quantity must be positive;")
+	}
+
+	return errMsg.String()
+}
+
+// (POST /api/cart/items): добавить товар в корзину.
+func (h *CartHandler) AddCartItem(c echo.Context) error {
+	ctx := c.Request().Context()
+	claimsValue := ctx.Value(ctxkey.ClaimsKey)
+	if claimsValue == nil {
+		return response.SendHandlerError(c, http.StatusUnauthorized, response.ErrInvalidClaimsMessage)
+	}
+
+	claims, ok := claimsValue.(model.Claims)
+	if !ok {
+		return response.SendHandlerError(c, http.StatusUnauthorized, response.ErrInvalidClaimsMessage)
+	}
+
+	var input dto.AddCartItemRequest
+	if err := c.Bind(&input); err != nil {
+		return response.SendHandlerError(c, http.StatusBadRequest, response.ErrBindingMessage)
+	}
+
+	if errMsg := h.validateAddCartItemRequest(&input); errMsg != "" {
+		return response.SendHandlerError(c, http.StatusBadRequest, errMsg)
+	}
+
+	if err := h.cartUsecase.AddToCart(ctx, claims, converter.ConvertAddCartItemRequestToInput(&input)); err != nil {
+		return response.SendUsecaseError(c, err)
+	}
+
+	return response.SendNoContent(c)
+}
+
+// (DELETE /api/cart/items/{item}): убрать товар из корзины.
+func (h *CartHandler) RemoveCartItem(c echo.Context) error {
+	ctx := c.Request().Context()
+	claimsValue := ctx.Value(ctxkey.ClaimsKey)
+	if claimsValue == nil {
+		return response.SendHandlerError(c, http.StatusUnauthorized, response.ErrInvalidClaimsMessage)
+	}
+
+	claims, ok := claimsValue.(model.Claims)
+	if !ok {
+		return response.SendHandlerError(c, http.StatusUnauthorized, response.ErrInvalidClaimsMessage)
+	}
+
+	item := c.Param("item")
+	if item == "" {
+		return response.SendHandlerError(c, http.StatusBadRequest, "item name is required")
+	}
+
+	// Без параметра variant убирается сам товар, а не один из его вариантов.
+	variant := c.QueryParam("variant")
+
+	if err := h.cartUsecase.RemoveFromCart(ctx, claims, item, variant); err != nil {
+		return response.SendUsecaseError(c, err)
+	}
+
+	return response.SendNoContent(c)
+}
+
+// (POST /api/checkout): купить все товары из корзины одной транзакцией.
+func (h *CartHandler) Checkout(c echo.Context) error {
+	ctx := c.Request().Context()
+	claimsValue := ctx.Value(ctxkey.ClaimsKey)
+	if claimsValue == nil {
+		return response.SendHandlerError(c, http.StatusUnauthorized, response.ErrInvalidClaimsMessage)
+	}
+
+	claims, ok := claimsValue.(model.Claims)
+	if !ok {
+		return response.SendHandlerError(c, http.StatusUnauthorized, response.ErrInvalidClaimsMessage)
+	}
+
+	cart, err := h.cartUsecase.Checkout(ctx, claims, c.Request().Header.Get(otpCodeHeader))
+	if err != nil {
+		return response.SendUsecaseError(c, err)
+	}
+
+	return response.SendOk(c, converter.ConvertCartToCartResponse(cart))
+}
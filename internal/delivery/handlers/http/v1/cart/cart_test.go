@@ -0,0 +1,227 @@
+package cart
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo"
+	dto "github.com/resueman/merch-store/internal/api/v1"
+	"github.com/resueman/merch-store/internal/delivery/ctxkey"
+	"github.com/resueman/merch-store/internal/model"
+	"github.com/resueman/merch-store/internal/usecase/apperrors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockCartUsecase struct {
+	mock.Mock
+}
+
+func (m *MockCartUsecase) AddToCart(ctx context.Context, claims model.Claims, input model.CartItemInput) error {
+	args := m.Called(ctx, claims, input)
+	return args.Error(0)
+}
+
+func (m *MockCartUsecase) RemoveFromCart(ctx context.Context, claims model.Claims, itemName string,
+	variant string,
+) error {
+	args := m.Called(ctx, claims, itemName, variant)
+	return args.Error(0)
+}
+
+func (m *MockCartUsecase) GetCart(ctx context.Context, claims model.Claims) (model.Cart, error) {
+	args := m.Called(ctx, claims)
+	return args.Get(0).(model.Cart), args.Error(1)
+}
+
+func (m *MockCartUsecase) Checkout(ctx context.Context, claims model.Claims, otpCode string) (model.Cart, error) {
+	args := m.Called(ctx, claims, otpCode)
+	return args.Get(0).(model.Cart), args.Error(1)
+}
+
+func newCartContext(e *echo.Echo, method, target, body string, claims model.Claims) (
+	echo.Context, *httptest.ResponseRecorder,
+) {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	ctx := context.WithValue(c.Request().Context(), ctxkey.ClaimsKey, claims)
+	c.SetRequest(c.Request().WithContext(ctx))
+
+	return c, rec
+}
+
+func TestGetCart(t *testing.T) {
+	e := echo.New()
+	mockUsecase := new(MockCartUsecase)
+	handler := NewCartHandler(e, mockUsecase, nil)
+
+	claims := model.Claims{UserID: 123}
+	mockUsecase.On("GetCart", mock.Anything, claims).Return(model.Cart{
+		Items:      []model.CartItem{{Name: "pen", Price: 10, Quantity: 3, TotalPrice: 30}},
+		TotalPrice: 30,
+	}, nil)
+
+	c, rec := newCartContext(e, http.MethodGet, "/api/cart", "", claims)
+
+	err := handler.GetCart(c)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var resp dto.CartResponse
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, dto.CartResponse{
+		Items:      []dto.CartItem{{Name: "pen", Price: 10, Quantity: 3, TotalPrice: 30}},
+		TotalPrice: 30,
+	}, resp)
+}
+
+func TestAddCartItem(t *testing.T) {
+	testCases := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		mockSetup      func(m *MockCartUsecase, claims model.Claims)
+	}{
+		{
+			name:           "quantity defaults to one",
+			body:           `{"item":"pen"}`,
+			expectedStatus: http.StatusOK,
+			mockSetup: func(m *MockCartUsecase, claims model.Claims) {
+				m.On("AddToCart", mock.Anything, claims, model.CartItemInput{ItemName: "pen", Quantity: 1}).Return(nil)
+			},
+		},
+		{
+			name:           "variant",
+			body:           `{"item":"hoody","variant":"XL","quantity":2}`,
+			expectedStatus: http.StatusOK,
+			mockSetup: func(m *MockCartUsecase, claims model.Claims) {
+				m.On("AddToCart", mock.Anything, claims,
+					model.CartItemInput{ItemName: "hoody", Variant: "XL", Quantity: 2}).Return(nil)
+			},
+		},
+		{
+			name:           "item is required",
+			body:           `{"quantity":2}`,
+			expectedStatus: http.StatusBadRequest,
+			mockSetup:      func(_ *MockCartUsecase, _ model.Claims) {},
+		},
+		{
+			name:           "negative quantity",
+			body:           `{"item":"pen","quantity":-2}`,
+			expectedStatus: http.StatusBadRequest,
+			mockSetup:      func(_ *MockCartUsecase, _ model.Claims) {},
+		},
+		{
+			name:           "unknown product",
+			body:           `{"item":"jujuju","quantity":2}`,
+			expectedStatus: http.StatusBadRequest,
+			mockSetup: func(m *MockCartUsecase, claims model.Claims) {
+				m.On("AddToCart", mock.Anything, claims, model.CartItemInput{ItemName: "jujuju", Quantity: 2}).
+					Return(apperrors.ErrProductNotFound)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			e := echo.New()
+			mockUsecase := new(MockCartUsecase)
+			handler := NewCartHandler(e, mockUsecase, nil)
+
+			claims := model.Claims{UserID: 123}
+			testCase.mockSetup(mockUsecase, claims)
+
+			c, rec := newCartContext(e, http.MethodPost, "/api/cart/items", testCase.body, claims)
+
+			err := handler.AddCartItem(c)
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expectedStatus, rec.Code)
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRemoveCartItem_NotInCart(t *testing.T) {
+	e := echo.New()
+	mockUsecase := new(MockCartUsecase)
+	handler := NewCartHandler(e, mockUsecase, nil)
+
+	claims := model.Claims{UserID: 123}
+	mockUsecase.On("RemoveFromCart", mock.Anything, claims, "pen", "").Return(apperrors.ErrCartItemNotFound)
+
+	c, rec := newCartContext(e, http.MethodDelete, "/api/cart/items/pen", "", claims)
+	c.SetParamNames("item")
+	c.SetParamValues("pen")
+
+	err := handler.RemoveCartItem(c)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestRemoveCartItem_Variant(t *testing.T) {
+	e := echo.New()
+	mockUsecase := new(MockCartUsecase)
+	handler := NewCartHandler(e, mockUsecase, nil)
+
+	claims := model.Claims{UserID: 123}
+	mockUsecase.On("RemoveFromCart", mock.Anything, claims, "hoody", "XL").Return(nil)
+
+	c, rec := newCartContext(e, http.MethodDelete, "/api/cart/items/hoody?variant=XL", "", claims)
+	c.SetParamNames("item")
+	c.SetParamValues("hoody")
+
+	err := handler.RemoveCartItem(c)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	mockUsecase.AssertExpectations(t)
+}
+
+func TestCheckout_LineErrors(t *testing.T) {
+	e := echo.New()
+	mockUsecase := new(MockCartUsecase)
+	handler := NewCartHandler(e, mockUsecase, nil)
+
+	claims := model.Claims{UserID: 123}
+	checkoutErr := &apperrors.CheckoutError{Lines: []apperrors.CheckoutLineError{
+		{ItemName: "retired", Err: apperrors.ErrProductNotFound},
+	}}
+	mockUsecase.On("Checkout", mock.Anything, claims, "").Return(model.Cart{}, checkoutErr)
+
+	c, rec := newCartContext(e, http.MethodPost, "/api/checkout", "", claims)
+
+	err := handler.Checkout(c)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	var resp dto.CheckoutErrorResponse
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	if assert.NotNil(t, resp.Lines) {
+		assert.Equal(t, []dto.CheckoutLineError{{Item: "retired", Errors: "product not found"}}, *resp.Lines)
+	}
+}
+
+func TestCheckout_Success(t *testing.T) {
+	e := echo.New()
+	mockUsecase := new(MockCartUsecase)
+	handler := NewCartHandler(e, mockUsecase, nil)
+
+	claims := model.Claims{UserID: 123}
+	mockUsecase.On("Checkout", mock.Anything, claims, "654321").Return(model.Cart{
+		Items:      []model.CartItem{{Name: "socks", Price: 10, Quantity: 12, TotalPrice: 120}},
+		TotalPrice: 120,
+	}, nil)
+
+	c, rec := newCartContext(e, http.MethodPost, "/api/checkout", "", claims)
+	c.Request().Header.Set(otpCodeHeader, "654321")
+
+	err := handler.Checkout(c)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
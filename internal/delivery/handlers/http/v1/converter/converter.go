@@ -1,6 +1,11 @@
 package converter
 
 import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"math/big"
+
 	dto "github.com/resueman/merch-store/internal/api/v1"
 	"github.com/resueman/merch-store/internal/model"
 )
@@ -8,6 +13,11 @@ import (
 func ConvertAccountInfoToInfoResponse(info *model.AccountInfo) dto.InfoResponse {
 	var infoResponse dto.InfoResponse
 	infoResponse.Coins = &info.Balance
+	infoResponse.LastOperationId = &info.LastOperationID
+
+	if !info.SnapshotAt.IsZero() {
+		infoResponse.SnapshotAt = &info.SnapshotAt
+	}
 
 	if len(info.Inventory) > 0 {
 		infoResponse.Inventory = convertInventory(info.Inventory)
@@ -102,3 +112,332 @@ func convertOutgoingTransfers(transfers []model.OutgoingTransfer) *[]struct {
 
 	return &result
 }
+
+func ConvertAuthRequestToInput(input *dto.AuthRequest) model.AuthRequestInput {
+	authInput := model.AuthRequestInput{Username: input.Username, Password: input.Password}
+	if input.OtpCode != nil {
+		authInput.OTPCode = *input.OtpCode
+	}
+
+	if input.Scopes != nil {
+		authInput.Scopes = make([]model.Scope, 0, len(*input.Scopes))
+		for _, s := range *input.Scopes {
+			authInput.Scopes = append(authInput.Scopes, model.Scope(s))
+		}
+	}
+
+	return authInput
+}
+
+// Ключи неизвестных типов пропускаются: клиенты JWKS игнорируют ключи, которые не умеют разбирать,
+// но лучше вообще не публиковать то, что нельзя корректно описать.
+func ConvertPublicKeysToJWKSet(keys []model.PublicKey) dto.JWKSet {
+	set := dto.JWKSet{Keys: make([]dto.JWK, 0, len(keys))}
+	for _, k := range keys {
+		jwk := dto.JWK{Alg: k.Algorithm, Kid: k.ID, Use: "sig"}
+
+		switch key := k.Key.(type) {
+		case *rsa.PublicKey:
+			n := base64.RawURLEncoding.EncodeToString(key.N.Bytes())
+			e := base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes())
+			jwk.Kty, jwk.N, jwk.E = "RSA", &n, &e
+		case ed25519.PublicKey:
+			crv, x := "Ed25519", base64.RawURLEncoding.EncodeToString(key)
+			jwk.Kty, jwk.Crv, jwk.X = "OKP", &crv, &x
+		default:
+			continue
+		}
+
+		set.Keys = append(set.Keys, jwk)
+	}
+
+	return set
+}
+
+func ConvertCreateAPIKeyRequestToInput(username string, input *dto.CreateApiKeyRequest) model.CreateAPIKeyInput {
+	keyInput := model.CreateAPIKeyInput{Username: username, Name: input.Name}
+	if input.Scopes != nil {
+		keyInput.Scopes = make([]model.Scope, 0, len(*input.Scopes))
+		for _, s := range *input.Scopes {
+			keyInput.Scopes = append(keyInput.Scopes, model.Scope(s))
+		}
+	}
+
+	return keyInput
+}
+
+func ConvertDeactivateUserRequestToInput(username string, input *dto.DeactivateUserRequest) model.DeactivateUserInput {
+	deactivateInput := model.DeactivateUserInput{Username: username}
+	if input.SweepBalance != nil {
+		deactivateInput.SweepBalance = *input.SweepBalance
+	}
+
+	return deactivateInput
+}
+
+func ConvertAPIKey(key model.APIKey) dto.ApiKey {
+	result := dto.ApiKey{
+		Id:         key.ID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     make([]string, 0, len(key.Scopes)),
+		CreatedAt:  key.CreatedAt,
+		LastUsedAt: key.LastUsedAt,
+		RevokedAt:  key.RevokedAt,
+	}
+
+	for _, s := range key.Scopes {
+		result.Scopes = append(result.Scopes, string(s))
+	}
+
+	if key.Key != "" {
+		result.Key = &key.Key
+	}
+
+	return result
+}
+
+func ConvertAddCartItemRequestToInput(input *dto.AddCartItemRequest) model.CartItemInput {
+	cartItemInput := model.CartItemInput{ItemName: input.Item, Quantity: 1}
+	if input.Quantity != nil {
+		cartItemInput.Quantity = *input.Quantity
+	}
+
+	if input.Variant != nil {
+		cartItemInput.Variant = *input.Variant
+	}
+
+	return cartItemInput
+}
+
+func ConvertCartToCartResponse(cart model.Cart) dto.CartResponse {
+	result := dto.CartResponse{
+		Items:      make([]dto.CartItem, 0, len(cart.Items)),
+		TotalPrice: cart.TotalPrice,
+	}
+
+	for _, item := range cart.Items {
+		cartItem := dto.CartItem{
+			Name:       item.Name,
+			Price:      item.Price,
+			Quantity:   item.Quantity,
+			TotalPrice: item.TotalPrice,
+		}
+
+		if item.Variant != "" {
+			cartItem.Variant = &item.Variant
+		}
+
+		result.Items = append(result.Items, cartItem)
+	}
+
+	return result
+}
+
+func ConvertRefundRequestToInput(input *dto.RefundRequest) model.RefundInput {
+	refundInput := model.RefundInput{PurchaseID: input.PurchaseId}
+	if input.Quantity != nil {
+		refundInput.Quantity = *input.Quantity
+	}
+
+	return refundInput
+}
+
+func ConvertRefund(refund model.Refund) dto.Refund {
+	return dto.Refund{
+		Id:         refund.ID,
+		PurchaseId: refund.PurchaseID,
+		Username:   refund.Username,
+		Item:       refund.ItemName,
+		Quantity:   refund.Quantity,
+		Amount:     refund.Amount,
+		Status:     dto.RefundStatus(refund.Status),
+		CreatedAt:  refund.CreatedAt,
+		DecidedAt:  refund.DecidedAt,
+	}
+}
+
+func ConvertRefunds(refunds []model.Refund) []dto.Refund {
+	result := make([]dto.Refund, 0, len(refunds))
+	for _, refund := range refunds {
+		result = append(result, ConvertRefund(refund))
+	}
+
+	return result
+}
+
+func ConvertRefundablePurchases(purchases []model.RefundablePurchase) []dto.RefundablePurchase {
+	result := make([]dto.RefundablePurchase, 0, len(purchases))
+	for _, purchase := range purchases {
+		result = append(result, dto.RefundablePurchase{
+			Id:                 purchase.ID,
+			Item:               purchase.ItemName,
+			Quantity:           purchase.Quantity,
+			RefundableQuantity: purchase.RefundableQuantity,
+			TotalPrice:         purchase.TotalPrice,
+			PurchasedAt:        purchase.PurchasedAt,
+			RefundableUntil:    purchase.RefundableUntil,
+		})
+	}
+
+	return result
+}
+
+func ConvertProduct(product model.Product) dto.Product {
+	result := dto.Product{
+		Name:        product.Name,
+		Price:       product.Price,
+		Stock:       product.Stock,
+		Description: product.Description,
+	}
+
+	if product.Variants != nil {
+		variants := ConvertProductVariants(product.Variants)
+		result.Variants = &variants
+	}
+
+	return result
+}
+
+func ConvertProductVariant(variant model.ProductVariant) dto.ProductVariant {
+	return dto.ProductVariant{
+		Name:  variant.Name,
+		Sku:   variant.SKU,
+		Price: variant.Price,
+		Stock: variant.Stock,
+	}
+}
+
+func ConvertProductVariants(variants []model.ProductVariant) []dto.ProductVariant {
+	result := make([]dto.ProductVariant, 0, len(variants))
+	for _, variant := range variants {
+		result = append(result, ConvertProductVariant(variant))
+	}
+
+	return result
+}
+
+func ConvertProducts(products []model.Product) []dto.Product {
+	result := make([]dto.Product, 0, len(products))
+	for _, product := range products {
+		result = append(result, ConvertProduct(product))
+	}
+
+	return result
+}
+
+func ConvertCreateProductRequest(request dto.CreateProductRequest) model.CreateProductInput {
+	input := model.CreateProductInput{Name: request.Name, Price: request.Price}
+	if request.Description != nil {
+		input.Description = *request.Description
+	}
+
+	if request.Stock != nil {
+		input.Stock = *request.Stock
+	}
+
+	return input
+}
+
+func ConvertProductAudit(entries []model.ProductAuditEntry) []dto.ProductAuditEntry {
+	result := make([]dto.ProductAuditEntry, 0, len(entries))
+	for _, entry := range entries {
+		item := dto.ProductAuditEntry{
+			Action:    dto.ProductAuditEntryAction(entry.Action),
+			ChangedBy: entry.ChangedBy,
+			ChangedAt: entry.CreatedAt,
+		}
+
+		if entry.Action != model.ProductAuditRetire {
+			field, newValue := entry.Field, entry.NewValue
+			item.Field, item.NewValue = &field, &newValue
+		}
+
+		if entry.Action == model.ProductAuditUpdate {
+			oldValue := entry.OldValue
+			item.OldValue = &oldValue
+		}
+
+		result = append(result, item)
+	}
+
+	return result
+}
+
+func ConvertProductPage(page model.ProductPage) dto.ProductListResponse {
+	return dto.ProductListResponse{
+		Items:  ConvertProducts(page.Items),
+		Total:  page.Total,
+		Limit:  page.Limit,
+		Offset: page.Offset,
+	}
+}
+
+func ConvertHistoryPage(page model.HistoryPage) dto.HistoryResponse {
+	items := make([]dto.HistoryEntry, 0, len(page.Items))
+	for _, entry := range page.Items {
+		item := dto.HistoryEntry{
+			Id:        entry.ID,
+			Type:      dto.HistoryEntryType(entry.Type),
+			Direction: dto.HistoryEntryDirection(entry.Direction),
+			Amount:    entry.Amount,
+			CreatedAt: entry.CreatedAt,
+		}
+
+		if entry.Type == model.HistoryTransfer {
+			counterparty := entry.Counterparty
+			item.Counterparty = &counterparty
+		} else {
+			itemName, quantity := entry.ItemName, entry.Quantity
+			item.Item, item.Quantity = &itemName, &quantity
+		}
+
+		if entry.Type == model.HistoryRefund {
+			status := dto.RefundStatus(entry.RefundStatus)
+			item.RefundStatus = &status
+		}
+
+		items = append(items, item)
+	}
+
+	response := dto.HistoryResponse{Items: items, Limit: page.Limit}
+	if page.NextCursor != "" {
+		nextCursor := page.NextCursor
+		response.NextCursor = &nextCursor
+	}
+
+	return response
+}
+
+func ConvertOperation(operation model.Operation) dto.Operation {
+	result := dto.Operation{
+		Id:        operation.ID,
+		Type:      dto.OperationType(operation.Type),
+		User:      operation.Username,
+		CreatedAt: operation.CreatedAt,
+	}
+
+	if operation.Type == model.HistoryTransfer {
+		recipient := operation.RecipientUsername
+		result.Recipient = &recipient
+	} else {
+		item, quantity := operation.ItemName, operation.Quantity
+		result.Item, result.Quantity = &item, &quantity
+	}
+
+	switch operation.Type {
+	case model.HistoryPurchase:
+		totalPrice := operation.TotalPrice
+		result.TotalPrice = &totalPrice
+	case model.HistoryTransfer, model.HistoryRefund:
+		amount := operation.Amount
+		result.Amount = &amount
+	}
+
+	if operation.Type == model.HistoryRefund {
+		status := dto.RefundStatus(operation.RefundStatus)
+		result.RefundStatus = &status
+	}
+
+	return result
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/labstack/echo"
 	"github.com/resueman/merch-store/internal/delivery/ctxkey"
 	"github.com/resueman/merch-store/internal/model"
+	"github.com/resueman/merch-store/internal/usecase/apperrors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -17,10 +18,10 @@ import (
 func TestBuyItem_Success(t *testing.T) {
 	e := echo.New()
 	mockUsecase := new(MockOperationUsecase)
-	handler := NewOperationHandler(e, mockUsecase)
+	handler := NewOperationHandler(e, mockUsecase, nil)
 
 	claims := model.Claims{UserID: 123}
-	mockUsecase.On("BuyItem", mock.Anything, claims, "pen").Return(nil)
+	mockUsecase.On("BuyItem", mock.Anything, claims, "pen", "", 1, "").Return(nil)
 
 	req := httptest.NewRequest(http.MethodGet, "/api/buy/pen", nil)
 	rec := httptest.NewRecorder()
@@ -38,7 +39,7 @@ func TestBuyItem_Success(t *testing.T) {
 
 func TestBuyItem_ErrorUnauthorized(t *testing.T) {
 	e := echo.New()
-	handler := NewOperationHandler(e, new(MockOperationUsecase))
+	handler := NewOperationHandler(e, new(MockOperationUsecase), nil)
 
 	req := httptest.NewRequest(http.MethodGet, "/api/buy/", nil)
 	rec := httptest.NewRecorder()
@@ -52,7 +53,7 @@ func TestBuyItem_ErrorUnauthorized(t *testing.T) {
 func TestBuyItem_BadClaims(t *testing.T) {
 	e := echo.New()
 	mockUsecase := new(MockOperationUsecase)
-	handler := NewOperationHandler(e, mockUsecase)
+	handler := NewOperationHandler(e, mockUsecase, nil)
 
 	claims := struct {
 		UserID int
@@ -60,7 +61,7 @@ func TestBuyItem_BadClaims(t *testing.T) {
 		UserID: 123,
 	}
 
-	mockUsecase.On("BuyItem", mock.Anything, claims, "pen").Return(nil)
+	mockUsecase.On("BuyItem", mock.Anything, claims, "pen", "", 1, "").Return(nil)
 
 	req := httptest.NewRequest(http.MethodGet, "/api/buy/", nil)
 	rec := httptest.NewRecorder()
@@ -77,7 +78,7 @@ func TestBuyItem_BadClaims(t *testing.T) {
 func TestBuyItem_ErrorBadRequest(t *testing.T) {
 	e := echo.New()
 	mockUsecase := new(MockOperationUsecase)
-	handler := NewOperationHandler(e, mockUsecase)
+	handler := NewOperationHandler(e, mockUsecase, nil)
 
 	claims := model.Claims{UserID: 123}
 	req := httptest.NewRequest(http.MethodGet, "/api/buy/", nil)
@@ -95,10 +96,10 @@ func TestBuyItem_ErrorBadRequest(t *testing.T) {
 func TestBuyItem_ErrorUsecase(t *testing.T) {
 	e := echo.New()
 	mockUsecase := new(MockOperationUsecase)
-	handler := NewOperationHandler(e, mockUsecase)
+	handler := NewOperationHandler(e, mockUsecase, nil)
 
 	claims := model.Claims{UserID: 123}
-	mockUsecase.On("BuyItem", mock.Anything, claims, "pen").Return(errors.New("some error"))
+	mockUsecase.On("BuyItem", mock.Anything, claims, "pen", "", 1, "").Return(errors.New("some error"))
 
 	req := httptest.NewRequest(http.MethodGet, "/api/buy/", nil)
 	rec := httptest.NewRecorder()
@@ -113,3 +114,76 @@ func TestBuyItem_ErrorUsecase(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, http.StatusInternalServerError, rec.Code)
 }
+
+func TestBuyItem_Quantity(t *testing.T) {
+	testCases := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		mockSetup      func(m *MockOperationUsecase, claims model.Claims)
+	}{
+		{
+			name:           "several units",
+			query:          "?quantity=12",
+			expectedStatus: http.StatusOK,
+			mockSetup: func(m *MockOperationUsecase, claims model.Claims) {
+				m.On("BuyItem", mock.Anything, claims, "socks", "", 12, "").Return(nil)
+			},
+		},
+		{
+			name:           "variant",
+			query:          "?variant=XL&quantity=2",
+			expectedStatus: http.StatusOK,
+			mockSetup: func(m *MockOperationUsecase, claims model.Claims) {
+				m.On("BuyItem", mock.Anything, claims, "socks", "XL", 2, "").Return(nil)
+			},
+		},
+		{
+			name:           "unknown variant",
+			query:          "?variant=XXXL",
+			expectedStatus: http.StatusBadRequest,
+			mockSetup: func(m *MockOperationUsecase, claims model.Claims) {
+				m.On("BuyItem", mock.Anything, claims, "socks", "XXXL", 1, "").Return(apperrors.ErrVariantNotFound)
+			},
+		},
+		{
+			name:           "not a number",
+			query:          "?quantity=many",
+			expectedStatus: http.StatusBadRequest,
+			mockSetup:      func(_ *MockOperationUsecase, _ model.Claims) {},
+		},
+		{
+			name:           "limit exceeded",
+			query:          "?quantity=1000",
+			expectedStatus: http.StatusBadRequest,
+			mockSetup: func(m *MockOperationUsecase, claims model.Claims) {
+				m.On("BuyItem", mock.Anything, claims, "socks", "", 1000, "").Return(apperrors.ErrQuantityLimitExceeded)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			e := echo.New()
+			mockUsecase := new(MockOperationUsecase)
+			handler := NewOperationHandler(e, mockUsecase, nil)
+
+			claims := model.Claims{UserID: 123}
+			testCase.mockSetup(mockUsecase, claims)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/buy/socks"+testCase.query, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("item")
+			c.SetParamValues("socks")
+
+			ctx := context.WithValue(c.Request().Context(), ctxkey.ClaimsKey, claims)
+			c.SetRequest(c.Request().WithContext(ctx))
+
+			err := handler.BuyItem(c)
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expectedStatus, rec.Code)
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
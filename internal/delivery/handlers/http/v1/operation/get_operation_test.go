@@ -0,0 +1,137 @@
+package operation
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo"
+	dto "github.com/resueman/merch-store/internal/api/v1"
+	"github.com/resueman/merch-store/internal/delivery/ctxkey"
+	"github.com/resueman/merch-store/internal/model"
+	"github.com/resueman/merch-store/internal/usecase/apperrors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func getOperationContext(e *echo.Echo, claims *model.Claims, id string) (echo.Context, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(http.MethodGet, "/api/operations/"+id, nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(id)
+
+	if claims != nil {
+		c.SetRequest(c.Request().WithContext(context.WithValue(c.Request().Context(), ctxkey.ClaimsKey, *claims)))
+	}
+
+	return c, rec
+}
+
+func TestGetOperation_Transfer(t *testing.T) {
+	e := echo.New()
+	mockUsecase := new(MockOperationUsecase)
+	handler := NewOperationHandler(e, mockUsecase, nil)
+
+	claims := model.Claims{UserID: 123}
+	createdAt := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	mockUsecase.On("GetOperation", mock.Anything, claims, 42).Return(model.Operation{
+		ID:                42,
+		Type:              model.HistoryTransfer,
+		Username:          "A",
+		RecipientUsername: "B",
+		Amount:            50,
+		CreatedAt:         createdAt,
+	}, nil)
+
+	c, rec := getOperationContext(e, &claims, "42")
+
+	require.NoError(t, handler.GetOperation(c))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var operation dto.Operation
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&operation))
+	assert.Equal(t, dto.OperationTypeTransfer, operation.Type)
+	assert.Equal(t, "A", operation.User)
+	assert.Equal(t, "B", *operation.Recipient)
+	assert.Equal(t, 50, *operation.Amount)
+	assert.Nil(t, operation.Item)
+	assert.Nil(t, operation.TotalPrice)
+	assert.True(t, createdAt.Equal(operation.CreatedAt))
+}
+
+func TestGetOperation_Purchase(t *testing.T) {
+	e := echo.New()
+	mockUsecase := new(MockOperationUsecase)
+	handler := NewOperationHandler(e, mockUsecase, nil)
+
+	claims := model.Claims{UserID: 123}
+	mockUsecase.On("GetOperation", mock.Anything, claims, 7).Return(model.Operation{
+		ID:         7,
+		Type:       model.HistoryPurchase,
+		Username:   "A",
+		ItemName:   "cup",
+		Quantity:   3,
+		TotalPrice: 60,
+	}, nil)
+
+	c, rec := getOperationContext(e, &claims, "7")
+
+	require.NoError(t, handler.GetOperation(c))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var operation dto.Operation
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&operation))
+	assert.Equal(t, "cup", *operation.Item)
+	assert.Equal(t, 3, *operation.Quantity)
+	assert.Equal(t, 60, *operation.TotalPrice)
+	assert.Nil(t, operation.Recipient)
+	assert.Nil(t, operation.Amount)
+}
+
+func TestGetOperation_Errors(t *testing.T) {
+	claims := model.Claims{UserID: 123}
+
+	t.Run("unauthorized", func(t *testing.T) {
+		e := echo.New()
+		handler := NewOperationHandler(e, new(MockOperationUsecase), nil)
+
+		c, rec := getOperationContext(e, nil, "1")
+
+		assert.NoError(t, handler.GetOperation(c))
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("invalid id", func(t *testing.T) {
+		e := echo.New()
+		mockUsecase := new(MockOperationUsecase)
+		handler := NewOperationHandler(e, mockUsecase, nil)
+
+		for _, id := range []string{"abc", "0", "-1"} {
+			c, rec := getOperationContext(e, &claims, id)
+
+			assert.NoError(t, handler.GetOperation(c))
+			assert.Equal(t, http.StatusBadRequest, rec.Code, id)
+		}
+
+		mockUsecase.AssertNotCalled(t, "GetOperation", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("not found or not a participant", func(t *testing.T) {
+		e := echo.New()
+		mockUsecase := new(MockOperationUsecase)
+		handler := NewOperationHandler(e, mockUsecase, nil)
+
+		mockUsecase.On("GetOperation", mock.Anything, claims, 5).
+			Return(model.Operation{}, apperrors.ErrOperationNotFound)
+
+		c, rec := getOperationContext(e, &claims, "5")
+
+		assert.NoError(t, handler.GetOperation(c))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
@@ -3,25 +3,35 @@ package operation
 
 import (
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/labstack/echo"
 	dto "github.com/resueman/merch-store/internal/api/v1"
 	"github.com/resueman/merch-store/internal/delivery/ctxkey"
+	"github.com/resueman/merch-store/internal/delivery/handlers/http/v1/converter"
 	"github.com/resueman/merch-store/internal/delivery/handlers/http/v1/response"
+	"github.com/resueman/merch-store/internal/delivery/middleware"
 	"github.com/resueman/merch-store/internal/model"
 	"github.com/resueman/merch-store/internal/usecase"
 )
 
+// Код второго фактора для переводов и покупок дороже порога, заданного пользователем.
+const otpCodeHeader = "X-OTP-Code"
+
 type OperationHandler struct {
 	operationUsecase usecase.Operation
 }
 
-func NewOperationHandler(e *echo.Echo, usecase usecase.Operation, m ...echo.MiddlewareFunc) *OperationHandler {
+func NewOperationHandler(e *echo.Echo, usecase usecase.Operation, idempotency echo.MiddlewareFunc,
+	m ...echo.MiddlewareFunc) *OperationHandler {
 	h := &OperationHandler{operationUsecase: usecase}
 
-	e.GET("api/buy/:item", h.BuyItem, m...)
-	e.POST("api/sendCoin", h.SendCoin, m...)
+	e.GET("api/buy/:item", h.BuyItem,
+		middleware.WithIdempotency(middleware.WithScopes(m, model.ScopeShopBuy), idempotency)...)
+	e.POST("api/sendCoin", h.SendCoin,
+		middleware.WithIdempotency(middleware.WithScopes(m, model.ScopeCoinsSend), idempotency)...)
+	e.GET("api/operations/:id", h.GetOperation, middleware.WithScopes(m, model.ScopeInfoRead)...)
 
 	return h
 }
@@ -44,7 +54,22 @@ func (h *OperationHandler) BuyItem(c echo.Context) error {
 		return response.SendHandlerError(c, http.StatusBadRequest, "item name is required")
 	}
 
-	if err := h.operationUsecase.BuyItem(ctx, claims, item); err != nil {
+	// Без параметра quantity покупается одна единица товара.
+	quantity := 1
+	if value := c.QueryParam("quantity"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return response.SendHandlerError(c, http.StatusBadRequest, "quantity must be an integer")
+		}
+
+		quantity = parsed
+	}
+
+	// Без параметра variant покупается сам товар, а не один из его вариантов.
+	variant := c.QueryParam("variant")
+
+	otpCode := c.Request().Header.Get(otpCodeHeader)
+	if err := h.operationUsecase.BuyItem(ctx, claims, item, variant, quantity, otpCode); err != nil {
 		return response.SendUsecaseError(c, err)
 	}
 
@@ -86,10 +111,33 @@ func (h *OperationHandler) SendCoin(c echo.Context) error {
 		return response.SendHandlerError(c, http.StatusBadRequest, errMsg)
 	}
 
-	err := h.operationUsecase.SendCoin(ctx, claims, input.ToUser, input.Amount)
+	otpCode := c.Request().Header.Get(otpCodeHeader)
+	err := h.operationUsecase.SendCoin(ctx, claims, input.ToUser, input.Amount, otpCode)
 	if err != nil {
 		return response.SendUsecaseError(c, err)
 	}
 
 	return response.SendNoContent(c)
 }
+
+// (GET /api/operations/{id}): одна операция с участниками, суммой и товаром.
+// Доступна участникам операции и администраторам.
+func (h *OperationHandler) GetOperation(c echo.Context) error {
+	ctx := c.Request().Context()
+	claims, ok := ctx.Value(ctxkey.ClaimsKey).(model.Claims)
+	if !ok {
+		return response.SendHandlerError(c, http.StatusUnauthorized, response.ErrInvalidClaimsMessage)
+	}
+
+	operationID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || operationID <= 0 {
+		return response.SendHandlerError(c, http.StatusBadRequest, "invalid operation id")
+	}
+
+	operation, err := h.operationUsecase.GetOperation(ctx, claims, operationID)
+	if err != nil {
+		return response.SendUsecaseError(c, err)
+	}
+
+	return response.SendOk(c, converter.ConvertOperation(operation))
+}
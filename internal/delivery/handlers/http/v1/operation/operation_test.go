@@ -14,20 +14,31 @@ type MockOperationUsecase struct {
 	mock.Mock
 }
 
-func (m *MockOperationUsecase) BuyItem(ctx context.Context, claims model.Claims, item string) error {
-	args := m.Called(ctx, claims, item)
+func (m *MockOperationUsecase) BuyItem(ctx context.Context, claims model.Claims, item string, variant string,
+	quantity int, otpCode string,
+) error {
+	args := m.Called(ctx, claims, item, variant, quantity, otpCode)
 	return args.Error(0)
 }
 
-func (m *MockOperationUsecase) SendCoin(ctx context.Context, claims model.Claims, toUser string, amount int) error {
-	args := m.Called(ctx, claims, toUser, amount)
+func (m *MockOperationUsecase) SendCoin(ctx context.Context, claims model.Claims, toUser string, amount int,
+	otpCode string,
+) error {
+	args := m.Called(ctx, claims, toUser, amount, otpCode)
 	return args.Error(0)
 }
 
+func (m *MockOperationUsecase) GetOperation(ctx context.Context, claims model.Claims, operationID int) (
+	model.Operation, error,
+) {
+	args := m.Called(ctx, claims, operationID)
+	return args.Get(0).(model.Operation), args.Error(1)
+}
+
 func TestNewOperationHandler(t *testing.T) {
 	e := echo.New()
 	mockUsecase := new(MockOperationUsecase)
-	handler := NewOperationHandler(e, mockUsecase)
+	handler := NewOperationHandler(e, mockUsecase, nil)
 
 	assert.NotNil(t, handler)
 }
@@ -11,6 +11,7 @@ import (
 	"github.com/labstack/echo"
 	"github.com/resueman/merch-store/internal/delivery/ctxkey"
 	"github.com/resueman/merch-store/internal/model"
+	"github.com/resueman/merch-store/internal/usecase/apperrors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -18,10 +19,10 @@ import (
 func TestSendCoin_Success(t *testing.T) {
 	e := echo.New()
 	mockUsecase := new(MockOperationUsecase)
-	handler := NewOperationHandler(e, mockUsecase)
+	handler := NewOperationHandler(e, mockUsecase, nil)
 
 	claims := model.Claims{UserID: 123}
-	mockUsecase.On("SendCoin", mock.Anything, claims, "B", 100).Return(nil)
+	mockUsecase.On("SendCoin", mock.Anything, claims, "B", 100, "").Return(nil)
 
 	req := httptest.NewRequest(http.MethodPost, "/api/sendCoin", strings.NewReader(`{"toUser":"B","amount":100}`))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
@@ -38,7 +39,7 @@ func TestSendCoin_Success(t *testing.T) {
 
 func TestSendCoin_ErrorUnauthorized(t *testing.T) {
 	e := echo.New()
-	handler := NewOperationHandler(e, new(MockOperationUsecase))
+	handler := NewOperationHandler(e, new(MockOperationUsecase), nil)
 
 	req := httptest.NewRequest(http.MethodPost, "/api/sendCoin", nil)
 	rec := httptest.NewRecorder()
@@ -52,7 +53,7 @@ func TestSendCoin_ErrorUnauthorized(t *testing.T) {
 func TestSendCoin_ErrorBadRequestNoToUser(t *testing.T) {
 	e := echo.New()
 	mockUsecase := new(MockOperationUsecase)
-	handler := NewOperationHandler(e, mockUsecase)
+	handler := NewOperationHandler(e, mockUsecase, nil)
 
 	claims := model.Claims{UserID: 123}
 	req := httptest.NewRequest(http.MethodPost, "/api/sendCoin", strings.NewReader(`{"toUser":"","amount":100}`))
@@ -71,7 +72,7 @@ func TestSendCoin_ErrorBadRequestNoToUser(t *testing.T) {
 func TestSendCoin_ErrorBadRequestIncorrectAmount(t *testing.T) {
 	e := echo.New()
 	mockUsecase := new(MockOperationUsecase)
-	handler := NewOperationHandler(e, mockUsecase)
+	handler := NewOperationHandler(e, mockUsecase, nil)
 
 	claims := model.Claims{UserID: 123}
 	req := httptest.NewRequest(http.MethodPost, "/api/sendCoin", strings.NewReader(`{"toUser":"B","amount":0}`))
@@ -90,7 +91,7 @@ func TestSendCoin_ErrorBadRequestIncorrectAmount(t *testing.T) {
 func TestSendCoin_BadClaims(t *testing.T) {
 	e := echo.New()
 	mockUsecase := new(MockOperationUsecase)
-	handler := NewOperationHandler(e, mockUsecase)
+	handler := NewOperationHandler(e, mockUsecase, nil)
 
 	claims := struct {
 		UserID int
@@ -98,7 +99,7 @@ func TestSendCoin_BadClaims(t *testing.T) {
 		UserID: 123,
 	}
 
-	mockUsecase.On("SendCoin", mock.Anything, claims, "B", 100).Return(nil)
+	mockUsecase.On("SendCoin", mock.Anything, claims, "B", 100, "").Return(nil)
 
 	req := httptest.NewRequest(http.MethodPost, "/api/sendCoin", nil)
 	rec := httptest.NewRecorder()
@@ -115,7 +116,7 @@ func TestSendCoin_BadClaims(t *testing.T) {
 func TestSendCoin_ErrorBinding(t *testing.T) {
 	e := echo.New()
 	mockUsecase := new(MockOperationUsecase)
-	handler := NewOperationHandler(e, mockUsecase)
+	handler := NewOperationHandler(e, mockUsecase, nil)
 
 	claims := model.Claims{UserID: 123}
 	req := httptest.NewRequest(http.MethodPost, "/api/sendCoin", strings.NewReader(`{"invalidJson}`))
@@ -134,10 +135,10 @@ func TestSendCoin_ErrorBinding(t *testing.T) {
 func TestSendCoin_ErrorUsecase(t *testing.T) {
 	e := echo.New()
 	mockUsecase := new(MockOperationUsecase)
-	handler := NewOperationHandler(e, mockUsecase)
+	handler := NewOperationHandler(e, mockUsecase, nil)
 
 	claims := model.Claims{UserID: 123}
-	mockUsecase.On("SendCoin", mock.Anything, claims, "user2", 100).Return(errors.New("error"))
+	mockUsecase.On("SendCoin", mock.Anything, claims, "user2", 100, "").Return(errors.New("error"))
 
 	req := httptest.NewRequest(http.MethodPost, "/api/sendCoin", strings.NewReader(`{"toUser":"user2","amount":100}`))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
@@ -151,3 +152,34 @@ func TestSendCoin_ErrorUsecase(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, http.StatusInternalServerError, rec.Code)
 }
+
+func TestSendCoin_OTPCodeHeader(t *testing.T) {
+	e := echo.New()
+	mockUsecase := new(MockOperationUsecase)
+	handler := NewOperationHandler(e, mockUsecase, nil)
+
+	claims := model.Claims{UserID: 123}
+	mockUsecase.On("SendCoin", mock.Anything, claims, "B", 1000, "123456").Return(nil)
+	mockUsecase.On("SendCoin", mock.Anything, claims, "B", 1000, "").Return(apperrors.ErrOTPRequired)
+
+	send := func(otpCode string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/sendCoin", strings.NewReader(`{"toUser":"B","amount":1000}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		if otpCode != "" {
+			req.Header.Set("X-OTP-Code", otpCode)
+		}
+
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		ctx := context.WithValue(c.Request().Context(), ctxkey.ClaimsKey, claims)
+		c.SetRequest(c.Request().WithContext(ctx))
+
+		assert.NoError(t, handler.SendCoin(c))
+
+		return rec
+	}
+
+	assert.Equal(t, http.StatusOK, send("123456").Code)
+	assert.Equal(t, http.StatusUnauthorized, send("").Code)
+}
@@ -0,0 +1,112 @@
+//nolint:wrapcheck
+package product
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo"
+	"github.com/resueman/merch-store/internal/delivery/handlers/http/v1/converter"
+	"github.com/resueman/merch-store/internal/delivery/handlers/http/v1/response"
+	"github.com/resueman/merch-store/internal/delivery/middleware"
+	"github.com/resueman/merch-store/internal/model"
+	"github.com/resueman/merch-store/internal/usecase"
+)
+
+type ProductHandler struct {
+	productUsecase usecase.Product
+}
+
+func NewProductHandler(e *echo.Echo, usecase usecase.Product, m ...echo.MiddlewareFunc) *ProductHandler {
+	h := &ProductHandler{productUsecase: usecase}
+
+	e.GET("api/products", h.GetProducts, middleware.WithScopes(m, model.ScopeShopBuy)...)
+	e.GET("api/products/:name", h.GetProduct, middleware.WithScopes(m, model.ScopeShopBuy)...)
+
+	return h
+}
+
+// Разбирает необязательный целочисленный параметр запроса. nil означает, что параметр не передан.
+func queryInt(c echo.Context, name string) (*int, bool) {
+	value := c.QueryParam(name)
+	if value == "" {
+		return nil, true
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, false
+	}
+
+	return &parsed, true
+}
+
+// (GET /api/products): каталог товаров с фильтрами, сортировкой и постраничным выводом.
+func (h *ProductHandler) GetProducts(c echo.Context) error {
+	filter := model.ProductFilter{
+		Query:  c.QueryParam("q"),
+		SortBy: model.ProductSort(c.QueryParam("sort")),
+	}
+
+	switch c.QueryParam("order") {
+	case "", "asc":
+	case "desc":
+		filter.Desc = true
+	default:
+		return response.SendHandlerError(c, http.StatusBadRequest, "order must be one of: asc, desc")
+	}
+
+	var ok bool
+	if filter.MinPrice, ok = queryInt(c, "minPrice"); !ok {
+		return response.SendHandlerError(c, http.StatusBadRequest, "minPrice must be an integer")
+	}
+
+	if filter.MaxPrice, ok = queryInt(c, "maxPrice"); !ok {
+		return response.SendHandlerError(c, http.StatusBadRequest, "maxPrice must be an integer")
+	}
+
+	limit, ok := queryInt(c, "limit")
+	if !ok {
+		return response.SendHandlerError(c, http.StatusBadRequest, "limit must be an integer")
+	}
+
+	if limit != nil {
+		// Явно переданный ноль не должен превращаться в размер страницы по умолчанию.
+		if *limit <= 0 {
+			return response.SendHandlerError(c, http.StatusBadRequest, response.ErrInvalidPaginationMessage)
+		}
+
+		filter.Limit = *limit
+	}
+
+	offset, ok := queryInt(c, "offset")
+	if !ok {
+		return response.SendHandlerError(c, http.StatusBadRequest, "offset must be an integer")
+	}
+
+	if offset != nil {
+		filter.Offset = *offset
+	}
+
+	page, err := h.productUsecase.GetProducts(c.Request().Context(), filter)
+	if err != nil {
+		return response.SendUsecaseError(c, err)
+	}
+
+	return response.SendOk(c, converter.ConvertProductPage(page))
+}
+
+// (GET /api/products/{name}): карточка товара.
+func (h *ProductHandler) GetProduct(c echo.Context) error {
+	name := c.Param("name")
+	if name == "" {
+		return response.SendHandlerError(c, http.StatusBadRequest, "product name is required")
+	}
+
+	product, err := h.productUsecase.GetProduct(c.Request().Context(), name)
+	if err != nil {
+		return response.SendUsecaseError(c, err)
+	}
+
+	return response.SendOk(c, converter.ConvertProduct(product))
+}
@@ -0,0 +1,157 @@
+package product
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo"
+	dto "github.com/resueman/merch-store/internal/api/v1"
+	"github.com/resueman/merch-store/internal/model"
+	"github.com/resueman/merch-store/internal/usecase"
+	"github.com/resueman/merch-store/internal/usecase/apperrors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// Обработчику нужно только чтение каталога, остальные методы usecase.Product не вызываются.
+type MockProductUsecase struct {
+	usecase.Product
+	mock.Mock
+}
+
+func (m *MockProductUsecase) GetProducts(ctx context.Context, filter model.ProductFilter) (model.ProductPage, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).(model.ProductPage), args.Error(1)
+}
+
+func (m *MockProductUsecase) GetProduct(ctx context.Context, name string) (model.Product, error) {
+	args := m.Called(ctx, name)
+	return args.Get(0).(model.Product), args.Error(1)
+}
+
+func TestGetProducts(t *testing.T) {
+	minPrice, maxPrice := 10, 100
+
+	testCases := []struct {
+		name           string
+		target         string
+		expectedStatus int
+		mockSetup      func(m *MockProductUsecase)
+	}{
+		{
+			name:           "filters, sorting and pagination are passed to the usecase",
+			target:         "/api/products?q=hood&minPrice=10&maxPrice=100&sort=price&order=desc&limit=5&offset=10",
+			expectedStatus: http.StatusOK,
+			mockSetup: func(m *MockProductUsecase) {
+				m.On("GetProducts", mock.Anything, model.ProductFilter{
+					Query:    "hood",
+					MinPrice: &minPrice,
+					MaxPrice: &maxPrice,
+					SortBy:   model.ProductSortPrice,
+					Desc:     true,
+					Limit:    5,
+					Offset:   10,
+				}).Return(model.ProductPage{
+					Items:  []model.Product{{Name: "hoody", Price: 300, Stock: 3}},
+					Total:  11,
+					Limit:  5,
+					Offset: 10,
+				}, nil)
+			},
+		},
+		{
+			name:           "unknown order",
+			target:         "/api/products?order=up",
+			expectedStatus: http.StatusBadRequest,
+			mockSetup:      func(_ *MockProductUsecase) {},
+		},
+		{
+			name:           "price is not a number",
+			target:         "/api/products?minPrice=cheap",
+			expectedStatus: http.StatusBadRequest,
+			mockSetup:      func(_ *MockProductUsecase) {},
+		},
+		{
+			name:           "zero limit",
+			target:         "/api/products?limit=0",
+			expectedStatus: http.StatusBadRequest,
+			mockSetup:      func(_ *MockProductUsecase) {},
+		},
+		{
+			name:           "unknown sort",
+			target:         "/api/products?sort=stock",
+			expectedStatus: http.StatusBadRequest,
+			mockSetup: func(m *MockProductUsecase) {
+				m.On("GetProducts", mock.Anything, model.ProductFilter{SortBy: "stock"}).
+					Return(model.ProductPage{}, apperrors.ErrInvalidProductSort)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			e := echo.New()
+			mockUsecase := new(MockProductUsecase)
+			handler := NewProductHandler(e, mockUsecase)
+			testCase.mockSetup(mockUsecase)
+
+			req := httptest.NewRequest(http.MethodGet, testCase.target, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			err := handler.GetProducts(c)
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expectedStatus, rec.Code)
+			mockUsecase.AssertExpectations(t)
+
+			if testCase.expectedStatus == http.StatusOK {
+				var page dto.ProductListResponse
+				assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+				assert.Equal(t, 11, page.Total)
+				assert.Equal(t, []dto.Product{{Name: "hoody", Price: 300, Stock: 3}}, page.Items)
+			}
+		})
+	}
+}
+
+func TestGetProduct(t *testing.T) {
+	e := echo.New()
+	mockUsecase := new(MockProductUsecase)
+	handler := NewProductHandler(e, mockUsecase)
+
+	newProductContext := func(name string) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodGet, "/api/products/"+name, nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("name")
+		c.SetParamValues(name)
+
+		return c, rec
+	}
+
+	t.Run("Existing product", func(t *testing.T) {
+		mockUsecase.On("GetProduct", mock.Anything, "pen").
+			Return(model.Product{Name: "pen", Price: 10, Stock: 100}, nil)
+
+		c, rec := newProductContext("pen")
+
+		if assert.NoError(t, handler.GetProduct(c)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Contains(t, rec.Body.String(), `"price":10`)
+		}
+	})
+
+	t.Run("Unknown product", func(t *testing.T) {
+		mockUsecase.On("GetProduct", mock.Anything, "jujuju").
+			Return(model.Product{}, apperrors.ErrProductNotFound)
+
+		c, rec := newProductContext("jujuju")
+
+		if assert.NoError(t, handler.GetProduct(c)) {
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+		}
+	})
+}
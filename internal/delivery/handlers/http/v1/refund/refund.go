@@ -0,0 +1,102 @@
+//nolint:wrapcheck
+package refund
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo"
+	dto "github.com/resueman/merch-store/internal/api/v1"
+	"github.com/resueman/merch-store/internal/delivery/ctxkey"
+	"github.com/resueman/merch-store/internal/delivery/handlers/http/v1/converter"
+	"github.com/resueman/merch-store/internal/delivery/handlers/http/v1/response"
+	"github.com/resueman/merch-store/internal/delivery/middleware"
+	"github.com/resueman/merch-store/internal/model"
+	"github.com/resueman/merch-store/internal/usecase"
+)
+
+type RefundHandler struct {
+	refundUsecase usecase.Refund
+}
+
+func NewRefundHandler(e *echo.Echo, usecase usecase.Refund, idempotency echo.MiddlewareFunc,
+	m ...echo.MiddlewareFunc) *RefundHandler {
+	h := &RefundHandler{refundUsecase: usecase}
+
+	e.GET("api/purchases/refundable", h.GetRefundablePurchases, middleware.WithScopes(m, model.ScopeShopBuy)...)
+	e.GET("api/refunds", h.GetRefunds, middleware.WithScopes(m, model.ScopeShopBuy)...)
+	e.POST("api/refunds", h.RequestRefund,
+		middleware.WithIdempotency(middleware.WithScopes(m, model.ScopeShopBuy), idempotency)...)
+
+	return h
+}
+
+// (GET /api/purchases/refundable): покупки, по которым еще можно подать заявку на возврат.
+func (h *RefundHandler) GetRefundablePurchases(c echo.Context) error {
+	ctx := c.Request().Context()
+	claims, ok := ctx.Value(ctxkey.ClaimsKey).(model.Claims)
+	if !ok {
+		return response.SendHandlerError(c, http.StatusUnauthorized, response.ErrInvalidClaimsMessage)
+	}
+
+	purchases, err := h.refundUsecase.GetRefundablePurchases(ctx, claims)
+	if err != nil {
+		return response.SendUsecaseError(c, err)
+	}
+
+	return response.SendOk(c, converter.ConvertRefundablePurchases(purchases))
+}
+
+// (GET /api/refunds): заявки пользователя на возврат.
+func (h *RefundHandler) GetRefunds(c echo.Context) error {
+	ctx := c.Request().Context()
+	claims, ok := ctx.Value(ctxkey.ClaimsKey).(model.Claims)
+	if !ok {
+		return response.SendHandlerError(c, http.StatusUnauthorized, response.ErrInvalidClaimsMessage)
+	}
+
+	refunds, err := h.refundUsecase.GetRefunds(ctx, claims)
+	if err != nil {
+		return response.SendUsecaseError(c, err)
+	}
+
+	return response.SendOk(c, converter.ConvertRefunds(refunds))
+}
+
+func (h *RefundHandler) validateRefundRequest(input *dto.RefundRequest) string {
+	var errMsg strings.Builder
+	if input.PurchaseId <= 0 {
+		errMsg.WriteString("purchaseId is required;")
+	}
+
+	if input.Quantity != nil && *input.Quantity <= 0 {
+		errMsg.WriteString("quantity must be positive;")
+	}
+
+	return errMsg.String()
+}
+
+// (POST /api/refunds): заявка на возврат всей покупки или ее части.
+func (h *RefundHandler) RequestRefund(c echo.Context) error {
+	ctx := c.Request().Context()
+	claims, ok := ctx.Value(ctxkey.ClaimsKey).(model.Claims)
+	if !ok {
+		return response.SendHandlerError(c, http.StatusUnauthorized, response.ErrInvalidClaimsMessage)
+	}
+
+	var input dto.RefundRequest
+	if err := c.Bind(&input); err != nil {
+		return response.SendHandlerError(c, http.StatusBadRequest, response.ErrBindingMessage)
+	}
+
+	if errMsg := h.validateRefundRequest(&input); errMsg != "" {
+		return response.SendHandlerError(c, http.StatusBadRequest, errMsg)
+	}
+
+	refund, err := h.refundUsecase.RequestRefund(ctx, claims, converter.ConvertRefundRequestToInput(&input))
+	if err != nil {
+		return response.SendUsecaseError(c, err)
+	}
+
+	return response.SendOk(c, converter.ConvertRefund(refund))
+}
@@ -0,0 +1,100 @@
+package refund
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo"
+	"github.com/resueman/merch-store/internal/delivery/ctxkey"
+	"github.com/resueman/merch-store/internal/model"
+	"github.com/resueman/merch-store/internal/usecase"
+	"github.com/resueman/merch-store/internal/usecase/apperrors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// Обработчику нужна только часть методов usecase.Refund, остальные не вызываются.
+type MockRefundUsecase struct {
+	usecase.Refund
+	mock.Mock
+}
+
+func (m *MockRefundUsecase) RequestRefund(ctx context.Context, claims model.Claims, input model.RefundInput) (
+	model.Refund, error,
+) {
+	args := m.Called(ctx, claims, input)
+	return args.Get(0).(model.Refund), args.Error(1)
+}
+
+func newRefundContext(e *echo.Echo, body string, claims model.Claims) (echo.Context, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(http.MethodPost, "/api/refunds", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	ctx := context.WithValue(c.Request().Context(), ctxkey.ClaimsKey, claims)
+	c.SetRequest(c.Request().WithContext(ctx))
+
+	return c, rec
+}
+
+func TestRequestRefund(t *testing.T) {
+	testCases := []struct {
+		name           string
+		body           string
+		expectedStatus int
+		mockSetup      func(m *MockRefundUsecase, claims model.Claims)
+	}{
+		{
+			name:           "whole purchase by default",
+			body:           `{"purchaseId":7}`,
+			expectedStatus: http.StatusOK,
+			mockSetup: func(m *MockRefundUsecase, claims model.Claims) {
+				m.On("RequestRefund", mock.Anything, claims, model.RefundInput{PurchaseID: 7}).
+					Return(model.Refund{ID: 3, Status: model.RefundRequested}, nil)
+			},
+		},
+		{
+			name:           "purchase is required",
+			body:           `{"quantity":2}`,
+			expectedStatus: http.StatusBadRequest,
+			mockSetup:      func(_ *MockRefundUsecase, _ model.Claims) {},
+		},
+		{
+			name:           "negative quantity",
+			body:           `{"purchaseId":7,"quantity":-1}`,
+			expectedStatus: http.StatusBadRequest,
+			mockSetup:      func(_ *MockRefundUsecase, _ model.Claims) {},
+		},
+		{
+			name:           "window expired",
+			body:           `{"purchaseId":7,"quantity":1}`,
+			expectedStatus: http.StatusBadRequest,
+			mockSetup: func(m *MockRefundUsecase, claims model.Claims) {
+				m.On("RequestRefund", mock.Anything, claims, model.RefundInput{PurchaseID: 7, Quantity: 1}).
+					Return(model.Refund{}, apperrors.ErrRefundWindowExpired)
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			e := echo.New()
+			mockUsecase := new(MockRefundUsecase)
+			handler := NewRefundHandler(e, mockUsecase, nil)
+
+			claims := model.Claims{UserID: 123}
+			testCase.mockSetup(mockUsecase, claims)
+
+			c, rec := newRefundContext(e, testCase.body, claims)
+
+			err := handler.RequestRefund(c)
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.expectedStatus, rec.Code)
+			mockUsecase.AssertExpectations(t)
+		})
+	}
+}
@@ -14,15 +14,90 @@ const (
 	ErrUserNotFoundMessage     = "user not found"
 	ErrProductNotFoundMessage  = "product not found"
 
-	ErrInvalidPasswordMessage = "invalid password"
-	ErrInvalidTokenMessage    = "invalid token"
-	ErrTokenExpiredMessage    = "token expired, please re-authenticate"
-	ErrGenerateTokenMessage   = "failed to generate token, please try again"
+	ErrInvalidQuantityMessage       = "quantity must be positive"
+	ErrQuantityLimitExceededMessage = "quantity exceeds the maximum allowed per purchase"
+	ErrTotalPriceOverflowMessage    = "total price of the purchase is too large"
+	ErrOutOfStockMessage            = "not enough items in stock"
+
+	ErrInvalidProductSortMessage = "sort must be one of: name, price"
+	ErrInvalidPaginationMessage  = "limit must be between 1 and 100 and offset must not be negative"
+	ErrInvalidPriceRangeMessage  = "price bounds must not be negative and minPrice must not exceed maxPrice"
+
+	ErrInvalidHistoryTypeMessage      = "type must be one of: purchase, transfer, refund"
+	ErrInvalidHistoryDirectionMessage = "direction must be one of: incoming, outgoing"
+	ErrInvalidHistoryPeriodMessage    = "from must be earlier than to"
+	ErrInvalidHistoryCursorMessage    = "invalid cursor"
+	ErrOperationNotFoundMessage       = "operation not found"
+
+	ErrProductAlreadyExistsMessage = "product with this name already exists"
+	ErrInvalidProductNameMessage   = "product name must not be blank"
+	ErrInvalidPriceMessage         = "price must not be negative"
+	ErrInvalidStockMessage         = "stock must not be negative"
+
+	ErrVariantNotFoundMessage      = "product variant not found"
+	ErrVariantAlreadyExistsMessage = "variant with this name or sku already exists"
+	ErrInvalidVariantMessage       = "variant name and sku must not be blank"
+
+	ErrCartEmptyMessage        = "cart is empty"
+	ErrCartItemNotFoundMessage = "item is not in the cart"
+	ErrCheckoutFailedMessage   = "some items in the cart can't be bought, see lines"
+
+	ErrPurchaseNotFoundMessage       = "purchase not found"
+	ErrRefundWindowExpiredMessage    = "refund window for this purchase has expired"
+	ErrRefundQuantityExceededMessage = "quantity exceeds the number of units that can still be refunded"
+	ErrRefundNotFoundMessage         = "refund not found"
+	ErrRefundAlreadyDecidedMessage   = "refund has already been approved or rejected"
+	ErrInvalidRefundStatusMessage    = "invalid refund status"
+
+	ErrIdempotencyKeyReusedMessage = "idempotency key was already used for a different request"
+
+	ErrUserDeactivatedMessage       = "user is deactivated, contact an administrator"
+	ErrRecipientDeactivatedMessage  = "recipient is deactivated and can't receive coins"
+	ErrSelfDeactivationMessage      = "you can't deactivate yourself"
+	ErrTreasuryNotConfiguredMessage = "treasury account is not configured or does not exist"
+
+	ErrInvalidPasswordMessage   = "invalid password"
+	ErrUserNotRegisteredMessage = "user not registered, please sign up first"
+	ErrUserAlreadyExistsMessage = "user with this username already exists"
+	ErrInvalidUsernameMessage   = "username must not be blank"
+	ErrReservedUsernameMessage  = "this username is reserved"
+
+	ErrWeakPasswordMessage      = "password does not meet the password policy"
+	ErrSamePasswordMessage      = "new password must differ from the old one"
+	ErrInvalidResetTokenMessage = "invalid password reset token"
+	ErrResetTokenExpiredMessage = "password reset token expired, ask an administrator for a new one"
+
+	ErrTooManyLoginAttemptsMessage = "too many failed login attempts, try again later"
+
+	ErrInvalidAPIKeyMessage     = "invalid or revoked api key"
+	ErrAPIKeyNotFoundMessage    = "api key not found"
+	ErrNotServiceAccountMessage = "api keys can only be issued to service accounts"
+	ErrServiceAccountMessage    = "operation is not available for service accounts"
+
+	ErrOTPRequiredMessage        = "one-time code from the authenticator app is required"
+	ErrInvalidOTPMessage         = "invalid or already used one-time code"
+	ErrTOTPAlreadyEnabledMessage = "two-factor authentication is already enabled"
+	ErrTOTPNotEnabledMessage     = "two-factor authentication is not enabled"
+	ErrInvalidThresholdMessage   = "threshold must not be negative"
+
+	ErrInvalidScopeMessage   = "requested scope is not allowed"
+	ErrInvalidRoleMessage    = "invalid role"
+	ErrSelfRoleChangeMessage = "you can't change your own role"
+	ErrInvalidTokenMessage   = "invalid token"
+	ErrTokenExpiredMessage   = "token expired, please re-authenticate"
+	ErrTokenRevokedMessage   = "token revoked, please re-authenticate"
+	ErrGenerateTokenMessage  = "failed to generate token, please try again"
+
+	ErrInvalidRefreshTokenMessage = "invalid refresh token"
+	ErrRefreshTokenExpiredMessage = "refresh token expired, please re-authenticate"
+	ErrRefreshTokenReusedMessage  = "refresh token has already been used, all sessions of this login were revoked"
 
 	ErrUnknownMessage = "internal server error"
 
-	ErrInvalidClaimsMessage = "invalid claims"
-	ErrBindingMessage       = "invalid request body"
+	ErrInvalidClaimsMessage     = "invalid claims"
+	ErrForbiddenMessage         = "insufficient permissions"
+	ErrInsufficientScopeMessage = "token scope does not allow this operation"
+	ErrBindingMessage           = "invalid request body"
 )
 
 //nolint:errorlint
@@ -36,6 +111,48 @@ func getReturnHTTPCodeAndMessage(err error) (int, string) {
 		{apperrors.ErrNotEnoughBalance, ErrNotEnoughBalanceMessage},
 		{apperrors.ErrUserNotFound, ErrUserNotFoundMessage},
 		{apperrors.ErrProductNotFound, ErrProductNotFoundMessage},
+		{apperrors.ErrInvalidQuantity, ErrInvalidQuantityMessage},
+		{apperrors.ErrQuantityLimitExceeded, ErrQuantityLimitExceededMessage},
+		{apperrors.ErrTotalPriceOverflow, ErrTotalPriceOverflowMessage},
+		{apperrors.ErrOutOfStock, ErrOutOfStockMessage},
+		{apperrors.ErrInvalidProductSort, ErrInvalidProductSortMessage},
+		{apperrors.ErrInvalidPagination, ErrInvalidPaginationMessage},
+		{apperrors.ErrInvalidPriceRange, ErrInvalidPriceRangeMessage},
+		{apperrors.ErrInvalidHistoryType, ErrInvalidHistoryTypeMessage},
+		{apperrors.ErrInvalidHistoryDirection, ErrInvalidHistoryDirectionMessage},
+		{apperrors.ErrInvalidHistoryPeriod, ErrInvalidHistoryPeriodMessage},
+		{apperrors.ErrInvalidHistoryCursor, ErrInvalidHistoryCursorMessage},
+		{apperrors.ErrOperationNotFound, ErrOperationNotFoundMessage},
+		{apperrors.ErrInvalidProductName, ErrInvalidProductNameMessage},
+		{apperrors.ErrInvalidPrice, ErrInvalidPriceMessage},
+		{apperrors.ErrInvalidStock, ErrInvalidStockMessage},
+		{apperrors.ErrVariantNotFound, ErrVariantNotFoundMessage},
+		{apperrors.ErrInvalidVariant, ErrInvalidVariantMessage},
+		{apperrors.ErrCartEmpty, ErrCartEmptyMessage},
+		{apperrors.ErrCartItemNotFound, ErrCartItemNotFoundMessage},
+		{apperrors.ErrCheckoutFailed, ErrCheckoutFailedMessage},
+		{apperrors.ErrPurchaseNotFound, ErrPurchaseNotFoundMessage},
+		{apperrors.ErrRefundWindowExpired, ErrRefundWindowExpiredMessage},
+		{apperrors.ErrRefundQuantityExceeded, ErrRefundQuantityExceededMessage},
+		{apperrors.ErrRefundNotFound, ErrRefundNotFoundMessage},
+		{apperrors.ErrInvalidRefundStatus, ErrInvalidRefundStatusMessage},
+		{apperrors.ErrInvalidUsername, ErrInvalidUsernameMessage},
+		{apperrors.ErrReservedUsername, ErrReservedUsernameMessage},
+		{apperrors.ErrRecipientDeactivated, ErrRecipientDeactivatedMessage},
+		{apperrors.ErrSelfDeactivation, ErrSelfDeactivationMessage},
+		{apperrors.ErrTreasuryNotConfigured, ErrTreasuryNotConfiguredMessage},
+		{apperrors.ErrInvalidScope, ErrInvalidScopeMessage},
+		{apperrors.ErrInvalidRole, ErrInvalidRoleMessage},
+		{apperrors.ErrSelfRoleChange, ErrSelfRoleChangeMessage},
+		{apperrors.ErrWeakPassword, ErrWeakPasswordMessage},
+		{apperrors.ErrSamePassword, ErrSamePasswordMessage},
+		{apperrors.ErrInvalidResetToken, ErrInvalidResetTokenMessage},
+		{apperrors.ErrResetTokenExpired, ErrResetTokenExpiredMessage},
+		{apperrors.ErrAPIKeyNotFound, ErrAPIKeyNotFoundMessage},
+		{apperrors.ErrNotServiceAccount, ErrNotServiceAccountMessage},
+		{apperrors.ErrServiceAccount, ErrServiceAccountMessage},
+		{apperrors.ErrTOTPNotEnabled, ErrTOTPNotEnabledMessage},
+		{apperrors.ErrInvalidThreshold, ErrInvalidThresholdMessage},
 	}
 
 	for _, e := range badRequestErrors {
@@ -49,9 +166,18 @@ func getReturnHTTPCodeAndMessage(err error) (int, string) {
 		message string
 	}{
 		{apperrors.ErrInvalidPassword, ErrInvalidPasswordMessage},
+		{apperrors.ErrUserNotRegistered, ErrUserNotRegisteredMessage},
+		{apperrors.ErrUserDeactivated, ErrUserDeactivatedMessage},
 		{apperrors.ErrInvalidToken, ErrInvalidTokenMessage},
 		{apperrors.ErrTokenExpired, ErrTokenExpiredMessage},
+		{apperrors.ErrTokenRevoked, ErrTokenRevokedMessage},
 		{apperrors.ErrGenerateToken, ErrGenerateTokenMessage},
+		{apperrors.ErrInvalidRefreshToken, ErrInvalidRefreshTokenMessage},
+		{apperrors.ErrRefreshTokenExpired, ErrRefreshTokenExpiredMessage},
+		{apperrors.ErrRefreshTokenReused, ErrRefreshTokenReusedMessage},
+		{apperrors.ErrInvalidAPIKey, ErrInvalidAPIKeyMessage},
+		{apperrors.ErrOTPRequired, ErrOTPRequiredMessage},
+		{apperrors.ErrInvalidOTP, ErrInvalidOTPMessage},
 	}
 
 	for _, e := range unauthorizedErrors {
@@ -60,5 +186,48 @@ func getReturnHTTPCodeAndMessage(err error) (int, string) {
 		}
 	}
 
+	conflictErrors := []struct {
+		err     error
+		message string
+	}{
+		{apperrors.ErrUserAlreadyExists, ErrUserAlreadyExistsMessage},
+		{apperrors.ErrTOTPAlreadyEnabled, ErrTOTPAlreadyEnabledMessage},
+		{apperrors.ErrRefundAlreadyDecided, ErrRefundAlreadyDecidedMessage},
+		{apperrors.ErrProductAlreadyExists, ErrProductAlreadyExistsMessage},
+		{apperrors.ErrVariantAlreadyExists, ErrVariantAlreadyExistsMessage},
+	}
+
+	for _, e := range conflictErrors {
+		if errors.Is(err, e.err) {
+			return http.StatusConflict, e.message
+		}
+	}
+
+	unprocessableEntityErrors := []struct {
+		err     error
+		message string
+	}{
+		{apperrors.ErrIdempotencyKeyReused, ErrIdempotencyKeyReusedMessage},
+	}
+
+	for _, e := range unprocessableEntityErrors {
+		if errors.Is(err, e.err) {
+			return http.StatusUnprocessableEntity, e.message
+		}
+	}
+
+	tooManyRequestsErrors := []struct {
+		err     error
+		message string
+	}{
+		{apperrors.ErrTooManyLoginAttempts, ErrTooManyLoginAttemptsMessage},
+	}
+
+	for _, e := range tooManyRequestsErrors {
+		if errors.Is(err, e.err) {
+			return http.StatusTooManyRequests, e.message
+		}
+	}
+
 	return http.StatusInternalServerError, ErrUnknownMessage
 }
@@ -1,11 +1,13 @@
 package response
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 
 	"github.com/labstack/echo"
 	dto "github.com/resueman/merch-store/internal/api/v1"
+	"github.com/resueman/merch-store/internal/usecase/apperrors"
 )
 
 func SendHandlerError(c echo.Context, httpCode int, message string) error {
@@ -17,6 +19,11 @@ func SendHandlerError(c echo.Context, httpCode int, message string) error {
 }
 
 func SendUsecaseError(c echo.Context, err error) error {
+	var checkoutErr *apperrors.CheckoutError
+	if errors.As(err, &checkoutErr) {
+		return sendCheckoutError(c, checkoutErr)
+	}
+
 	httpCode, errMsg := getReturnHTTPCodeAndMessage(err)
 	if e := c.JSON(httpCode, dto.ErrorResponse{Errors: &errMsg}); e != nil {
 		return fmt.Errorf("failed to send error response: %w", e)
@@ -25,6 +32,28 @@ func SendUsecaseError(c echo.Context, err error) error {
 	return nil
 }
 
+// Кроме общего сообщения, перечисляет строки корзины с причиной, по которой их нельзя купить.
+func sendCheckoutError(c echo.Context, err *apperrors.CheckoutError) error {
+	httpCode, errMsg := getReturnHTTPCodeAndMessage(err)
+
+	lines := make([]dto.CheckoutLineError, 0, len(err.Lines))
+	for _, line := range err.Lines {
+		_, lineMsg := getReturnHTTPCodeAndMessage(line.Err)
+		lineErr := dto.CheckoutLineError{Item: line.ItemName, Errors: lineMsg}
+		if line.Variant != "" {
+			lineErr.Variant = &line.Variant
+		}
+
+		lines = append(lines, lineErr)
+	}
+
+	if e := c.JSON(httpCode, dto.CheckoutErrorResponse{Errors: &errMsg, Lines: &lines}); e != nil {
+		return fmt.Errorf("failed to send error response: %w", e)
+	}
+
+	return nil
+}
+
 func SendNoContent(c echo.Context) error {
 	if err := c.NoContent(http.StatusOK); err != nil {
 		return fmt.Errorf("failed to send no content response: %w", err)
@@ -3,16 +3,31 @@ package v1
 import (
 	"github.com/labstack/echo"
 	"github.com/resueman/merch-store/internal/delivery/handlers/http/v1/account"
+	"github.com/resueman/merch-store/internal/delivery/handlers/http/v1/admin"
 	"github.com/resueman/merch-store/internal/delivery/handlers/http/v1/auth"
+	"github.com/resueman/merch-store/internal/delivery/handlers/http/v1/cart"
 	"github.com/resueman/merch-store/internal/delivery/handlers/http/v1/operation"
+	"github.com/resueman/merch-store/internal/delivery/handlers/http/v1/product"
+	"github.com/resueman/merch-store/internal/delivery/handlers/http/v1/refund"
 	"github.com/resueman/merch-store/internal/delivery/middleware"
+	"github.com/resueman/merch-store/internal/model"
 	"github.com/resueman/merch-store/internal/usecase"
 )
 
 func NewRouter(handler *echo.Echo, services *usecase.Usecase, m *middleware.AuthMiddleware) {
 	handler.Use(middleware.LoggerMiddleware)
 
-	auth.NewAuthHandler(handler, services.Auth)
-	operation.NewOperationHandler(handler, services.Operation, m.AuthMiddleware)
+	// Idempotency-Key принимают все изменяющие маршруты с аутентификацией. Обработчики сами подключают его
+	// к своим маршрутам: чтение и маршруты, выдающие секреты (токены, API-ключи, ссылки сброса пароля),
+	// его не принимают, чтобы их ответы не сохранялись в БД.
+	idempotency := middleware.NewIdempotencyMiddleware(services.Idempotency).IdempotencyMiddleware
+
+	auth.NewAuthHandler(handler, services.Auth, idempotency, m.AuthMiddleware)
+	operation.NewOperationHandler(handler, services.Operation, idempotency, m.AuthMiddleware)
+	cart.NewCartHandler(handler, services.Cart, idempotency, m.AuthMiddleware)
+	refund.NewRefundHandler(handler, services.Refund, idempotency, m.AuthMiddleware)
 	account.NewAccountHandler(handler, services.Account, m.AuthMiddleware)
+	product.NewProductHandler(handler, services.Product, m.AuthMiddleware)
+	admin.NewAdminHandler(handler, services.Auth, services.Refund, services.Product, idempotency, m.AuthMiddleware,
+		middleware.RequireRole(model.RoleAdmin))
 }
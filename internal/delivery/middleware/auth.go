@@ -28,7 +28,7 @@ func (m *AuthMiddleware) AuthMiddleware(next echo.HandlerFunc) echo.HandlerFunc
 		}
 
 		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || parts[0] != "Bearer" {
+		if len(parts) != 2 || (parts[0] != "Bearer" && parts[0] != "ApiKey") {
 			return response.SendHandlerError(ctx, http.StatusUnauthorized, "Invalid authorization header format")
 		}
 
@@ -37,7 +37,15 @@ func (m *AuthMiddleware) AuthMiddleware(next echo.HandlerFunc) echo.HandlerFunc
 			return response.SendHandlerError(ctx, http.StatusUnauthorized, "Token is empty")
 		}
 
-		claims, err := m.authUsecase.ParseToken(ctx.Request().Context(), tokenString)
+		var claims model.Claims
+		var err error
+		// Сервисные аккаунты аутентифицируются долгоживущими API-ключами, пользователи - JWT.
+		if parts[0] == "ApiKey" {
+			claims, err = m.authUsecase.AuthenticateAPIKey(ctx.Request().Context(), tokenString)
+		} else {
+			claims, err = m.authUsecase.ParseToken(ctx.Request().Context(), tokenString)
+		}
+
 		if err != nil {
 			return response.SendUsecaseError(ctx, err)
 		}
@@ -8,7 +8,9 @@ import (
 
 	"github.com/labstack/echo"
 	"github.com/resueman/merch-store/internal/delivery/ctxkey"
+	"github.com/resueman/merch-store/internal/delivery/handlers/http/v1/response"
 	"github.com/resueman/merch-store/internal/model"
+	"github.com/resueman/merch-store/internal/usecase/apperrors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -22,9 +24,139 @@ func (m *MockAuthUsecase) ParseToken(ctx context.Context, tokenString string) (m
 	return args.Get(0).(model.Claims), args.Error(1)
 }
 
-func (m *MockAuthUsecase) GenerateToken(ctx context.Context, claims model.AuthRequestInput) (string, error) {
+func (m *MockAuthUsecase) GenerateToken(ctx context.Context, claims model.AuthRequestInput) (model.AuthTokens, error) {
 	args := m.Called(ctx, claims)
-	return args.String(0), args.Error(1)
+	return args.Get(0).(model.AuthTokens), args.Error(1)
+}
+
+func (m *MockAuthUsecase) Register(ctx context.Context, input model.AuthRequestInput) (model.AuthTokens, error) {
+	args := m.Called(ctx, input)
+	return args.Get(0).(model.AuthTokens), args.Error(1)
+}
+
+func (m *MockAuthUsecase) SetUserRole(ctx context.Context, claims model.Claims, input model.SetUserRoleInput) error {
+	args := m.Called(ctx, claims, input)
+	return args.Error(0)
+}
+
+func (m *MockAuthUsecase) BootstrapAdmin(ctx context.Context, username string) error {
+	args := m.Called(ctx, username)
+	return args.Error(0)
+}
+
+func (m *MockAuthUsecase) PublicKeys() []model.PublicKey {
+	args := m.Called()
+	return args.Get(0).([]model.PublicKey)
+}
+
+func (m *MockAuthUsecase) UnlockUser(ctx context.Context, username string) error {
+	args := m.Called(ctx, username)
+	return args.Error(0)
+}
+
+func (m *MockAuthUsecase) DeactivateUser(ctx context.Context, claims model.Claims,
+	input model.DeactivateUserInput,
+) (int, error) {
+	args := m.Called(ctx, claims, input)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockAuthUsecase) ReactivateUser(ctx context.Context, username string) error {
+	args := m.Called(ctx, username)
+	return args.Error(0)
+}
+
+func (m *MockAuthUsecase) ChangePassword(ctx context.Context, claims model.Claims,
+	input model.ChangePasswordInput,
+) (model.AuthTokens, error) {
+	args := m.Called(ctx, claims, input)
+	return args.Get(0).(model.AuthTokens), args.Error(1)
+}
+
+func (m *MockAuthUsecase) CreatePasswordReset(ctx context.Context, claims model.Claims,
+	username string,
+) (model.PasswordResetToken, error) {
+	args := m.Called(ctx, claims, username)
+	return args.Get(0).(model.PasswordResetToken), args.Error(1)
+}
+
+func (m *MockAuthUsecase) ResetPassword(ctx context.Context, input model.ResetPasswordInput) error {
+	args := m.Called(ctx, input)
+	return args.Error(0)
+}
+
+func (m *MockAuthUsecase) CreateServiceAccount(ctx context.Context, username string) error {
+	args := m.Called(ctx, username)
+	return args.Error(0)
+}
+
+func (m *MockAuthUsecase) CreateAPIKey(ctx context.Context, claims model.Claims,
+	input model.CreateAPIKeyInput,
+) (model.APIKey, error) {
+	args := m.Called(ctx, claims, input)
+	return args.Get(0).(model.APIKey), args.Error(1)
+}
+
+func (m *MockAuthUsecase) GetAPIKeys(ctx context.Context, username string) ([]model.APIKey, error) {
+	args := m.Called(ctx, username)
+	return args.Get(0).([]model.APIKey), args.Error(1)
+}
+
+func (m *MockAuthUsecase) RevokeAPIKey(ctx context.Context, keyID int) error {
+	args := m.Called(ctx, keyID)
+	return args.Error(0)
+}
+
+func (m *MockAuthUsecase) AuthenticateAPIKey(ctx context.Context, key string) (model.Claims, error) {
+	args := m.Called(ctx, key)
+	return args.Get(0).(model.Claims), args.Error(1)
+}
+
+func (m *MockAuthUsecase) EnrollTOTP(ctx context.Context, claims model.Claims) (model.TOTPEnrollment, error) {
+	args := m.Called(ctx, claims)
+	return args.Get(0).(model.TOTPEnrollment), args.Error(1)
+}
+
+func (m *MockAuthUsecase) ConfirmTOTP(ctx context.Context, claims model.Claims,
+	input model.ConfirmTOTPInput,
+) ([]string, error) {
+	args := m.Called(ctx, claims, input)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockAuthUsecase) DisableTOTP(ctx context.Context, claims model.Claims, code string) error {
+	args := m.Called(ctx, claims, code)
+	return args.Error(0)
+}
+
+func (m *MockAuthUsecase) SetTOTPThreshold(ctx context.Context, claims model.Claims, input model.SetTOTPThresholdInput) error {
+	args := m.Called(ctx, claims, input)
+	return args.Error(0)
+}
+
+func (m *MockAuthUsecase) VerifyOTP(ctx context.Context, userID int, amount int, code string) error {
+	args := m.Called(ctx, userID, amount, code)
+	return args.Error(0)
+}
+
+func (m *MockAuthUsecase) RecordOTPFailure(ctx context.Context, userID int) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *MockAuthUsecase) RefreshTokens(ctx context.Context, refreshToken string) (model.AuthTokens, error) {
+	args := m.Called(ctx, refreshToken)
+	return args.Get(0).(model.AuthTokens), args.Error(1)
+}
+
+func (m *MockAuthUsecase) Logout(ctx context.Context, claims model.Claims, refreshToken string) error {
+	args := m.Called(ctx, claims, refreshToken)
+	return args.Error(0)
+}
+
+func (m *MockAuthUsecase) LogoutAll(ctx context.Context, claims model.Claims) error {
+	args := m.Called(ctx, claims)
+	return args.Error(0)
 }
 
 func TestAuthMiddleware(t *testing.T) {
@@ -115,4 +247,43 @@ func TestAuthMiddleware(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, http.StatusInternalServerError, rec.Code)
 	})
+
+	t.Run("Valid api key", func(t *testing.T) {
+		claims := model.Claims{UserID: 7, APIKeyID: 1, Scopes: []model.Scope{model.ScopeCoinsSend}}
+		mockUsecase.On("AuthenticateAPIKey", mock.Anything, "msk_key").Return(claims, nil)
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Authorization", "ApiKey msk_key")
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		handler := authMiddleware.AuthMiddleware(func(c echo.Context) error {
+			return c.String(http.StatusOK, "OK")
+		})
+
+		err := handler(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		assert.Equal(t, claims, c.Request().Context().Value(ctxkey.ClaimsKey))
+	})
+
+	t.Run("Revoked api key", func(t *testing.T) {
+		mockUsecase.On("AuthenticateAPIKey", mock.Anything, "msk_revoked").
+			Return(model.Claims{}, apperrors.ErrInvalidAPIKey)
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Authorization", "ApiKey msk_revoked")
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		handler := authMiddleware.AuthMiddleware(func(c echo.Context) error {
+			return c.String(http.StatusOK, "OK")
+		})
+
+		err := handler(c)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Contains(t, rec.Body.String(), response.ErrInvalidAPIKeyMessage)
+	})
 }
@@ -0,0 +1,132 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+
+	"github.com/labstack/echo"
+	"github.com/resueman/merch-store/internal/delivery/ctxkey"
+	"github.com/resueman/merch-store/internal/delivery/handlers/http/v1/response"
+	"github.com/resueman/merch-store/internal/model"
+	"github.com/resueman/merch-store/internal/usecase"
+)
+
+const (
+	IdempotencyKeyHeader     = "Idempotency-Key"
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 255
+)
+
+type IdempotencyMiddleware struct {
+	idempotencyUsecase usecase.Idempotency
+}
+
+func NewIdempotencyMiddleware(idempotencyUsecase usecase.Idempotency) *IdempotencyMiddleware {
+	return &IdempotencyMiddleware{idempotencyUsecase: idempotencyUsecase}
+}
+
+// Запрос с заголовком Idempotency-Key выполняется не больше одного раза: повтор с тем же ключом
+// получает сохраненный ответ. Ставится после AuthMiddleware, так как ключи у каждого пользователя свои.
+// Без заголовка запрос обрабатывается как обычно.
+func (m *IdempotencyMiddleware) IdempotencyMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		key := c.Request().Header.Get(IdempotencyKeyHeader)
+		if key == "" {
+			return next(c)
+		}
+
+		if len(key) > maxIdempotencyKeyLength {
+			return response.SendHandlerError(c, http.StatusBadRequest, "idempotency key is too long")
+		}
+
+		claims, ok := c.Request().Context().Value(ctxkey.ClaimsKey).(model.Claims)
+		if !ok {
+			return response.SendHandlerError(c, http.StatusUnauthorized, response.ErrInvalidClaimsMessage)
+		}
+
+		body, err := io.ReadAll(c.Request().Body)
+		if err != nil {
+			return response.SendHandlerError(c, http.StatusBadRequest, response.ErrBindingMessage)
+		}
+
+		request := c.Request()
+		writer := c.Response().Writer
+		input := model.IdempotentRequest{Key: key, Fingerprint: requestFingerprint(request, body)}
+
+		// При повторе транзакции обработчик запускается заново, поэтому тело запроса и ответ
+		// каждый раз подставляются заново, а клиенту уходит только ответ последней попытки.
+		handle := func(ctx context.Context) (model.IdempotentResponse, error) {
+			recorder := &responseRecorder{header: http.Header{}}
+			resetResponse(c.Response(), recorder)
+
+			c.SetRequest(request.WithContext(ctx))
+			c.Request().Body = io.NopCloser(bytes.NewReader(body))
+
+			if err := next(c); err != nil {
+				return model.IdempotentResponse{}, err
+			}
+
+			return model.IdempotentResponse{
+				StatusCode:  c.Response().Status,
+				ContentType: recorder.header.Get(echo.HeaderContentType),
+				Body:        recorder.body.Bytes(),
+			}, nil
+		}
+
+		result, err := m.idempotencyUsecase.Execute(request.Context(), claims, input, handle)
+
+		resetResponse(c.Response(), writer)
+		c.SetRequest(request)
+
+		if err != nil {
+			return response.SendUsecaseError(c, err)
+		}
+
+		if result.Replayed {
+			c.Response().Header().Set(IdempotentReplayedHeader, "true")
+		}
+
+		if result.ContentType == "" {
+			return c.NoContent(result.StatusCode)
+		}
+
+		return c.Blob(result.StatusCode, result.ContentType, result.Body)
+	}
+}
+
+// Ключ относится к конкретному запросу: тот же ключ с другим методом, путем или телом отклоняется.
+func requestFingerprint(request *http.Request, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(request.Method + " " + request.URL.RequestURI() + "\n"))
+	hash.Write(body)
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func resetResponse(res *echo.Response, writer http.ResponseWriter) {
+	res.Writer = writer
+	res.Status = http.StatusOK
+	res.Size = 0
+	res.Committed = false
+}
+
+// Накапливает ответ обработчика, чтобы сохранить его вместе с ключом до отправки клиенту.
+type responseRecorder struct {
+	header http.Header
+	body   bytes.Buffer
+}
+
+func (r *responseRecorder) Header() http.Header {
+	return r.header
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	return r.body.Write(b)
+}
+
+func (r *responseRecorder) WriteHeader(int) {}
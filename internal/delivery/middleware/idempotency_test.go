@@ -0,0 +1,111 @@
+package middleware
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo"
+	"github.com/resueman/merch-store/internal/model"
+	"github.com/resueman/merch-store/internal/usecase/apperrors"
+	"github.com/stretchr/testify/assert"
+)
+
+// Хранит ответы в памяти так же, как usecase хранит их в БД.
+type fakeIdempotencyUsecase struct {
+	stored map[string]model.IdempotentResponse
+	prints map[string]string
+}
+
+func (f *fakeIdempotencyUsecase) Execute(
+	ctx context.Context,
+	_ model.Claims,
+	request model.IdempotentRequest,
+	handle func(ctx context.Context) (model.IdempotentResponse, error),
+) (model.IdempotentResponse, error) {
+	if stored, ok := f.stored[request.Key]; ok {
+		if f.prints[request.Key] != request.Fingerprint {
+			return model.IdempotentResponse{}, apperrors.ErrIdempotencyKeyReused
+		}
+
+		stored.Replayed = true
+		return stored, nil
+	}
+
+	result, err := handle(ctx)
+	if err != nil {
+		return model.IdempotentResponse{}, err
+	}
+
+	f.stored[request.Key] = result
+	f.prints[request.Key] = request.Fingerprint
+
+	return result, nil
+}
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	e := echo.New()
+	usecase := &fakeIdempotencyUsecase{stored: map[string]model.IdempotentResponse{}, prints: map[string]string{}}
+
+	calls := 0
+	handler := NewIdempotencyMiddleware(usecase).IdempotencyMiddleware(func(c echo.Context) error {
+		calls++
+		body, _ := io.ReadAll(c.Request().Body)
+		return c.String(http.StatusOK, "done "+string(body))
+	})
+
+	send := func(key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/sendCoin", strings.NewReader(body))
+		if key != "" {
+			req.Header.Set(IdempotencyKeyHeader, key)
+		}
+
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		SetContext(c, model.Claims{UserID: 1})
+
+		assert.NoError(t, handler(c))
+
+		return rec
+	}
+
+	t.Run("Without key", func(t *testing.T) {
+		rec := send("", "a")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "done a", rec.Body.String())
+		assert.Equal(t, 1, calls)
+		assert.Empty(t, usecase.stored)
+	})
+
+	t.Run("First request", func(t *testing.T) {
+		rec := send("key", "a")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "done a", rec.Body.String())
+		assert.Empty(t, rec.Header().Get(IdempotentReplayedHeader))
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("Replay", func(t *testing.T) {
+		rec := send("key", "a")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "done a", rec.Body.String())
+		assert.Equal(t, echo.MIMETextPlainCharsetUTF8, rec.Header().Get(echo.HeaderContentType))
+		assert.Equal(t, "true", rec.Header().Get(IdempotentReplayedHeader))
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("Key reused with another body", func(t *testing.T) {
+		rec := send("key", "b")
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("Key too long", func(t *testing.T) {
+		rec := send(strings.Repeat("k", maxIdempotencyKeyLength+1), "a")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, 2, calls)
+	})
+}
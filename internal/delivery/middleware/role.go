@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/labstack/echo"
+	"github.com/resueman/merch-store/internal/delivery/ctxkey"
+	"github.com/resueman/merch-store/internal/delivery/handlers/http/v1/response"
+	"github.com/resueman/merch-store/internal/model"
+)
+
+// RequireRole пропускает запрос, только если роль из claims входит в roles.
+// Должен подключаться после AuthMiddleware, который кладет claims в контекст.
+func RequireRole(roles ...model.Role) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			claims, ok := ctx.Request().Context().Value(ctxkey.ClaimsKey).(model.Claims)
+			if !ok {
+				return response.SendHandlerError(ctx, http.StatusUnauthorized, response.ErrInvalidClaimsMessage)
+			}
+
+			if !slices.Contains(roles, claims.Role) {
+				return response.SendHandlerError(ctx, http.StatusForbidden, response.ErrForbiddenMessage)
+			}
+
+			return next(ctx)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo"
+	"github.com/resueman/merch-store/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequireRole(t *testing.T) {
+	e := echo.New()
+	handler := RequireRole(model.RoleAdmin)(func(c echo.Context) error {
+		return c.String(http.StatusOK, "OK")
+	})
+
+	t.Run("Missing claims", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+
+		assert.NoError(t, handler(c))
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("Insufficient role", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		SetContext(c, model.Claims{UserID: 1, Role: model.RoleUser})
+
+		assert.NoError(t, handler(c))
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("Allowed role", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		SetContext(c, model.Claims{UserID: 1, Role: model.RoleAdmin})
+
+		assert.NoError(t, handler(c))
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+
+	"github.com/labstack/echo"
+	"github.com/resueman/merch-store/internal/delivery/ctxkey"
+	"github.com/resueman/merch-store/internal/delivery/handlers/http/v1/response"
+	"github.com/resueman/merch-store/internal/model"
+)
+
+// RequireScopes пропускает запрос, только если токен содержит все перечисленные области доступа.
+// Должен подключаться после AuthMiddleware.
+func RequireScopes(scopes ...model.Scope) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			claims, ok := ctx.Request().Context().Value(ctxkey.ClaimsKey).(model.Claims)
+			if !ok {
+				return response.SendHandlerError(ctx, http.StatusUnauthorized, response.ErrInvalidClaimsMessage)
+			}
+
+			if !claims.HasScopes(scopes...) {
+				return response.SendHandlerError(ctx, http.StatusForbidden, response.ErrInsufficientScopeMessage)
+			}
+
+			return next(ctx)
+		}
+	}
+}
+
+// WithScopes возвращает цепочку m, дополненную проверкой областей доступа.
+// Обработчики используют ее при регистрации маршрутов, чтобы объявить нужные им области.
+func WithScopes(m []echo.MiddlewareFunc, scopes ...model.Scope) []echo.MiddlewareFunc {
+	return append(slices.Clone(m), RequireScopes(scopes...))
+}
+
+// WithIdempotency возвращает цепочку m, дополненную обработкой Idempotency-Key.
+// Подключается к изменяющим маршрутам, ответы которых не содержат секретов: сохраненный ответ лежит в БД.
+// Если idempotency не передан, цепочка не меняется.
+func WithIdempotency(m []echo.MiddlewareFunc, idempotency echo.MiddlewareFunc) []echo.MiddlewareFunc {
+	if idempotency == nil {
+		return m
+	}
+
+	return append(slices.Clone(m), idempotency)
+}
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo"
+	"github.com/resueman/merch-store/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequireScopes(t *testing.T) {
+	e := echo.New()
+	handler := RequireScopes(model.ScopeCoinsSend)(func(c echo.Context) error {
+		return c.String(http.StatusOK, "OK")
+	})
+
+	t.Run("Read-only token", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		SetContext(c, model.Claims{UserID: 1, Scopes: []model.Scope{model.ScopeInfoRead}})
+
+		assert.NoError(t, handler(c))
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	})
+
+	t.Run("Token with required scope", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		SetContext(c, model.Claims{UserID: 1, Scopes: []model.Scope{model.ScopeInfoRead, model.ScopeCoinsSend}})
+
+		assert.NoError(t, handler(c))
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+}
+
+func TestWithScopesDoesNotModifyChain(t *testing.T) {
+	base := make([]echo.MiddlewareFunc, 1, 4)
+	base[0] = func(next echo.HandlerFunc) echo.HandlerFunc { return next }
+
+	read := WithScopes(base, model.ScopeInfoRead)
+	send := WithScopes(base, model.ScopeCoinsSend)
+
+	assert.Len(t, base, 1)
+	assert.Len(t, read, 2)
+	assert.Len(t, send, 2)
+}
+
+func TestWithIdempotency(t *testing.T) {
+	base := make([]echo.MiddlewareFunc, 1, 4)
+	base[0] = func(next echo.HandlerFunc) echo.HandlerFunc { return next }
+
+	assert.Len(t, WithIdempotency(base, nil), 1)
+	assert.Len(t, WithIdempotency(base, base[0]), 2)
+	assert.Len(t, base, 1)
+}
@@ -0,0 +1,28 @@
+package entity
+
+import "time"
+
+type APIKey struct {
+	ID         int        `db:"id"`
+	UserID     int        `db:"user_id"`
+	Name       string     `db:"name"`
+	Prefix     string     `db:"prefix"`
+	KeyHash    string     `db:"key_hash"`
+	Scopes     string     `db:"scopes"`
+	CreatedAt  time.Time  `db:"created_at"`
+	LastUsedAt *time.Time `db:"last_used_at"`
+	RevokedAt  *time.Time `db:"revoked_at"`
+	// Роль владельца ключа, заполняется только при поиске ключа по хешу.
+	Role string `db:"role"`
+	// Время деактивации владельца ключа, заполняется только при поиске ключа по хешу.
+	UserDeactivatedAt *time.Time `db:"deactivated_at"`
+}
+
+type CreateAPIKeyInput struct {
+	UserID    int    `db:"user_id"`
+	CreatedBy int    `db:"created_by"`
+	Name      string `db:"name"`
+	Prefix    string `db:"prefix"`
+	KeyHash   string `db:"key_hash"`
+	Scopes    string `db:"scopes"`
+}
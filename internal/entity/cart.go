@@ -0,0 +1,12 @@
+package entity
+
+// Строка корзины вместе с названием и текущей ценой товара. VariantID пуст, если в корзине сам товар;
+// Price - цена варианта, если она у него задана, иначе цена товара.
+type CartItem struct {
+	ProductID   int    `db:"product_id"`
+	VariantID   *int   `db:"variant_id"`
+	ProductName string `db:"name"`
+	VariantName string `db:"variant_name"`
+	Price       int    `db:"price"`
+	Quantity    int    `db:"quantity"`
+}
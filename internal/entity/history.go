@@ -0,0 +1,36 @@
+package entity
+
+import "time"
+
+// Пустые поля фильтра не ограничивают выборку. Если задан AfterID, возвращаются операции,
+// которые в порядке (created_at, id) по убыванию идут после (AfterCreatedAt, AfterID).
+type HistoryFilter struct {
+	AccountID             int
+	OperationType         string
+	Direction             string
+	CounterpartyAccountID *int
+	From                  *time.Time
+	To                    *time.Time
+	AfterCreatedAt        time.Time
+	AfterID               int
+	Limit                 int
+}
+
+// Операция из истории счета. Поля, которые не относятся к типу операции, пустые.
+type HistoryEntry struct {
+	OperationID   int       `db:"operation_id"`
+	OperationType string    `db:"operation_type"`
+	Direction     string    `db:"direction"`
+	Amount        int       `db:"amount"`
+	Counterparty  string    `db:"counterparty"`
+	ProductName   string    `db:"name"`
+	Quantity      int       `db:"quantity"`
+	RefundStatus  string    `db:"refund_status"`
+	CreatedAt     time.Time `db:"created_at"`
+}
+
+// Отметка снимка истории: время его получения и последняя видимая в нем операция счета.
+type HistoryMark struct {
+	TakenAt         time.Time `db:"taken_at"`
+	LastOperationID int       `db:"last_operation_id"`
+}
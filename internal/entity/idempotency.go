@@ -0,0 +1,14 @@
+package entity
+
+import "time"
+
+type IdempotencyKey struct {
+	UserID       int       `db:"user_id"`
+	Key          string    `db:"idempotency_key"`
+	Fingerprint  string    `db:"fingerprint"`
+	StatusCode   int       `db:"status_code"`
+	ContentType  string    `db:"content_type"`
+	ResponseBody []byte    `db:"response_body"`
+	CreatedAt    time.Time `db:"created_at"`
+	ExpiresAt    time.Time `db:"expires_at"`
+}
@@ -0,0 +1,20 @@
+package entity
+
+import "time"
+
+const (
+	LoginAttemptKeyUsername = "username"
+	LoginAttemptKeyIP       = "ip"
+)
+
+type LoginAttemptKey struct {
+	KeyType string `db:"key_type"`
+	Key     string `db:"key"`
+}
+
+type LoginFailure struct {
+	LoginAttemptKey
+	FailedAt time.Time
+	// Счетчик сбрасывается, если предыдущая неудачная попытка была раньше ResetBefore.
+	ResetBefore time.Time
+}
@@ -1,10 +1,13 @@
 package entity
 
+import "time"
+
 type PurchaseOperation struct {
-	ItemID            int `db:"item_id"`
-	CustomerAccountID int `db:"customer_account_id"`
-	Quantity          int `db:"quantity"`
-	TotalPrice        int `db:"total_price"`
+	ItemID            int  `db:"item_id"`
+	VariantID         *int `db:"variant_id"`
+	CustomerAccountID int  `db:"customer_account_id"`
+	Quantity          int  `db:"quantity"`
+	TotalPrice        int  `db:"total_price"`
 }
 
 type TransferOperation struct {
@@ -12,3 +15,27 @@ type TransferOperation struct {
 	RecipientAccountID int `db:"recipient_account_id"`
 	Amount             int `db:"amount"`
 }
+
+// Шаг возврата, который записывается в историю операций покупателя.
+type RefundOperation struct {
+	RefundID          int    `db:"refund_id"`
+	CustomerAccountID int    `db:"customer_account_id"`
+	Status            string `db:"status"`
+}
+
+// Операция вместе с участниками. Username - инициатор: покупатель или отправитель перевода.
+// Поля, которые не относятся к типу операции, пустые.
+type OperationDetails struct {
+	ID                 int       `db:"id"`
+	OperationType      string    `db:"operation_type"`
+	AccountID          int       `db:"account_id"`
+	Username           string    `db:"username"`
+	RecipientAccountID *int      `db:"recipient_account_id"`
+	RecipientUsername  string    `db:"recipient_username"`
+	Amount             int       `db:"amount"`
+	ProductName        string    `db:"name"`
+	Quantity           int       `db:"quantity"`
+	TotalPrice         int       `db:"total_price"`
+	RefundStatus       string    `db:"refund_status"`
+	CreatedAt          time.Time `db:"created_at"`
+}
@@ -0,0 +1,18 @@
+package entity
+
+import "time"
+
+type PasswordResetToken struct {
+	ID        int        `db:"id"`
+	UserID    int        `db:"user_id"`
+	TokenHash string     `db:"token_hash"`
+	ExpiresAt time.Time  `db:"expires_at"`
+	UsedAt    *time.Time `db:"used_at"`
+}
+
+type CreatePasswordResetTokenInput struct {
+	UserID    int       `db:"user_id"`
+	CreatedBy int       `db:"created_by"`
+	TokenHash string    `db:"token_hash"`
+	ExpiresAt time.Time `db:"expires_at"`
+}
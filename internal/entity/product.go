@@ -1,7 +1,81 @@
 package entity
 
+import "time"
+
 type Product struct {
-	ID    int    `db:"id"`
-	Name  string `db:"name"`
-	Price int    `db:"price"`
+	ID          int    `db:"id"`
+	Name        string `db:"name"`
+	Price       int    `db:"price"`
+	Description string `db:"description"`
+	Stock       int    `db:"stock"`
+}
+
+// Фильтр каталога. OrderBy содержит имя колонки, по которой сортируются товары.
+type ProductFilter struct {
+	NameSubstring string
+	MinPrice      *int
+	MaxPrice      *int
+	OrderBy       string
+	Desc          bool
+	Limit         int
+	Offset        int
+}
+
+// Вариант товара, например размер. Price и Stock пусты, если вариант использует цену и остаток товара.
+type ProductVariant struct {
+	ID        int    `db:"id"`
+	ProductID int    `db:"product_id"`
+	Name      string `db:"name"`
+	SKU       string `db:"sku"`
+	Price     *int   `db:"price"`
+	Stock     *int   `db:"stock"`
+}
+
+// Строка отчета о заканчивающихся товарах. Variant пуст, если заканчивается сам товар,
+// иначе это вариант с собственным остатком.
+type LowStockProduct struct {
+	Product Product
+	Variant *ProductVariant
+}
+
+type CreateProductVariantInput struct {
+	ProductID int    `db:"product_id"`
+	Name      string `db:"name"`
+	SKU       string `db:"sku"`
+	Price     *int   `db:"price"`
+	Stock     *int   `db:"stock"`
+}
+
+// Пополнение склада администратором. VariantID задан, если пополнялся собственный остаток варианта.
+type Restock struct {
+	ProductID   int  `db:"product_id"`
+	VariantID   *int `db:"variant_id"`
+	Quantity    int  `db:"quantity"`
+	RestockedBy int  `db:"restocked_by"`
+}
+
+type CreateProductInput struct {
+	Name        string `db:"name"`
+	Price       int    `db:"price"`
+	Description string `db:"description"`
+	Stock       int    `db:"stock"`
+}
+
+// nil-поля не меняются.
+type UpdateProductInput struct {
+	Price       *int    `db:"price"`
+	Description *string `db:"description"`
+}
+
+// Строка журнала изменений каталога. ChangedByUsername пуст, если администратор уже удален.
+type ProductAudit struct {
+	ID                int       `db:"id"`
+	ProductID         int       `db:"product_id"`
+	ChangedBy         int       `db:"changed_by"`
+	ChangedByUsername *string   `db:"username"`
+	Action            string    `db:"action"`
+	Field             *string   `db:"field"`
+	OldValue          *string   `db:"old_value"`
+	NewValue          *string   `db:"new_value"`
+	CreatedAt         time.Time `db:"created_at"`
 }
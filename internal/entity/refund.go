@@ -0,0 +1,37 @@
+package entity
+
+import "time"
+
+// Покупка вместе с количеством единиц, на которые уже заявлены или одобрены возвраты.
+type RefundablePurchase struct {
+	ID                int       `db:"id"`
+	CustomerAccountID int       `db:"customer_account_id"`
+	ProductName       string    `db:"name"`
+	Quantity          int       `db:"quantity"`
+	TotalPrice        int       `db:"total_price"`
+	RefundedQuantity  int       `db:"refunded_quantity"`
+	CreatedAt         time.Time `db:"created_at"`
+}
+
+type CreateRefundInput struct {
+	PurchaseID        int `db:"purchase_operation_id"`
+	CustomerAccountID int `db:"customer_account_id"`
+	Quantity          int `db:"quantity"`
+	Amount            int `db:"amount"`
+}
+
+type Refund struct {
+	ID                int        `db:"id"`
+	PurchaseID        int        `db:"purchase_operation_id"`
+	CustomerAccountID int        `db:"customer_account_id"`
+	CustomerUsername  string     `db:"username"`
+	ProductID         int        `db:"product_id"`
+	VariantID         *int       `db:"variant_id"`
+	VariantHasStock   bool       `db:"variant_has_stock"`
+	ProductName       string     `db:"name"`
+	Quantity          int        `db:"quantity"`
+	Amount            int        `db:"amount"`
+	Status            string     `db:"status"`
+	CreatedAt         time.Time  `db:"created_at"`
+	DecidedAt         *time.Time `db:"decided_at"`
+}
@@ -0,0 +1,33 @@
+package entity
+
+import "time"
+
+type RefreshToken struct {
+	ID        int        `db:"id"`
+	UserID    int        `db:"user_id"`
+	FamilyID  string     `db:"family_id"`
+	TokenHash string     `db:"token_hash"`
+	ExpiresAt time.Time  `db:"expires_at"`
+	UsedAt    *time.Time `db:"used_at"`
+	RevokedAt *time.Time `db:"revoked_at"`
+	Scopes    string     `db:"scopes"`
+}
+
+type CreateRefreshTokenInput struct {
+	UserID    int       `db:"user_id"`
+	FamilyID  string    `db:"family_id"`
+	TokenHash string    `db:"token_hash"`
+	ExpiresAt time.Time `db:"expires_at"`
+	Scopes    string    `db:"scopes"`
+}
+
+type RevokedToken struct {
+	TokenID   string    `db:"jti"`
+	UserID    int       `db:"user_id"`
+	ExpiresAt time.Time `db:"expires_at"`
+}
+
+type TokenVersion struct {
+	UserID  int `db:"id"`
+	Version int `db:"token_version"`
+}
@@ -0,0 +1,19 @@
+package entity
+
+import "time"
+
+type TOTP struct {
+	UserID       int        `db:"user_id"`
+	Secret       string     `db:"secret"`
+	Threshold    int        `db:"threshold"`
+	LastUsedStep int64      `db:"last_used_step"`
+	CreatedAt    time.Time  `db:"created_at"`
+	EnabledAt    *time.Time `db:"enabled_at"`
+}
+
+type RecoveryCode struct {
+	ID       int        `db:"id"`
+	UserID   int        `db:"user_id"`
+	CodeHash string     `db:"code_hash"`
+	UsedAt   *time.Time `db:"used_at"`
+}
@@ -1,7 +1,11 @@
 package entity
 
+import "time"
+
 type Transfer struct {
-	Amount            int    `db:"amount"`
-	SenderUsername    string `db:"sender_username"`
-	RecipientUsername string `db:"recipient_username"`
+	OperationID       int       `db:"operation_id"`
+	Amount            int       `db:"amount"`
+	SenderUsername    string    `db:"sender_username"`
+	RecipientUsername string    `db:"recipient_username"`
+	CreatedAt         time.Time `db:"created_at"`
 }
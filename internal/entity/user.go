@@ -1,12 +1,29 @@
 package entity
 
+import "time"
+
 type User struct {
-	ID       int    `db:"id"`
-	Username string `db:"username"`
-	Hash     string `db:"password"`
+	ID           int    `db:"id"`
+	Username     string `db:"username"`
+	Hash         string `db:"password"`
+	TokenVersion int    `db:"token_version"`
+	Role         string `db:"role"`
+	IsService    bool   `db:"is_service"`
+	// Включен ли второй фактор, вычисляется по таблице user_totp.
+	TOTPEnabled bool `db:"totp_enabled"`
+	// Деактивированный пользователь не может войти и получать переводы.
+	DeactivatedAt *time.Time `db:"deactivated_at"`
 }
 
 type CreateUserInput struct {
-	Username string `db:"username"`
-	Hash     string `db:"password"`
+	Username  string `db:"username"`
+	Hash      string `db:"password"`
+	IsService bool   `db:"is_service"`
+}
+
+// Хеш обновляется, только если в БД все еще хранится OldHash.
+type UpdatePasswordHashInput struct {
+	UserID  int
+	OldHash string
+	NewHash string
 }
@@ -1,8 +1,14 @@
 package model
 
+import "time"
+
+// Все поля прочитаны из одного снимка БД. SnapshotAt - момент снимка, LastOperationID - последняя
+// попавшая в него операция счета (0, если операций нет): по ним клиент понимает, устарели ли данные.
 type AccountInfo struct {
 	Balance           int
 	Inventory         []Inventory
 	IncomingTransfers []IncomingTransfer
 	OutgoingTransfers []OutgoingTransfer
+	SnapshotAt        time.Time
+	LastOperationID   int
 }
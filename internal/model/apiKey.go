@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+type CreateAPIKeyInput struct {
+	Username string
+	Name     string
+	// Запрошенные области доступа. Пустой список означает все области, доступные роли владельца.
+	Scopes []Scope
+}
+
+// Key заполнен только в ответе на создание ключа: в БД хранится лишь его хеш.
+type APIKey struct {
+	ID         int
+	Name       string
+	Prefix     string
+	Key        string
+	Scopes     []Scope
+	CreatedAt  time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+}
@@ -1,6 +1,44 @@
 package model
 
+import (
+	"crypto"
+	"time"
+)
+
 type AuthRequestInput struct {
 	Username string
 	Password string
+	// Запрошенные области доступа. Пустой список означает все области, доступные роли.
+	Scopes []Scope
+	// IP-адрес клиента для ограничения числа неудачных попыток входа.
+	ClientIP string
+	// Код из приложения-аутентификатора или код восстановления, если у пользователя включен второй фактор.
+	OTPCode string
+}
+
+type PublicKey struct {
+	ID        string
+	Algorithm string
+	Key       crypto.PublicKey
+}
+
+type AuthTokens struct {
+	AccessToken  string
+	RefreshToken string
+}
+
+type ChangePasswordInput struct {
+	OldPassword string
+	NewPassword string
+}
+
+type ResetPasswordInput struct {
+	ResetToken  string
+	NewPassword string
+}
+
+// Одноразовый токен сброса пароля. Токен в открытом виде возвращается только при создании.
+type PasswordResetToken struct {
+	Token     string
+	ExpiresAt time.Time
 }
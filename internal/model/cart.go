@@ -0,0 +1,21 @@
+package model
+
+// Variant пуст, если в корзину кладется сам товар.
+type CartItemInput struct {
+	ItemName string
+	Variant  string
+	Quantity int
+}
+
+type CartItem struct {
+	Name       string
+	Variant    string
+	Price      int
+	Quantity   int
+	TotalPrice int
+}
+
+type Cart struct {
+	Items      []CartItem
+	TotalPrice int
+}
@@ -1,5 +1,27 @@
 package model
 
+import (
+	"slices"
+	"time"
+)
+
 type Claims struct {
-	UserID int
+	UserID       int
+	TokenID      string
+	TokenVersion int
+	Role         Role
+	Scopes       []Scope
+	ExpiresAt    time.Time
+	// Ненулевой, если запрос аутентифицирован API-ключом, а не JWT.
+	APIKeyID int
+}
+
+func (c Claims) HasScopes(scopes ...Scope) bool {
+	for _, s := range scopes {
+		if !slices.Contains(c.Scopes, s) {
+			return false
+		}
+	}
+
+	return true
 }
@@ -0,0 +1,61 @@
+package model
+
+import "time"
+
+type HistoryOperationType string
+
+const (
+	HistoryPurchase HistoryOperationType = "purchase"
+	HistoryTransfer HistoryOperationType = "transfer"
+	HistoryRefund   HistoryOperationType = "refund"
+)
+
+func (t HistoryOperationType) Valid() bool {
+	return t == HistoryPurchase || t == HistoryTransfer || t == HistoryRefund
+}
+
+// Направление движения монет: покупки и отправленные переводы исходящие,
+// полученные переводы и возвраты входящие.
+type HistoryDirection string
+
+const (
+	HistoryIncoming HistoryDirection = "incoming"
+	HistoryOutgoing HistoryDirection = "outgoing"
+)
+
+func (d HistoryDirection) Valid() bool {
+	return d == HistoryIncoming || d == HistoryOutgoing
+}
+
+// Пустые поля не ограничивают выборку. Период полуоткрытый: [From, To).
+// Cursor берется из NextCursor предыдущей страницы.
+type HistoryFilter struct {
+	Type         HistoryOperationType
+	Direction    HistoryDirection
+	Counterparty string
+	From         *time.Time
+	To           *time.Time
+	Cursor       string
+	Limit        int
+}
+
+// Counterparty заполняется только для переводов, ItemName и Quantity - для покупок и возвратов,
+// RefundStatus - для возвратов.
+type HistoryEntry struct {
+	ID           int
+	Type         HistoryOperationType
+	Direction    HistoryDirection
+	Amount       int
+	Counterparty string
+	ItemName     string
+	Quantity     int
+	RefundStatus RefundStatus
+	CreatedAt    time.Time
+}
+
+// Пустой NextCursor означает, что это последняя страница.
+type HistoryPage struct {
+	Items      []HistoryEntry
+	NextCursor string
+	Limit      int
+}
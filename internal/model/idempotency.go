@@ -0,0 +1,15 @@
+package model
+
+// Запрос с заголовком Idempotency-Key. Fingerprint отличает повтор того же запроса от другого запроса с тем же ключом.
+type IdempotentRequest struct {
+	Key         string
+	Fingerprint string
+}
+
+// Ответ на запрос. Replayed выставляется, если ответ взят из сохраненного результата, а не выполнен заново.
+type IdempotentResponse struct {
+	StatusCode  int
+	ContentType string
+	Body        []byte
+	Replayed    bool
+}
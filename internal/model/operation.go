@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+// Username - инициатор операции: покупатель или отправитель перевода. RecipientUsername и Amount
+// заполняются для переводов, ItemName, Quantity и TotalPrice - для покупок. У возврата есть
+// товар, количество и сумма возврата (Amount), а также RefundStatus.
+type Operation struct {
+	ID                int
+	Type              HistoryOperationType
+	Username          string
+	RecipientUsername string
+	Amount            int
+	ItemName          string
+	Quantity          int
+	TotalPrice        int
+	RefundStatus      RefundStatus
+	CreatedAt         time.Time
+}
@@ -0,0 +1,91 @@
+package model
+
+import "time"
+
+type Product struct {
+	Name        string
+	Price       int
+	Description string
+	Stock       int
+	// Заполняется в карточке отдельного товара и в отчете о заканчивающихся товарах.
+	Variants []ProductVariant
+}
+
+// Вариант товара с действующими ценой и остатком: если у варианта они не заданы, берутся значения товара.
+type ProductVariant struct {
+	Name  string
+	SKU   string
+	Price int
+	Stock int
+}
+
+// Пустые Price и Stock означают, что вариант использует цену и остаток товара.
+type CreateProductVariantInput struct {
+	Name  string
+	SKU   string
+	Price *int
+	Stock *int
+}
+
+type CreateProductInput struct {
+	Name        string
+	Price       int
+	Description string
+	Stock       int
+}
+
+// nil-поля не меняются.
+type UpdateProductInput struct {
+	Price       *int
+	Description *string
+}
+
+type ProductAuditAction string
+
+const (
+	ProductAuditCreate ProductAuditAction = "create"
+	ProductAuditUpdate ProductAuditAction = "update"
+	ProductAuditRetire ProductAuditAction = "retire"
+)
+
+// Изменение одного поля товара. Для снятия с продажи Field, OldValue и NewValue пустые,
+// для создания товара пуст OldValue.
+type ProductAuditEntry struct {
+	Action    ProductAuditAction
+	Field     string
+	OldValue  string
+	NewValue  string
+	ChangedBy string
+	CreatedAt time.Time
+}
+
+type ProductSort string
+
+const (
+	ProductSortName  ProductSort = "name"
+	ProductSortPrice ProductSort = "price"
+)
+
+func (s ProductSort) Valid() bool {
+	return s == ProductSortName || s == ProductSortPrice
+}
+
+// Параметры выборки каталога. Пустое значение поля означает отсутствие фильтра,
+// нулевой Limit заменяется размером страницы по умолчанию.
+type ProductFilter struct {
+	Query    string
+	MinPrice *int
+	MaxPrice *int
+	SortBy   ProductSort
+	Desc     bool
+	Limit    int
+	Offset   int
+}
+
+// Страница каталога и общее число товаров, подходящих под фильтр.
+type ProductPage struct {
+	Items  []Product
+	Total  int
+	Limit  int
+	Offset int
+}
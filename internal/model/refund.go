@@ -0,0 +1,43 @@
+package model
+
+import "time"
+
+type RefundStatus string
+
+const (
+	RefundRequested RefundStatus = "requested"
+	RefundApproved  RefundStatus = "approved"
+	RefundRejected  RefundStatus = "rejected"
+)
+
+func (s RefundStatus) Valid() bool {
+	return s == RefundRequested || s == RefundApproved || s == RefundRejected
+}
+
+// Quantity равное нулю означает возврат всех еще не возвращенных единиц покупки.
+type RefundInput struct {
+	PurchaseID int
+	Quantity   int
+}
+
+type Refund struct {
+	ID         int
+	PurchaseID int
+	Username   string
+	ItemName   string
+	Quantity   int
+	Amount     int
+	Status     RefundStatus
+	CreatedAt  time.Time
+	DecidedAt  *time.Time
+}
+
+type RefundablePurchase struct {
+	ID                 int
+	ItemName           string
+	Quantity           int
+	RefundableQuantity int
+	TotalPrice         int
+	PurchasedAt        time.Time
+	RefundableUntil    time.Time
+}
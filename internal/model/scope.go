@@ -0,0 +1,50 @@
+package model
+
+import (
+	"slices"
+	"strings"
+)
+
+type Scope string
+
+const (
+	ScopeInfoRead  Scope = "info:read"
+	ScopeCoinsSend Scope = "coins:send"
+	ScopeShopBuy   Scope = "shop:buy"
+	ScopeAdmin     Scope = "admin"
+)
+
+var userScopes = []Scope{ScopeInfoRead, ScopeCoinsSend, ScopeShopBuy}
+
+// Все области, которые может получить пользователь с данной ролью.
+func ScopesForRole(role Role) []Scope {
+	scopes := slices.Clone(userScopes)
+	if role == RoleAdmin {
+		scopes = append(scopes, ScopeAdmin)
+	}
+
+	return scopes
+}
+
+func ParseScopes(scope string) []Scope {
+	fields := strings.Fields(scope)
+	if len(fields) == 0 {
+		return nil
+	}
+
+	scopes := make([]Scope, 0, len(fields))
+	for _, f := range fields {
+		scopes = append(scopes, Scope(f))
+	}
+
+	return scopes
+}
+
+func FormatScopes(scopes []Scope) string {
+	fields := make([]string, 0, len(scopes))
+	for _, s := range scopes {
+		fields = append(fields, string(s))
+	}
+
+	return strings.Join(fields, " ")
+}
@@ -0,0 +1,18 @@
+package model
+
+// Данные для подключения приложения-аутентификатора. Секрет показывается только при подключении.
+type TOTPEnrollment struct {
+	Secret string
+	URI    string
+}
+
+type ConfirmTOTPInput struct {
+	Code string
+	// Переводы и покупки дороже Threshold монет будут требовать свежего кода.
+	Threshold int
+}
+
+type SetTOTPThresholdInput struct {
+	Code      string
+	Threshold int
+}
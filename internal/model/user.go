@@ -4,3 +4,25 @@ type CreateUserInput struct {
 	Username string
 	Password string
 }
+
+type Role string
+
+const (
+	RoleUser  Role = "user"
+	RoleAdmin Role = "admin"
+)
+
+func (r Role) Valid() bool {
+	return r == RoleUser || r == RoleAdmin
+}
+
+type SetUserRoleInput struct {
+	Username string
+	Role     Role
+}
+
+type DeactivateUserInput struct {
+	Username string
+	// Перевести остаток монет на казначейский счет.
+	SweepBalance bool
+}
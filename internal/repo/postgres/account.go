@@ -9,6 +9,7 @@ import (
 	"github.com/resueman/merch-store/internal/entity"
 	"github.com/resueman/merch-store/internal/repo/repoerrors"
 	"github.com/resueman/merch-store/pkg/db"
+	"github.com/resueman/merch-store/pkg/username"
 )
 
 type AccountRepo struct {
@@ -49,7 +50,7 @@ func (r *AccountRepo) GetIDByUserID(ctx context.Context, userID int) (int, error
 	return accountID, nil
 }
 
-func (r *AccountRepo) GetIDByUsername(ctx context.Context, username string) (int, error) {
+func (r *AccountRepo) GetIDByUsername(ctx context.Context, name string) (int, error) {
 	database, ok := ctx.Value(db.DBKey).(db.DB)
 	if !ok {
 		database = r.client.Replica()
@@ -59,7 +60,7 @@ func (r *AccountRepo) GetIDByUsername(ctx context.Context, username string) (int
 		Select("accounts.id").
 		From("accounts").
 		Join("users ON accounts.user_id = users.id").
-		Where(sq.Eq{"users.username": username}).
+		Where(sq.Eq{"users.username_normalized": username.Normalize(name)}).
 		ToSql()
 
 	if err != nil {
@@ -106,18 +107,26 @@ func (r *AccountRepo) GetBalanceByAccountID(ctx context.Context, accountID int)
 	return balance, nil
 }
 
+// Одобренные возвраты уменьшают количество товара, полностью возвращенные товары не показываются.
 func (r *AccountRepo) GetPurchasesByAccountID(ctx context.Context, accountID int) ([]entity.Purchase, error) {
 	database, ok := ctx.Value(db.DBKey).(db.DB)
 	if !ok {
 		database = r.client.Replica()
 	}
 
+	const ownedQuantity = "SUM(ops.quantity - COALESCE(rf.quantity, 0))"
+
 	queryRaw, args, err := database.QueryBuilder().
-		Select("p.name", "SUM(ops.quantity) AS quantity").
+		Select(purchasedItemNameExpr+" AS item", ownedQuantity+" AS quantity").
 		From("purchase_operations ops").
 		Join("products p ON ops.product_id = p.id").
+		LeftJoin("product_variants pv ON ops.variant_id = pv.id").
+		LeftJoin(`(SELECT purchase_operation_id, SUM(quantity) AS quantity FROM refunds
+			WHERE status = ? GROUP BY purchase_operation_id) rf ON rf.purchase_operation_id = ops.id`,
+			refundStatusApproved).
 		Where(sq.Eq{"ops.customer_account_id": accountID}).
-		GroupBy("p.name").
+		GroupBy("item").
+		Having(ownedQuantity + " > 0").
 		OrderBy("quantity DESC").
 		ToSql()
 
@@ -192,6 +201,8 @@ func (r *AccountRepo) Withdraw(ctx context.Context, accountID int, amount int) e
 	return nil
 }
 
+// Деактивированные пользователи не получают переводы: для них возвращается repoerrors.ErrNotFound.
+// Проверка в том же запросе, чтобы перевод не проскочил одновременно с деактивацией.
 func (r *AccountRepo) Deposit(ctx context.Context, accountID int, amount int) error {
 	database, ok := ctx.Value(db.DBKey).(db.DB)
 	if !ok {
@@ -202,6 +213,7 @@ func (r *AccountRepo) Deposit(ctx context.Context, accountID int, amount int) er
 		Update("accounts").
 		Set("balance", sq.Expr("balance + ?", amount)).
 		Where(sq.Eq{"id": accountID}).
+		Where("NOT EXISTS (SELECT 1 FROM users u WHERE u.id = accounts.user_id AND u.deactivated_at IS NOT NULL)").
 		ToSql()
 
 	if err != nil {
@@ -209,9 +221,15 @@ func (r *AccountRepo) Deposit(ctx context.Context, accountID int, amount int) er
 	}
 
 	query := db.Query{Name: "Deposit", QueryRaw: queryRaw}
-	if _, err = database.Exec(ctx, query, args...); err != nil {
+
+	tag, err := database.Exec(ctx, query, args...)
+	if err != nil {
 		return err
 	}
 
+	if tag.RowsAffected() == 0 {
+		return repoerrors.ErrNotFound
+	}
+
 	return nil
 }
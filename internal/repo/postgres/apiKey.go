@@ -0,0 +1,177 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/resueman/merch-store/internal/entity"
+	"github.com/resueman/merch-store/internal/repo/repoerrors"
+	"github.com/resueman/merch-store/pkg/db"
+)
+
+type APIKeyRepo struct {
+	client db.Client
+}
+
+func NewAPIKeyRepo(client db.Client) *APIKeyRepo {
+	return &APIKeyRepo{client: client}
+}
+
+func (r *APIKeyRepo) CreateAPIKey(ctx context.Context, input *entity.CreateAPIKeyInput) (int, error) {
+	database, ok := ctx.Value(db.DBKey).(db.DB)
+	if !ok {
+		database = r.client.Primary()
+	}
+
+	queryRaw, args, err := database.QueryBuilder().
+		Insert("api_keys").
+		Columns("user_id", "created_by", "name", "prefix", "key_hash", "scopes").
+		Values(input.UserID, input.CreatedBy, input.Name, input.Prefix, input.KeyHash, input.Scopes).
+		Suffix("RETURNING id").
+		ToSql()
+
+	if err != nil {
+		return 0, err
+	}
+
+	query := db.Query{Name: "CreateAPIKey", QueryRaw: queryRaw}
+
+	var keyID int
+	if err = database.QueryRow(ctx, query, args...).Scan(&keyID); err != nil {
+		return 0, err
+	}
+
+	return keyID, nil
+}
+
+// Ищет ключ вместе с ролью и статусом его владельца, чтобы аутентификация обходилась одним запросом.
+func (r *APIKeyRepo) GetAPIKeyByHash(ctx context.Context, keyHash string) (*entity.APIKey, error) {
+	database, ok := ctx.Value(db.DBKey).(db.DB)
+	if !ok {
+		database = r.client.Replica()
+	}
+
+	queryRaw, args, err := database.QueryBuilder().
+		Select("k.id", "k.user_id", "k.name", "k.prefix", "k.key_hash", "k.scopes",
+			"k.created_at", "k.last_used_at", "k.revoked_at", "u.role", "u.deactivated_at").
+		From("api_keys k").
+		Join("users u ON u.id = k.user_id").
+		Where(sq.Eq{"k.key_hash": keyHash}).
+		ToSql()
+
+	if err != nil {
+		return nil, err
+	}
+
+	query := db.Query{Name: "GetAPIKeyByHash", QueryRaw: queryRaw}
+
+	var key entity.APIKey
+	if err = database.QueryRow(ctx, query, args...).Scan(&key.ID, &key.UserID, &key.Name, &key.Prefix,
+		&key.KeyHash, &key.Scopes, &key.CreatedAt, &key.LastUsedAt, &key.RevokedAt, &key.Role,
+		&key.UserDeactivatedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repoerrors.ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	return &key, nil
+}
+
+func (r *APIKeyRepo) GetAPIKeysByUserID(ctx context.Context, userID int) ([]entity.APIKey, error) {
+	database, ok := ctx.Value(db.DBKey).(db.DB)
+	if !ok {
+		database = r.client.Replica()
+	}
+
+	queryRaw, args, err := database.QueryBuilder().
+		Select("id", "user_id", "name", "prefix", "key_hash", "scopes", "created_at", "last_used_at", "revoked_at").
+		From("api_keys").
+		Where(sq.Eq{"user_id": userID}).
+		OrderBy("id").
+		ToSql()
+
+	if err != nil {
+		return nil, err
+	}
+
+	query := db.Query{Name: "GetAPIKeysByUserID", QueryRaw: queryRaw}
+	rows, err := database.Query(ctx, query, args...)
+
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	key := entity.APIKey{}
+	keys := []entity.APIKey{}
+
+	for rows.Next() {
+		if err = rows.Scan(&key.ID, &key.UserID, &key.Name, &key.Prefix, &key.KeyHash, &key.Scopes,
+			&key.CreatedAt, &key.LastUsedAt, &key.RevokedAt); err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+func (r *APIKeyRepo) RevokeAPIKey(ctx context.Context, keyID int) error {
+	database, ok := ctx.Value(db.DBKey).(db.DB)
+	if !ok {
+		database = r.client.Primary()
+	}
+
+	queryRaw, args, err := database.QueryBuilder().
+		Update("api_keys").
+		Set("revoked_at", sq.Expr("CURRENT_TIMESTAMP")).
+		Where(sq.Eq{"id": keyID, "revoked_at": nil}).
+		ToSql()
+
+	if err != nil {
+		return err
+	}
+
+	query := db.Query{Name: "RevokeAPIKey", QueryRaw: queryRaw}
+
+	tag, err := database.Exec(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return repoerrors.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *APIKeyRepo) TouchAPIKey(ctx context.Context, keyID int, usedAt time.Time) error {
+	database, ok := ctx.Value(db.DBKey).(db.DB)
+	if !ok {
+		database = r.client.Primary()
+	}
+
+	queryRaw, args, err := database.QueryBuilder().
+		Update("api_keys").
+		Set("last_used_at", usedAt).
+		Where(sq.Eq{"id": keyID}).
+		ToSql()
+
+	if err != nil {
+		return err
+	}
+
+	query := db.Query{Name: "TouchAPIKey", QueryRaw: queryRaw}
+	if _, err = database.Exec(ctx, query, args...); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,184 @@
+package postgres
+
+import (
+	"context"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/resueman/merch-store/internal/entity"
+	"github.com/resueman/merch-store/internal/repo/repoerrors"
+	"github.com/resueman/merch-store/pkg/db"
+)
+
+type CartRepo struct {
+	client db.Client
+}
+
+func NewCartRepo(client db.Client) *CartRepo {
+	return &CartRepo{client: client}
+}
+
+// Добавляет товар или его вариант в корзину или увеличивает количество уже добавленного.
+// Возвращает количество в строке корзины после добавления.
+func (r *CartRepo) AddCartItem(ctx context.Context, userID int, productID int, variantID *int, quantity int,
+) (int, error) {
+	database, ok := ctx.Value(db.DBKey).(db.DB)
+	if !ok {
+		database = r.client.Primary()
+	}
+
+	queryRaw, args, err := database.QueryBuilder().
+		Insert("cart_items").
+		Columns("user_id", "product_id", "variant_id", "quantity").
+		Values(userID, productID, variantID, quantity).
+		Suffix(`ON CONFLICT (user_id, product_id, COALESCE(variant_id, 0)) DO UPDATE SET
+			quantity = cart_items.quantity + EXCLUDED.quantity
+			RETURNING quantity`).
+		ToSql()
+
+	if err != nil {
+		return 0, err
+	}
+
+	query := db.Query{Name: "AddCartItem", QueryRaw: queryRaw}
+
+	var total int
+	if err = database.QueryRow(ctx, query, args...).Scan(&total); err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+// Без variantName удаляется строка самого товара, строки его вариантов остаются в корзине.
+func (r *CartRepo) RemoveCartItem(ctx context.Context, userID int, productName string, variantName string) error {
+	database, ok := ctx.Value(db.DBKey).(db.DB)
+	if !ok {
+		database = r.client.Primary()
+	}
+
+	var variant sq.Sqlizer = sq.Eq{"variant_id": nil}
+	if variantName != "" {
+		variant = sq.Expr(`variant_id IN (SELECT id FROM product_variants
+			WHERE product_id = cart_items.product_id AND name = ?)`, variantName)
+	}
+
+	queryRaw, args, err := database.QueryBuilder().
+		Delete("cart_items").
+		Where(sq.Eq{"user_id": userID}).
+		Where(sq.Expr("product_id IN (SELECT id FROM products WHERE name = ?)", productName)).
+		Where(variant).
+		ToSql()
+
+	if err != nil {
+		return err
+	}
+
+	query := db.Query{Name: "RemoveCartItem", QueryRaw: queryRaw}
+
+	tag, err := database.Exec(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return repoerrors.ErrNotFound
+	}
+
+	return nil
+}
+
+// Уменьшает количество товаров в корзине на купленное, например после оформления заказа.
+// Строка удаляется, только если в ней не осталось товара сверх купленного.
+func (r *CartRepo) RemoveCartItems(ctx context.Context, userID int, items []entity.CartItem) error {
+	database, ok := ctx.Value(db.DBKey).(db.DB)
+	if !ok {
+		database = r.client.Primary()
+	}
+
+	for _, item := range items {
+		where := sq.Eq{"user_id": userID, "product_id": item.ProductID, "variant_id": item.VariantID}
+
+		queryRaw, args, err := database.QueryBuilder().
+			Delete("cart_items").
+			Where(where).
+			Where(sq.LtOrEq{"quantity": item.Quantity}).
+			ToSql()
+
+		if err != nil {
+			return err
+		}
+
+		query := db.Query{Name: "RemoveCartItems", QueryRaw: queryRaw}
+
+		tag, err := database.Exec(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+
+		if tag.RowsAffected() > 0 {
+			continue
+		}
+
+		queryRaw, args, err = database.QueryBuilder().
+			Update("cart_items").
+			Set("quantity", sq.Expr("quantity - ?", item.Quantity)).
+			Where(where).
+			ToSql()
+
+		if err != nil {
+			return err
+		}
+
+		query = db.Query{Name: "DecreaseCartItemQuantity", QueryRaw: queryRaw}
+		if _, err = database.Exec(ctx, query, args...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Читается с primary: корзину обычно смотрят сразу после изменения.
+func (r *CartRepo) GetCartItems(ctx context.Context, userID int) ([]entity.CartItem, error) {
+	database, ok := ctx.Value(db.DBKey).(db.DB)
+	if !ok {
+		database = r.client.Primary()
+	}
+
+	queryRaw, args, err := database.QueryBuilder().
+		Select("c.product_id", "c.variant_id", "p.name", "COALESCE(v.name, '')", "COALESCE(v.price, p.price)",
+			"c.quantity").
+		From("cart_items c").
+		Join("products p ON c.product_id = p.id").
+		LeftJoin("product_variants v ON c.variant_id = v.id").
+		Where(sq.Eq{"c.user_id": userID}).
+		OrderBy("c.added_at", "p.name", "v.name NULLS FIRST").
+		ToSql()
+
+	if err != nil {
+		return nil, err
+	}
+
+	query := db.Query{Name: "GetCartItems", QueryRaw: queryRaw}
+
+	rows, err := database.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []entity.CartItem{}
+
+	for rows.Next() {
+		// Строка объявляется заново на каждой итерации: pgx переиспользовал бы указатель VariantID.
+		var item entity.CartItem
+		if err = rows.Scan(&item.ProductID, &item.VariantID, &item.ProductName, &item.VariantName, &item.Price,
+			&item.Quantity); err != nil {
+			return nil, err
+		}
+
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
@@ -0,0 +1,119 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/resueman/merch-store/internal/entity"
+	"github.com/resueman/merch-store/pkg/db"
+)
+
+type DenylistRepo struct {
+	client db.Client
+}
+
+func NewDenylistRepo(client db.Client) *DenylistRepo {
+	return &DenylistRepo{client: client}
+}
+
+func (r *DenylistRepo) RevokeToken(ctx context.Context, input *entity.RevokedToken) error {
+	database, ok := ctx.Value(db.DBKey).(db.DB)
+	if !ok {
+		database = r.client.Primary()
+	}
+
+	queryRaw, args, err := database.QueryBuilder().
+		Insert("revoked_tokens").
+		Columns("jti", "user_id", "expires_at").
+		Values(input.TokenID, input.UserID, input.ExpiresAt).
+		Suffix("ON CONFLICT (jti) DO NOTHING").
+		ToSql()
+
+	if err != nil {
+		return err
+	}
+
+	query := db.Query{Name: "RevokeToken", QueryRaw: queryRaw}
+	if _, err = database.Exec(ctx, query, args...); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *DenylistRepo) GetRevokedTokens(ctx context.Context) ([]entity.RevokedToken, error) {
+	database, ok := ctx.Value(db.DBKey).(db.DB)
+	if !ok {
+		database = r.client.Replica()
+	}
+
+	queryRaw, args, err := database.QueryBuilder().
+		Select("jti", "user_id", "expires_at").
+		From("revoked_tokens").
+		Where(sq.Expr("expires_at > CURRENT_TIMESTAMP")).
+		ToSql()
+
+	if err != nil {
+		return nil, err
+	}
+
+	query := db.Query{Name: "GetRevokedTokens", QueryRaw: queryRaw}
+	rows, err := database.Query(ctx, query, args...)
+
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	token := entity.RevokedToken{}
+	tokens := []entity.RevokedToken{}
+
+	for rows.Next() {
+		if err = rows.Scan(&token.TokenID, &token.UserID, &token.ExpiresAt); err != nil {
+			return nil, err
+		}
+
+		tokens = append(tokens, token)
+	}
+
+	return tokens, rows.Err()
+}
+
+func (r *DenylistRepo) GetTokenVersions(ctx context.Context, revokedSince time.Time) ([]entity.TokenVersion, error) {
+	database, ok := ctx.Value(db.DBKey).(db.DB)
+	if !ok {
+		database = r.client.Replica()
+	}
+
+	queryRaw, args, err := database.QueryBuilder().
+		Select("id", "token_version").
+		From("users").
+		Where(sq.Gt{"tokens_revoked_at": revokedSince}).
+		ToSql()
+
+	if err != nil {
+		return nil, err
+	}
+
+	query := db.Query{Name: "GetTokenVersions", QueryRaw: queryRaw}
+	rows, err := database.Query(ctx, query, args...)
+
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	version := entity.TokenVersion{}
+	versions := []entity.TokenVersion{}
+
+	for rows.Next() {
+		if err = rows.Scan(&version.UserID, &version.Version); err != nil {
+			return nil, err
+		}
+
+		versions = append(versions, version)
+	}
+
+	return versions, rows.Err()
+}
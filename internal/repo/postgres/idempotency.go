@@ -0,0 +1,169 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/resueman/merch-store/internal/entity"
+	"github.com/resueman/merch-store/internal/repo/repoerrors"
+	"github.com/resueman/merch-store/pkg/db"
+)
+
+type IdempotencyRepo struct {
+	client db.Client
+}
+
+func NewIdempotencyRepo(client db.Client) *IdempotencyRepo {
+	return &IdempotencyRepo{client: client}
+}
+
+// Если ключ уже занят, возвращает ErrAlreadyExists. Параллельный запрос с тем же ключом
+// ждет завершения транзакции, занявшей ключ.
+func (r *IdempotencyRepo) CreateIdempotencyKey(ctx context.Context, key entity.IdempotencyKey) error {
+	database, ok := ctx.Value(db.DBKey).(db.DB)
+	if !ok {
+		database = r.client.Primary()
+	}
+
+	queryRaw, args, err := database.QueryBuilder().
+		Insert("idempotency_keys").
+		Columns("user_id", "idempotency_key", "fingerprint", "expires_at").
+		Values(key.UserID, key.Key, key.Fingerprint, key.ExpiresAt).
+		Suffix("ON CONFLICT (user_id, idempotency_key) DO NOTHING").
+		ToSql()
+
+	if err != nil {
+		return err
+	}
+
+	query := db.Query{Name: "CreateIdempotencyKey", QueryRaw: queryRaw}
+
+	tag, err := database.Exec(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return repoerrors.ErrAlreadyExists
+	}
+
+	return nil
+}
+
+func (r *IdempotencyRepo) GetIdempotencyKey(ctx context.Context, userID int, key string) (*entity.IdempotencyKey,
+	error,
+) {
+	database, ok := ctx.Value(db.DBKey).(db.DB)
+	if !ok {
+		database = r.client.Primary()
+	}
+
+	queryRaw, args, err := database.QueryBuilder().
+		Select("user_id", "idempotency_key", "fingerprint", "status_code", "content_type", "response_body",
+			"created_at", "expires_at").
+		From("idempotency_keys").
+		Where(sq.Eq{"user_id": userID, "idempotency_key": key}).
+		ToSql()
+
+	if err != nil {
+		return nil, err
+	}
+
+	query := db.Query{Name: "GetIdempotencyKey", QueryRaw: queryRaw}
+
+	var stored entity.IdempotencyKey
+	if err = database.QueryRow(ctx, query, args...).Scan(&stored.UserID, &stored.Key, &stored.Fingerprint,
+		&stored.StatusCode, &stored.ContentType, &stored.ResponseBody, &stored.CreatedAt,
+		&stored.ExpiresAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repoerrors.ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	return &stored, nil
+}
+
+func (r *IdempotencyRepo) SaveIdempotencyResponse(ctx context.Context, key entity.IdempotencyKey) error {
+	database, ok := ctx.Value(db.DBKey).(db.DB)
+	if !ok {
+		database = r.client.Primary()
+	}
+
+	queryRaw, args, err := database.QueryBuilder().
+		Update("idempotency_keys").
+		Set("status_code", key.StatusCode).
+		Set("content_type", key.ContentType).
+		Set("response_body", key.ResponseBody).
+		Where(sq.Eq{"user_id": key.UserID, "idempotency_key": key.Key}).
+		ToSql()
+
+	if err != nil {
+		return err
+	}
+
+	query := db.Query{Name: "SaveIdempotencyResponse", QueryRaw: queryRaw}
+
+	tag, err := database.Exec(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return repoerrors.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *IdempotencyRepo) DeleteIdempotencyKey(ctx context.Context, userID int, key string) error {
+	database, ok := ctx.Value(db.DBKey).(db.DB)
+	if !ok {
+		database = r.client.Primary()
+	}
+
+	queryRaw, args, err := database.QueryBuilder().
+		Delete("idempotency_keys").
+		Where(sq.Eq{"user_id": userID, "idempotency_key": key}).
+		ToSql()
+
+	if err != nil {
+		return err
+	}
+
+	query := db.Query{Name: "DeleteIdempotencyKey", QueryRaw: queryRaw}
+	if _, err = database.Exec(ctx, query, args...); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Удаляет истекшие ключи пользователя, чтобы их можно было использовать снова.
+func (r *IdempotencyRepo) DeleteExpiredIdempotencyKeys(ctx context.Context, userID int, now time.Time) error {
+	database, ok := ctx.Value(db.DBKey).(db.DB)
+	if !ok {
+		database = r.client.Primary()
+	}
+
+	queryRaw, args, err := database.QueryBuilder().
+		Delete("idempotency_keys").
+		Where(sq.Eq{"user_id": userID}).
+		Where(sq.LtOrEq{"expires_at": now}).
+		ToSql()
+
+	if err != nil {
+		return err
+	}
+
+	query := db.Query{Name: "DeleteExpiredIdempotencyKeys", QueryRaw: queryRaw}
+	if _, err = database.Exec(ctx, query, args...); err != nil {
+		return err
+	}
+
+	return nil
+}
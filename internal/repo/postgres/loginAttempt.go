@@ -0,0 +1,128 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/resueman/merch-store/internal/entity"
+	"github.com/resueman/merch-store/pkg/db"
+)
+
+type LoginAttemptRepo struct {
+	client db.Client
+}
+
+func NewLoginAttemptRepo(client db.Client) *LoginAttemptRepo {
+	return &LoginAttemptRepo{client: client}
+}
+
+// Возвращает самую позднюю блокировку среди ключей или nil, если ключи никогда не блокировались.
+func (r *LoginAttemptRepo) GetLoginLockedUntil(ctx context.Context, keys []entity.LoginAttemptKey) (*time.Time, error) {
+	database, ok := ctx.Value(db.DBKey).(db.DB)
+	if !ok {
+		database = r.client.Primary()
+	}
+
+	condition := sq.Or{}
+	for _, k := range keys {
+		condition = append(condition, sq.Eq{"key_type": k.KeyType, "key": k.Key})
+	}
+
+	queryRaw, args, err := database.QueryBuilder().
+		Select("MAX(locked_until)").
+		From("login_attempts").
+		Where(condition).
+		ToSql()
+
+	if err != nil {
+		return nil, err
+	}
+
+	query := db.Query{Name: "GetLoginLockedUntil", QueryRaw: queryRaw}
+
+	var lockedUntil *time.Time
+	if err = database.QueryRow(ctx, query, args...).Scan(&lockedUntil); err != nil {
+		return nil, err
+	}
+
+	return lockedUntil, nil
+}
+
+// Увеличивает счетчик неудачных попыток и возвращает его новое значение.
+func (r *LoginAttemptRepo) RecordLoginFailure(ctx context.Context, input *entity.LoginFailure) (int, error) {
+	database, ok := ctx.Value(db.DBKey).(db.DB)
+	if !ok {
+		database = r.client.Primary()
+	}
+
+	queryRaw, args, err := database.QueryBuilder().
+		Insert("login_attempts").
+		Columns("key_type", "key", "failures", "last_failure_at").
+		Values(input.KeyType, input.Key, 1, input.FailedAt).
+		Suffix(`ON CONFLICT (key_type, key) DO UPDATE SET
+			failures = CASE WHEN login_attempts.last_failure_at < ? THEN 1 ELSE login_attempts.failures + 1 END,
+			last_failure_at = EXCLUDED.last_failure_at
+			RETURNING failures`, input.ResetBefore).
+		ToSql()
+
+	if err != nil {
+		return 0, err
+	}
+
+	query := db.Query{Name: "RecordLoginFailure", QueryRaw: queryRaw}
+
+	var failures int
+	if err = database.QueryRow(ctx, query, args...).Scan(&failures); err != nil {
+		return 0, err
+	}
+
+	return failures, nil
+}
+
+func (r *LoginAttemptRepo) LockLogin(ctx context.Context, key entity.LoginAttemptKey, until time.Time) error {
+	database, ok := ctx.Value(db.DBKey).(db.DB)
+	if !ok {
+		database = r.client.Primary()
+	}
+
+	queryRaw, args, err := database.QueryBuilder().
+		Update("login_attempts").
+		Set("locked_until", until).
+		Where(sq.Eq{"key_type": key.KeyType, "key": key.Key}).
+		ToSql()
+
+	if err != nil {
+		return err
+	}
+
+	query := db.Query{Name: "LockLogin", QueryRaw: queryRaw}
+	if _, err = database.Exec(ctx, query, args...); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *LoginAttemptRepo) ResetLoginFailures(ctx context.Context, key entity.LoginAttemptKey) error {
+	database, ok := ctx.Value(db.DBKey).(db.DB)
+	if !ok {
+		database = r.client.Primary()
+	}
+
+	queryRaw, args, err := database.QueryBuilder().
+		Delete("login_attempts").
+		Where(sq.Eq{"key_type": key.KeyType, "key": key.Key}).
+		ToSql()
+
+	if err != nil {
+		return err
+	}
+
+	query := db.Query{Name: "ResetLoginFailures", QueryRaw: queryRaw}
+	if _, err = database.Exec(ctx, query, args...); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -2,9 +2,12 @@ package postgres
 
 import (
 	"context"
+	"errors"
 
 	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
 	"github.com/resueman/merch-store/internal/entity"
+	"github.com/resueman/merch-store/internal/repo/repoerrors"
 	"github.com/resueman/merch-store/pkg/db"
 )
 
@@ -19,6 +22,7 @@ func NewOperationRepo(client db.Client) *OperationRepo {
 const (
 	operationTypePurchase = "purchase"
 	operationTypeTransfer = "transfer"
+	operationTypeRefund   = "refund"
 )
 
 func (r *OperationRepo) ExecPurchaseOperation(ctx context.Context, input entity.PurchaseOperation) error {
@@ -47,8 +51,8 @@ func (r *OperationRepo) ExecPurchaseOperation(ctx context.Context, input entity.
 
 	queryRaw, args, err := database.QueryBuilder().
 		Insert("purchase_operations").
-		Columns("operation_id", "product_id", "customer_account_id", "quantity", "total_price").
-		Values(operationID, input.ItemID, input.CustomerAccountID, input.Quantity, input.TotalPrice).
+		Columns("operation_id", "product_id", "variant_id", "customer_account_id", "quantity", "total_price").
+		Values(operationID, input.ItemID, input.VariantID, input.CustomerAccountID, input.Quantity, input.TotalPrice).
 		ToSql()
 
 	if err != nil {
@@ -105,6 +109,50 @@ func (r *OperationRepo) ExecTransferOperation(ctx context.Context, input entity.
 	return nil
 }
 
+// Записывает шаг возврата в историю операций покупателя.
+func (r *OperationRepo) ExecRefundOperation(ctx context.Context, input entity.RefundOperation) error {
+	database, ok := ctx.Value(db.DBKey).(db.DB)
+	if !ok {
+		database = r.client.Primary()
+	}
+
+	insertOperationQuery, args, err := database.QueryBuilder().
+		Insert("operations").
+		Columns("account_id", "operation_type").
+		Values(input.CustomerAccountID, operationTypeRefund).
+		Suffix("RETURNING id").
+		ToSql()
+
+	if err != nil {
+		return err
+	}
+
+	query := db.Query{Name: "ExecRefundOperation", QueryRaw: insertOperationQuery}
+
+	var operationID int
+	if err := database.QueryRow(ctx, query, args...).Scan(&operationID); err != nil {
+		return err
+	}
+
+	queryRaw, args, err := database.QueryBuilder().
+		Insert("refund_operations").
+		Columns("operation_id", "refund_id", "status").
+		Values(operationID, input.RefundID, input.Status).
+		ToSql()
+
+	if err != nil {
+		return err
+	}
+
+	query = db.Query{Name: "ExecRefundOperation", QueryRaw: queryRaw}
+	if _, err = database.Exec(ctx, query, args...); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Возвращает отправленные переводы от новых к старым.
 func (r *OperationRepo) GetOutgoingTransfers(ctx context.Context, accountID int) ([]entity.Transfer, error) {
 	database, ok := ctx.Value(db.DBKey).(db.DB)
 	if !ok {
@@ -112,11 +160,13 @@ func (r *OperationRepo) GetOutgoingTransfers(ctx context.Context, accountID int)
 	}
 
 	queryRaw, args, err := database.QueryBuilder().
-		Select("amount", "users.username as recipient_username").
-		From("transfer_operations").
-		Join("accounts ON transfer_operations.recipient_account_id = accounts.id").
-		Join("users ON accounts.id = users.id").
-		Where(sq.Eq{"transfer_operations.sender_account_id": accountID}).
+		Select("o.id", "t.amount", "u.username AS recipient_username", "o.created_at").
+		From("transfer_operations t").
+		Join("operations o ON t.operation_id = o.id").
+		Join("accounts a ON t.recipient_account_id = a.id").
+		Join("users u ON a.user_id = u.id").
+		Where(sq.Eq{"t.sender_account_id": accountID}).
+		OrderBy("o.created_at DESC", "o.id DESC").
 		ToSql()
 
 	if err != nil {
@@ -129,21 +179,24 @@ func (r *OperationRepo) GetOutgoingTransfers(ctx context.Context, accountID int)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
 	sentOp := entity.Transfer{}
 	sentOps := []entity.Transfer{}
 
 	for rows.Next() {
-		if err = rows.Scan(&sentOp.Amount, &sentOp.RecipientUsername); err != nil {
+		if err = rows.Scan(&sentOp.OperationID, &sentOp.Amount, &sentOp.RecipientUsername,
+			&sentOp.CreatedAt); err != nil {
 			return nil, err
 		}
 
 		sentOps = append(sentOps, sentOp)
 	}
 
-	return sentOps, nil
+	return sentOps, rows.Err()
 }
 
+// Возвращает полученные переводы от новых к старым.
 func (r *OperationRepo) GetIncomingTransfers(ctx context.Context, accountID int) ([]entity.Transfer, error) {
 	database, ok := ctx.Value(db.DBKey).(db.DB)
 	if !ok {
@@ -151,11 +204,13 @@ func (r *OperationRepo) GetIncomingTransfers(ctx context.Context, accountID int)
 	}
 
 	queryRaw, args, err := database.QueryBuilder().
-		Select("amount", "users.username as sender_username").
-		From("transfer_operations").
-		Join("accounts ON transfer_operations.sender_account_id = accounts.id").
-		Join("users ON accounts.id = users.id").
-		Where(sq.Eq{"transfer_operations.recipient_account_id": accountID}).
+		Select("o.id", "t.amount", "u.username AS sender_username", "o.created_at").
+		From("transfer_operations t").
+		Join("operations o ON t.operation_id = o.id").
+		Join("accounts a ON t.sender_account_id = a.id").
+		Join("users u ON a.user_id = u.id").
+		Where(sq.Eq{"t.recipient_account_id": accountID}).
+		OrderBy("o.created_at DESC", "o.id DESC").
 		ToSql()
 
 	if err != nil {
@@ -168,17 +223,195 @@ func (r *OperationRepo) GetIncomingTransfers(ctx context.Context, accountID int)
 	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
 	receivedOp := entity.Transfer{}
 	receivedOps := []entity.Transfer{}
 
 	for rows.Next() {
-		if err = rows.Scan(&receivedOp.Amount, &receivedOp.SenderUsername); err != nil {
+		if err = rows.Scan(&receivedOp.OperationID, &receivedOp.Amount, &receivedOp.SenderUsername,
+			&receivedOp.CreatedAt); err != nil {
 			return nil, err
 		}
 
 		receivedOps = append(receivedOps, receivedOp)
 	}
 
-	return receivedOps, nil
+	return receivedOps, rows.Err()
+}
+
+const (
+	historyDirectionIncoming = "incoming"
+	historyDirectionOutgoing = "outgoing"
+)
+
+// Направление считается по движению монет: возвраты и полученные переводы входящие,
+// покупки и отправленные переводы исходящие. Полученный перевод записан на счет отправителя.
+const historyIncomingExpr = "o.operation_type = 'refund' OR o.account_id <> ?"
+
+// Возвращает не больше filter.Limit операций счета от новых к старым: покупки, возвраты,
+// отправленные и полученные переводы.
+func (r *OperationRepo) GetHistory(ctx context.Context, filter entity.HistoryFilter) ([]entity.HistoryEntry, error) {
+	database, ok := ctx.Value(db.DBKey).(db.DB)
+	if !ok {
+		database = r.client.Replica()
+	}
+
+	builder := joinOperationDetails(database.QueryBuilder().
+		Select("o.id", "o.operation_type::text").
+		Column(sq.Expr("CASE WHEN "+historyIncomingExpr+" THEN ? ELSE ? END",
+			filter.AccountID, historyDirectionIncoming, historyDirectionOutgoing)).
+		Columns("COALESCE(po.total_price, t.amount, rf.amount)", "COALESCE(cu.username, '')",
+			"COALESCE("+purchasedItemNameExpr+", '')", "COALESCE(rf.quantity, po.quantity, 0)",
+			"COALESCE(ro.status::text, '')", "o.created_at").
+		From("operations o")).
+		LeftJoin("accounts ca ON ca.id = CASE WHEN t.sender_account_id = ? "+
+			"THEN t.recipient_account_id ELSE t.sender_account_id END", filter.AccountID).
+		LeftJoin("users cu ON ca.user_id = cu.id").
+		Where(sq.Or{sq.Eq{"o.account_id": filter.AccountID}, sq.Eq{"t.recipient_account_id": filter.AccountID}})
+
+	if filter.OperationType != "" {
+		builder = builder.Where(sq.Eq{"o.operation_type": filter.OperationType})
+	}
+
+	switch filter.Direction {
+	case historyDirectionIncoming:
+		builder = builder.Where("("+historyIncomingExpr+")", filter.AccountID)
+	case historyDirectionOutgoing:
+		builder = builder.Where("NOT ("+historyIncomingExpr+")", filter.AccountID)
+	}
+
+	if filter.CounterpartyAccountID != nil {
+		builder = builder.Where(sq.Or{
+			sq.Eq{"t.sender_account_id": *filter.CounterpartyAccountID},
+			sq.Eq{"t.recipient_account_id": *filter.CounterpartyAccountID},
+		})
+	}
+
+	if filter.From != nil {
+		builder = builder.Where(sq.GtOrEq{"o.created_at": *filter.From})
+	}
+
+	if filter.To != nil {
+		builder = builder.Where(sq.Lt{"o.created_at": *filter.To})
+	}
+
+	if filter.AfterID != 0 {
+		builder = builder.Where("(o.created_at, o.id) < (?, ?)", filter.AfterCreatedAt, filter.AfterID)
+	}
+
+	queryRaw, args, err := builder.
+		OrderBy("o.created_at DESC", "o.id DESC").
+		Limit(uint64(filter.Limit)).
+		ToSql()
+
+	if err != nil {
+		return nil, err
+	}
+
+	query := db.Query{Name: "GetHistory", QueryRaw: queryRaw}
+	rows, err := database.Query(ctx, query, args...)
+
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entry := entity.HistoryEntry{}
+	entries := []entity.HistoryEntry{}
+
+	for rows.Next() {
+		if err = rows.Scan(&entry.OperationID, &entry.OperationType, &entry.Direction, &entry.Amount,
+			&entry.Counterparty, &entry.ProductName, &entry.Quantity, &entry.RefundStatus,
+			&entry.CreatedAt); err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// Возвращает время запроса и наибольший id операции счета, включая полученные переводы.
+// В транзакции-снимке время первого запроса совпадает с моментом получения снимка.
+func (r *OperationRepo) GetHistoryMark(ctx context.Context, accountID int) (*entity.HistoryMark, error) {
+	database, ok := ctx.Value(db.DBKey).(db.DB)
+	if !ok {
+		database = r.client.Replica()
+	}
+
+	queryRaw, args, err := database.QueryBuilder().
+		Select("statement_timestamp()", "COALESCE(MAX(o.id), 0)").
+		From("operations o").
+		LeftJoin("transfer_operations t ON t.operation_id = o.id").
+		Where(sq.Or{sq.Eq{"o.account_id": accountID}, sq.Eq{"t.recipient_account_id": accountID}}).
+		ToSql()
+
+	if err != nil {
+		return nil, err
+	}
+
+	query := db.Query{Name: "GetHistoryMark", QueryRaw: queryRaw}
+
+	var mark entity.HistoryMark
+	if err = database.QueryRow(ctx, query, args...).Scan(&mark.TakenAt, &mark.LastOperationID); err != nil {
+		return nil, err
+	}
+
+	return &mark, nil
+}
+
+// Возвращает операцию вместе с участниками, суммой и товаром.
+func (r *OperationRepo) GetOperationByID(ctx context.Context, operationID int) (*entity.OperationDetails, error) {
+	database, ok := ctx.Value(db.DBKey).(db.DB)
+	if !ok {
+		database = r.client.Replica()
+	}
+
+	queryRaw, args, err := joinOperationDetails(database.QueryBuilder().
+		Select("o.id", "o.operation_type::text", "o.account_id", "u.username", "t.recipient_account_id",
+			"COALESCE(ru.username, '')", "COALESCE(t.amount, rf.amount, 0)",
+			"COALESCE("+purchasedItemNameExpr+", '')", "COALESCE(rf.quantity, po.quantity, 0)",
+			"COALESCE(po.total_price, 0)", "COALESCE(ro.status::text, '')", "o.created_at").
+		From("operations o").
+		Join("accounts a ON o.account_id = a.id").
+		Join("users u ON a.user_id = u.id")).
+		LeftJoin("accounts ra ON t.recipient_account_id = ra.id").
+		LeftJoin("users ru ON ra.user_id = ru.id").
+		Where(sq.Eq{"o.id": operationID}).
+		ToSql()
+
+	if err != nil {
+		return nil, err
+	}
+
+	query := db.Query{Name: "GetOperationByID", QueryRaw: queryRaw}
+
+	var operation entity.OperationDetails
+	if err = database.QueryRow(ctx, query, args...).Scan(&operation.ID, &operation.OperationType,
+		&operation.AccountID, &operation.Username, &operation.RecipientAccountID, &operation.RecipientUsername,
+		&operation.Amount, &operation.ProductName, &operation.Quantity, &operation.TotalPrice,
+		&operation.RefundStatus, &operation.CreatedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repoerrors.ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	return &operation, nil
+}
+
+// Присоединяет к operations o данные перевода (t), шага возврата (ro, rf) и покупки (po)
+// вместе с товаром (p, pv). Для возврата товар берется из покупки, к которой он относится (rpo).
+func joinOperationDetails(builder sq.SelectBuilder) sq.SelectBuilder {
+	return builder.
+		LeftJoin("transfer_operations t ON t.operation_id = o.id").
+		LeftJoin("refund_operations ro ON ro.operation_id = o.id").
+		LeftJoin("refunds rf ON ro.refund_id = rf.id").
+		LeftJoin("purchase_operations po ON po.operation_id = o.id").
+		LeftJoin("purchase_operations rpo ON rf.purchase_operation_id = rpo.id").
+		LeftJoin("products p ON p.id = COALESCE(po.product_id, rpo.product_id)").
+		LeftJoin("product_variants pv ON pv.id = COALESCE(po.variant_id, rpo.variant_id)")
 }
@@ -0,0 +1,125 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/resueman/merch-store/internal/entity"
+	"github.com/resueman/merch-store/internal/repo/repoerrors"
+	"github.com/resueman/merch-store/pkg/db"
+)
+
+type PasswordResetRepo struct {
+	client db.Client
+}
+
+func NewPasswordResetRepo(client db.Client) *PasswordResetRepo {
+	return &PasswordResetRepo{client: client}
+}
+
+func (r *PasswordResetRepo) CreatePasswordResetToken(ctx context.Context, input *entity.CreatePasswordResetTokenInput) error {
+	database, ok := ctx.Value(db.DBKey).(db.DB)
+	if !ok {
+		database = r.client.Primary()
+	}
+
+	queryRaw, args, err := database.QueryBuilder().
+		Insert("password_reset_tokens").
+		Columns("user_id", "created_by", "token_hash", "expires_at").
+		Values(input.UserID, input.CreatedBy, input.TokenHash, input.ExpiresAt).
+		ToSql()
+
+	if err != nil {
+		return err
+	}
+
+	query := db.Query{Name: "CreatePasswordResetToken", QueryRaw: queryRaw}
+	if _, err = database.Exec(ctx, query, args...); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Блокирует строку токена до конца транзакции, чтобы один токен нельзя было использовать дважды.
+func (r *PasswordResetRepo) GetPasswordResetTokenByHash(ctx context.Context, tokenHash string) (*entity.PasswordResetToken, error) {
+	database, ok := ctx.Value(db.DBKey).(db.DB)
+	if !ok {
+		database = r.client.Primary()
+	}
+
+	queryRaw, args, err := database.QueryBuilder().
+		Select("id", "user_id", "token_hash", "expires_at", "used_at").
+		From("password_reset_tokens").
+		Where(sq.Eq{"token_hash": tokenHash}).
+		Suffix("FOR UPDATE").
+		ToSql()
+
+	if err != nil {
+		return nil, err
+	}
+
+	query := db.Query{Name: "GetPasswordResetTokenByHash", QueryRaw: queryRaw}
+
+	var token entity.PasswordResetToken
+	if err = database.QueryRow(ctx, query, args...).Scan(&token.ID, &token.UserID,
+		&token.TokenHash, &token.ExpiresAt, &token.UsedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repoerrors.ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+func (r *PasswordResetRepo) MarkPasswordResetTokenUsed(ctx context.Context, tokenID int) error {
+	database, ok := ctx.Value(db.DBKey).(db.DB)
+	if !ok {
+		database = r.client.Primary()
+	}
+
+	queryRaw, args, err := database.QueryBuilder().
+		Update("password_reset_tokens").
+		Set("used_at", sq.Expr("CURRENT_TIMESTAMP")).
+		Where(sq.Eq{"id": tokenID}).
+		ToSql()
+
+	if err != nil {
+		return err
+	}
+
+	query := db.Query{Name: "MarkPasswordResetTokenUsed", QueryRaw: queryRaw}
+	if _, err = database.Exec(ctx, query, args...); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Удаляет еще не использованные токены пользователя, использованные остаются для истории.
+func (r *PasswordResetRepo) DeleteUnusedPasswordResetTokens(ctx context.Context, userID int) error {
+	database, ok := ctx.Value(db.DBKey).(db.DB)
+	if !ok {
+		database = r.client.Primary()
+	}
+
+	queryRaw, args, err := database.QueryBuilder().
+		Delete("password_reset_tokens").
+		Where(sq.Eq{"user_id": userID, "used_at": nil}).
+		ToSql()
+
+	if err != nil {
+		return err
+	}
+
+	query := db.Query{Name: "DeleteUnusedPasswordResetTokens", QueryRaw: queryRaw}
+	if _, err = database.Exec(ctx, query, args...); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -3,14 +3,20 @@ package postgres
 import (
 	"context"
 	"errors"
+	"strings"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/resueman/merch-store/internal/entity"
 	"github.com/resueman/merch-store/internal/repo/repoerrors"
 	"github.com/resueman/merch-store/pkg/db"
 )
 
+// Название купленного товара вместе с вариантом, например "hoody (XL)".
+// Запрос должен соединять products p и product_variants pv.
+const purchasedItemNameExpr = "p.name || COALESCE(' (' || pv.name || ')', '')"
+
 type ProductRepo struct {
 	client db.Client
 }
@@ -19,6 +25,7 @@ func NewProductRepo(client db.Client) *ProductRepo {
 	return &ProductRepo{client: client}
 }
 
+// Ищет только товары, которые не сняты с продажи.
 func (r *ProductRepo) GetProductByName(ctx context.Context, name string) (*entity.Product, error) {
 	database, ok := ctx.Value(db.DBKey).(db.DB)
 	if !ok {
@@ -26,9 +33,9 @@ func (r *ProductRepo) GetProductByName(ctx context.Context, name string) (*entit
 	}
 
 	queryRaw, args, err := database.QueryBuilder().
-		Select("id", "name", "price").
+		Select("id", "name", "price", "description", "stock").
 		From("products").
-		Where(sq.Eq{"name": name}).
+		Where(sq.Eq{"name": name, "retired_at": nil}).
 		ToSql()
 
 	if err != nil {
@@ -38,7 +45,8 @@ func (r *ProductRepo) GetProductByName(ctx context.Context, name string) (*entit
 	query := db.Query{Name: "GetProductByName", QueryRaw: queryRaw}
 	product := entity.Product{}
 
-	if err = database.QueryRow(ctx, query, args...).Scan(&product.ID, &product.Name, &product.Price); err != nil {
+	if err = database.QueryRow(ctx, query, args...).Scan(&product.ID, &product.Name, &product.Price,
+		&product.Description, &product.Stock); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, repoerrors.ErrNotFound
 		}
@@ -48,3 +56,621 @@ func (r *ProductRepo) GetProductByName(ctx context.Context, name string) (*entit
 
 	return &product, nil
 }
+
+// Списывает quantity единиц со склада. Если их не хватает, остаток не меняется
+// и возвращается ErrNotEnoughStock.
+func (r *ProductRepo) ReserveStock(ctx context.Context, productID int, quantity int) error {
+	database, ok := ctx.Value(db.DBKey).(db.DB)
+	if !ok {
+		database = r.client.Primary()
+	}
+
+	queryRaw, args, err := database.QueryBuilder().
+		Update("products").
+		Set("stock", sq.Expr("stock - ?", quantity)).
+		Where(sq.Eq{"id": productID}).
+		Where(sq.GtOrEq{"stock": quantity}).
+		ToSql()
+
+	if err != nil {
+		return err
+	}
+
+	query := db.Query{Name: "ReserveStock", QueryRaw: queryRaw}
+
+	tag, err := database.Exec(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return repoerrors.ErrNotEnoughStock
+	}
+
+	return nil
+}
+
+// Возвращает на склад quantity единиц и отдает новый остаток.
+func (r *ProductRepo) AddStock(ctx context.Context, productID int, quantity int) (int, error) {
+	database, ok := ctx.Value(db.DBKey).(db.DB)
+	if !ok {
+		database = r.client.Primary()
+	}
+
+	queryRaw, args, err := database.QueryBuilder().
+		Update("products").
+		Set("stock", sq.Expr("stock + ?", quantity)).
+		Where(sq.Eq{"id": productID}).
+		Suffix("RETURNING stock").
+		ToSql()
+
+	if err != nil {
+		return 0, err
+	}
+
+	query := db.Query{Name: "AddStock", QueryRaw: queryRaw}
+
+	var stock int
+	if err = database.QueryRow(ctx, query, args...).Scan(&stock); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, repoerrors.ErrNotFound
+		}
+
+		return 0, err
+	}
+
+	return stock, nil
+}
+
+func (r *ProductRepo) CreateRestock(ctx context.Context, restock entity.Restock) error {
+	database, ok := ctx.Value(db.DBKey).(db.DB)
+	if !ok {
+		database = r.client.Primary()
+	}
+
+	queryRaw, args, err := database.QueryBuilder().
+		Insert("product_restocks").
+		Columns("product_id", "variant_id", "quantity", "restocked_by").
+		Values(restock.ProductID, restock.VariantID, restock.Quantity, restock.RestockedBy).
+		ToSql()
+
+	if err != nil {
+		return err
+	}
+
+	query := db.Query{Name: "CreateRestock", QueryRaw: queryRaw}
+
+	_, err = database.Exec(ctx, query, args...)
+
+	return err
+}
+
+// Товары в продаже и их варианты с собственным остатком, которых на складе осталось не больше threshold,
+// начиная с самых дефицитных. Варианты без собственного остатка расходуют остаток товара и отдельно не попадают.
+func (r *ProductRepo) GetLowStockProducts(ctx context.Context, threshold int) ([]entity.LowStockProduct, error) {
+	database, ok := ctx.Value(db.DBKey).(db.DB)
+	if !ok {
+		database = r.client.Replica()
+	}
+
+	// Вторая часть UNION собирается с плейсхолдерами "?": их нумерует общий построитель запроса,
+	// иначе обе части ссылались бы на $1, а аргументов было бы два.
+	variantsRaw, variantsArgs, err := sq.
+		Select("p.id", "p.name", "p.price", "p.description", "p.stock",
+			"v.id", "v.name", "v.sku", "v.price", "v.stock", "v.stock AS left_in_stock").
+		From("product_variants v").
+		Join("products p ON p.id = v.product_id").
+		Where(sq.LtOrEq{"v.stock": threshold}).
+		Where(sq.Eq{"p.retired_at": nil}).
+		ToSql()
+
+	if err != nil {
+		return nil, err
+	}
+
+	queryRaw, args, err := database.QueryBuilder().
+		Select("id", "name", "price", "description", "stock",
+			"NULL::int", "NULL::varchar", "NULL::varchar", "NULL::int", "NULL::int", "stock AS left_in_stock").
+		From("products").
+		Where(sq.LtOrEq{"stock": threshold}).
+		Where(sq.Eq{"retired_at": nil}).
+		Suffix("UNION ALL "+variantsRaw+" ORDER BY left_in_stock, 2, 7 NULLS FIRST", variantsArgs...).
+		ToSql()
+
+	if err != nil {
+		return nil, err
+	}
+
+	query := db.Query{Name: "GetLowStockProducts", QueryRaw: queryRaw}
+	rows, err := database.Query(ctx, query, args...)
+
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	products := []entity.LowStockProduct{}
+
+	for rows.Next() {
+		var (
+			item        entity.LowStockProduct
+			variantID   *int
+			variantName *string
+			variantSKU  *string
+			variant     entity.ProductVariant
+			leftInStock int
+		)
+
+		if err = rows.Scan(&item.Product.ID, &item.Product.Name, &item.Product.Price, &item.Product.Description,
+			&item.Product.Stock, &variantID, &variantName, &variantSKU, &variant.Price, &variant.Stock,
+			&leftInStock); err != nil {
+			return nil, err
+		}
+
+		if variantID != nil {
+			variant.ID, variant.ProductID = *variantID, item.Product.ID
+			variant.Name, variant.SKU = *variantName, *variantSKU
+			item.Variant = &variant
+		}
+
+		products = append(products, item)
+	}
+
+	return products, rows.Err()
+}
+
+// Колонки, по которым можно сортировать каталог. Остальные значения OrderBy заменяются сортировкой по имени.
+var productOrderColumns = map[string]string{
+	"name":  "name",
+	"price": "price",
+}
+
+// Подстрока ищется без учета регистра, символы шаблона LIKE в ней экранируются.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// Снятые с продажи товары в каталог не попадают.
+func productFilterCondition(filter entity.ProductFilter) sq.And {
+	condition := sq.And{sq.Eq{"retired_at": nil}}
+
+	if filter.NameSubstring != "" {
+		condition = append(condition, sq.ILike{"name": "%" + likeEscaper.Replace(filter.NameSubstring) + "%"})
+	}
+
+	if filter.MinPrice != nil {
+		condition = append(condition, sq.GtOrEq{"price": *filter.MinPrice})
+	}
+
+	if filter.MaxPrice != nil {
+		condition = append(condition, sq.LtOrEq{"price": *filter.MaxPrice})
+	}
+
+	return condition
+}
+
+func (r *ProductRepo) GetProducts(ctx context.Context, filter entity.ProductFilter) ([]entity.Product, error) {
+	database, ok := ctx.Value(db.DBKey).(db.DB)
+	if !ok {
+		database = r.client.Replica()
+	}
+
+	column, ok := productOrderColumns[filter.OrderBy]
+	if !ok {
+		column = productOrderColumns["name"]
+	}
+
+	direction := " ASC"
+	if filter.Desc {
+		direction = " DESC"
+	}
+
+	queryRaw, args, err := database.QueryBuilder().
+		Select("id", "name", "price", "description", "stock").
+		From("products").
+		Where(productFilterCondition(filter)).
+		OrderBy(column+direction, "id"+direction).
+		Limit(uint64(filter.Limit)).
+		Offset(uint64(filter.Offset)).
+		ToSql()
+
+	if err != nil {
+		return nil, err
+	}
+
+	query := db.Query{Name: "GetProducts", QueryRaw: queryRaw}
+	rows, err := database.Query(ctx, query, args...)
+
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	product := entity.Product{}
+	products := []entity.Product{}
+
+	for rows.Next() {
+		if err = rows.Scan(&product.ID, &product.Name, &product.Price, &product.Description,
+			&product.Stock); err != nil {
+			return nil, err
+		}
+
+		products = append(products, product)
+	}
+
+	return products, rows.Err()
+}
+
+func (r *ProductRepo) CountProducts(ctx context.Context, filter entity.ProductFilter) (int, error) {
+	database, ok := ctx.Value(db.DBKey).(db.DB)
+	if !ok {
+		database = r.client.Replica()
+	}
+
+	queryRaw, args, err := database.QueryBuilder().
+		Select("COUNT(*)").
+		From("products").
+		Where(productFilterCondition(filter)).
+		ToSql()
+
+	if err != nil {
+		return 0, err
+	}
+
+	query := db.Query{Name: "CountProducts", QueryRaw: queryRaw}
+
+	var total int
+	if err = database.QueryRow(ctx, query, args...).Scan(&total); err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+func (r *ProductRepo) CreateProduct(ctx context.Context, input entity.CreateProductInput) (int, error) {
+	database, ok := ctx.Value(db.DBKey).(db.DB)
+	if !ok {
+		database = r.client.Primary()
+	}
+
+	queryRaw, args, err := database.QueryBuilder().
+		Insert("products").
+		Columns("name", "price", "description", "stock").
+		Values(input.Name, input.Price, input.Description, input.Stock).
+		Suffix("RETURNING id").
+		ToSql()
+
+	if err != nil {
+		return 0, err
+	}
+
+	query := db.Query{Name: "CreateProduct", QueryRaw: queryRaw}
+
+	var productID int
+	if err = database.QueryRow(ctx, query, args...).Scan(&productID); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode {
+			return 0, repoerrors.ErrAlreadyExists
+		}
+
+		return 0, err
+	}
+
+	return productID, nil
+}
+
+// Меняет только переданные поля товара, который еще продается. Иначе возвращает ErrNotFound.
+func (r *ProductRepo) UpdateProduct(ctx context.Context, productID int, input entity.UpdateProductInput) error {
+	database, ok := ctx.Value(db.DBKey).(db.DB)
+	if !ok {
+		database = r.client.Primary()
+	}
+
+	builder := database.QueryBuilder().
+		Update("products").
+		Where(sq.Eq{"id": productID, "retired_at": nil})
+
+	if input.Price != nil {
+		builder = builder.Set("price", *input.Price)
+	}
+
+	if input.Description != nil {
+		builder = builder.Set("description", *input.Description)
+	}
+
+	queryRaw, args, err := builder.ToSql()
+	if err != nil {
+		return err
+	}
+
+	query := db.Query{Name: "UpdateProduct", QueryRaw: queryRaw}
+
+	tag, err := database.Exec(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return repoerrors.ErrNotFound
+	}
+
+	return nil
+}
+
+// Снимает товар с продажи. Повторное снятие возвращает ErrNotFound.
+func (r *ProductRepo) RetireProduct(ctx context.Context, productID int) error {
+	database, ok := ctx.Value(db.DBKey).(db.DB)
+	if !ok {
+		database = r.client.Primary()
+	}
+
+	queryRaw, args, err := database.QueryBuilder().
+		Update("products").
+		Set("retired_at", sq.Expr("CURRENT_TIMESTAMP")).
+		Where(sq.Eq{"id": productID, "retired_at": nil}).
+		ToSql()
+
+	if err != nil {
+		return err
+	}
+
+	query := db.Query{Name: "RetireProduct", QueryRaw: queryRaw}
+
+	tag, err := database.Exec(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return repoerrors.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *ProductRepo) CreateProductAudit(ctx context.Context, entries []entity.ProductAudit) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	database, ok := ctx.Value(db.DBKey).(db.DB)
+	if !ok {
+		database = r.client.Primary()
+	}
+
+	builder := database.QueryBuilder().
+		Insert("product_audit").
+		Columns("product_id", "changed_by", "action", "field", "old_value", "new_value")
+
+	for _, entry := range entries {
+		builder = builder.Values(entry.ProductID, entry.ChangedBy, entry.Action, entry.Field, entry.OldValue,
+			entry.NewValue)
+	}
+
+	queryRaw, args, err := builder.ToSql()
+	if err != nil {
+		return err
+	}
+
+	query := db.Query{Name: "CreateProductAudit", QueryRaw: queryRaw}
+
+	_, err = database.Exec(ctx, query, args...)
+
+	return err
+}
+
+// Журнал изменений товара с заданным именем, включая снятые с продажи, от новых записей к старым.
+func (r *ProductRepo) GetProductAudit(ctx context.Context, name string) ([]entity.ProductAudit, error) {
+	database, ok := ctx.Value(db.DBKey).(db.DB)
+	if !ok {
+		database = r.client.Replica()
+	}
+
+	queryRaw, args, err := database.QueryBuilder().
+		Select("pa.id", "pa.product_id", "COALESCE(pa.changed_by, 0)", "u.username", "pa.action::text",
+			"pa.field", "pa.old_value", "pa.new_value", "pa.created_at").
+		From("product_audit pa").
+		Join("products p ON pa.product_id = p.id").
+		LeftJoin("users u ON pa.changed_by = u.id").
+		Where(sq.Eq{"p.name": name}).
+		OrderBy("pa.id DESC").
+		ToSql()
+
+	if err != nil {
+		return nil, err
+	}
+
+	query := db.Query{Name: "GetProductAudit", QueryRaw: queryRaw}
+	rows, err := database.Query(ctx, query, args...)
+
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entry := entity.ProductAudit{}
+	entries := []entity.ProductAudit{}
+
+	for rows.Next() {
+		if err = rows.Scan(&entry.ID, &entry.ProductID, &entry.ChangedBy, &entry.ChangedByUsername, &entry.Action,
+			&entry.Field, &entry.OldValue, &entry.NewValue, &entry.CreatedAt); err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+func (r *ProductRepo) GetProductVariant(ctx context.Context, productID int, name string) (
+	*entity.ProductVariant, error,
+) {
+	database, ok := ctx.Value(db.DBKey).(db.DB)
+	if !ok {
+		database = r.client.Replica()
+	}
+
+	queryRaw, args, err := database.QueryBuilder().
+		Select("id", "product_id", "name", "sku", "price", "stock").
+		From("product_variants").
+		Where(sq.Eq{"product_id": productID, "name": name}).
+		ToSql()
+
+	if err != nil {
+		return nil, err
+	}
+
+	query := db.Query{Name: "GetProductVariant", QueryRaw: queryRaw}
+
+	var variant entity.ProductVariant
+	if err = database.QueryRow(ctx, query, args...).Scan(&variant.ID, &variant.ProductID, &variant.Name,
+		&variant.SKU, &variant.Price, &variant.Stock); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repoerrors.ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	return &variant, nil
+}
+
+func (r *ProductRepo) GetProductVariants(ctx context.Context, productID int) ([]entity.ProductVariant, error) {
+	database, ok := ctx.Value(db.DBKey).(db.DB)
+	if !ok {
+		database = r.client.Replica()
+	}
+
+	queryRaw, args, err := database.QueryBuilder().
+		Select("id", "product_id", "name", "sku", "price", "stock").
+		From("product_variants").
+		Where(sq.Eq{"product_id": productID}).
+		OrderBy("id").
+		ToSql()
+
+	if err != nil {
+		return nil, err
+	}
+
+	query := db.Query{Name: "GetProductVariants", QueryRaw: queryRaw}
+	rows, err := database.Query(ctx, query, args...)
+
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	variant := entity.ProductVariant{}
+	variants := []entity.ProductVariant{}
+
+	for rows.Next() {
+		if err = rows.Scan(&variant.ID, &variant.ProductID, &variant.Name, &variant.SKU, &variant.Price,
+			&variant.Stock); err != nil {
+			return nil, err
+		}
+
+		variants = append(variants, variant)
+	}
+
+	return variants, rows.Err()
+}
+
+// Название варианта уникально в пределах товара, SKU — среди всех вариантов.
+// При повторе возвращает ErrAlreadyExists.
+func (r *ProductRepo) CreateProductVariant(ctx context.Context, input entity.CreateProductVariantInput) (int, error) {
+	database, ok := ctx.Value(db.DBKey).(db.DB)
+	if !ok {
+		database = r.client.Primary()
+	}
+
+	queryRaw, args, err := database.QueryBuilder().
+		Insert("product_variants").
+		Columns("product_id", "name", "sku", "price", "stock").
+		Values(input.ProductID, input.Name, input.SKU, input.Price, input.Stock).
+		Suffix("RETURNING id").
+		ToSql()
+
+	if err != nil {
+		return 0, err
+	}
+
+	query := db.Query{Name: "CreateProductVariant", QueryRaw: queryRaw}
+
+	var variantID int
+	if err = database.QueryRow(ctx, query, args...).Scan(&variantID); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode {
+			return 0, repoerrors.ErrAlreadyExists
+		}
+
+		return 0, err
+	}
+
+	return variantID, nil
+}
+
+// Списывает quantity единиц с собственного остатка варианта. Если их не хватает
+// или у варианта нет собственного остатка, возвращается ErrNotEnoughStock.
+func (r *ProductRepo) ReserveVariantStock(ctx context.Context, variantID int, quantity int) error {
+	database, ok := ctx.Value(db.DBKey).(db.DB)
+	if !ok {
+		database = r.client.Primary()
+	}
+
+	queryRaw, args, err := database.QueryBuilder().
+		Update("product_variants").
+		Set("stock", sq.Expr("stock - ?", quantity)).
+		Where(sq.Eq{"id": variantID}).
+		Where(sq.GtOrEq{"stock": quantity}).
+		ToSql()
+
+	if err != nil {
+		return err
+	}
+
+	query := db.Query{Name: "ReserveVariantStock", QueryRaw: queryRaw}
+
+	tag, err := database.Exec(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return repoerrors.ErrNotEnoughStock
+	}
+
+	return nil
+}
+
+// Возвращает quantity единиц на собственный остаток варианта и отдает новый остаток.
+// Если у варианта нет собственного остатка, возвращает ErrNotFound.
+func (r *ProductRepo) AddVariantStock(ctx context.Context, variantID int, quantity int) (int, error) {
+	database, ok := ctx.Value(db.DBKey).(db.DB)
+	if !ok {
+		database = r.client.Primary()
+	}
+
+	queryRaw, args, err := database.QueryBuilder().
+		Update("product_variants").
+		Set("stock", sq.Expr("stock + ?", quantity)).
+		Where(sq.Eq{"id": variantID}).
+		Where(sq.NotEq{"stock": nil}).
+		Suffix("RETURNING stock").
+		ToSql()
+
+	if err != nil {
+		return 0, err
+	}
+
+	query := db.Query{Name: "AddVariantStock", QueryRaw: queryRaw}
+
+	var stock int
+	if err = database.QueryRow(ctx, query, args...).Scan(&stock); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, repoerrors.ErrNotFound
+		}
+
+		return 0, err
+	}
+
+	return stock, nil
+}
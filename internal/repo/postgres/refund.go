@@ -0,0 +1,288 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/resueman/merch-store/internal/entity"
+	"github.com/resueman/merch-store/internal/repo/repoerrors"
+	"github.com/resueman/merch-store/pkg/db"
+)
+
+type RefundRepo struct {
+	client db.Client
+}
+
+func NewRefundRepo(client db.Client) *RefundRepo {
+	return &RefundRepo{client: client}
+}
+
+const (
+	refundStatusRequested = "requested"
+	refundStatusApproved  = "approved"
+)
+
+// Количество единиц покупки, на которые уже заявлены или одобрены возвраты.
+const refundedQuantityExpr = `COALESCE((SELECT SUM(r.quantity) FROM refunds r
+	WHERE r.purchase_operation_id = ops.id AND r.status IN ('requested', 'approved')), 0)`
+
+// Блокирует строку покупки до конца транзакции, чтобы параллельные заявки на возврат
+// не превысили купленное количество.
+func (r *RefundRepo) GetPurchaseForRefund(ctx context.Context, purchaseID int) (*entity.RefundablePurchase, error) {
+	database, ok := ctx.Value(db.DBKey).(db.DB)
+	if !ok {
+		database = r.client.Primary()
+	}
+
+	queryRaw, args, err := database.QueryBuilder().
+		Select("ops.id", "ops.customer_account_id", purchasedItemNameExpr, "ops.quantity", "ops.total_price",
+			refundedQuantityExpr+" AS refunded_quantity", "o.created_at").
+		From("purchase_operations ops").
+		Join("products p ON ops.product_id = p.id").
+		LeftJoin("product_variants pv ON ops.variant_id = pv.id").
+		Join("operations o ON ops.operation_id = o.id").
+		Where(sq.Eq{"ops.id": purchaseID}).
+		Suffix("FOR UPDATE OF ops").
+		ToSql()
+
+	if err != nil {
+		return nil, err
+	}
+
+	query := db.Query{Name: "GetPurchaseForRefund", QueryRaw: queryRaw}
+
+	var purchase entity.RefundablePurchase
+	if err = database.QueryRow(ctx, query, args...).Scan(&purchase.ID, &purchase.CustomerAccountID,
+		&purchase.ProductName, &purchase.Quantity, &purchase.TotalPrice, &purchase.RefundedQuantity,
+		&purchase.CreatedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repoerrors.ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	return &purchase, nil
+}
+
+// Возвращает покупки, сделанные не раньше since, у которых остались невозвращенные единицы.
+func (r *RefundRepo) GetRefundablePurchases(ctx context.Context, accountID int, since time.Time) (
+	[]entity.RefundablePurchase, error,
+) {
+	database, ok := ctx.Value(db.DBKey).(db.DB)
+	if !ok {
+		database = r.client.Replica()
+	}
+
+	queryRaw, args, err := database.QueryBuilder().
+		Select("ops.id", "ops.customer_account_id", purchasedItemNameExpr, "ops.quantity", "ops.total_price",
+			refundedQuantityExpr+" AS refunded_quantity", "o.created_at").
+		From("purchase_operations ops").
+		Join("products p ON ops.product_id = p.id").
+		LeftJoin("product_variants pv ON ops.variant_id = pv.id").
+		Join("operations o ON ops.operation_id = o.id").
+		Where(sq.Eq{"ops.customer_account_id": accountID}).
+		Where(sq.GtOrEq{"o.created_at": since}).
+		Where("ops.quantity > "+refundedQuantityExpr).
+		OrderBy("o.created_at DESC", "ops.id DESC").
+		ToSql()
+
+	if err != nil {
+		return nil, err
+	}
+
+	query := db.Query{Name: "GetRefundablePurchases", QueryRaw: queryRaw}
+	rows, err := database.Query(ctx, query, args...)
+
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	purchase := entity.RefundablePurchase{}
+	purchases := []entity.RefundablePurchase{}
+
+	for rows.Next() {
+		if err = rows.Scan(&purchase.ID, &purchase.CustomerAccountID, &purchase.ProductName, &purchase.Quantity,
+			&purchase.TotalPrice, &purchase.RefundedQuantity, &purchase.CreatedAt); err != nil {
+			return nil, err
+		}
+
+		purchases = append(purchases, purchase)
+	}
+
+	return purchases, rows.Err()
+}
+
+func (r *RefundRepo) CreateRefund(ctx context.Context, input *entity.CreateRefundInput) (int, error) {
+	database, ok := ctx.Value(db.DBKey).(db.DB)
+	if !ok {
+		database = r.client.Primary()
+	}
+
+	queryRaw, args, err := database.QueryBuilder().
+		Insert("refunds").
+		Columns("purchase_operation_id", "customer_account_id", "quantity", "amount").
+		Values(input.PurchaseID, input.CustomerAccountID, input.Quantity, input.Amount).
+		Suffix("RETURNING id").
+		ToSql()
+
+	if err != nil {
+		return 0, err
+	}
+
+	query := db.Query{Name: "CreateRefund", QueryRaw: queryRaw}
+
+	var refundID int
+	if err = database.QueryRow(ctx, query, args...).Scan(&refundID); err != nil {
+		return 0, err
+	}
+
+	return refundID, nil
+}
+
+func (r *RefundRepo) GetRefundByID(ctx context.Context, refundID int) (*entity.Refund, error) {
+	database, ok := ctx.Value(db.DBKey).(db.DB)
+	if !ok {
+		database = r.client.Primary()
+	}
+
+	queryRaw, args, err := selectRefunds(database).
+		Where(sq.Eq{"rf.id": refundID}).
+		ToSql()
+
+	if err != nil {
+		return nil, err
+	}
+
+	query := db.Query{Name: "GetRefundByID", QueryRaw: queryRaw}
+
+	var refund entity.Refund
+	if err = scanRefund(database.QueryRow(ctx, query, args...), &refund); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repoerrors.ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	return &refund, nil
+}
+
+func (r *RefundRepo) GetRefundsByAccountID(ctx context.Context, accountID int) ([]entity.Refund, error) {
+	database, ok := ctx.Value(db.DBKey).(db.DB)
+	if !ok {
+		database = r.client.Replica()
+	}
+
+	queryRaw, args, err := selectRefunds(database).
+		Where(sq.Eq{"rf.customer_account_id": accountID}).
+		OrderBy("rf.id DESC").
+		ToSql()
+
+	if err != nil {
+		return nil, err
+	}
+
+	query := db.Query{Name: "GetRefundsByAccountID", QueryRaw: queryRaw}
+
+	return r.queryRefunds(ctx, database, query, args...)
+}
+
+func (r *RefundRepo) GetRefundsByStatus(ctx context.Context, status string) ([]entity.Refund, error) {
+	database, ok := ctx.Value(db.DBKey).(db.DB)
+	if !ok {
+		database = r.client.Replica()
+	}
+
+	queryRaw, args, err := selectRefunds(database).
+		Where(sq.Eq{"rf.status": status}).
+		OrderBy("rf.id").
+		ToSql()
+
+	if err != nil {
+		return nil, err
+	}
+
+	query := db.Query{Name: "GetRefundsByStatus", QueryRaw: queryRaw}
+
+	return r.queryRefunds(ctx, database, query, args...)
+}
+
+// Меняет статус только у заявки, по которой еще нет решения. Иначе возвращает ErrNotFound.
+func (r *RefundRepo) DecideRefund(ctx context.Context, refundID int, status string, decidedBy int) error {
+	database, ok := ctx.Value(db.DBKey).(db.DB)
+	if !ok {
+		database = r.client.Primary()
+	}
+
+	queryRaw, args, err := database.QueryBuilder().
+		Update("refunds").
+		Set("status", status).
+		Set("decided_at", sq.Expr("CURRENT_TIMESTAMP")).
+		Set("decided_by", decidedBy).
+		Where(sq.Eq{"id": refundID, "status": refundStatusRequested}).
+		ToSql()
+
+	if err != nil {
+		return err
+	}
+
+	query := db.Query{Name: "DecideRefund", QueryRaw: queryRaw}
+
+	tag, err := database.Exec(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return repoerrors.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *RefundRepo) queryRefunds(ctx context.Context, database db.DB, query db.Query, args ...interface{}) (
+	[]entity.Refund, error,
+) {
+	rows, err := database.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	refund := entity.Refund{}
+	refunds := []entity.Refund{}
+
+	for rows.Next() {
+		if err = scanRefund(rows, &refund); err != nil {
+			return nil, err
+		}
+
+		refunds = append(refunds, refund)
+	}
+
+	return refunds, rows.Err()
+}
+
+func selectRefunds(database db.DB) sq.SelectBuilder {
+	return database.QueryBuilder().
+		Select("rf.id", "rf.purchase_operation_id", "rf.customer_account_id", "u.username", "ops.product_id",
+			"ops.variant_id", "pv.stock IS NOT NULL", purchasedItemNameExpr, "rf.quantity", "rf.amount",
+			"rf.status::text", "rf.created_at", "rf.decided_at").
+		From("refunds rf").
+		Join("purchase_operations ops ON rf.purchase_operation_id = ops.id").
+		Join("products p ON ops.product_id = p.id").
+		LeftJoin("product_variants pv ON ops.variant_id = pv.id").
+		Join("accounts a ON rf.customer_account_id = a.id").
+		Join("users u ON a.user_id = u.id")
+}
+
+func scanRefund(row pgx.Row, refund *entity.Refund) error {
+	return row.Scan(&refund.ID, &refund.PurchaseID, &refund.CustomerAccountID, &refund.CustomerUsername,
+		&refund.ProductID, &refund.VariantID, &refund.VariantHasStock, &refund.ProductName, &refund.Quantity,
+		&refund.Amount, &refund.Status, &refund.CreatedAt, &refund.DecidedAt)
+}
@@ -0,0 +1,150 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/resueman/merch-store/internal/entity"
+	"github.com/resueman/merch-store/internal/repo/repoerrors"
+	"github.com/resueman/merch-store/pkg/db"
+)
+
+type RefreshTokenRepo struct {
+	client db.Client
+}
+
+func NewRefreshTokenRepo(client db.Client) *RefreshTokenRepo {
+	return &RefreshTokenRepo{client: client}
+}
+
+func (r *RefreshTokenRepo) CreateRefreshToken(ctx context.Context, input *entity.CreateRefreshTokenInput) error {
+	database, ok := ctx.Value(db.DBKey).(db.DB)
+	if !ok {
+		database = r.client.Primary()
+	}
+
+	queryRaw, args, err := database.QueryBuilder().
+		Insert("refresh_tokens").
+		Columns("user_id", "family_id", "token_hash", "expires_at", "scopes").
+		Values(input.UserID, input.FamilyID, input.TokenHash, input.ExpiresAt, input.Scopes).
+		ToSql()
+
+	if err != nil {
+		return err
+	}
+
+	query := db.Query{Name: "CreateRefreshToken", QueryRaw: queryRaw}
+	if _, err = database.Exec(ctx, query, args...); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Блокирует строку токена до конца транзакции, чтобы один и тот же
+// refresh-токен нельзя было обменять дважды параллельными запросами.
+func (r *RefreshTokenRepo) GetRefreshTokenByHash(ctx context.Context, tokenHash string) (*entity.RefreshToken, error) {
+	database, ok := ctx.Value(db.DBKey).(db.DB)
+	if !ok {
+		database = r.client.Primary()
+	}
+
+	queryRaw, args, err := database.QueryBuilder().
+		Select("id", "user_id", "family_id", "token_hash", "expires_at", "used_at", "revoked_at", "scopes").
+		From("refresh_tokens").
+		Where(sq.Eq{"token_hash": tokenHash}).
+		Suffix("FOR UPDATE").
+		ToSql()
+
+	if err != nil {
+		return nil, err
+	}
+
+	query := db.Query{Name: "GetRefreshTokenByHash", QueryRaw: queryRaw}
+
+	var token entity.RefreshToken
+	if err = database.QueryRow(ctx, query, args...).Scan(&token.ID, &token.UserID, &token.FamilyID,
+		&token.TokenHash, &token.ExpiresAt, &token.UsedAt, &token.RevokedAt, &token.Scopes); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repoerrors.ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+func (r *RefreshTokenRepo) MarkRefreshTokenUsed(ctx context.Context, tokenID int) error {
+	database, ok := ctx.Value(db.DBKey).(db.DB)
+	if !ok {
+		database = r.client.Primary()
+	}
+
+	queryRaw, args, err := database.QueryBuilder().
+		Update("refresh_tokens").
+		Set("used_at", sq.Expr("CURRENT_TIMESTAMP")).
+		Where(sq.Eq{"id": tokenID}).
+		ToSql()
+
+	if err != nil {
+		return err
+	}
+
+	query := db.Query{Name: "MarkRefreshTokenUsed", QueryRaw: queryRaw}
+	if _, err = database.Exec(ctx, query, args...); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *RefreshTokenRepo) RevokeRefreshTokenFamily(ctx context.Context, familyID string) error {
+	database, ok := ctx.Value(db.DBKey).(db.DB)
+	if !ok {
+		database = r.client.Primary()
+	}
+
+	queryRaw, args, err := database.QueryBuilder().
+		Update("refresh_tokens").
+		Set("revoked_at", sq.Expr("CURRENT_TIMESTAMP")).
+		Where(sq.Eq{"family_id": familyID, "revoked_at": nil}).
+		ToSql()
+
+	if err != nil {
+		return err
+	}
+
+	query := db.Query{Name: "RevokeRefreshTokenFamily", QueryRaw: queryRaw}
+	if _, err = database.Exec(ctx, query, args...); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *RefreshTokenRepo) RevokeUserRefreshTokens(ctx context.Context, userID int) error {
+	database, ok := ctx.Value(db.DBKey).(db.DB)
+	if !ok {
+		database = r.client.Primary()
+	}
+
+	queryRaw, args, err := database.QueryBuilder().
+		Update("refresh_tokens").
+		Set("revoked_at", sq.Expr("CURRENT_TIMESTAMP")).
+		Where(sq.Eq{"user_id": userID, "revoked_at": nil}).
+		ToSql()
+
+	if err != nil {
+		return err
+	}
+
+	query := db.Query{Name: "RevokeUserRefreshTokens", QueryRaw: queryRaw}
+	if _, err = database.Exec(ctx, query, args...); err != nil {
+		return err
+	}
+
+	return nil
+}
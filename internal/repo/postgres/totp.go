@@ -0,0 +1,287 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"github.com/resueman/merch-store/internal/entity"
+	"github.com/resueman/merch-store/internal/repo/repoerrors"
+	"github.com/resueman/merch-store/pkg/db"
+)
+
+type TOTPRepo struct {
+	client db.Client
+}
+
+func NewTOTPRepo(client db.Client) *TOTPRepo {
+	return &TOTPRepo{client: client}
+}
+
+// Читается с primary: подтверждение второго фактора следует сразу за подключением,
+// и отставание реплики приводило бы к ложным ошибкам.
+func (r *TOTPRepo) GetTOTP(ctx context.Context, userID int) (*entity.TOTP, error) {
+	database, ok := ctx.Value(db.DBKey).(db.DB)
+	if !ok {
+		database = r.client.Primary()
+	}
+
+	queryRaw, args, err := database.QueryBuilder().
+		Select("user_id", "secret", "threshold", "last_used_step", "created_at", "enabled_at").
+		From("user_totp").
+		Where(sq.Eq{"user_id": userID}).
+		ToSql()
+
+	if err != nil {
+		return nil, err
+	}
+
+	query := db.Query{Name: "GetTOTP", QueryRaw: queryRaw}
+
+	var totp entity.TOTP
+	if err = database.QueryRow(ctx, query, args...).Scan(&totp.UserID, &totp.Secret, &totp.Threshold,
+		&totp.LastUsedStep, &totp.CreatedAt, &totp.EnabledAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repoerrors.ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	return &totp, nil
+}
+
+// Сохраняет секрет неподтвержденного подключения, заменяя предыдущий неподтвержденный.
+// Если второй фактор уже включен, возвращает ErrAlreadyExists.
+func (r *TOTPRepo) SaveTOTPSecret(ctx context.Context, userID int, secret string) error {
+	database, ok := ctx.Value(db.DBKey).(db.DB)
+	if !ok {
+		database = r.client.Primary()
+	}
+
+	queryRaw, args, err := database.QueryBuilder().
+		Insert("user_totp").
+		Columns("user_id", "secret").
+		Values(userID, secret).
+		Suffix(`ON CONFLICT (user_id) DO UPDATE SET
+			secret = EXCLUDED.secret, last_used_step = 0, created_at = CURRENT_TIMESTAMP
+			WHERE user_totp.enabled_at IS NULL`).
+		ToSql()
+
+	if err != nil {
+		return err
+	}
+
+	query := db.Query{Name: "SaveTOTPSecret", QueryRaw: queryRaw}
+
+	tag, err := database.Exec(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return repoerrors.ErrAlreadyExists
+	}
+
+	return nil
+}
+
+func (r *TOTPRepo) EnableTOTP(ctx context.Context, userID int, threshold int) error {
+	database, ok := ctx.Value(db.DBKey).(db.DB)
+	if !ok {
+		database = r.client.Primary()
+	}
+
+	queryRaw, args, err := database.QueryBuilder().
+		Update("user_totp").
+		Set("enabled_at", sq.Expr("CURRENT_TIMESTAMP")).
+		Set("threshold", threshold).
+		Where(sq.Eq{"user_id": userID, "enabled_at": nil}).
+		ToSql()
+
+	if err != nil {
+		return err
+	}
+
+	query := db.Query{Name: "EnableTOTP", QueryRaw: queryRaw}
+
+	tag, err := database.Exec(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return repoerrors.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *TOTPRepo) SetTOTPThreshold(ctx context.Context, userID int, threshold int) error {
+	database, ok := ctx.Value(db.DBKey).(db.DB)
+	if !ok {
+		database = r.client.Primary()
+	}
+
+	queryRaw, args, err := database.QueryBuilder().
+		Update("user_totp").
+		Set("threshold", threshold).
+		Where(sq.Eq{"user_id": userID}).
+		Where(sq.NotEq{"enabled_at": nil}).
+		ToSql()
+
+	if err != nil {
+		return err
+	}
+
+	query := db.Query{Name: "SetTOTPThreshold", QueryRaw: queryRaw}
+
+	tag, err := database.Exec(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return repoerrors.ErrNotFound
+	}
+
+	return nil
+}
+
+// Запоминает интервал принятого кода. Если код этого или более позднего интервала уже принимался,
+// возвращает ErrNotFound: так один код нельзя использовать дважды даже при параллельных запросах.
+func (r *TOTPRepo) UseTOTPStep(ctx context.Context, userID int, step int64) error {
+	database, ok := ctx.Value(db.DBKey).(db.DB)
+	if !ok {
+		database = r.client.Primary()
+	}
+
+	queryRaw, args, err := database.QueryBuilder().
+		Update("user_totp").
+		Set("last_used_step", step).
+		Where(sq.Eq{"user_id": userID}).
+		Where(sq.Lt{"last_used_step": step}).
+		ToSql()
+
+	if err != nil {
+		return err
+	}
+
+	query := db.Query{Name: "UseTOTPStep", QueryRaw: queryRaw}
+
+	tag, err := database.Exec(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return repoerrors.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *TOTPRepo) DeleteTOTP(ctx context.Context, userID int) error {
+	database, ok := ctx.Value(db.DBKey).(db.DB)
+	if !ok {
+		database = r.client.Primary()
+	}
+
+	queryRaw, args, err := database.QueryBuilder().
+		Delete("user_totp").
+		Where(sq.Eq{"user_id": userID}).
+		ToSql()
+
+	if err != nil {
+		return err
+	}
+
+	query := db.Query{Name: "DeleteTOTP", QueryRaw: queryRaw}
+	if _, err = database.Exec(ctx, query, args...); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *TOTPRepo) CreateRecoveryCodes(ctx context.Context, userID int, codeHashes []string) error {
+	database, ok := ctx.Value(db.DBKey).(db.DB)
+	if !ok {
+		database = r.client.Primary()
+	}
+
+	builder := database.QueryBuilder().
+		Insert("totp_recovery_codes").
+		Columns("user_id", "code_hash")
+
+	for _, hash := range codeHashes {
+		builder = builder.Values(userID, hash)
+	}
+
+	queryRaw, args, err := builder.ToSql()
+	if err != nil {
+		return err
+	}
+
+	query := db.Query{Name: "CreateRecoveryCodes", QueryRaw: queryRaw}
+	if _, err = database.Exec(ctx, query, args...); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *TOTPRepo) DeleteRecoveryCodes(ctx context.Context, userID int) error {
+	database, ok := ctx.Value(db.DBKey).(db.DB)
+	if !ok {
+		database = r.client.Primary()
+	}
+
+	queryRaw, args, err := database.QueryBuilder().
+		Delete("totp_recovery_codes").
+		Where(sq.Eq{"user_id": userID}).
+		ToSql()
+
+	if err != nil {
+		return err
+	}
+
+	query := db.Query{Name: "DeleteRecoveryCodes", QueryRaw: queryRaw}
+	if _, err = database.Exec(ctx, query, args...); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Погашает код восстановления. Если такого неиспользованного кода нет, возвращает ErrNotFound.
+func (r *TOTPRepo) UseRecoveryCode(ctx context.Context, userID int, codeHash string) error {
+	database, ok := ctx.Value(db.DBKey).(db.DB)
+	if !ok {
+		database = r.client.Primary()
+	}
+
+	queryRaw, args, err := database.QueryBuilder().
+		Update("totp_recovery_codes").
+		Set("used_at", sq.Expr("CURRENT_TIMESTAMP")).
+		Where(sq.Eq{"user_id": userID, "code_hash": codeHash, "used_at": nil}).
+		ToSql()
+
+	if err != nil {
+		return err
+	}
+
+	query := db.Query{Name: "UseRecoveryCode", QueryRaw: queryRaw}
+
+	tag, err := database.Exec(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return repoerrors.ErrNotFound
+	}
+
+	return nil
+}
@@ -7,9 +7,17 @@ import (
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/resueman/merch-store/internal/entity"
 	"github.com/resueman/merch-store/internal/repo/repoerrors"
 	"github.com/resueman/merch-store/pkg/db"
+	"github.com/resueman/merch-store/pkg/username"
+)
+
+const (
+	uniqueViolationCode = "23505"
+	// Флаг второго фактора читается вместе с пользователем, чтобы вход без 2FA не требовал лишнего запроса.
+	totpEnabledColumn = "EXISTS (SELECT 1 FROM user_totp t WHERE t.user_id = users.id AND t.enabled_at IS NOT NULL)"
 )
 
 type UserRepo struct {
@@ -20,16 +28,18 @@ func NewUserRepo(client db.Client) *UserRepo {
 	return &UserRepo{client: client}
 }
 
-func (r *UserRepo) GetUserByUsername(ctx context.Context, username string) (*entity.User, error) {
+// Имя сравнивается без учета регистра и формы записи, см. username.Normalize.
+func (r *UserRepo) GetUserByUsername(ctx context.Context, name string) (*entity.User, error) {
 	database, ok := ctx.Value(db.DBKey).(db.DB)
 	if !ok {
 		database = r.client.Replica()
 	}
 
 	queryRaw, args, err := database.QueryBuilder().
-		Select("id", "username", "password").
+		Select("id", "username", "password", "token_version", "role", "is_service", "deactivated_at",
+			totpEnabledColumn).
 		From("users").
-		Where(sq.Eq{"username": username}).
+		Where(sq.Eq{"username_normalized": username.Normalize(name)}).
 		ToSql()
 
 	if err != nil {
@@ -40,7 +50,41 @@ func (r *UserRepo) GetUserByUsername(ctx context.Context, username string) (*ent
 	row := database.QueryRow(ctx, query, args...)
 
 	var user entity.User
-	if err := row.Scan(&user.ID, &user.Username, &user.Hash); err != nil {
+	if err := row.Scan(&user.ID, &user.Username, &user.Hash, &user.TokenVersion,
+		&user.Role, &user.IsService, &user.DeactivatedAt, &user.TOTPEnabled); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, repoerrors.ErrNotFound
+		}
+
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (r *UserRepo) GetUserByID(ctx context.Context, userID int) (*entity.User, error) {
+	database, ok := ctx.Value(db.DBKey).(db.DB)
+	if !ok {
+		database = r.client.Replica()
+	}
+
+	queryRaw, args, err := database.QueryBuilder().
+		Select("id", "username", "password", "token_version", "role", "is_service", "deactivated_at",
+			totpEnabledColumn).
+		From("users").
+		Where(sq.Eq{"id": userID}).
+		ToSql()
+
+	if err != nil {
+		return nil, err
+	}
+
+	query := db.Query{QueryRaw: queryRaw, Name: "GetUserByID"}
+	row := database.QueryRow(ctx, query, args...)
+
+	var user entity.User
+	if err := row.Scan(&user.ID, &user.Username, &user.Hash, &user.TokenVersion,
+		&user.Role, &user.IsService, &user.DeactivatedAt, &user.TOTPEnabled); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, repoerrors.ErrNotFound
 		}
@@ -51,6 +95,187 @@ func (r *UserRepo) GetUserByUsername(ctx context.Context, username string) (*ent
 	return &user, nil
 }
 
+func (r *UserRepo) IncrementTokenVersion(ctx context.Context, userID int) (int, error) {
+	database, ok := ctx.Value(db.DBKey).(db.DB)
+	if !ok {
+		database = r.client.Primary()
+	}
+
+	queryRaw, args, err := database.QueryBuilder().
+		Update("users").
+		Set("token_version", sq.Expr("token_version + 1")).
+		Set("tokens_revoked_at", sq.Expr("CURRENT_TIMESTAMP")).
+		Where(sq.Eq{"id": userID}).
+		Suffix("RETURNING token_version").
+		ToSql()
+
+	if err != nil {
+		return 0, err
+	}
+
+	query := db.Query{QueryRaw: queryRaw, Name: "IncrementTokenVersion"}
+
+	var version int
+	if err = database.QueryRow(ctx, query, args...).Scan(&version); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, repoerrors.ErrNotFound
+		}
+
+		return 0, err
+	}
+
+	return version, nil
+}
+
+func (r *UserRepo) SetUserRole(ctx context.Context, userID int, role string) error {
+	database, ok := ctx.Value(db.DBKey).(db.DB)
+	if !ok {
+		database = r.client.Primary()
+	}
+
+	queryRaw, args, err := database.QueryBuilder().
+		Update("users").
+		Set("role", role).
+		Where(sq.Eq{"id": userID}).
+		ToSql()
+
+	if err != nil {
+		return err
+	}
+
+	query := db.Query{QueryRaw: queryRaw, Name: "SetUserRole"}
+
+	tag, err := database.Exec(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return repoerrors.ErrNotFound
+	}
+
+	return nil
+}
+
+// Повторная деактивация не меняет время первой.
+func (r *UserRepo) DeactivateUser(ctx context.Context, userID int) error {
+	database, ok := ctx.Value(db.DBKey).(db.DB)
+	if !ok {
+		database = r.client.Primary()
+	}
+
+	queryRaw, args, err := database.QueryBuilder().
+		Update("users").
+		Set("deactivated_at", sq.Expr("COALESCE(deactivated_at, CURRENT_TIMESTAMP)")).
+		Where(sq.Eq{"id": userID}).
+		ToSql()
+
+	if err != nil {
+		return err
+	}
+
+	query := db.Query{QueryRaw: queryRaw, Name: "DeactivateUser"}
+
+	tag, err := database.Exec(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return repoerrors.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *UserRepo) ReactivateUser(ctx context.Context, userID int) error {
+	database, ok := ctx.Value(db.DBKey).(db.DB)
+	if !ok {
+		database = r.client.Primary()
+	}
+
+	queryRaw, args, err := database.QueryBuilder().
+		Update("users").
+		Set("deactivated_at", nil).
+		Where(sq.Eq{"id": userID}).
+		ToSql()
+
+	if err != nil {
+		return err
+	}
+
+	query := db.Query{QueryRaw: queryRaw, Name: "ReactivateUser"}
+
+	tag, err := database.Exec(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return repoerrors.ErrNotFound
+	}
+
+	return nil
+}
+
+func (r *UserRepo) HasUsersWithRole(ctx context.Context, role string) (bool, error) {
+	database, ok := ctx.Value(db.DBKey).(db.DB)
+	if !ok {
+		database = r.client.Primary()
+	}
+
+	queryRaw, args, err := database.QueryBuilder().
+		Select("1").
+		Prefix("SELECT EXISTS (").
+		From("users").
+		Where(sq.Eq{"role": role}).
+		Suffix(")").
+		ToSql()
+
+	if err != nil {
+		return false, err
+	}
+
+	query := db.Query{QueryRaw: queryRaw, Name: "HasUsersWithRole"}
+
+	var exists bool
+	if err = database.QueryRow(ctx, query, args...).Scan(&exists); err != nil {
+		return false, err
+	}
+
+	return exists, nil
+}
+
+func (r *UserRepo) UpdatePasswordHash(ctx context.Context, input *entity.UpdatePasswordHashInput) error {
+	database, ok := ctx.Value(db.DBKey).(db.DB)
+	if !ok {
+		database = r.client.Primary()
+	}
+
+	queryRaw, args, err := database.QueryBuilder().
+		Update("users").
+		Set("password", input.NewHash).
+		Where(sq.Eq{"id": input.UserID, "password": input.OldHash}).
+		ToSql()
+
+	if err != nil {
+		return err
+	}
+
+	query := db.Query{QueryRaw: queryRaw, Name: "UpdatePasswordHash"}
+
+	tag, err := database.Exec(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	if tag.RowsAffected() == 0 {
+		return repoerrors.ErrNotFound
+	}
+
+	return nil
+}
+
 func (r *UserRepo) CreateUser(ctx context.Context, user *entity.CreateUserInput) (int, error) {
 	database, ok := ctx.Value(db.DBKey).(db.DB)
 	if !ok {
@@ -83,8 +308,8 @@ func (r *UserRepo) CreateUser(ctx context.Context, user *entity.CreateUserInput)
 
 	createUserRaw, args, err := database.QueryBuilder().
 		Insert("users").
-		Columns("username", "password").
-		Values(user.Username, user.Hash).
+		Columns("username", "username_normalized", "password", "is_service").
+		Values(user.Username, username.Normalize(user.Username), user.Hash, user.IsService).
 		Suffix("RETURNING id").
 		ToSql()
 
@@ -95,7 +320,12 @@ func (r *UserRepo) CreateUser(ctx context.Context, user *entity.CreateUserInput)
 	row := tx.QueryRow(ctx, createUserRaw, args...)
 
 	var userID int
-	if err := row.Scan(&userID); err != nil {
+	if err = row.Scan(&userID); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode {
+			return 0, repoerrors.ErrAlreadyExists
+		}
+
 		return 0, err
 	}
 
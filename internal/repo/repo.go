@@ -2,6 +2,7 @@ package repo
 
 import (
 	"context"
+	"time"
 
 	"github.com/resueman/merch-store/internal/entity"
 	"github.com/resueman/merch-store/internal/repo/postgres"
@@ -10,7 +11,62 @@ import (
 
 type User interface {
 	GetUserByUsername(ctx context.Context, username string) (*entity.User, error)
+	GetUserByID(ctx context.Context, userID int) (*entity.User, error)
 	CreateUser(ctx context.Context, user *entity.CreateUserInput) (int, error)
+	IncrementTokenVersion(ctx context.Context, userID int) (int, error)
+	UpdatePasswordHash(ctx context.Context, input *entity.UpdatePasswordHashInput) error
+	SetUserRole(ctx context.Context, userID int, role string) error
+	DeactivateUser(ctx context.Context, userID int) error
+	ReactivateUser(ctx context.Context, userID int) error
+	HasUsersWithRole(ctx context.Context, role string) (bool, error)
+}
+
+type RefreshToken interface {
+	CreateRefreshToken(ctx context.Context, input *entity.CreateRefreshTokenInput) error
+	GetRefreshTokenByHash(ctx context.Context, tokenHash string) (*entity.RefreshToken, error)
+	MarkRefreshTokenUsed(ctx context.Context, tokenID int) error
+	RevokeRefreshTokenFamily(ctx context.Context, familyID string) error
+	RevokeUserRefreshTokens(ctx context.Context, userID int) error
+}
+
+type Denylist interface {
+	RevokeToken(ctx context.Context, input *entity.RevokedToken) error
+	GetRevokedTokens(ctx context.Context) ([]entity.RevokedToken, error)
+	GetTokenVersions(ctx context.Context, revokedSince time.Time) ([]entity.TokenVersion, error)
+}
+
+type LoginAttempt interface {
+	GetLoginLockedUntil(ctx context.Context, keys []entity.LoginAttemptKey) (*time.Time, error)
+	RecordLoginFailure(ctx context.Context, input *entity.LoginFailure) (int, error)
+	LockLogin(ctx context.Context, key entity.LoginAttemptKey, until time.Time) error
+	ResetLoginFailures(ctx context.Context, key entity.LoginAttemptKey) error
+}
+
+type PasswordReset interface {
+	CreatePasswordResetToken(ctx context.Context, input *entity.CreatePasswordResetTokenInput) error
+	GetPasswordResetTokenByHash(ctx context.Context, tokenHash string) (*entity.PasswordResetToken, error)
+	MarkPasswordResetTokenUsed(ctx context.Context, tokenID int) error
+	DeleteUnusedPasswordResetTokens(ctx context.Context, userID int) error
+}
+
+type APIKey interface {
+	CreateAPIKey(ctx context.Context, input *entity.CreateAPIKeyInput) (int, error)
+	GetAPIKeyByHash(ctx context.Context, keyHash string) (*entity.APIKey, error)
+	GetAPIKeysByUserID(ctx context.Context, userID int) ([]entity.APIKey, error)
+	RevokeAPIKey(ctx context.Context, keyID int) error
+	TouchAPIKey(ctx context.Context, keyID int, usedAt time.Time) error
+}
+
+type TOTP interface {
+	GetTOTP(ctx context.Context, userID int) (*entity.TOTP, error)
+	SaveTOTPSecret(ctx context.Context, userID int, secret string) error
+	EnableTOTP(ctx context.Context, userID int, threshold int) error
+	SetTOTPThreshold(ctx context.Context, userID int, threshold int) error
+	UseTOTPStep(ctx context.Context, userID int, step int64) error
+	DeleteTOTP(ctx context.Context, userID int) error
+	CreateRecoveryCodes(ctx context.Context, userID int, codeHashes []string) error
+	DeleteRecoveryCodes(ctx context.Context, userID int) error
+	UseRecoveryCode(ctx context.Context, userID int, codeHash string) error
 }
 
 type Account interface {
@@ -27,24 +83,87 @@ type Operation interface {
 	ExecTransferOperation(ctx context.Context, input entity.TransferOperation) error    // +
 	GetOutgoingTransfers(ctx context.Context, accountID int) ([]entity.Transfer, error) // +
 	GetIncomingTransfers(ctx context.Context, accountID int) ([]entity.Transfer, error) // +
+	ExecRefundOperation(ctx context.Context, input entity.RefundOperation) error
+	GetHistory(ctx context.Context, filter entity.HistoryFilter) ([]entity.HistoryEntry, error)
+	GetOperationByID(ctx context.Context, operationID int) (*entity.OperationDetails, error)
+	GetHistoryMark(ctx context.Context, accountID int) (*entity.HistoryMark, error)
 }
 
 type Product interface {
 	GetProductByName(ctx context.Context, name string) (*entity.Product, error) // +
+	ReserveStock(ctx context.Context, productID int, quantity int) error
+	AddStock(ctx context.Context, productID int, quantity int) (int, error)
+	CreateRestock(ctx context.Context, restock entity.Restock) error
+	GetLowStockProducts(ctx context.Context, threshold int) ([]entity.LowStockProduct, error)
+	GetProducts(ctx context.Context, filter entity.ProductFilter) ([]entity.Product, error)
+	CountProducts(ctx context.Context, filter entity.ProductFilter) (int, error)
+	CreateProduct(ctx context.Context, input entity.CreateProductInput) (int, error)
+	UpdateProduct(ctx context.Context, productID int, input entity.UpdateProductInput) error
+	RetireProduct(ctx context.Context, productID int) error
+	CreateProductAudit(ctx context.Context, entries []entity.ProductAudit) error
+	GetProductAudit(ctx context.Context, name string) ([]entity.ProductAudit, error)
+	GetProductVariant(ctx context.Context, productID int, name string) (*entity.ProductVariant, error)
+	GetProductVariants(ctx context.Context, productID int) ([]entity.ProductVariant, error)
+	CreateProductVariant(ctx context.Context, input entity.CreateProductVariantInput) (int, error)
+	ReserveVariantStock(ctx context.Context, variantID int, quantity int) error
+	AddVariantStock(ctx context.Context, variantID int, quantity int) (int, error)
+}
+
+type Cart interface {
+	AddCartItem(ctx context.Context, userID int, productID int, variantID *int, quantity int) (int, error)
+	RemoveCartItem(ctx context.Context, userID int, productName string, variantName string) error
+	RemoveCartItems(ctx context.Context, userID int, items []entity.CartItem) error
+	GetCartItems(ctx context.Context, userID int) ([]entity.CartItem, error)
+}
+
+type Refund interface {
+	GetPurchaseForRefund(ctx context.Context, purchaseID int) (*entity.RefundablePurchase, error)
+	GetRefundablePurchases(ctx context.Context, accountID int, since time.Time) ([]entity.RefundablePurchase, error)
+	CreateRefund(ctx context.Context, input *entity.CreateRefundInput) (int, error)
+	GetRefundByID(ctx context.Context, refundID int) (*entity.Refund, error)
+	GetRefundsByAccountID(ctx context.Context, accountID int) ([]entity.Refund, error)
+	GetRefundsByStatus(ctx context.Context, status string) ([]entity.Refund, error)
+	DecideRefund(ctx context.Context, refundID int, status string, decidedBy int) error
+}
+
+type Idempotency interface {
+	CreateIdempotencyKey(ctx context.Context, key entity.IdempotencyKey) error
+	GetIdempotencyKey(ctx context.Context, userID int, key string) (*entity.IdempotencyKey, error)
+	SaveIdempotencyResponse(ctx context.Context, key entity.IdempotencyKey) error
+	DeleteIdempotencyKey(ctx context.Context, userID int, key string) error
+	DeleteExpiredIdempotencyKeys(ctx context.Context, userID int, now time.Time) error
 }
 
 type Repositories struct {
 	User
+	RefreshToken
+	Denylist
+	LoginAttempt
+	PasswordReset
+	APIKey
+	TOTP
 	Account
 	Operation
 	Product
+	Cart
+	Refund
+	Idempotency
 }
 
 func NewRepositories(pg db.Client) *Repositories {
 	return &Repositories{
-		User:      postgres.NewUserRepo(pg),
-		Account:   postgres.NewAccountRepo(pg),
-		Operation: postgres.NewOperationRepo(pg),
-		Product:   postgres.NewProductRepo(pg),
+		User:          postgres.NewUserRepo(pg),
+		RefreshToken:  postgres.NewRefreshTokenRepo(pg),
+		Denylist:      postgres.NewDenylistRepo(pg),
+		LoginAttempt:  postgres.NewLoginAttemptRepo(pg),
+		PasswordReset: postgres.NewPasswordResetRepo(pg),
+		APIKey:        postgres.NewAPIKeyRepo(pg),
+		TOTP:          postgres.NewTOTPRepo(pg),
+		Account:       postgres.NewAccountRepo(pg),
+		Operation:     postgres.NewOperationRepo(pg),
+		Product:       postgres.NewProductRepo(pg),
+		Cart:          postgres.NewCartRepo(pg),
+		Refund:        postgres.NewRefundRepo(pg),
+		Idempotency:   postgres.NewIdempotencyRepo(pg),
 	}
 }
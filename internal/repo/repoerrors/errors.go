@@ -5,4 +5,6 @@ import "errors"
 var (
 	ErrNotEnoughBalance = errors.New("not enough balance")
 	ErrNotFound         = errors.New("not found")
+	ErrAlreadyExists    = errors.New("already exists")
+	ErrNotEnoughStock   = errors.New("not enough stock")
 )
@@ -33,32 +33,35 @@ func (u *accountUsecase) GetInfo(ctx context.Context, claims model.Claims) (*mod
 		return nil, err
 	}
 
+	var mark *entity.HistoryMark
 	balance := 0
 	purchases := []entity.Purchase{}
 	incomingTransfers := []entity.Transfer{}
 	outgoingTransfers := []entity.Transfer{}
 	transaction := func(ctx context.Context) error {
 		var err error
-		// в этот момент кто-то может прислать монет
-		// пользователь может на одной странице купить товар, а на второй смотреть баланс и информацию о покупках
+
+		// Метка читается первым запросом транзакции, поэтому ее время совпадает с моментом снимка.
+		mark, err = u.operationRepo.GetHistoryMark(ctx, accountID)
+		if err != nil {
+			return err
+		}
+
 		balance, err = u.accountRepo.GetBalanceByAccountID(ctx, accountID)
 		if err != nil {
 			return err
 		}
 
-		// пользователь может на одной странице купить товар, а на второй смотреть баланс и информацию о покупках
 		purchases, err = u.accountRepo.GetPurchasesByAccountID(ctx, accountID)
 		if err != nil {
 			return err
 		}
 
-		// в этот момент кто-то может прислать монет
 		incomingTransfers, err = u.operationRepo.GetIncomingTransfers(ctx, accountID)
 		if err != nil {
 			return err
 		}
 
-		// на одной странице пользователь отправляет монеты, а на другой подгружает баланс
 		outgoingTransfers, err = u.operationRepo.GetOutgoingTransfers(ctx, accountID)
 		if err != nil {
 			return err
@@ -67,14 +70,11 @@ func (u *accountUsecase) GetInfo(ctx context.Context, claims model.Claims) (*mod
 		return nil
 	}
 
-	/*shouldRetry := func(_ error) bool {
-		return true
-	}*/
-
-	// пожертвуем тем, что баланс может не соответствовать истории операций, если
-	// между его запросом и получением истории операции кто-то выполнит покупку или перевод
-	serializable := u.txManager.ReadCommitted(ctx, db.Read, transaction)
-	if err = u.txManager.WithRetry(serializable); err != nil {
+	// Все чтения идут из одного снимка, поэтому баланс всегда соответствует истории операций:
+	// покупка или перевод меняют баланс и историю в одной транзакции, и в снимок попадают
+	// либо оба изменения, либо ни одного.
+	snapshot := u.txManager.Snapshot(ctx, transaction)
+	if err = u.txManager.WithRetry(snapshot); err != nil {
 		return nil, err
 	}
 
@@ -83,27 +83,9 @@ func (u *accountUsecase) GetInfo(ctx context.Context, claims model.Claims) (*mod
 		Inventory:         converter.ConvertPurchasesToInventory(purchases),
 		IncomingTransfers: converter.ConvertTransfersToIncomingTransfers(incomingTransfers),
 		OutgoingTransfers: converter.ConvertTransfersToOutgoingTransfers(outgoingTransfers),
+		SnapshotAt:        mark.TakenAt,
+		LastOperationID:   mark.LastOperationID,
 	}
 
 	return info, nil
 }
-
-// Если сделаем Repeatable read, то будет проблема, что если между чтением баланса и
-// чтением количества полученных монет кто-то прислал монеты, то мы увидим баланс
-// на момент начала транзакции.
-// (Аномалия phantom read, вставка в таблицу транзакций)
-
-// Или если мы послали монеты и тут же начали смотреть информацию об аккаунте,
-// то может быть проблема при Repeatable read, что изменения об отправке монет
-// зафиксируются между чтением баланса и чтением отправленных монет.
-// (Аномалия phantom read, вставка в таблицу транзакций)
-
-// Для борьбы с фантомными чтениями можно использовать Serializable.
-// Но тогда придется обрабатывать 40001 ошибку. Возникает проблема, что если
-// пользователи будут отправлять мне монеты, а я буду получать информацию о них, то
-// чья-то транзакция может завершиться с ошибкой и надо будет ее повторять.
-
-// Можно использовать repeatable read, но корректировать баланс вручную.
-// actualBalance := totalReceived - totalSent - totalPurchase + initialBalance -- ужасное решение
-
-// Еще есть repeatable read + SELECT ... FOR SHARE, но надо посмотреть подробнее.
@@ -5,17 +5,18 @@ import (
 	"errors"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/resueman/merch-store/internal/entity"
 	"github.com/resueman/merch-store/internal/model"
-	"github.com/resueman/merch-store/pkg/db"
 	"github.com/resueman/merch-store/test/mocks"
 	"github.com/stretchr/testify/require"
 )
 
 type repoInfo struct {
 	accountID         int
+	mark              entity.HistoryMark
 	balance           int
 	purchases         []entity.Purchase
 	incomingTransfers []entity.Transfer
@@ -24,6 +25,7 @@ type repoInfo struct {
 
 type repoInfoError struct {
 	gettingAccountErr    error
+	historyMarkErr       error
 	balanceErr           error
 	purchasesErr         error
 	incomingTransfersErr error
@@ -41,6 +43,10 @@ func getRepoInfoMock(
 		GetIDByUserID(gomock.Any(), claims.UserID).
 		Return(accountID, nil)
 
+	operationRepo.EXPECT().
+		GetHistoryMark(gomock.Any(), accountID).
+		Return(&repoData.mark, nil)
+
 	accountRepo.EXPECT().
 		GetBalanceByAccountID(gomock.Any(), accountID).
 		Return(repoData.balance, nil)
@@ -73,6 +79,14 @@ func getRepoInfoWithErrorMock(
 		return
 	}
 
+	operationRepo.EXPECT().
+		GetHistoryMark(gomock.Any(), accountID).
+		Return(&entity.HistoryMark{}, repoData.historyMarkErr)
+
+	if repoData.historyMarkErr != nil {
+		return
+	}
+
 	accountRepo.EXPECT().
 		GetBalanceByAccountID(gomock.Any(), accountID).
 		Return(100, repoData.balanceErr)
@@ -104,8 +118,8 @@ func getRepoInfoWithErrorMock(
 
 func txManagerMock(txManager *mocks.MockTxManager) {
 	txManager.EXPECT().
-		ReadCommitted(gomock.Any(), gomock.Any(), gomock.Any()).
-		DoAndReturn(func(ctx context.Context, _ db.Mode, f func(context.Context) error) func() error {
+		Snapshot(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, f func(context.Context) error) func() error {
 			return func() error { return f(ctx) }
 		})
 
@@ -120,6 +134,8 @@ func TestGetInfo_Success(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
+	snapshotAt := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+
 	tests := []struct {
 		name string
 		mock func(
@@ -145,6 +161,7 @@ func TestGetInfo_Success(t *testing.T) {
 				txManagerMock(txManager)
 			},
 			in: &repoInfo{
+				mark:    entity.HistoryMark{TakenAt: snapshotAt, LastOperationID: 42},
 				balance: 300,
 				purchases: []entity.Purchase{
 					{Name: "pen", Quantity: 1},
@@ -162,7 +179,9 @@ func TestGetInfo_Success(t *testing.T) {
 				},
 			},
 			want: &model.AccountInfo{
-				Balance: 300,
+				SnapshotAt:      snapshotAt,
+				LastOperationID: 42,
+				Balance:         300,
 				Inventory: []model.Inventory{
 					{Name: "pen", Quantity: 1},
 					{Name: "book", Quantity: 2},
@@ -279,16 +298,26 @@ func TestGetInfo_Error_ErrorGettingBalance(t *testing.T) {
 	unknownErrGettingBalance := errors.New("error")
 
 	tests := []struct {
-		name    string
-		mock    func(accountRepo *mocks.MockAccount, txManager *mocks.MockTxManager, claims model.Claims)
+		name string
+		mock func(
+			accountRepo *mocks.MockAccount,
+			operationRepo *mocks.MockOperation,
+			txManager *mocks.MockTxManager,
+			claims model.Claims,
+		)
 		want    *model.AccountInfo
 		wantErr error
 	}{
 		{
 			name: "unknown error getting balance",
-			mock: func(accountRepo *mocks.MockAccount, txManager *mocks.MockTxManager, claims model.Claims) {
+			mock: func(
+				accountRepo *mocks.MockAccount,
+				operationRepo *mocks.MockOperation,
+				txManager *mocks.MockTxManager,
+				claims model.Claims,
+			) {
 				repoInfoError := &repoInfoError{balanceErr: unknownErrGettingBalance}
-				getRepoInfoWithErrorMock(accountRepo, nil, claims, repoInfoError)
+				getRepoInfoWithErrorMock(accountRepo, operationRepo, claims, repoInfoError)
 
 				txManagerMock(txManager)
 			},
@@ -301,11 +330,12 @@ func TestGetInfo_Error_ErrorGettingBalance(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			accountRepo := mocks.NewMockAccount(ctrl)
+			operationRepo := mocks.NewMockOperation(ctrl)
 			txManager := mocks.NewMockTxManager(ctrl)
 
-			tt.mock(accountRepo, txManager, claims)
+			tt.mock(accountRepo, operationRepo, txManager, claims)
 
-			accountUsecase := NewAccountUsecase(accountRepo, nil, nil, txManager)
+			accountUsecase := NewAccountUsecase(accountRepo, operationRepo, nil, txManager)
 			info, err := accountUsecase.GetInfo(context.Background(), claims)
 
 			require.ErrorIs(t, err, tt.wantErr)
@@ -321,16 +351,26 @@ func TestGetInfo_Error_ErrorGettingPurchases(t *testing.T) {
 	unknownErrGettingPurchases := errors.New("error")
 
 	tests := []struct {
-		name    string
-		mock    func(accountRepo *mocks.MockAccount, txManager *mocks.MockTxManager, claims model.Claims)
+		name string
+		mock func(
+			accountRepo *mocks.MockAccount,
+			operationRepo *mocks.MockOperation,
+			txManager *mocks.MockTxManager,
+			claims model.Claims,
+		)
 		want    *model.AccountInfo
 		wantErr error
 	}{
 		{
 			name: "unknown error getting purchases",
-			mock: func(accountRepo *mocks.MockAccount, txManager *mocks.MockTxManager, claims model.Claims) {
+			mock: func(
+				accountRepo *mocks.MockAccount,
+				operationRepo *mocks.MockOperation,
+				txManager *mocks.MockTxManager,
+				claims model.Claims,
+			) {
 				repoInfoError := &repoInfoError{purchasesErr: unknownErrGettingPurchases}
-				getRepoInfoWithErrorMock(accountRepo, nil, claims, repoInfoError)
+				getRepoInfoWithErrorMock(accountRepo, operationRepo, claims, repoInfoError)
 
 				txManagerMock(txManager)
 			},
@@ -343,11 +383,12 @@ func TestGetInfo_Error_ErrorGettingPurchases(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			accountRepo := mocks.NewMockAccount(ctrl)
+			operationRepo := mocks.NewMockOperation(ctrl)
 			txManager := mocks.NewMockTxManager(ctrl)
 
-			tt.mock(accountRepo, txManager, claims)
+			tt.mock(accountRepo, operationRepo, txManager, claims)
 
-			accountUsecase := NewAccountUsecase(accountRepo, nil, nil, txManager)
+			accountUsecase := NewAccountUsecase(accountRepo, operationRepo, nil, txManager)
 			info, err := accountUsecase.GetInfo(context.Background(), claims)
 
 			require.ErrorIs(t, err, tt.wantErr)
@@ -514,3 +555,24 @@ func TestGetInfo_Error_TxManagerError(t *testing.T) {
 		})
 	}
 }
+
+func TestGetInfo_Error_ErrorGettingHistoryMark(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	errGettingHistoryMark := errors.New("error")
+	claims := model.Claims{UserID: 111}
+
+	accountRepo := mocks.NewMockAccount(ctrl)
+	operationRepo := mocks.NewMockOperation(ctrl)
+	txManager := mocks.NewMockTxManager(ctrl)
+
+	getRepoInfoWithErrorMock(accountRepo, operationRepo, claims, &repoInfoError{historyMarkErr: errGettingHistoryMark})
+	txManagerMock(txManager)
+
+	accountUsecase := NewAccountUsecase(accountRepo, operationRepo, nil, txManager)
+	info, err := accountUsecase.GetInfo(context.Background(), claims)
+
+	require.ErrorIs(t, err, errGettingHistoryMark)
+	require.Nil(t, info)
+}
@@ -0,0 +1,138 @@
+package account
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/resueman/merch-store/internal/entity"
+	"github.com/resueman/merch-store/internal/model"
+	"github.com/resueman/merch-store/internal/repo/repoerrors"
+	"github.com/resueman/merch-store/internal/usecase/apperrors"
+	"github.com/resueman/merch-store/internal/usecase/converter"
+)
+
+const (
+	defaultHistoryLimit = 20
+	maxHistoryLimit     = 100
+)
+
+// Постраничная история операций пользователя от новых к старым. Страницы разбиваются по
+// (created_at, id) последней операции, поэтому новые операции не сдвигают уже полученные страницы.
+func (u *accountUsecase) GetHistory(ctx context.Context, claims model.Claims, filter model.HistoryFilter) (
+	model.HistoryPage, error,
+) {
+	if filter.Limit == 0 {
+		filter.Limit = defaultHistoryLimit
+	}
+
+	if filter.Limit < 0 || filter.Limit > maxHistoryLimit {
+		return model.HistoryPage{}, apperrors.ErrInvalidPagination
+	}
+
+	if filter.Type != "" && !filter.Type.Valid() {
+		return model.HistoryPage{}, apperrors.ErrInvalidHistoryType
+	}
+
+	if filter.Direction != "" && !filter.Direction.Valid() {
+		return model.HistoryPage{}, apperrors.ErrInvalidHistoryDirection
+	}
+
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return model.HistoryPage{}, apperrors.ErrInvalidHistoryPeriod
+	}
+
+	accountID, err := u.accountRepo.GetIDByUserID(ctx, claims.UserID)
+	if err != nil {
+		return model.HistoryPage{}, err
+	}
+
+	repoFilter := entity.HistoryFilter{
+		AccountID:     accountID,
+		OperationType: string(filter.Type),
+		Direction:     string(filter.Direction),
+		// Лишняя запись показывает, есть ли следующая страница.
+		Limit: filter.Limit + 1,
+	}
+
+	if filter.Cursor != "" {
+		repoFilter.AfterCreatedAt, repoFilter.AfterID, err = decodeHistoryCursor(filter.Cursor)
+		if err != nil {
+			return model.HistoryPage{}, err
+		}
+	}
+
+	// created_at хранится без часового пояса в UTC.
+	if filter.From != nil {
+		from := filter.From.UTC()
+		repoFilter.From = &from
+	}
+
+	if filter.To != nil {
+		to := filter.To.UTC()
+		repoFilter.To = &to
+	}
+
+	if filter.Counterparty != "" {
+		counterpartyAccountID, err := u.accountRepo.GetIDByUsername(ctx, filter.Counterparty)
+		if err != nil {
+			if errors.Is(err, repoerrors.ErrNotFound) {
+				return model.HistoryPage{}, apperrors.ErrUserNotFound
+			}
+
+			return model.HistoryPage{}, err
+		}
+
+		repoFilter.CounterpartyAccountID = &counterpartyAccountID
+	}
+
+	entries, err := u.operationRepo.GetHistory(ctx, repoFilter)
+	if err != nil {
+		return model.HistoryPage{}, err
+	}
+
+	page := model.HistoryPage{Limit: filter.Limit}
+	if len(entries) > filter.Limit {
+		entries = entries[:filter.Limit]
+		last := entries[len(entries)-1]
+		page.NextCursor = encodeHistoryCursor(last.CreatedAt, last.OperationID)
+	}
+
+	page.Items = converter.ConvertHistoryEntries(entries)
+
+	return page, nil
+}
+
+// Курсор непрозрачен для клиента: base64 от времени и идентификатора последней операции страницы.
+func encodeHistoryCursor(createdAt time.Time, operationID int) string {
+	raw := createdAt.UTC().Format(time.RFC3339Nano) + "," + strconv.Itoa(operationID)
+
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeHistoryCursor(cursor string) (time.Time, int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, 0, apperrors.ErrInvalidHistoryCursor
+	}
+
+	createdAtRaw, operationIDRaw, found := strings.Cut(string(raw), ",")
+	if !found {
+		return time.Time{}, 0, apperrors.ErrInvalidHistoryCursor
+	}
+
+	createdAt, err := time.Parse(time.RFC3339Nano, createdAtRaw)
+	if err != nil {
+		return time.Time{}, 0, apperrors.ErrInvalidHistoryCursor
+	}
+
+	operationID, err := strconv.Atoi(operationIDRaw)
+	if err != nil || operationID <= 0 {
+		return time.Time{}, 0, apperrors.ErrInvalidHistoryCursor
+	}
+
+	return createdAt, operationID, nil
+}
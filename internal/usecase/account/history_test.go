@@ -0,0 +1,173 @@
+package account
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/resueman/merch-store/internal/entity"
+	"github.com/resueman/merch-store/internal/model"
+	"github.com/resueman/merch-store/internal/repo/repoerrors"
+	"github.com/resueman/merch-store/internal/usecase/apperrors"
+	"github.com/resueman/merch-store/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetHistory_Pagination(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	accountRepo := mocks.NewMockAccount(ctrl)
+	operationRepo := mocks.NewMockOperation(ctrl)
+	accountUsecase := NewAccountUsecase(accountRepo, operationRepo, nil, nil)
+
+	claims := model.Claims{UserID: 111}
+	createdAt := time.Date(2025, 3, 10, 12, 0, 0, 123000, time.UTC)
+	entries := []entity.HistoryEntry{
+		{OperationID: 9, OperationType: "transfer", Direction: "incoming", Amount: 50, Counterparty: "bob",
+			CreatedAt: createdAt.Add(time.Minute)},
+		{OperationID: 8, OperationType: "purchase", Direction: "outgoing", Amount: 80, ProductName: "cup",
+			Quantity: 4, CreatedAt: createdAt},
+		{OperationID: 7, OperationType: "refund", Direction: "incoming", Amount: 20, ProductName: "cup",
+			Quantity: 1, RefundStatus: "requested", CreatedAt: createdAt},
+	}
+
+	accountRepo.EXPECT().GetIDByUserID(gomock.Any(), claims.UserID).Return(1, nil).Times(2)
+
+	operationRepo.EXPECT().
+		GetHistory(gomock.Any(), entity.HistoryFilter{AccountID: 1, Limit: 3}).
+		Return(entries, nil)
+
+	page, err := accountUsecase.GetHistory(context.Background(), claims, model.HistoryFilter{Limit: 2})
+	require.NoError(t, err)
+	require.Equal(t, 2, page.Limit)
+	require.Equal(t, []model.HistoryEntry{
+		{ID: 9, Type: model.HistoryTransfer, Direction: model.HistoryIncoming, Amount: 50, Counterparty: "bob",
+			CreatedAt: createdAt.Add(time.Minute)},
+		{ID: 8, Type: model.HistoryPurchase, Direction: model.HistoryOutgoing, Amount: 80, ItemName: "cup",
+			Quantity: 4, CreatedAt: createdAt},
+	}, page.Items)
+	require.NotEmpty(t, page.NextCursor)
+
+	// Следующая страница продолжается строго после последней операции предыдущей.
+	operationRepo.EXPECT().
+		GetHistory(gomock.Any(), entity.HistoryFilter{AccountID: 1, AfterCreatedAt: createdAt, AfterID: 8, Limit: 3}).
+		Return(entries[2:], nil)
+
+	page, err = accountUsecase.GetHistory(context.Background(), claims,
+		model.HistoryFilter{Cursor: page.NextCursor, Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+	require.Equal(t, model.RefundRequested, page.Items[0].RefundStatus)
+	require.Empty(t, page.NextCursor)
+}
+
+func TestGetHistory_Filters(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	accountRepo := mocks.NewMockAccount(ctrl)
+	operationRepo := mocks.NewMockOperation(ctrl)
+	accountUsecase := NewAccountUsecase(accountRepo, operationRepo, nil, nil)
+
+	claims := model.Claims{UserID: 111}
+	moscow := time.FixedZone("MSK", 3*60*60)
+	from := time.Date(2025, 3, 1, 3, 0, 0, 0, moscow)
+	to := time.Date(2025, 3, 2, 3, 0, 0, 0, moscow)
+	fromUTC, toUTC := from.UTC(), to.UTC()
+	counterpartyAccountID := 2
+
+	accountRepo.EXPECT().GetIDByUserID(gomock.Any(), claims.UserID).Return(1, nil)
+	accountRepo.EXPECT().GetIDByUsername(gomock.Any(), "bob").Return(counterpartyAccountID, nil)
+	operationRepo.EXPECT().
+		GetHistory(gomock.Any(), entity.HistoryFilter{
+			AccountID:             1,
+			OperationType:         "transfer",
+			Direction:             "outgoing",
+			CounterpartyAccountID: &counterpartyAccountID,
+			From:                  &fromUTC,
+			To:                    &toUTC,
+			Limit:                 defaultHistoryLimit + 1,
+		}).
+		Return([]entity.HistoryEntry{}, nil)
+
+	page, err := accountUsecase.GetHistory(context.Background(), claims, model.HistoryFilter{
+		Type:         model.HistoryTransfer,
+		Direction:    model.HistoryOutgoing,
+		Counterparty: "bob",
+		From:         &from,
+		To:           &to,
+	})
+	require.NoError(t, err)
+	require.Empty(t, page.Items)
+	require.Equal(t, defaultHistoryLimit, page.Limit)
+}
+
+func TestGetHistory_Errors(t *testing.T) {
+	from := time.Date(2025, 3, 2, 0, 0, 0, 0, time.UTC)
+	to := from.Add(-time.Hour)
+	claims := model.Claims{UserID: 111}
+
+	tests := []struct {
+		name    string
+		mock    func(accountRepo *mocks.MockAccount)
+		filter  model.HistoryFilter
+		wantErr error
+	}{
+		{
+			name:    "limit too large",
+			filter:  model.HistoryFilter{Limit: maxHistoryLimit + 1},
+			wantErr: apperrors.ErrInvalidPagination,
+		},
+		{
+			name:    "unknown type",
+			filter:  model.HistoryFilter{Type: "deposit"},
+			wantErr: apperrors.ErrInvalidHistoryType,
+		},
+		{
+			name:    "unknown direction",
+			filter:  model.HistoryFilter{Direction: "sideways"},
+			wantErr: apperrors.ErrInvalidHistoryDirection,
+		},
+		{
+			name:    "from after to",
+			filter:  model.HistoryFilter{From: &from, To: &to},
+			wantErr: apperrors.ErrInvalidHistoryPeriod,
+		},
+		{
+			name: "malformed cursor",
+			mock: func(accountRepo *mocks.MockAccount) {
+				accountRepo.EXPECT().GetIDByUserID(gomock.Any(), claims.UserID).Return(1, nil)
+			},
+			filter:  model.HistoryFilter{Cursor: "not a cursor"},
+			wantErr: apperrors.ErrInvalidHistoryCursor,
+		},
+		{
+			name: "unknown counterparty",
+			mock: func(accountRepo *mocks.MockAccount) {
+				accountRepo.EXPECT().GetIDByUserID(gomock.Any(), claims.UserID).Return(1, nil)
+				accountRepo.EXPECT().GetIDByUsername(gomock.Any(), "ghost").Return(0, repoerrors.ErrNotFound)
+			},
+			filter:  model.HistoryFilter{Counterparty: "ghost"},
+			wantErr: apperrors.ErrUserNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			accountRepo := mocks.NewMockAccount(ctrl)
+			if tt.mock != nil {
+				tt.mock(accountRepo)
+			}
+
+			accountUsecase := NewAccountUsecase(accountRepo, mocks.NewMockOperation(ctrl), nil, nil)
+
+			_, err := accountUsecase.GetHistory(context.Background(), claims, tt.filter)
+			require.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package account
+
+import (
+	"context"
+	"errors"
+
+	"github.com/resueman/merch-store/internal/entity"
+	"github.com/resueman/merch-store/internal/repo/repoerrors"
+	"github.com/resueman/merch-store/internal/usecase/apperrors"
+	"github.com/resueman/merch-store/pkg/db"
+)
+
+// Переводит весь баланс пользователя на счет recipientUsername, например на казначейский счет
+// при деактивации сотрудника. Перевод записывается в историю так же, как обычный.
+func (u *accountUsecase) SweepBalance(ctx context.Context, userID int, recipientUsername string) (int, error) {
+	swept := 0
+	transaction := func(ctx context.Context) error {
+		swept = 0
+
+		accountID, err := u.accountRepo.GetIDByUserID(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		recipientAccountID, err := u.accountRepo.GetIDByUsername(ctx, recipientUsername)
+		if err != nil {
+			if errors.Is(err, repoerrors.ErrNotFound) {
+				return apperrors.ErrTreasuryNotConfigured
+			}
+
+			return err
+		}
+
+		// Казначейский счет не переводит монеты сам себе.
+		if accountID == recipientAccountID {
+			return nil
+		}
+
+		balance, err := u.accountRepo.GetBalanceByAccountID(ctx, accountID)
+		if err != nil || balance == 0 {
+			return err
+		}
+
+		if err = u.accountRepo.Withdraw(ctx, accountID, balance); err != nil {
+			return err
+		}
+
+		if err = u.accountRepo.Deposit(ctx, recipientAccountID, balance); err != nil {
+			if errors.Is(err, repoerrors.ErrNotFound) {
+				return apperrors.ErrRecipientDeactivated
+			}
+
+			return err
+		}
+
+		operation := entity.TransferOperation{
+			SenderAccountID:    accountID,
+			RecipientAccountID: recipientAccountID,
+			Amount:             balance,
+		}
+
+		if err = u.operationRepo.ExecTransferOperation(ctx, operation); err != nil {
+			return err
+		}
+
+		swept = balance
+
+		return nil
+	}
+
+	serializable := u.txManager.Serializable(ctx, db.Write, transaction)
+	if err := u.txManager.WithRetry(serializable); err != nil {
+		return 0, err
+	}
+
+	return swept, nil
+}
@@ -0,0 +1,124 @@
+package account
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/resueman/merch-store/internal/entity"
+	"github.com/resueman/merch-store/internal/repo/repoerrors"
+	"github.com/resueman/merch-store/internal/usecase/apperrors"
+	"github.com/resueman/merch-store/pkg/db"
+	"github.com/resueman/merch-store/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func sweepTxMock(txManager *mocks.MockTxManager) {
+	txManager.EXPECT().
+		Serializable(gomock.Any(), db.Write, gomock.Any()).
+		DoAndReturn(func(ctx context.Context, _ db.Mode, f func(context.Context) error) func() error {
+			return func() error { return f(ctx) }
+		})
+
+	txManager.EXPECT().
+		WithRetry(gomock.Any()).
+		DoAndReturn(func(f func() error) error {
+			return f()
+		})
+}
+
+func TestSweepBalance_Success(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	accountRepo := mocks.NewMockAccount(ctrl)
+	operationRepo := mocks.NewMockOperation(ctrl)
+	txManager := mocks.NewMockTxManager(ctrl)
+
+	sweepTxMock(txManager)
+
+	accountRepo.EXPECT().GetIDByUserID(gomock.Any(), 2).Return(20, nil)
+	accountRepo.EXPECT().GetIDByUsername(gomock.Any(), "treasury").Return(10, nil)
+	accountRepo.EXPECT().GetBalanceByAccountID(gomock.Any(), 20).Return(750, nil)
+	accountRepo.EXPECT().Withdraw(gomock.Any(), 20, 750).Return(nil)
+	accountRepo.EXPECT().Deposit(gomock.Any(), 10, 750).Return(nil)
+	operationRepo.EXPECT().
+		ExecTransferOperation(gomock.Any(), entity.TransferOperation{
+			SenderAccountID:    20,
+			RecipientAccountID: 10,
+			Amount:             750,
+		}).
+		Return(nil)
+
+	accountUsecase := NewAccountUsecase(accountRepo, operationRepo, nil, txManager)
+
+	swept, err := accountUsecase.SweepBalance(context.Background(), 2, "treasury")
+	require.NoError(t, err)
+	require.Equal(t, 750, swept)
+}
+
+func TestSweepBalance_EmptyBalance(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	accountRepo := mocks.NewMockAccount(ctrl)
+	txManager := mocks.NewMockTxManager(ctrl)
+
+	sweepTxMock(txManager)
+
+	accountRepo.EXPECT().GetIDByUserID(gomock.Any(), 2).Return(20, nil)
+	accountRepo.EXPECT().GetIDByUsername(gomock.Any(), "treasury").Return(10, nil)
+	accountRepo.EXPECT().GetBalanceByAccountID(gomock.Any(), 20).Return(0, nil)
+
+	accountUsecase := NewAccountUsecase(accountRepo, nil, nil, txManager)
+
+	swept, err := accountUsecase.SweepBalance(context.Background(), 2, "treasury")
+	require.NoError(t, err)
+	require.Zero(t, swept)
+}
+
+func TestSweepBalance_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		mock    func(accountRepo *mocks.MockAccount)
+		wantErr error
+	}{
+		{
+			name: "treasury not found",
+			mock: func(accountRepo *mocks.MockAccount) {
+				accountRepo.EXPECT().GetIDByUserID(gomock.Any(), 2).Return(20, nil)
+				accountRepo.EXPECT().GetIDByUsername(gomock.Any(), "treasury").Return(0, repoerrors.ErrNotFound)
+			},
+			wantErr: apperrors.ErrTreasuryNotConfigured,
+		},
+		{
+			name: "treasury deactivated",
+			mock: func(accountRepo *mocks.MockAccount) {
+				accountRepo.EXPECT().GetIDByUserID(gomock.Any(), 2).Return(20, nil)
+				accountRepo.EXPECT().GetIDByUsername(gomock.Any(), "treasury").Return(10, nil)
+				accountRepo.EXPECT().GetBalanceByAccountID(gomock.Any(), 20).Return(750, nil)
+				accountRepo.EXPECT().Withdraw(gomock.Any(), 20, 750).Return(nil)
+				accountRepo.EXPECT().Deposit(gomock.Any(), 10, 750).Return(repoerrors.ErrNotFound)
+			},
+			wantErr: apperrors.ErrRecipientDeactivated,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			accountRepo := mocks.NewMockAccount(ctrl)
+			txManager := mocks.NewMockTxManager(ctrl)
+
+			sweepTxMock(txManager)
+			tt.mock(accountRepo)
+
+			accountUsecase := NewAccountUsecase(accountRepo, nil, nil, txManager)
+
+			_, err := accountUsecase.SweepBalance(context.Background(), 2, "treasury")
+			require.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
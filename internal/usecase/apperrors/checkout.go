@@ -0,0 +1,36 @@
+package apperrors
+
+import "strings"
+
+// CheckoutError перечисляет строки корзины, из-за которых заказ не был оформлен.
+// errors.Is(err, ErrCheckoutFailed) выполняется для любой такой ошибки.
+type CheckoutError struct {
+	Lines []CheckoutLineError
+}
+
+// Variant пуст, если в строке корзины лежит сам товар.
+type CheckoutLineError struct {
+	ItemName string
+	Variant  string
+	Err      error
+}
+
+func (e *CheckoutError) Error() string {
+	var msg strings.Builder
+	msg.WriteString(ErrCheckoutFailed.Error())
+
+	for _, line := range e.Lines {
+		msg.WriteString("; " + line.ItemName)
+		if line.Variant != "" {
+			msg.WriteString(" (" + line.Variant + ")")
+		}
+
+		msg.WriteString(": " + line.Err.Error())
+	}
+
+	return msg.String()
+}
+
+func (e *CheckoutError) Unwrap() error {
+	return ErrCheckoutFailed
+}
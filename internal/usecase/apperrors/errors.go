@@ -9,8 +9,81 @@ var (
 	ErrUserNotFound     = errors.New("user not found")
 	ErrProductNotFound  = errors.New("product not found")
 
-	ErrInvalidPassword = errors.New("invalid password")
-	ErrInvalidToken    = errors.New("invalid token")
-	ErrTokenExpired    = errors.New("token expired")
-	ErrGenerateToken   = errors.New("failed to generate token")
+	ErrInvalidQuantity       = errors.New("quantity must be positive")
+	ErrQuantityLimitExceeded = errors.New("quantity limit exceeded")
+	ErrTotalPriceOverflow    = errors.New("total price overflow")
+	ErrOutOfStock            = errors.New("out of stock")
+
+	ErrInvalidProductSort = errors.New("invalid product sort")
+	ErrInvalidPagination  = errors.New("invalid pagination")
+	ErrInvalidPriceRange  = errors.New("invalid price range")
+
+	ErrInvalidHistoryType      = errors.New("invalid history operation type")
+	ErrInvalidHistoryDirection = errors.New("invalid history direction")
+	ErrInvalidHistoryPeriod    = errors.New("invalid history period")
+	ErrInvalidHistoryCursor    = errors.New("invalid history cursor")
+	ErrOperationNotFound       = errors.New("operation not found")
+
+	ErrProductAlreadyExists = errors.New("product already exists")
+	ErrInvalidProductName   = errors.New("invalid product name")
+	ErrInvalidPrice         = errors.New("invalid price")
+	ErrInvalidStock         = errors.New("invalid stock")
+
+	ErrVariantNotFound      = errors.New("product variant not found")
+	ErrVariantAlreadyExists = errors.New("product variant already exists")
+	ErrInvalidVariant       = errors.New("invalid product variant")
+
+	ErrCartEmpty        = errors.New("cart is empty")
+	ErrCartItemNotFound = errors.New("item is not in the cart")
+	ErrCheckoutFailed   = errors.New("checkout failed")
+
+	ErrPurchaseNotFound       = errors.New("purchase not found")
+	ErrRefundWindowExpired    = errors.New("refund window expired")
+	ErrRefundQuantityExceeded = errors.New("refund quantity exceeds the refundable quantity")
+	ErrRefundNotFound         = errors.New("refund not found")
+	ErrRefundAlreadyDecided   = errors.New("refund already decided")
+	ErrInvalidRefundStatus    = errors.New("invalid refund status")
+
+	ErrIdempotencyKeyReused = errors.New("idempotency key reused with a different request")
+
+	ErrUserDeactivated       = errors.New("user is deactivated")
+	ErrRecipientDeactivated  = errors.New("recipient is deactivated")
+	ErrSelfDeactivation      = errors.New("self deactivation")
+	ErrTreasuryNotConfigured = errors.New("treasury account is not configured")
+
+	ErrInvalidPassword   = errors.New("invalid password")
+	ErrUserNotRegistered = errors.New("user not registered")
+	ErrUserAlreadyExists = errors.New("user already exists")
+	ErrInvalidUsername   = errors.New("invalid username")
+	ErrReservedUsername  = errors.New("reserved username")
+
+	ErrWeakPassword      = errors.New("weak password")
+	ErrSamePassword      = errors.New("new password equals the old one")
+	ErrInvalidResetToken = errors.New("invalid password reset token")
+	ErrResetTokenExpired = errors.New("password reset token expired")
+
+	ErrTooManyLoginAttempts = errors.New("too many login attempts")
+
+	ErrInvalidAPIKey     = errors.New("invalid api key")
+	ErrAPIKeyNotFound    = errors.New("api key not found")
+	ErrNotServiceAccount = errors.New("not a service account")
+	ErrServiceAccount    = errors.New("operation is not available for service accounts")
+
+	ErrOTPRequired        = errors.New("one-time code required")
+	ErrInvalidOTP         = errors.New("invalid one-time code")
+	ErrTOTPAlreadyEnabled = errors.New("totp already enabled")
+	ErrTOTPNotEnabled     = errors.New("totp not enabled")
+	ErrInvalidThreshold   = errors.New("invalid threshold")
+
+	ErrInvalidScope   = errors.New("invalid scope")
+	ErrInvalidRole    = errors.New("invalid role")
+	ErrSelfRoleChange = errors.New("self role change")
+	ErrInvalidToken   = errors.New("invalid token")
+	ErrTokenExpired   = errors.New("token expired")
+	ErrTokenRevoked   = errors.New("token revoked")
+	ErrGenerateToken  = errors.New("failed to generate token")
+
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrRefreshTokenExpired = errors.New("refresh token expired")
+	ErrRefreshTokenReused  = errors.New("refresh token reuse detected")
 )
@@ -0,0 +1,184 @@
+package auth
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/labstack/gommon/log"
+	"github.com/pkg/errors"
+	"github.com/resueman/merch-store/internal/entity"
+	"github.com/resueman/merch-store/internal/model"
+	"github.com/resueman/merch-store/internal/repo/repoerrors"
+	"github.com/resueman/merch-store/internal/usecase/apperrors"
+	"github.com/resueman/merch-store/pkg/username"
+)
+
+const (
+	apiKeyBytes = 32
+	// Ключ выглядит как msk_<prefix><остаток>, prefix хранится открыто и показывается в списке ключей.
+	apiKeyMarker    = "msk_"
+	apiKeyPrefixLen = 8
+	// Время последнего использования обновляется не чаще раза в минуту, чтобы не писать в БД на каждый запрос.
+	apiKeyTouchInterval = time.Minute
+	// Не является хешем ни одного алгоритма, поэтому войти в сервисный аккаунт по паролю невозможно.
+	serviceAccountPasswordHash = "!"
+)
+
+// Создает сервисный аккаунт для бота. У него есть счет, как у обычного пользователя,
+// но аутентифицироваться он может только API-ключами.
+func (u *authUsecase) CreateServiceAccount(ctx context.Context, name string) error {
+	if username.Normalize(name) == "" {
+		return apperrors.ErrInvalidUsername
+	}
+
+	input := &entity.CreateUserInput{
+		Username:  strings.TrimSpace(name),
+		Hash:      serviceAccountPasswordHash,
+		IsService: true,
+	}
+	if _, err := u.userRepo.CreateUser(ctx, input); err != nil {
+		if errors.Is(err, repoerrors.ErrAlreadyExists) {
+			return apperrors.ErrUserAlreadyExists
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// Выпускает API-ключ сервисного аккаунта. Ключ в открытом виде возвращается только здесь.
+func (u *authUsecase) CreateAPIKey(ctx context.Context, claims model.Claims,
+	input model.CreateAPIKeyInput,
+) (model.APIKey, error) {
+	user, err := u.getServiceAccount(ctx, input.Username)
+	if err != nil {
+		return model.APIKey{}, err
+	}
+
+	scopes, err := grantScopes(model.Role(user.Role), input.Scopes)
+	if err != nil {
+		return model.APIKey{}, err
+	}
+
+	secret, err := randomString(apiKeyBytes)
+	if err != nil {
+		return model.APIKey{}, apperrors.ErrGenerateToken
+	}
+
+	key := apiKeyMarker + secret
+	create := &entity.CreateAPIKeyInput{
+		UserID:    user.ID,
+		CreatedBy: claims.UserID,
+		Name:      input.Name,
+		Prefix:    key[:len(apiKeyMarker)+apiKeyPrefixLen],
+		KeyHash:   hashToken(key),
+		Scopes:    model.FormatScopes(scopes),
+	}
+
+	keyID, err := u.apiKeyRepo.CreateAPIKey(ctx, create)
+	if err != nil {
+		return model.APIKey{}, err
+	}
+
+	return model.APIKey{
+		ID:        keyID,
+		Name:      create.Name,
+		Prefix:    create.Prefix,
+		Key:       key,
+		Scopes:    scopes,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+// Все ключи сервисного аккаунта, включая отозванные, без самих значений ключей.
+func (u *authUsecase) GetAPIKeys(ctx context.Context, username string) ([]model.APIKey, error) {
+	user, err := u.getServiceAccount(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := u.apiKeyRepo.GetAPIKeysByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]model.APIKey, 0, len(keys))
+	for _, k := range keys {
+		result = append(result, model.APIKey{
+			ID:         k.ID,
+			Name:       k.Name,
+			Prefix:     k.Prefix,
+			Scopes:     model.ParseScopes(k.Scopes),
+			CreatedAt:  k.CreatedAt,
+			LastUsedAt: k.LastUsedAt,
+			RevokedAt:  k.RevokedAt,
+		})
+	}
+
+	return result, nil
+}
+
+func (u *authUsecase) RevokeAPIKey(ctx context.Context, keyID int) error {
+	if err := u.apiKeyRepo.RevokeAPIKey(ctx, keyID); err != nil {
+		if errors.Is(err, repoerrors.ErrNotFound) {
+			return apperrors.ErrAPIKeyNotFound
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// Проверяет API-ключ и возвращает claims его владельца. Области доступа ключа дополнительно
+// ограничиваются текущей ролью владельца, чтобы ключ не пережил понижение роли.
+func (u *authUsecase) AuthenticateAPIKey(ctx context.Context, key string) (model.Claims, error) {
+	stored, err := u.apiKeyRepo.GetAPIKeyByHash(ctx, hashToken(key))
+	if err != nil {
+		if errors.Is(err, repoerrors.ErrNotFound) {
+			return emptyClaims, apperrors.ErrInvalidAPIKey
+		}
+
+		return emptyClaims, err
+	}
+
+	// Ключи деактивированного сервисного аккаунта не отзываются и снова заработают после реактивации.
+	if stored.RevokedAt != nil || stored.UserDeactivatedAt != nil {
+		return emptyClaims, apperrors.ErrInvalidAPIKey
+	}
+
+	now := time.Now()
+	if stored.LastUsedAt == nil || now.Sub(*stored.LastUsedAt) >= apiKeyTouchInterval {
+		if err = u.apiKeyRepo.TouchAPIKey(ctx, stored.ID, now); err != nil {
+			log.Warnf("failed to update last use of api key %d: %v", stored.ID, err)
+		}
+	}
+
+	role := model.Role(stored.Role)
+	allowed := model.ScopesForRole(role)
+	scopes := slices.DeleteFunc(model.ParseScopes(stored.Scopes), func(s model.Scope) bool {
+		return !slices.Contains(allowed, s)
+	})
+
+	return model.Claims{UserID: stored.UserID, Role: role, Scopes: scopes, APIKeyID: stored.ID}, nil
+}
+
+func (u *authUsecase) getServiceAccount(ctx context.Context, username string) (*entity.User, error) {
+	user, err := u.userRepo.GetUserByUsername(ctx, username)
+	if err != nil {
+		if errors.Is(err, repoerrors.ErrNotFound) {
+			return nil, apperrors.ErrUserNotFound
+		}
+
+		return nil, err
+	}
+
+	if !user.IsService {
+		return nil, apperrors.ErrNotServiceAccount
+	}
+
+	return user, nil
+}
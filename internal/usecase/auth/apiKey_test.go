@@ -0,0 +1,146 @@
+package auth
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/resueman/merch-store/internal/entity"
+	"github.com/resueman/merch-store/internal/model"
+	"github.com/resueman/merch-store/internal/repo/repoerrors"
+	"github.com/resueman/merch-store/internal/usecase/apperrors"
+	"github.com/resueman/merch-store/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthUsecase_CreateAPIKey_StoresOnlyHash(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userRepo := mocks.NewMockUser(ctrl)
+	apiKeyRepo := mocks.NewMockAPIKey(ctrl)
+
+	userRepo.EXPECT().
+		GetUserByUsername(gomock.Any(), "bot").
+		Return(&entity.User{ID: 5, Username: "bot", Role: "user", IsService: true}, nil)
+
+	var stored *entity.CreateAPIKeyInput
+	apiKeyRepo.EXPECT().
+		CreateAPIKey(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, input *entity.CreateAPIKeyInput) (int, error) {
+			stored = input
+
+			return 3, nil
+		})
+
+	uc := NewAuthUsecase(userRepo, nil, nil, nil, nil, apiKeyRepo, nil, nil, nil, nil, testConfig)
+	input := model.CreateAPIKeyInput{Username: "bot", Name: "ci", Scopes: []model.Scope{model.ScopeCoinsSend}}
+
+	key, err := uc.CreateAPIKey(context.Background(), model.Claims{UserID: 1}, input)
+	require.NoError(t, err)
+	require.Equal(t, 3, key.ID)
+	require.True(t, strings.HasPrefix(key.Key, apiKeyMarker))
+
+	require.Equal(t, 5, stored.UserID)
+	require.Equal(t, 1, stored.CreatedBy)
+	require.Equal(t, "coins:send", stored.Scopes)
+	require.Equal(t, hashToken(key.Key), stored.KeyHash)
+	require.NotContains(t, stored.KeyHash, key.Key)
+	require.True(t, strings.HasPrefix(key.Key, stored.Prefix))
+}
+
+func TestAuthUsecase_CreateAPIKey_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		user     *entity.User
+		userErr  error
+		scopes   []model.Scope
+		expected error
+	}{
+		{
+			name:     "regular user",
+			user:     &entity.User{ID: 2, Username: "bob", Role: "user"},
+			expected: apperrors.ErrNotServiceAccount,
+		},
+		{
+			name:     "unknown user",
+			userErr:  repoerrors.ErrNotFound,
+			expected: apperrors.ErrUserNotFound,
+		},
+		{
+			name:     "scope above role",
+			user:     &entity.User{ID: 5, Username: "bot", Role: "user", IsService: true},
+			scopes:   []model.Scope{model.ScopeAdmin},
+			expected: apperrors.ErrInvalidScope,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			userRepo := mocks.NewMockUser(ctrl)
+			userRepo.EXPECT().GetUserByUsername(gomock.Any(), gomock.Any()).Return(tt.user, tt.userErr)
+
+			uc := NewAuthUsecase(userRepo, nil, nil, nil, nil, mocks.NewMockAPIKey(ctrl), nil, nil, nil, nil,
+				testConfig)
+			input := model.CreateAPIKeyInput{Username: "bot", Name: "ci", Scopes: tt.scopes}
+
+			_, err := uc.CreateAPIKey(context.Background(), model.Claims{UserID: 1}, input)
+			require.ErrorIs(t, err, tt.expected)
+		})
+	}
+}
+
+func TestAuthUsecase_AuthenticateAPIKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	apiKeyRepo := mocks.NewMockAPIKey(ctrl)
+	uc := NewAuthUsecase(nil, nil, nil, nil, nil, apiKeyRepo, nil, nil, nil, nil, testConfig)
+
+	t.Run("scopes are limited by current role", func(t *testing.T) {
+		apiKeyRepo.EXPECT().
+			GetAPIKeyByHash(gomock.Any(), hashToken("msk_demoted")).
+			Return(&entity.APIKey{ID: 3, UserID: 5, Role: "user", Scopes: "admin coins:send"}, nil)
+		apiKeyRepo.EXPECT().TouchAPIKey(gomock.Any(), 3, gomock.Any()).Return(nil)
+
+		claims, err := uc.AuthenticateAPIKey(context.Background(), "msk_demoted")
+		require.NoError(t, err)
+		require.Equal(t, 5, claims.UserID)
+		require.Equal(t, 3, claims.APIKeyID)
+		require.Equal(t, []model.Scope{model.ScopeCoinsSend}, claims.Scopes)
+	})
+
+	t.Run("recently used key is not touched", func(t *testing.T) {
+		lastUsed := time.Now().Add(-time.Second)
+		apiKeyRepo.EXPECT().
+			GetAPIKeyByHash(gomock.Any(), hashToken("msk_recent")).
+			Return(&entity.APIKey{ID: 4, UserID: 5, Role: "user", Scopes: "info:read", LastUsedAt: &lastUsed}, nil)
+
+		_, err := uc.AuthenticateAPIKey(context.Background(), "msk_recent")
+		require.NoError(t, err)
+	})
+
+	t.Run("revoked key", func(t *testing.T) {
+		revokedAt := time.Now().Add(-time.Hour)
+		apiKeyRepo.EXPECT().
+			GetAPIKeyByHash(gomock.Any(), hashToken("msk_revoked")).
+			Return(&entity.APIKey{ID: 5, UserID: 5, Role: "user", RevokedAt: &revokedAt}, nil)
+
+		_, err := uc.AuthenticateAPIKey(context.Background(), "msk_revoked")
+		require.ErrorIs(t, err, apperrors.ErrInvalidAPIKey)
+	})
+
+	t.Run("unknown key", func(t *testing.T) {
+		apiKeyRepo.EXPECT().
+			GetAPIKeyByHash(gomock.Any(), hashToken("msk_unknown")).
+			Return(nil, repoerrors.ErrNotFound)
+
+		_, err := uc.AuthenticateAPIKey(context.Background(), "msk_unknown")
+		require.ErrorIs(t, err, apperrors.ErrInvalidAPIKey)
+	})
+}
@@ -2,79 +2,267 @@ package auth
 
 import (
 	"context"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/gommon/log"
 	"github.com/pkg/errors"
 	"github.com/resueman/merch-store/internal/entity"
 	"github.com/resueman/merch-store/internal/model"
 	"github.com/resueman/merch-store/internal/repo"
 	"github.com/resueman/merch-store/internal/repo/repoerrors"
 	"github.com/resueman/merch-store/internal/usecase/apperrors"
+	"github.com/resueman/merch-store/pkg/db"
+	"github.com/resueman/merch-store/pkg/jwtkeys"
+	"github.com/resueman/merch-store/pkg/password"
+	"github.com/resueman/merch-store/pkg/username"
 )
 
+type Config struct {
+	// Секрет HS256, используется, если не задан набор ключей KeySet.
+	SecretKey            string
+	KeySet               KeySet
+	AccessTokenTTL       time.Duration
+	RefreshTokenTTL      time.Duration
+	DenylistSyncInterval time.Duration
+	AutoRegister         bool
+	LoginThrottle        LoginThrottleConfig
+	PasswordPolicy       password.Policy
+	PasswordResetTTL     time.Duration
+	TOTPIssuer           string
+	// Пользователь, на счет которого переводится остаток при деактивации.
+	TreasuryUsername string
+	// Имена, которые нельзя занять при регистрации. Сравниваются после нормализации.
+	ReservedUsernames []string
+}
+
 type authUsecase struct {
-	userRepo        repo.User
-	secretKey       string
-	tokenTTL        time.Duration
-	passwordManager PasswordManager
+	userRepo          repo.User
+	refreshTokenRepo  repo.RefreshToken
+	denylistRepo      repo.Denylist
+	passwordManager   PasswordManager
+	txManager         db.TxManager
+	denylist          *denylist
+	keys              KeySet
+	tokenTTL          time.Duration
+	refreshTokenTTL   time.Duration
+	autoRegister      bool
+	loginAttemptRepo  repo.LoginAttempt
+	loginThrottle     LoginThrottleConfig
+	passwordResetRepo repo.PasswordReset
+	apiKeyRepo        repo.APIKey
+	passwordPolicy    password.Policy
+	passwordResetTTL  time.Duration
+	totpRepo          repo.TOTP
+	totpIssuer        string
+	balanceSweeper    BalanceSweeper
+	treasuryUsername  string
+	reservedUsernames map[string]struct{}
 }
 
-func NewAuthUsecase(userRepo repo.User, passwordManager PasswordManager,
-	secretKey string, tokenTTL time.Duration) *authUsecase {
+func NewAuthUsecase(userRepo repo.User, refreshTokenRepo repo.RefreshToken, denylistRepo repo.Denylist,
+	loginAttemptRepo repo.LoginAttempt, passwordResetRepo repo.PasswordReset, apiKeyRepo repo.APIKey,
+	totpRepo repo.TOTP, balanceSweeper BalanceSweeper, passwordManager PasswordManager, txManager db.TxManager,
+	cfg Config,
+) *authUsecase {
+	keys := cfg.KeySet
+	if keys == nil {
+		keys = jwtkeys.NewHMACKeySet(cfg.SecretKey)
+	}
+
+	reserved := make(map[string]struct{}, len(cfg.ReservedUsernames))
+	for _, name := range cfg.ReservedUsernames {
+		reserved[username.Normalize(name)] = struct{}{}
+	}
+
 	return &authUsecase{
-		userRepo:        userRepo,
-		passwordManager: passwordManager,
-		secretKey:       secretKey,
-		tokenTTL:        tokenTTL,
+		userRepo:          userRepo,
+		refreshTokenRepo:  refreshTokenRepo,
+		denylistRepo:      denylistRepo,
+		passwordManager:   passwordManager,
+		txManager:         txManager,
+		denylist:          newDenylist(denylistRepo, cfg.DenylistSyncInterval, cfg.AccessTokenTTL),
+		keys:              keys,
+		tokenTTL:          cfg.AccessTokenTTL,
+		refreshTokenTTL:   cfg.RefreshTokenTTL,
+		autoRegister:      cfg.AutoRegister,
+		loginAttemptRepo:  loginAttemptRepo,
+		loginThrottle:     cfg.LoginThrottle,
+		passwordResetRepo: passwordResetRepo,
+		apiKeyRepo:        apiKeyRepo,
+		passwordPolicy:    cfg.PasswordPolicy,
+		passwordResetTTL:  cfg.PasswordResetTTL,
+		totpRepo:          totpRepo,
+		totpIssuer:        cfg.TOTPIssuer,
+		balanceSweeper:    balanceSweeper,
+		treasuryUsername:  cfg.TreasuryUsername,
+		reservedUsernames: reserved,
 	}
 }
 
 type PasswordManager interface {
-	HashPassword(password string) string
+	HashPassword(password string) (string, error)
 	ComparePassword(password, hash string) bool
+	NeedsRehash(hash string) bool
+}
+
+// KeySet подписывает access-токены и выбирает ключ для проверки подписи по заголовку kid.
+type KeySet interface {
+	Sign(claims jwt.Claims) (string, error)
+	Keyfunc(token *jwt.Token) (interface{}, error)
+	PublicKeys() []jwtkeys.PublicKey
 }
 
 type tokenClaims struct {
 	jwt.RegisteredClaims
-	UserID int
+	UserID       int
+	TokenVersion int    `json:"ver"`
+	Role         string `json:"role"`
+	Scope        string `json:"scope"`
 }
 
-var emptyClaims = model.Claims{}
+const tokenIDBytes = 16
+
+var (
+	emptyClaims = model.Claims{}
+	emptyTokens = model.AuthTokens{}
+)
+
+func (u *authUsecase) GenerateToken(ctx context.Context, input model.AuthRequestInput) (model.AuthTokens, error) {
+	attemptKeys := loginAttemptKeys(input)
+	if err := u.checkLoginAllowed(ctx, attemptKeys); err != nil {
+		return emptyTokens, err
+	}
 
-func (u *authUsecase) GenerateToken(ctx context.Context, input model.AuthRequestInput) (string, error) {
 	user, err := u.userRepo.GetUserByUsername(ctx, input.Username)
 	if err == nil {
 		if !u.passwordManager.ComparePassword(input.Password, user.Hash) {
-			return "", apperrors.ErrInvalidPassword
+			if err = u.recordLoginFailure(ctx, attemptKeys); err != nil {
+				return emptyTokens, err
+			}
+
+			return emptyTokens, apperrors.ErrInvalidPassword
+		}
+
+		// Статус проверяется только после пароля, чтобы не раскрывать его подбирающему пароль.
+		if user.DeactivatedAt != nil {
+			return emptyTokens, apperrors.ErrUserDeactivated
+		}
+
+		if err = u.checkLoginSecondFactor(ctx, user, attemptKeys, input.OTPCode); err != nil {
+			return emptyTokens, err
+		}
+
+		if err = u.loginAttemptRepo.ResetLoginFailures(ctx, attemptKeys[0]); err != nil {
+			return emptyTokens, err
+		}
+
+		if u.passwordManager.NeedsRehash(user.Hash) {
+			u.rehashPassword(ctx, user, input.Password)
+		}
+
+		claims := model.Claims{UserID: user.ID, TokenVersion: user.TokenVersion, Role: model.Role(user.Role)}
+		if claims.Scopes, err = grantScopes(claims.Role, input.Scopes); err != nil {
+			return emptyTokens, err
 		}
 
-		return u.generateToken(model.Claims{UserID: user.ID})
+		return u.issueTokens(ctx, claims, "")
 	}
 
 	if !errors.Is(err, repoerrors.ErrNotFound) {
-		return "", err
+		return emptyTokens, err
+	}
+
+	// Без автоматической регистрации опечатка в имени пользователя
+	// не должна молча создавать новый аккаунт.
+	if !u.autoRegister {
+		if err = u.recordLoginFailure(ctx, attemptKeys); err != nil {
+			return emptyTokens, err
+		}
+
+		return emptyTokens, apperrors.ErrUserNotRegistered
+	}
+
+	return u.Register(ctx, input)
+}
+
+// Явная регистрация нового пользователя с выдачей пары токенов.
+func (u *authUsecase) Register(ctx context.Context, input model.AuthRequestInput) (model.AuthTokens, error) {
+	if err := u.checkUsernameAvailable(input.Username); err != nil {
+		return emptyTokens, err
+	}
+
+	scopes, err := grantScopes(model.RoleUser, input.Scopes)
+	if err != nil {
+		return emptyTokens, err
 	}
 
 	userID, err := u.registerUser(ctx, input)
 	if err != nil {
-		return "", err
+		return emptyTokens, err
 	}
 
-	return u.generateToken(model.Claims{UserID: userID})
+	return u.issueTokens(ctx, model.Claims{UserID: userID, Role: model.RoleUser, Scopes: scopes}, "")
+}
+
+// Проверяет, что запрошенные области доступа разрешены роли. Если области не запрошены,
+// выдаются все доступные роли. Так можно получить ограниченный токен, например только для чтения.
+func grantScopes(role model.Role, requested []model.Scope) ([]model.Scope, error) {
+	allowed := model.ScopesForRole(role)
+	if len(requested) == 0 {
+		return allowed, nil
+	}
+
+	scopes := make([]model.Scope, 0, len(requested))
+	for _, s := range requested {
+		if !slices.Contains(allowed, s) {
+			return nil, apperrors.ErrInvalidScope
+		}
+
+		if !slices.Contains(scopes, s) {
+			scopes = append(scopes, s)
+		}
+	}
+
+	return scopes, nil
+}
+
+// Выпускает пару access и refresh токенов. Пустой familyID означает новую сессию,
+// иначе refresh-токен продолжает цепочку ротации существующей сессии.
+func (u *authUsecase) issueTokens(ctx context.Context, claims model.Claims, familyID string) (model.AuthTokens, error) {
+	accessToken, err := u.generateToken(claims)
+	if err != nil {
+		return emptyTokens, err
+	}
+
+	refreshToken, err := u.issueRefreshToken(ctx, claims, familyID)
+	if err != nil {
+		return emptyTokens, err
+	}
+
+	return model.AuthTokens{AccessToken: accessToken, RefreshToken: refreshToken}, nil
 }
 
 func (u *authUsecase) generateToken(claims model.Claims) (string, error) {
+	tokenID, err := randomString(tokenIDBytes)
+	if err != nil {
+		return "", apperrors.ErrGenerateToken
+	}
+
 	now := time.Now()
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, tokenClaims{
+	tokenString, err := u.keys.Sign(tokenClaims{
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenID,
 			ExpiresAt: jwt.NewNumericDate(now.Add(u.tokenTTL)),
 			IssuedAt:  jwt.NewNumericDate(now),
 		},
-		UserID: claims.UserID,
+		UserID:       claims.UserID,
+		TokenVersion: claims.TokenVersion,
+		Role:         string(claims.Role),
+		Scope:        model.FormatScopes(claims.Scopes),
 	})
-
-	tokenString, err := token.SignedString([]byte(u.secretKey))
 	if err != nil {
 		return "", apperrors.ErrGenerateToken
 	}
@@ -82,24 +270,61 @@ func (u *authUsecase) generateToken(claims model.Claims) (string, error) {
 	return tokenString, nil
 }
 
+// Перехеширует пароль текущим алгоритмом, пока он известен в открытом виде.
+// Ошибка не должна мешать входу: хеш обновится при следующей попытке.
+func (u *authUsecase) rehashPassword(ctx context.Context, user *entity.User, password string) {
+	hash, err := u.passwordManager.HashPassword(password)
+	if err != nil {
+		log.Warnf("failed to rehash password of user %d: %v", user.ID, err)
+		return
+	}
+
+	input := &entity.UpdatePasswordHashInput{UserID: user.ID, OldHash: user.Hash, NewHash: hash}
+	if err := u.userRepo.UpdatePasswordHash(ctx, input); err != nil {
+		log.Warnf("failed to save rehashed password of user %d: %v", user.ID, err)
+	}
+}
+
 func (u *authUsecase) registerUser(ctx context.Context, input model.AuthRequestInput) (int, error) {
+	hash, err := u.passwordManager.HashPassword(input.Password)
+	if err != nil {
+		return 0, err
+	}
+
 	newUser := &entity.CreateUserInput{
-		Username: input.Username,
-		Hash:     u.passwordManager.HashPassword(input.Password),
+		Username: strings.TrimSpace(input.Username),
+		Hash:     hash,
 	}
 
 	userID, err := u.userRepo.CreateUser(ctx, newUser)
 	if err != nil {
+		if errors.Is(err, repoerrors.ErrAlreadyExists) {
+			return 0, apperrors.ErrUserAlreadyExists
+		}
+
 		return 0, err
 	}
 
 	return userID, nil
 }
 
+// Зарезервированные имена вроде "admin" или "system" не дают выдать себя за администрацию.
+// Сервисные аккаунты создает администратор, для них ограничение не действует.
+func (u *authUsecase) checkUsernameAvailable(name string) error {
+	normalized := username.Normalize(name)
+	if normalized == "" {
+		return apperrors.ErrInvalidUsername
+	}
+
+	if _, ok := u.reservedUsernames[normalized]; ok {
+		return apperrors.ErrReservedUsername
+	}
+
+	return nil
+}
+
 func (u *authUsecase) ParseToken(ctx context.Context, tokenString string) (model.Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &tokenClaims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(u.secretKey), nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, &tokenClaims{}, u.keys.Keyfunc)
 
 	if validationErr, ok := err.(*jwt.ValidationError); ok {
 		if validationErr.Errors == jwt.ValidationErrorExpired {
@@ -111,10 +336,45 @@ func (u *authUsecase) ParseToken(ctx context.Context, tokenString string) (model
 		return emptyClaims, apperrors.ErrInvalidToken
 	}
 
-	claims, ok := token.Claims.(*tokenClaims)
-	if !ok || !token.Valid {
+	parsed, ok := token.Claims.(*tokenClaims)
+	if !ok || !token.Valid || parsed.ExpiresAt == nil {
 		return emptyClaims, apperrors.ErrInvalidToken
 	}
 
-	return model.Claims{UserID: claims.UserID}, nil
+	claims := model.Claims{
+		UserID:       parsed.UserID,
+		TokenID:      parsed.ID,
+		TokenVersion: parsed.TokenVersion,
+		Role:         model.Role(parsed.Role),
+		Scopes:       model.ParseScopes(parsed.Scope),
+		ExpiresAt:    parsed.ExpiresAt.Time,
+	}
+
+	// Токены, выпущенные до появления областей доступа, дают все области своей роли.
+	if len(claims.Scopes) == 0 {
+		claims.Scopes = model.ScopesForRole(claims.Role)
+	}
+
+	revoked, err := u.denylist.isRevoked(ctx, claims)
+	if err != nil {
+		return emptyClaims, err
+	}
+
+	if revoked {
+		return emptyClaims, apperrors.ErrTokenRevoked
+	}
+
+	return claims, nil
+}
+
+// Открытые ключи проверки подписи access-токенов для публикации в JWKS.
+func (u *authUsecase) PublicKeys() []model.PublicKey {
+	keys := u.keys.PublicKeys()
+
+	result := make([]model.PublicKey, 0, len(keys))
+	for _, k := range keys {
+		result = append(result, model.PublicKey{ID: k.ID, Algorithm: k.Algorithm, Key: k.Key})
+	}
+
+	return result
 }
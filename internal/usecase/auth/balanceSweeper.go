@@ -0,0 +1,9 @@
+package auth
+
+import "context"
+
+// BalanceSweeper переводит весь остаток пользователя на счет получателя записанной операцией перевода.
+// Возвращает переведенную сумму. Вызывается внутри транзакции деактивации.
+type BalanceSweeper interface {
+	SweepBalance(ctx context.Context, userID int, recipientUsername string) (int, error)
+}
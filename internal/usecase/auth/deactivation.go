@@ -0,0 +1,94 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/resueman/merch-store/internal/model"
+	"github.com/resueman/merch-store/internal/repo/repoerrors"
+	"github.com/resueman/merch-store/internal/usecase/apperrors"
+	"github.com/resueman/merch-store/pkg/db"
+)
+
+// Деактивирует пользователя, например уволившегося сотрудника: он больше не может войти
+// и получать переводы, все его сессии завершаются, а API-ключи перестают приниматься.
+// По запросу остаток монет переводится на казначейский счет. Возвращает переведенную сумму.
+func (u *authUsecase) DeactivateUser(ctx context.Context, claims model.Claims,
+	input model.DeactivateUserInput,
+) (int, error) {
+	user, err := u.userRepo.GetUserByUsername(ctx, input.Username)
+	if err != nil {
+		if errors.Is(err, repoerrors.ErrNotFound) {
+			return 0, apperrors.ErrUserNotFound
+		}
+
+		return 0, err
+	}
+
+	// Иначе администратор может случайно заблокировать сам себя.
+	if user.ID == claims.UserID {
+		return 0, apperrors.ErrSelfDeactivation
+	}
+
+	if input.SweepBalance && u.treasuryUsername == "" {
+		return 0, apperrors.ErrTreasuryNotConfigured
+	}
+
+	version, swept := 0, 0
+	transaction := func(ctx context.Context) error {
+		if err := u.userRepo.DeactivateUser(ctx, user.ID); err != nil {
+			return err
+		}
+
+		var err error
+		if version, err = u.revokeUserSessionsTx(ctx, user.ID); err != nil {
+			return err
+		}
+
+		if !input.SweepBalance {
+			return nil
+		}
+
+		swept, err = u.balanceSweeper.SweepBalance(ctx, user.ID, u.treasuryUsername)
+
+		return err
+	}
+
+	// Деактивация и перевод остатка коммитятся вместе: монеты не останутся на счете,
+	// который уже не может ими распорядиться, и не уйдут с еще активного.
+	serializable := u.txManager.Serializable(ctx, db.Write, transaction)
+	if err = u.txManager.WithRetry(serializable); err != nil {
+		return 0, err
+	}
+
+	u.denylist.revokeUserTokens(user.ID, version)
+
+	return swept, nil
+}
+
+// Возвращает доступ деактивированному пользователю. Прежние сессии не восстанавливаются,
+// пользователь входит заново, а его API-ключи снова начинают приниматься.
+func (u *authUsecase) ReactivateUser(ctx context.Context, username string) error {
+	user, err := u.userRepo.GetUserByUsername(ctx, username)
+	if err != nil {
+		if errors.Is(err, repoerrors.ErrNotFound) {
+			return apperrors.ErrUserNotFound
+		}
+
+		return err
+	}
+
+	if user.DeactivatedAt == nil {
+		return nil
+	}
+
+	if err = u.userRepo.ReactivateUser(ctx, user.ID); err != nil {
+		if errors.Is(err, repoerrors.ErrNotFound) {
+			return apperrors.ErrUserNotFound
+		}
+
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,131 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/resueman/merch-store/internal/entity"
+	"github.com/resueman/merch-store/internal/model"
+	"github.com/resueman/merch-store/internal/usecase/apperrors"
+	"github.com/resueman/merch-store/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthUsecase_DeactivateUser_SweepsBalance(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userRepo := mocks.NewMockUser(ctrl)
+	refreshTokenRepo := mocks.NewMockRefreshToken(ctrl)
+	balanceSweeper := mocks.NewMockBalanceSweeper(ctrl)
+	txManager := mocks.NewMockTxManager(ctrl)
+
+	userRepo.EXPECT().
+		GetUserByUsername(gomock.Any(), "bob").
+		Return(&entity.User{ID: 2, Username: "bob", Role: "user"}, nil)
+
+	serializableTxMock(txManager)
+
+	userRepo.EXPECT().DeactivateUser(gomock.Any(), 2).Return(nil)
+	userRepo.EXPECT().IncrementTokenVersion(gomock.Any(), 2).Return(3, nil)
+	refreshTokenRepo.EXPECT().RevokeUserRefreshTokens(gomock.Any(), 2).Return(nil)
+	balanceSweeper.EXPECT().SweepBalance(gomock.Any(), 2, "treasury").Return(750, nil)
+
+	cfg := testConfig
+	cfg.TreasuryUsername = "treasury"
+
+	uc := NewAuthUsecase(userRepo, refreshTokenRepo, nil, nil, nil, nil, nil, balanceSweeper, nil, txManager, cfg)
+	input := model.DeactivateUserInput{Username: "bob", SweepBalance: true}
+
+	swept, err := uc.DeactivateUser(context.Background(), model.Claims{UserID: 1}, input)
+	require.NoError(t, err)
+	require.Equal(t, 750, swept)
+
+	// Уже выданные access-токены отклоняются сразу, не дожидаясь синхронизации denylist.
+	uc.denylist.syncedAt = time.Now()
+	revoked, err := uc.denylist.isRevoked(context.Background(), model.Claims{UserID: 2, TokenVersion: 2})
+	require.NoError(t, err)
+	require.True(t, revoked)
+}
+
+func TestAuthUsecase_DeactivateUser_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    model.DeactivateUserInput
+		treasury string
+		wantErr  error
+	}{
+		{
+			name:    "Self deactivation",
+			input:   model.DeactivateUserInput{Username: "alice"},
+			wantErr: apperrors.ErrSelfDeactivation,
+		},
+		{
+			name:    "Treasury not configured",
+			input:   model.DeactivateUserInput{Username: "bob", SweepBalance: true},
+			wantErr: apperrors.ErrTreasuryNotConfigured,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			userRepo := mocks.NewMockUser(ctrl)
+			userRepo.EXPECT().
+				GetUserByUsername(gomock.Any(), tt.input.Username).
+				DoAndReturn(func(_ context.Context, username string) (*entity.User, error) {
+					if username == "alice" {
+						return &entity.User{ID: 1, Username: "alice", Role: "admin"}, nil
+					}
+
+					return &entity.User{ID: 2, Username: username, Role: "user"}, nil
+				})
+
+			uc := NewAuthUsecase(userRepo, nil, nil, nil, nil, nil, nil, nil, nil, nil, testConfig)
+
+			_, err := uc.DeactivateUser(context.Background(), model.Claims{UserID: 1}, tt.input)
+			require.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestAuthUsecase_GenerateToken_DeactivatedUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	deactivatedAt := time.Now().Add(-time.Hour)
+	userRepo := mocks.NewMockUser(ctrl)
+	passwordManager := mocks.NewMockPasswordManager(ctrl)
+
+	userRepo.EXPECT().
+		GetUserByUsername(gomock.Any(), "bob").
+		Return(&entity.User{ID: 2, Username: "bob", Hash: "hash", Role: "user", DeactivatedAt: &deactivatedAt}, nil)
+	passwordManager.EXPECT().ComparePassword("password", "hash").Return(true)
+
+	uc := NewAuthUsecase(userRepo, nil, nil, noLoginLocksMock(ctrl), nil, nil, nil, nil, passwordManager, nil,
+		testConfig)
+	input := model.AuthRequestInput{Username: "bob", Password: "password"}
+
+	_, err := uc.GenerateToken(context.Background(), input)
+	require.ErrorIs(t, err, apperrors.ErrUserDeactivated)
+}
+
+func TestAuthUsecase_ReactivateUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	deactivatedAt := time.Now().Add(-time.Hour)
+	userRepo := mocks.NewMockUser(ctrl)
+
+	userRepo.EXPECT().
+		GetUserByUsername(gomock.Any(), "bob").
+		Return(&entity.User{ID: 2, Username: "bob", Role: "user", DeactivatedAt: &deactivatedAt}, nil)
+	userRepo.EXPECT().ReactivateUser(gomock.Any(), 2).Return(nil)
+
+	uc := NewAuthUsecase(userRepo, nil, nil, nil, nil, nil, nil, nil, nil, nil, testConfig)
+	require.NoError(t, uc.ReactivateUser(context.Background(), "bob"))
+}
@@ -0,0 +1,133 @@
+package auth
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/resueman/merch-store/internal/model"
+	"github.com/resueman/merch-store/internal/repo"
+)
+
+// Кеш отозванных токенов в памяти процесса. Источник истины — Postgres,
+// кеш перечитывается не чаще раза в syncInterval, поэтому проверка токена
+// не добавляет обращения к БД на каждый запрос. Отзыв, сделанный на другом
+// инстансе, становится виден здесь с задержкой не более syncInterval.
+type denylist struct {
+	repo         repo.Denylist
+	syncInterval time.Duration
+	tokenTTL     time.Duration
+
+	syncMu   sync.Mutex
+	mu       sync.RWMutex
+	syncedAt time.Time
+	tokens   map[string]time.Time // jti -> время истечения токена
+	versions map[int]int          // userID -> минимальная действительная версия токенов
+}
+
+func newDenylist(repo repo.Denylist, syncInterval, tokenTTL time.Duration) *denylist {
+	return &denylist{
+		repo:         repo,
+		syncInterval: syncInterval,
+		tokenTTL:     tokenTTL,
+		tokens:       map[string]time.Time{},
+		versions:     map[int]int{},
+	}
+}
+
+func (d *denylist) isRevoked(ctx context.Context, claims model.Claims) (bool, error) {
+	if err := d.syncIfStale(ctx); err != nil {
+		return false, err
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if _, ok := d.tokens[claims.TokenID]; ok {
+		return true, nil
+	}
+
+	if version, ok := d.versions[claims.UserID]; ok && claims.TokenVersion < version {
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// Отзыв сначала записывается в БД, а затем в кеш. Блокировка syncMu не дает
+// параллельной синхронизации, прочитавшей БД до записи, затереть его.
+func (d *denylist) revokeToken(tokenID string, expiresAt time.Time) {
+	d.syncMu.Lock()
+	defer d.syncMu.Unlock()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.tokens[tokenID] = expiresAt
+}
+
+func (d *denylist) revokeUserTokens(userID, version int) {
+	d.syncMu.Lock()
+	defer d.syncMu.Unlock()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.versions[userID] < version {
+		d.versions[userID] = version
+	}
+}
+
+func (d *denylist) syncIfStale(ctx context.Context) error {
+	if d.isFresh() {
+		return nil
+	}
+
+	d.syncMu.Lock()
+	defer d.syncMu.Unlock()
+
+	// Пока ждали блокировку, кеш мог обновить другой запрос.
+	if d.isFresh() {
+		return nil
+	}
+
+	now := time.Now()
+
+	revokedTokens, err := d.repo.GetRevokedTokens(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Токены, выпущенные раньше чем tokenTTL назад, уже истекли,
+	// поэтому более старые отзывы по версии можно не загружать.
+	tokenVersions, err := d.repo.GetTokenVersions(ctx, now.Add(-d.tokenTTL))
+	if err != nil {
+		return err
+	}
+
+	tokens := make(map[string]time.Time, len(revokedTokens))
+	for _, token := range revokedTokens {
+		tokens[token.TokenID] = token.ExpiresAt
+	}
+
+	versions := make(map[int]int, len(tokenVersions))
+	for _, version := range tokenVersions {
+		versions[version.UserID] = version.Version
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.tokens = tokens
+	d.versions = versions
+	d.syncedAt = now
+
+	return nil
+}
+
+func (d *denylist) isFresh() bool {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	return time.Since(d.syncedAt) < d.syncInterval
+}
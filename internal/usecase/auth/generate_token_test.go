@@ -15,11 +15,20 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+var testConfig = Config{
+	SecretKey:            "secret",
+	AccessTokenTTL:       time.Minute * 15,
+	RefreshTokenTTL:      time.Hour,
+	DenylistSyncInterval: time.Minute,
+	AutoRegister:         true,
+}
+
 func TestAuthUsecase_GenerateTokenWithRegistration_Ok(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	userRepo := mocks.NewMockUser(ctrl)
+	refreshTokenRepo := mocks.NewMockRefreshToken(ctrl)
 	passwordManager := mocks.NewMockPasswordManager(ctrl)
 
 	authRequestInput := model.AuthRequestInput{Username: "test", Password: "password"}
@@ -31,7 +40,7 @@ func TestAuthUsecase_GenerateTokenWithRegistration_Ok(t *testing.T) {
 
 		passwordManager.EXPECT().
 			HashPassword(authRequestInput.Password).
-			Return(hash)
+			Return(hash, nil)
 
 		userRepo.EXPECT().
 			CreateUser(gomock.Any(), &entity.CreateUserInput{
@@ -39,16 +48,27 @@ func TestAuthUsecase_GenerateTokenWithRegistration_Ok(t *testing.T) {
 				Hash:     hash,
 			}).
 			Return(userID, nil)
+
+		refreshTokenRepo.EXPECT().
+			CreateRefreshToken(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, input *entity.CreateRefreshTokenInput) error {
+				require.Equal(t, userID, input.UserID)
+				require.NotEmpty(t, input.FamilyID)
+				require.NotEmpty(t, input.TokenHash)
+
+				return nil
+			})
 	}
 
 	mock()
 
-	secretKey, tokenTTL := "secret", time.Minute*15
-	authUsecase := NewAuthUsecase(userRepo, passwordManager, secretKey, tokenTTL)
-	token, err := authUsecase.GenerateToken(context.Background(), authRequestInput)
+	authUsecase := NewAuthUsecase(userRepo, refreshTokenRepo, nil, noLoginLocksMock(ctrl), nil, nil, nil, nil,
+		passwordManager, nil, testConfig)
+	tokens, err := authUsecase.GenerateToken(context.Background(), authRequestInput)
 
 	require.NoError(t, err)
-	require.NotEmpty(t, token)
+	require.NotEmpty(t, tokens.AccessToken)
+	require.NotEmpty(t, tokens.RefreshToken)
 }
 
 func TestAuthUsecase_GenerateTokenWithRegistration_ErrorRegisterUser(t *testing.T) {
@@ -56,6 +76,7 @@ func TestAuthUsecase_GenerateTokenWithRegistration_ErrorRegisterUser(t *testing.
 	defer ctrl.Finish()
 
 	userRepo := mocks.NewMockUser(ctrl)
+	refreshTokenRepo := mocks.NewMockRefreshToken(ctrl)
 	passwordManager := mocks.NewMockPasswordManager(ctrl)
 
 	registerUserErr := errors.New("error")
@@ -68,7 +89,7 @@ func TestAuthUsecase_GenerateTokenWithRegistration_ErrorRegisterUser(t *testing.
 
 		passwordManager.EXPECT().
 			HashPassword(authRequestInput.Password).
-			Return(hash)
+			Return(hash, nil)
 
 		userRepo.EXPECT().
 			CreateUser(gomock.Any(), &entity.CreateUserInput{
@@ -80,7 +101,8 @@ func TestAuthUsecase_GenerateTokenWithRegistration_ErrorRegisterUser(t *testing.
 
 	mock()
 
-	authUsecase := NewAuthUsecase(userRepo, passwordManager, "secret", time.Minute*15)
+	authUsecase := NewAuthUsecase(userRepo, refreshTokenRepo, nil, noLoginLocksMock(ctrl), nil, nil, nil, nil,
+		passwordManager, nil, testConfig)
 	_, err := authUsecase.GenerateToken(context.Background(), authRequestInput)
 
 	require.ErrorIs(t, err, registerUserErr)
@@ -91,12 +113,11 @@ func TestAuthUsecase_GenerateTokenForExistingUser_Ok(t *testing.T) {
 	defer ctrl.Finish()
 
 	userRepo := mocks.NewMockUser(ctrl)
+	refreshTokenRepo := mocks.NewMockRefreshToken(ctrl)
 	passwordManager := mocks.NewMockPasswordManager(ctrl)
 
-	secretKey := "secret"
-	tokenTTL := time.Minute * 15
-
-	authUsecase := NewAuthUsecase(userRepo, passwordManager, secretKey, tokenTTL)
+	authUsecase := NewAuthUsecase(userRepo, refreshTokenRepo, nil, noLoginLocksMock(ctrl), nil, nil, nil, nil,
+		passwordManager, nil, testConfig)
 
 	userRepo.EXPECT().
 		GetUserByUsername(gomock.Any(), gomock.Any()).
@@ -106,13 +127,22 @@ func TestAuthUsecase_GenerateTokenForExistingUser_Ok(t *testing.T) {
 		ComparePassword(gomock.Any(), gomock.Any()).
 		Return(true)
 
-	token, err := authUsecase.GenerateToken(context.Background(), model.AuthRequestInput{
+	passwordManager.EXPECT().
+		NeedsRehash("hash").
+		Return(false)
+
+	refreshTokenRepo.EXPECT().
+		CreateRefreshToken(gomock.Any(), gomock.Any()).
+		Return(nil)
+
+	tokens, err := authUsecase.GenerateToken(context.Background(), model.AuthRequestInput{
 		Username: "test",
 		Password: "password",
 	})
 
 	require.NoError(t, err)
-	require.NotEmpty(t, token)
+	require.NotEmpty(t, tokens.AccessToken)
+	require.NotEmpty(t, tokens.RefreshToken)
 }
 
 func TestAuthUsecase_GenerateTokenForExistingUser_IncorrectPasswordError(t *testing.T) {
@@ -120,6 +150,7 @@ func TestAuthUsecase_GenerateTokenForExistingUser_IncorrectPasswordError(t *test
 	defer ctrl.Finish()
 
 	userRepo := mocks.NewMockUser(ctrl)
+	refreshTokenRepo := mocks.NewMockRefreshToken(ctrl)
 	passwordManager := mocks.NewMockPasswordManager(ctrl)
 
 	authRequestInput := model.AuthRequestInput{
@@ -139,8 +170,8 @@ func TestAuthUsecase_GenerateTokenForExistingUser_IncorrectPasswordError(t *test
 
 	mock()
 
-	secretKey, tokenTTL := "secret", time.Minute*15
-	authUsecase := NewAuthUsecase(userRepo, passwordManager, secretKey, tokenTTL)
+	authUsecase := NewAuthUsecase(userRepo, refreshTokenRepo, nil, noLoginLocksMock(ctrl), nil, nil, nil, nil,
+		passwordManager, nil, testConfig)
 	_, err := authUsecase.GenerateToken(context.Background(), authRequestInput)
 
 	require.ErrorIs(t, err, apperrors.ErrInvalidPassword)
@@ -151,6 +182,7 @@ func TestAuthUsecase_GenerateToken_ErrorGettingUser(t *testing.T) {
 	defer ctrl.Finish()
 
 	userRepo := mocks.NewMockUser(ctrl)
+	refreshTokenRepo := mocks.NewMockRefreshToken(ctrl)
 	passwordManager := mocks.NewMockPasswordManager(ctrl)
 
 	authRequestInput := model.AuthRequestInput{
@@ -167,8 +199,243 @@ func TestAuthUsecase_GenerateToken_ErrorGettingUser(t *testing.T) {
 
 	mock()
 
-	authUsecase := NewAuthUsecase(userRepo, passwordManager, "secret", time.Minute*15)
+	authUsecase := NewAuthUsecase(userRepo, refreshTokenRepo, nil, noLoginLocksMock(ctrl), nil, nil, nil, nil,
+		passwordManager, nil, testConfig)
 	_, err := authUsecase.GenerateToken(context.Background(), authRequestInput)
 
 	require.ErrorIs(t, err, errorGettingUser)
 }
+
+func TestAuthUsecase_GenerateToken_AutoRegisterDisabled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userRepo := mocks.NewMockUser(ctrl)
+	refreshTokenRepo := mocks.NewMockRefreshToken(ctrl)
+	passwordManager := mocks.NewMockPasswordManager(ctrl)
+
+	authRequestInput := model.AuthRequestInput{Username: "typo", Password: "password"}
+	userRepo.EXPECT().
+		GetUserByUsername(gomock.Any(), authRequestInput.Username).
+		Return(nil, repoerrors.ErrNotFound)
+
+	cfg := testConfig
+	cfg.AutoRegister = false
+
+	authUsecase := NewAuthUsecase(userRepo, refreshTokenRepo, nil, noLoginLocksMock(ctrl), nil, nil, nil, nil,
+		passwordManager, nil, cfg)
+	_, err := authUsecase.GenerateToken(context.Background(), authRequestInput)
+
+	require.ErrorIs(t, err, apperrors.ErrUserNotRegistered)
+}
+
+func TestAuthUsecase_Register_Ok(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userRepo := mocks.NewMockUser(ctrl)
+	refreshTokenRepo := mocks.NewMockRefreshToken(ctrl)
+	passwordManager := mocks.NewMockPasswordManager(ctrl)
+
+	authRequestInput := model.AuthRequestInput{Username: "test", Password: "password"}
+	mock := func() {
+		passwordManager.EXPECT().
+			HashPassword(authRequestInput.Password).
+			Return("hash", nil)
+
+		userRepo.EXPECT().
+			CreateUser(gomock.Any(), &entity.CreateUserInput{Username: authRequestInput.Username, Hash: "hash"}).
+			Return(1, nil)
+
+		refreshTokenRepo.EXPECT().
+			CreateRefreshToken(gomock.Any(), gomock.Any()).
+			Return(nil)
+	}
+
+	mock()
+
+	cfg := testConfig
+	cfg.AutoRegister = false
+
+	authUsecase := NewAuthUsecase(userRepo, refreshTokenRepo, nil, noLoginLocksMock(ctrl), nil, nil, nil, nil,
+		passwordManager, nil, cfg)
+	tokens, err := authUsecase.Register(context.Background(), authRequestInput)
+
+	require.NoError(t, err)
+	require.NotEmpty(t, tokens.AccessToken)
+	require.NotEmpty(t, tokens.RefreshToken)
+}
+
+func TestAuthUsecase_Register_AlreadyExists(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userRepo := mocks.NewMockUser(ctrl)
+	refreshTokenRepo := mocks.NewMockRefreshToken(ctrl)
+	passwordManager := mocks.NewMockPasswordManager(ctrl)
+
+	authRequestInput := model.AuthRequestInput{Username: "test", Password: "password"}
+	mock := func() {
+		passwordManager.EXPECT().
+			HashPassword(authRequestInput.Password).
+			Return("hash", nil)
+
+		userRepo.EXPECT().
+			CreateUser(gomock.Any(), gomock.Any()).
+			Return(0, repoerrors.ErrAlreadyExists)
+	}
+
+	mock()
+
+	authUsecase := NewAuthUsecase(userRepo, refreshTokenRepo, nil, noLoginLocksMock(ctrl), nil, nil, nil, nil,
+		passwordManager, nil, testConfig)
+	_, err := authUsecase.Register(context.Background(), authRequestInput)
+
+	require.ErrorIs(t, err, apperrors.ErrUserAlreadyExists)
+}
+
+func TestAuthUsecase_GenerateToken_RehashOutdatedPassword(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userRepo := mocks.NewMockUser(ctrl)
+	refreshTokenRepo := mocks.NewMockRefreshToken(ctrl)
+	passwordManager := mocks.NewMockPasswordManager(ctrl)
+
+	authRequestInput := model.AuthRequestInput{Username: "test", Password: "password"}
+	mock := func() {
+		userRepo.EXPECT().
+			GetUserByUsername(gomock.Any(), authRequestInput.Username).
+			Return(&entity.User{ID: 1, Username: "test", Hash: "old"}, nil)
+
+		passwordManager.EXPECT().
+			ComparePassword(authRequestInput.Password, "old").
+			Return(true)
+
+		passwordManager.EXPECT().
+			NeedsRehash("old").
+			Return(true)
+
+		passwordManager.EXPECT().
+			HashPassword(authRequestInput.Password).
+			Return("new", nil)
+
+		userRepo.EXPECT().
+			UpdatePasswordHash(gomock.Any(), &entity.UpdatePasswordHashInput{UserID: 1, OldHash: "old", NewHash: "new"}).
+			Return(errors.New("error"))
+
+		refreshTokenRepo.EXPECT().
+			CreateRefreshToken(gomock.Any(), gomock.Any()).
+			Return(nil)
+	}
+
+	mock()
+
+	authUsecase := NewAuthUsecase(userRepo, refreshTokenRepo, nil, noLoginLocksMock(ctrl), nil, nil, nil, nil,
+		passwordManager, nil, testConfig)
+	tokens, err := authUsecase.GenerateToken(context.Background(), authRequestInput)
+
+	require.NoError(t, err)
+	require.NotEmpty(t, tokens.AccessToken)
+}
+
+func TestAuthUsecase_GenerateToken_LimitedScopes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userRepo := mocks.NewMockUser(ctrl)
+	refreshTokenRepo := mocks.NewMockRefreshToken(ctrl)
+	denylistRepo := mocks.NewMockDenylist(ctrl)
+	passwordManager := mocks.NewMockPasswordManager(ctrl)
+
+	authRequestInput := model.AuthRequestInput{
+		Username: "test",
+		Password: "password",
+		Scopes:   []model.Scope{model.ScopeInfoRead},
+	}
+
+	mock := func() {
+		userRepo.EXPECT().
+			GetUserByUsername(gomock.Any(), authRequestInput.Username).
+			Return(&entity.User{ID: 1, Username: "test", Hash: "hash", Role: "user"}, nil)
+
+		passwordManager.EXPECT().ComparePassword(gomock.Any(), gomock.Any()).Return(true)
+		passwordManager.EXPECT().NeedsRehash(gomock.Any()).Return(false)
+
+		refreshTokenRepo.EXPECT().
+			CreateRefreshToken(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, input *entity.CreateRefreshTokenInput) error {
+				require.Equal(t, "info:read", input.Scopes)
+
+				return nil
+			})
+
+		emptyDenylistMock(denylistRepo)
+	}
+
+	mock()
+
+	authUsecase := NewAuthUsecase(userRepo, refreshTokenRepo, denylistRepo, noLoginLocksMock(ctrl), nil, nil, nil, nil,
+		passwordManager, nil, testConfig)
+	tokens, err := authUsecase.GenerateToken(context.Background(), authRequestInput)
+	require.NoError(t, err)
+
+	claims, err := authUsecase.ParseToken(context.Background(), tokens.AccessToken)
+	require.NoError(t, err)
+	require.Equal(t, []model.Scope{model.ScopeInfoRead}, claims.Scopes)
+	require.False(t, claims.HasScopes(model.ScopeCoinsSend))
+}
+
+func TestAuthUsecase_GenerateToken_ScopeNotAllowedForRole(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userRepo := mocks.NewMockUser(ctrl)
+	passwordManager := mocks.NewMockPasswordManager(ctrl)
+
+	authRequestInput := model.AuthRequestInput{
+		Username: "test",
+		Password: "password",
+		Scopes:   []model.Scope{model.ScopeAdmin},
+	}
+
+	userRepo.EXPECT().
+		GetUserByUsername(gomock.Any(), authRequestInput.Username).
+		Return(&entity.User{ID: 1, Username: "test", Hash: "hash", Role: "user"}, nil)
+
+	passwordManager.EXPECT().ComparePassword(gomock.Any(), gomock.Any()).Return(true)
+	passwordManager.EXPECT().NeedsRehash(gomock.Any()).Return(false)
+
+	authUsecase := NewAuthUsecase(userRepo, nil, nil, noLoginLocksMock(ctrl), nil, nil, nil, nil, passwordManager, nil,
+		testConfig)
+	_, err := authUsecase.GenerateToken(context.Background(), authRequestInput)
+
+	require.ErrorIs(t, err, apperrors.ErrInvalidScope)
+}
+
+func TestAuthUsecase_Register_UnavailableUsername(t *testing.T) {
+	tests := []struct {
+		name     string
+		username string
+		wantErr  error
+	}{
+		{name: "reserved", username: "admin", wantErr: apperrors.ErrReservedUsername},
+		{name: "reserved in other case", username: "System", wantErr: apperrors.ErrReservedUsername},
+		{name: "reserved in fullwidth form", username: "ａｄｍｉｎ", wantErr: apperrors.ErrReservedUsername},
+		{name: "blank", username: "   ", wantErr: apperrors.ErrInvalidUsername},
+	}
+
+	cfg := testConfig
+	cfg.ReservedUsernames = []string{"Admin", "system"}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := NewAuthUsecase(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, cfg)
+
+			input := model.AuthRequestInput{Username: tt.username, Password: "password"}
+
+			_, err := uc.Register(context.Background(), input)
+			require.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/resueman/merch-store/internal/entity"
+	"github.com/resueman/merch-store/internal/model"
+	"github.com/resueman/merch-store/internal/usecase/apperrors"
+	"github.com/resueman/merch-store/pkg/username"
+)
+
+// LoginThrottleConfig задает защиту от перебора паролей. После FreeAttempts неудачных попыток
+// каждая следующая блокирует вход на BackoffBase, 2*BackoffBase, 4*BackoffBase и т.д., но не больше MaxBackoff.
+// После LockoutThreshold неудачных попыток вход блокируется на LockoutDuration.
+// Для IP-адресов пороги выше, так как за одним адресом может быть весь офис.
+type LoginThrottleConfig struct {
+	FreeAttempts       int
+	LockoutThreshold   int
+	IPFreeAttempts     int
+	IPLockoutThreshold int
+	BackoffBase        time.Duration
+	MaxBackoff         time.Duration
+	LockoutDuration    time.Duration
+	// Счетчик неудачных попыток обнуляется, если их не было дольше FailureWindow.
+	FailureWindow time.Duration
+}
+
+func (c LoginThrottleConfig) lockDuration(keyType string, failures int) time.Duration {
+	free, threshold := c.FreeAttempts, c.LockoutThreshold
+	if keyType == entity.LoginAttemptKeyIP {
+		free, threshold = c.IPFreeAttempts, c.IPLockoutThreshold
+	}
+
+	if threshold > 0 && failures >= threshold {
+		return c.LockoutDuration
+	}
+
+	if failures <= free || c.BackoffBase <= 0 {
+		return 0
+	}
+
+	backoff := c.BackoffBase
+	for i := free + 1; i < failures && backoff < c.MaxBackoff; i++ {
+		backoff *= 2
+	}
+
+	return min(backoff, c.MaxBackoff)
+}
+
+// Первым всегда идет ключ имени пользователя: только его счетчик сбрасывается при успешном входе,
+// иначе злоумышленник со своим аккаунтом мог бы обнулять счетчик своего IP-адреса.
+func loginAttemptKeys(input model.AuthRequestInput) []entity.LoginAttemptKey {
+	keys := []entity.LoginAttemptKey{{KeyType: entity.LoginAttemptKeyUsername, Key: username.Normalize(input.Username)}}
+	if input.ClientIP != "" {
+		keys = append(keys, entity.LoginAttemptKey{KeyType: entity.LoginAttemptKeyIP, Key: input.ClientIP})
+	}
+
+	return keys
+}
+
+// Проверка выполняется до сравнения пароля, чтобы заблокированные попытки не нагружали CPU хешированием.
+func (u *authUsecase) checkLoginAllowed(ctx context.Context, keys []entity.LoginAttemptKey) error {
+	lockedUntil, err := u.loginAttemptRepo.GetLoginLockedUntil(ctx, keys)
+	if err != nil {
+		return err
+	}
+
+	if lockedUntil != nil && lockedUntil.After(time.Now()) {
+		return apperrors.ErrTooManyLoginAttempts
+	}
+
+	return nil
+}
+
+func (u *authUsecase) recordLoginFailure(ctx context.Context, keys []entity.LoginAttemptKey) error {
+	now := time.Now()
+	for _, key := range keys {
+		failure := &entity.LoginFailure{
+			LoginAttemptKey: key,
+			FailedAt:        now,
+			ResetBefore:     now.Add(-u.loginThrottle.FailureWindow),
+		}
+
+		failures, err := u.loginAttemptRepo.RecordLoginFailure(ctx, failure)
+		if err != nil {
+			return err
+		}
+
+		if d := u.loginThrottle.lockDuration(key.KeyType, failures); d > 0 {
+			if err = u.loginAttemptRepo.LockLogin(ctx, key, now.Add(d)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Снимает блокировку входа пользователя и обнуляет счетчик неудачных попыток.
+func (u *authUsecase) UnlockUser(ctx context.Context, name string) error {
+	key := entity.LoginAttemptKey{KeyType: entity.LoginAttemptKeyUsername, Key: username.Normalize(name)}
+
+	return u.loginAttemptRepo.ResetLoginFailures(ctx, key)
+}
@@ -0,0 +1,149 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/resueman/merch-store/internal/entity"
+	"github.com/resueman/merch-store/internal/model"
+	"github.com/resueman/merch-store/internal/usecase/apperrors"
+	"github.com/resueman/merch-store/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+var testLoginThrottle = LoginThrottleConfig{
+	FreeAttempts:       3,
+	LockoutThreshold:   10,
+	IPFreeAttempts:     20,
+	IPLockoutThreshold: 200,
+	BackoffBase:        time.Second,
+	MaxBackoff:         time.Minute,
+	LockoutDuration:    15 * time.Minute,
+	FailureWindow:      time.Hour,
+}
+
+// Для тестов, которые не проверяют защиту от перебора: блокировок нет, неудачи только учитываются.
+func noLoginLocksMock(ctrl *gomock.Controller) *mocks.MockLoginAttempt {
+	loginAttemptRepo := mocks.NewMockLoginAttempt(ctrl)
+	loginAttemptRepo.EXPECT().GetLoginLockedUntil(gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+	loginAttemptRepo.EXPECT().RecordLoginFailure(gomock.Any(), gomock.Any()).Return(1, nil).AnyTimes()
+	loginAttemptRepo.EXPECT().ResetLoginFailures(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	return loginAttemptRepo
+}
+
+func TestLoginThrottleConfig_LockDuration(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		keyType  string
+		failures int
+		expected time.Duration
+	}{
+		{"free attempts", entity.LoginAttemptKeyUsername, 3, 0},
+		{"first backoff", entity.LoginAttemptKeyUsername, 4, time.Second},
+		{"exponential backoff", entity.LoginAttemptKeyUsername, 6, 4 * time.Second},
+		{"lockout", entity.LoginAttemptKeyUsername, 10, 15 * time.Minute},
+		{"ip free attempts", entity.LoginAttemptKeyIP, 10, 0},
+		{"ip backoff capped", entity.LoginAttemptKeyIP, 150, time.Minute},
+		{"ip lockout", entity.LoginAttemptKeyIP, 200, 15 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tt.expected, testLoginThrottle.lockDuration(tt.keyType, tt.failures))
+		})
+	}
+}
+
+func TestAuthUsecase_GenerateToken_LockedOut(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	loginAttemptRepo := mocks.NewMockLoginAttempt(ctrl)
+	input := model.AuthRequestInput{Username: "test", Password: "password", ClientIP: "10.0.0.1"}
+
+	lockedUntil := time.Now().Add(time.Minute)
+	loginAttemptRepo.EXPECT().
+		GetLoginLockedUntil(gomock.Any(), []entity.LoginAttemptKey{
+			{KeyType: entity.LoginAttemptKeyUsername, Key: "test"},
+			{KeyType: entity.LoginAttemptKeyIP, Key: "10.0.0.1"},
+		}).
+		Return(&lockedUntil, nil)
+
+	// Пароль не проверяется, пока вход заблокирован.
+	uc := NewAuthUsecase(nil, nil, nil, loginAttemptRepo, nil, nil, nil, nil, nil, nil, testConfig)
+	_, err := uc.GenerateToken(context.Background(), input)
+
+	require.ErrorIs(t, err, apperrors.ErrTooManyLoginAttempts)
+}
+
+func TestAuthUsecase_GenerateToken_FailedAttemptLocks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userRepo := mocks.NewMockUser(ctrl)
+	loginAttemptRepo := mocks.NewMockLoginAttempt(ctrl)
+	passwordManager := mocks.NewMockPasswordManager(ctrl)
+
+	usernameKey := entity.LoginAttemptKey{KeyType: entity.LoginAttemptKeyUsername, Key: "test"}
+	ipKey := entity.LoginAttemptKey{KeyType: entity.LoginAttemptKeyIP, Key: "10.0.0.1"}
+	input := model.AuthRequestInput{Username: "test", Password: "wrong", ClientIP: "10.0.0.1"}
+
+	loginAttemptRepo.EXPECT().GetLoginLockedUntil(gomock.Any(), gomock.Any()).Return(nil, nil)
+	userRepo.EXPECT().
+		GetUserByUsername(gomock.Any(), "test").
+		Return(&entity.User{ID: 1, Username: "test", Hash: "hash"}, nil)
+	passwordManager.EXPECT().ComparePassword("wrong", "hash").Return(false)
+
+	loginAttemptRepo.EXPECT().
+		RecordLoginFailure(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, failure *entity.LoginFailure) (int, error) {
+			require.Equal(t, usernameKey, failure.LoginAttemptKey)
+			require.Equal(t, failure.FailedAt.Add(-time.Hour), failure.ResetBefore)
+
+			return 10, nil
+		})
+	loginAttemptRepo.EXPECT().
+		LockLogin(gomock.Any(), usernameKey, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ entity.LoginAttemptKey, until time.Time) error {
+			require.WithinDuration(t, time.Now().Add(15*time.Minute), until, time.Second)
+
+			return nil
+		})
+	// Счетчик IP ниже порога, поэтому IP не блокируется.
+	loginAttemptRepo.EXPECT().
+		RecordLoginFailure(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, failure *entity.LoginFailure) (int, error) {
+			require.Equal(t, ipKey, failure.LoginAttemptKey)
+
+			return 5, nil
+		})
+
+	cfg := testConfig
+	cfg.LoginThrottle = testLoginThrottle
+
+	uc := NewAuthUsecase(userRepo, nil, nil, loginAttemptRepo, nil, nil, nil, nil, passwordManager, nil, cfg)
+	_, err := uc.GenerateToken(context.Background(), input)
+
+	require.ErrorIs(t, err, apperrors.ErrInvalidPassword)
+}
+
+func TestAuthUsecase_UnlockUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	loginAttemptRepo := mocks.NewMockLoginAttempt(ctrl)
+	loginAttemptRepo.EXPECT().
+		ResetLoginFailures(gomock.Any(), entity.LoginAttemptKey{KeyType: entity.LoginAttemptKeyUsername, Key: "test"}).
+		Return(nil)
+
+	uc := NewAuthUsecase(nil, nil, nil, loginAttemptRepo, nil, nil, nil, nil, nil, nil, testConfig)
+
+	// Блокировка хранится под нормализованным именем, поэтому регистр не важен.
+	require.NoError(t, uc.UnlockUser(context.Background(), "Test"))
+}
@@ -0,0 +1,88 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/resueman/merch-store/internal/entity"
+	"github.com/resueman/merch-store/internal/model"
+	"github.com/resueman/merch-store/internal/repo/repoerrors"
+	"github.com/resueman/merch-store/internal/usecase/apperrors"
+	"github.com/resueman/merch-store/pkg/db"
+)
+
+// Завершает текущую сессию: access-токен попадает в denylist до истечения срока действия,
+// а если передан refresh-токен этой сессии, отзывается все его семейство.
+func (u *authUsecase) Logout(ctx context.Context, claims model.Claims, refreshToken string) error {
+	if claims.TokenID == "" {
+		return apperrors.ErrInvalidToken
+	}
+
+	if refreshToken != "" {
+		stored, err := u.refreshTokenRepo.GetRefreshTokenByHash(ctx, hashToken(refreshToken))
+		if err != nil && !errors.Is(err, repoerrors.ErrNotFound) {
+			return err
+		}
+
+		// Чужой или неизвестный refresh-токен просто игнорируем, чтобы не раскрывать его существование.
+		if err == nil && stored.UserID == claims.UserID {
+			if err = u.refreshTokenRepo.RevokeRefreshTokenFamily(ctx, stored.FamilyID); err != nil {
+				return err
+			}
+		}
+	}
+
+	revoked := &entity.RevokedToken{
+		TokenID:   claims.TokenID,
+		UserID:    claims.UserID,
+		ExpiresAt: claims.ExpiresAt,
+	}
+
+	if err := u.denylistRepo.RevokeToken(ctx, revoked); err != nil {
+		return err
+	}
+
+	u.denylist.revokeToken(revoked.TokenID, revoked.ExpiresAt)
+
+	return nil
+}
+
+// Завершает все сессии пользователя: увеличивает версию его токенов,
+// делая недействительными все выданные access-токены, и отзывает все refresh-токены.
+func (u *authUsecase) LogoutAll(ctx context.Context, claims model.Claims) error {
+	return u.revokeUserSessions(ctx, claims.UserID)
+}
+
+func (u *authUsecase) revokeUserSessions(ctx context.Context, userID int) error {
+	version := 0
+	transaction := func(ctx context.Context) error {
+		var err error
+		version, err = u.revokeUserSessionsTx(ctx, userID)
+
+		return err
+	}
+
+	readCommitted := u.txManager.ReadCommitted(ctx, db.Write, transaction)
+	if err := u.txManager.WithRetry(readCommitted); err != nil {
+		return err
+	}
+
+	u.denylist.revokeUserTokens(userID, version)
+
+	return nil
+}
+
+// Отзывает сессии внутри уже открытой транзакции и возвращает новую версию токенов пользователя.
+// Локальный denylist вызывающий обновляет сам, когда транзакция закоммичена.
+func (u *authUsecase) revokeUserSessionsTx(ctx context.Context, userID int) (int, error) {
+	version, err := u.userRepo.IncrementTokenVersion(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	if err = u.refreshTokenRepo.RevokeUserRefreshTokens(ctx, userID); err != nil {
+		return 0, err
+	}
+
+	return version, nil
+}
@@ -0,0 +1,107 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/resueman/merch-store/internal/entity"
+	"github.com/resueman/merch-store/internal/model"
+	"github.com/resueman/merch-store/internal/usecase/apperrors"
+	"github.com/resueman/merch-store/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthUsecase_Logout_Ok(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	refreshTokenRepo := mocks.NewMockRefreshToken(ctrl)
+	denylistRepo := mocks.NewMockDenylist(ctrl)
+
+	claims := model.Claims{UserID: 1, TokenID: "jti", ExpiresAt: time.Now().Add(time.Hour)}
+
+	refreshTokenRepo.EXPECT().
+		GetRefreshTokenByHash(gomock.Any(), hashToken("refresh")).
+		Return(&entity.RefreshToken{ID: 5, UserID: 1, FamilyID: "family"}, nil)
+
+	refreshTokenRepo.EXPECT().
+		RevokeRefreshTokenFamily(gomock.Any(), "family").
+		Return(nil)
+
+	denylistRepo.EXPECT().
+		RevokeToken(gomock.Any(), &entity.RevokedToken{TokenID: "jti", UserID: 1, ExpiresAt: claims.ExpiresAt}).
+		Return(nil)
+
+	uc := NewAuthUsecase(nil, refreshTokenRepo, denylistRepo, nil, nil, nil, nil, nil, nil, nil, testConfig)
+	require.NoError(t, uc.Logout(context.Background(), claims, "refresh"))
+
+	// Токен отозван локально и отклоняется сразу, не дожидаясь синхронизации с БД.
+	uc.denylist.syncedAt = time.Now()
+
+	revoked, err := uc.denylist.isRevoked(context.Background(), claims)
+	require.NoError(t, err)
+	require.True(t, revoked)
+}
+
+func TestAuthUsecase_Logout_ForeignRefreshTokenIgnored(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	refreshTokenRepo := mocks.NewMockRefreshToken(ctrl)
+	denylistRepo := mocks.NewMockDenylist(ctrl)
+
+	claims := model.Claims{UserID: 1, TokenID: "jti", ExpiresAt: time.Now().Add(time.Hour)}
+
+	refreshTokenRepo.EXPECT().
+		GetRefreshTokenByHash(gomock.Any(), gomock.Any()).
+		Return(&entity.RefreshToken{ID: 5, UserID: 2, FamilyID: "family"}, nil)
+
+	denylistRepo.EXPECT().
+		RevokeToken(gomock.Any(), gomock.Any()).
+		Return(nil)
+
+	uc := NewAuthUsecase(nil, refreshTokenRepo, denylistRepo, nil, nil, nil, nil, nil, nil, nil, testConfig)
+	require.NoError(t, uc.Logout(context.Background(), claims, "refresh"))
+}
+
+func TestAuthUsecase_Logout_TokenWithoutID(t *testing.T) {
+	uc := NewAuthUsecase(nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, testConfig)
+	err := uc.Logout(context.Background(), model.Claims{UserID: 1}, "")
+
+	require.ErrorIs(t, err, apperrors.ErrInvalidToken)
+}
+
+func TestAuthUsecase_LogoutAll_Ok(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userRepo := mocks.NewMockUser(ctrl)
+	refreshTokenRepo := mocks.NewMockRefreshToken(ctrl)
+	denylistRepo := mocks.NewMockDenylist(ctrl)
+	txManager := mocks.NewMockTxManager(ctrl)
+
+	readCommittedTxMock(txManager)
+
+	userRepo.EXPECT().
+		IncrementTokenVersion(gomock.Any(), 1).
+		Return(3, nil)
+
+	refreshTokenRepo.EXPECT().
+		RevokeUserRefreshTokens(gomock.Any(), 1).
+		Return(nil)
+
+	uc := NewAuthUsecase(userRepo, refreshTokenRepo, denylistRepo, nil, nil, nil, nil, nil, nil, txManager, testConfig)
+	require.NoError(t, uc.LogoutAll(context.Background(), model.Claims{UserID: 1, TokenVersion: 2}))
+
+	uc.denylist.syncedAt = time.Now()
+
+	revoked, err := uc.denylist.isRevoked(context.Background(), model.Claims{UserID: 1, TokenVersion: 2})
+	require.NoError(t, err)
+	require.True(t, revoked)
+
+	revoked, err = uc.denylist.isRevoked(context.Background(), model.Claims{UserID: 1, TokenVersion: 3})
+	require.NoError(t, err)
+	require.False(t, revoked)
+}
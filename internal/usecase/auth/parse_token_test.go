@@ -2,10 +2,17 @@ package auth
 
 import (
 	"context"
+	"crypto/ed25519"
 	"testing"
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/golang/mock/gomock"
+	"github.com/resueman/merch-store/internal/entity"
+	"github.com/resueman/merch-store/internal/model"
+	"github.com/resueman/merch-store/internal/usecase/apperrors"
+	"github.com/resueman/merch-store/pkg/jwtkeys"
+	"github.com/resueman/merch-store/test/mocks"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -54,7 +61,14 @@ func TestParseToken(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			uc := NewAuthUsecase(nil, nil, secretKey, time.Hour)
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			denylistRepo := mocks.NewMockDenylist(ctrl)
+			emptyDenylistMock(denylistRepo)
+
+			cfg := Config{SecretKey: secretKey, AccessTokenTTL: time.Hour, DenylistSyncInterval: time.Minute}
+			uc := NewAuthUsecase(nil, nil, denylistRepo, nil, nil, nil, nil, nil, nil, nil, cfg)
 
 			claims, err := uc.ParseToken(context.Background(), tt.tokenString)
 
@@ -68,6 +82,75 @@ func TestParseToken(t *testing.T) {
 	}
 }
 
+func TestParseToken_Revoked(t *testing.T) {
+	secretKey := "secret"
+	regClaims := func(tokenID string) jwt.RegisteredClaims {
+		return jwt.RegisteredClaims{
+			ID:        tokenID,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		}
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	denylistRepo := mocks.NewMockDenylist(ctrl)
+	denylistRepo.EXPECT().
+		GetRevokedTokens(gomock.Any()).
+		Return([]entity.RevokedToken{{TokenID: "revoked", UserID: 1}}, nil)
+
+	denylistRepo.EXPECT().
+		GetTokenVersions(gomock.Any(), gomock.Any()).
+		Return([]entity.TokenVersion{{UserID: 2, Version: 1}}, nil)
+
+	cfg := Config{SecretKey: secretKey, AccessTokenTTL: time.Hour, DenylistSyncInterval: time.Minute}
+	uc := NewAuthUsecase(nil, nil, denylistRepo, nil, nil, nil, nil, nil, nil, nil, cfg)
+
+	_, err := uc.ParseToken(context.Background(), createTestToken(t, []byte(secretKey), 1, regClaims("revoked")))
+	assert.ErrorIs(t, err, apperrors.ErrTokenRevoked)
+
+	_, err = uc.ParseToken(context.Background(), createTestToken(t, []byte(secretKey), 2, regClaims("old-version")))
+	assert.ErrorIs(t, err, apperrors.ErrTokenRevoked)
+
+	claims, err := uc.ParseToken(context.Background(), createTestToken(t, []byte(secretKey), 1, regClaims("active")))
+	assert.NoError(t, err)
+	assert.Equal(t, "active", claims.TokenID)
+}
+
+func TestParseToken_WithoutScopeGetsRoleScopes(t *testing.T) {
+	secretKey := "secret"
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	denylistRepo := mocks.NewMockDenylist(ctrl)
+	emptyDenylistMock(denylistRepo)
+
+	cfg := Config{SecretKey: secretKey, AccessTokenTTL: time.Hour, DenylistSyncInterval: time.Minute}
+	uc := NewAuthUsecase(nil, nil, denylistRepo, nil, nil, nil, nil, nil, nil, nil, cfg)
+
+	tokenString := createTestToken(t, []byte(secretKey), 1, jwt.RegisteredClaims{
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	})
+
+	claims, err := uc.ParseToken(context.Background(), tokenString)
+	assert.NoError(t, err)
+	assert.Equal(t, model.ScopesForRole(model.RoleUser), claims.Scopes)
+}
+
+func emptyDenylistMock(denylistRepo *mocks.MockDenylist) {
+	denylistRepo.EXPECT().
+		GetRevokedTokens(gomock.Any()).
+		Return([]entity.RevokedToken{}, nil).
+		AnyTimes()
+
+	denylistRepo.EXPECT().
+		GetTokenVersions(gomock.Any(), gomock.Any()).
+		Return([]entity.TokenVersion{}, nil).
+		AnyTimes()
+}
+
 func createTestToken(t *testing.T,
 	secretKey []byte,
 	userID int,
@@ -83,3 +166,40 @@ func createTestToken(t *testing.T,
 
 	return tokenString
 }
+
+func TestParseToken_AsymmetricKeySet(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	denylistRepo := mocks.NewMockDenylist(ctrl)
+	emptyDenylistMock(denylistRepo)
+
+	public, private, err := ed25519.GenerateKey(nil)
+	assert.NoError(t, err)
+
+	keySet, err := jwtkeys.NewKeySet("ed", jwtkeys.Key{
+		ID:         "ed",
+		Method:     jwt.SigningMethodEdDSA,
+		PrivateKey: private,
+		PublicKey:  public,
+	})
+	assert.NoError(t, err)
+
+	cfg := Config{KeySet: keySet, AccessTokenTTL: time.Hour, DenylistSyncInterval: time.Minute}
+	uc := NewAuthUsecase(nil, nil, denylistRepo, nil, nil, nil, nil, nil, nil, nil, cfg)
+
+	tokenString, err := uc.generateToken(model.Claims{UserID: 7, Role: model.RoleUser})
+	assert.NoError(t, err)
+
+	claims, err := uc.ParseToken(context.Background(), tokenString)
+	assert.NoError(t, err)
+	assert.Equal(t, 7, claims.UserID)
+
+	// Токен, подписанный общим секретом, больше не принимается.
+	hmacToken := createTestToken(t, []byte("secret"), 7, jwt.RegisteredClaims{
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	})
+
+	_, err = uc.ParseToken(context.Background(), hmacToken)
+	assert.ErrorIs(t, err, apperrors.ErrInvalidToken)
+}
@@ -0,0 +1,199 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/resueman/merch-store/internal/entity"
+	"github.com/resueman/merch-store/internal/model"
+	"github.com/resueman/merch-store/internal/repo/repoerrors"
+	"github.com/resueman/merch-store/internal/usecase/apperrors"
+	"github.com/resueman/merch-store/pkg/db"
+)
+
+const passwordResetTokenBytes = 32
+
+// Меняет пароль пользователя после проверки старого. Все сессии пользователя, включая текущую,
+// отзываются, а вместо текущей выдается новая пара токенов с теми же областями доступа.
+// Неверный старый пароль учитывается так же, как неудачная попытка входа.
+func (u *authUsecase) ChangePassword(ctx context.Context, claims model.Claims,
+	input model.ChangePasswordInput,
+) (model.AuthTokens, error) {
+	user, err := u.userRepo.GetUserByID(ctx, claims.UserID)
+	if err != nil {
+		if errors.Is(err, repoerrors.ErrNotFound) {
+			return emptyTokens, apperrors.ErrUserNotFound
+		}
+
+		return emptyTokens, err
+	}
+
+	attemptKeys := loginAttemptKeys(model.AuthRequestInput{Username: user.Username})
+	if err = u.checkLoginAllowed(ctx, attemptKeys); err != nil {
+		return emptyTokens, err
+	}
+
+	if !u.passwordManager.ComparePassword(input.OldPassword, user.Hash) {
+		if err = u.recordLoginFailure(ctx, attemptKeys); err != nil {
+			return emptyTokens, err
+		}
+
+		return emptyTokens, apperrors.ErrInvalidPassword
+	}
+
+	if input.NewPassword == input.OldPassword {
+		return emptyTokens, apperrors.ErrSamePassword
+	}
+
+	hash, err := u.newPasswordHash(user.Username, input.NewPassword)
+	if err != nil {
+		return emptyTokens, err
+	}
+
+	version := 0
+	transaction := func(ctx context.Context) error {
+		// Хеш сравнивается с прочитанным, чтобы параллельная смена пароля не была молча перезаписана.
+		input := &entity.UpdatePasswordHashInput{UserID: user.ID, OldHash: user.Hash, NewHash: hash}
+		if err := u.userRepo.UpdatePasswordHash(ctx, input); err != nil {
+			if errors.Is(err, repoerrors.ErrNotFound) {
+				return apperrors.ErrInvalidPassword
+			}
+
+			return err
+		}
+
+		version, err = u.revokeUserSessionsTx(ctx, user.ID)
+
+		return err
+	}
+
+	readCommitted := u.txManager.ReadCommitted(ctx, db.Write, transaction)
+	if err = u.txManager.WithRetry(readCommitted); err != nil {
+		return emptyTokens, err
+	}
+
+	u.denylist.revokeUserTokens(user.ID, version)
+
+	if err = u.loginAttemptRepo.ResetLoginFailures(ctx, attemptKeys[0]); err != nil {
+		return emptyTokens, err
+	}
+
+	newClaims := model.Claims{UserID: user.ID, TokenVersion: version, Role: model.Role(user.Role), Scopes: claims.Scopes}
+
+	return u.issueTokens(ctx, newClaims, "")
+}
+
+// Выдает одноразовый токен сброса пароля, который администратор передает пользователю.
+// Ранее выданные и еще не использованные токены пользователя перестают действовать.
+func (u *authUsecase) CreatePasswordReset(ctx context.Context, claims model.Claims,
+	username string,
+) (model.PasswordResetToken, error) {
+	user, err := u.userRepo.GetUserByUsername(ctx, username)
+	if err != nil {
+		if errors.Is(err, repoerrors.ErrNotFound) {
+			return model.PasswordResetToken{}, apperrors.ErrUserNotFound
+		}
+
+		return model.PasswordResetToken{}, err
+	}
+
+	// Иначе сброс пароля позволил бы входить в сервисный аккаунт по паролю.
+	if user.IsService {
+		return model.PasswordResetToken{}, apperrors.ErrServiceAccount
+	}
+
+	token, err := randomString(passwordResetTokenBytes)
+	if err != nil {
+		return model.PasswordResetToken{}, apperrors.ErrGenerateToken
+	}
+
+	input := &entity.CreatePasswordResetTokenInput{
+		UserID:    user.ID,
+		CreatedBy: claims.UserID,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(u.passwordResetTTL),
+	}
+
+	transaction := func(ctx context.Context) error {
+		if err := u.passwordResetRepo.DeleteUnusedPasswordResetTokens(ctx, user.ID); err != nil {
+			return err
+		}
+
+		return u.passwordResetRepo.CreatePasswordResetToken(ctx, input)
+	}
+
+	readCommitted := u.txManager.ReadCommitted(ctx, db.Write, transaction)
+	if err = u.txManager.WithRetry(readCommitted); err != nil {
+		return model.PasswordResetToken{}, err
+	}
+
+	return model.PasswordResetToken{Token: token, ExpiresAt: input.ExpiresAt}, nil
+}
+
+// Устанавливает новый пароль по токену сброса. Токен погашается, все сессии пользователя отзываются,
+// а блокировка входа после неудачных попыток снимается.
+func (u *authUsecase) ResetPassword(ctx context.Context, input model.ResetPasswordInput) error {
+	tokenHash := hashToken(input.ResetToken)
+
+	userID, username, version := 0, "", 0
+	transaction := func(ctx context.Context) error {
+		stored, err := u.passwordResetRepo.GetPasswordResetTokenByHash(ctx, tokenHash)
+		if err != nil {
+			if errors.Is(err, repoerrors.ErrNotFound) {
+				return apperrors.ErrInvalidResetToken
+			}
+
+			return err
+		}
+
+		if stored.UsedAt != nil {
+			return apperrors.ErrInvalidResetToken
+		}
+
+		if !stored.ExpiresAt.After(time.Now()) {
+			return apperrors.ErrResetTokenExpired
+		}
+
+		user, err := u.userRepo.GetUserByID(ctx, stored.UserID)
+		if err != nil {
+			return err
+		}
+
+		hash, err := u.newPasswordHash(user.Username, input.NewPassword)
+		if err != nil {
+			return err
+		}
+
+		if err = u.passwordResetRepo.MarkPasswordResetTokenUsed(ctx, stored.ID); err != nil {
+			return err
+		}
+
+		update := &entity.UpdatePasswordHashInput{UserID: user.ID, OldHash: user.Hash, NewHash: hash}
+		if err = u.userRepo.UpdatePasswordHash(ctx, update); err != nil {
+			return err
+		}
+
+		userID, username = user.ID, user.Username
+		version, err = u.revokeUserSessionsTx(ctx, user.ID)
+
+		return err
+	}
+
+	readCommitted := u.txManager.ReadCommitted(ctx, db.Write, transaction)
+	if err := u.txManager.WithRetry(readCommitted); err != nil {
+		return err
+	}
+
+	u.denylist.revokeUserTokens(userID, version)
+
+	return u.UnlockUser(ctx, username)
+}
+
+func (u *authUsecase) newPasswordHash(username, password string) (string, error) {
+	if err := u.passwordPolicy.Validate(username, password); err != nil {
+		return "", errors.Wrap(apperrors.ErrWeakPassword, err.Error())
+	}
+
+	return u.passwordManager.HashPassword(password)
+}
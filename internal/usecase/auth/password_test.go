@@ -0,0 +1,247 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/resueman/merch-store/internal/entity"
+	"github.com/resueman/merch-store/internal/model"
+	"github.com/resueman/merch-store/internal/repo/repoerrors"
+	"github.com/resueman/merch-store/internal/usecase/apperrors"
+	"github.com/resueman/merch-store/pkg/password"
+	"github.com/resueman/merch-store/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+var testPasswordPolicy = password.Policy{MinLength: 8, RequireDigit: true}
+
+func TestAuthUsecase_ChangePassword_Ok(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userRepo := mocks.NewMockUser(ctrl)
+	refreshTokenRepo := mocks.NewMockRefreshToken(ctrl)
+	passwordManager := mocks.NewMockPasswordManager(ctrl)
+	txManager := mocks.NewMockTxManager(ctrl)
+
+	claims := model.Claims{UserID: 1, TokenVersion: 2, Role: model.RoleUser, Scopes: []model.Scope{model.ScopeInfoRead}}
+	input := model.ChangePasswordInput{OldPassword: "old-password1", NewPassword: "new-password1"}
+
+	userRepo.EXPECT().
+		GetUserByID(gomock.Any(), 1).
+		Return(&entity.User{ID: 1, Username: "alice", Hash: "old-hash", TokenVersion: 2, Role: "user"}, nil)
+	passwordManager.EXPECT().ComparePassword(input.OldPassword, "old-hash").Return(true)
+	passwordManager.EXPECT().HashPassword(input.NewPassword).Return("new-hash", nil)
+
+	readCommittedTxMock(txManager)
+
+	userRepo.EXPECT().
+		UpdatePasswordHash(gomock.Any(), &entity.UpdatePasswordHashInput{UserID: 1, OldHash: "old-hash", NewHash: "new-hash"}).
+		Return(nil)
+	userRepo.EXPECT().IncrementTokenVersion(gomock.Any(), 1).Return(3, nil)
+	refreshTokenRepo.EXPECT().RevokeUserRefreshTokens(gomock.Any(), 1).Return(nil)
+
+	// Новая сессия сохраняет области доступа текущей.
+	refreshTokenRepo.EXPECT().
+		CreateRefreshToken(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, input *entity.CreateRefreshTokenInput) error {
+			require.Equal(t, "info:read", input.Scopes)
+
+			return nil
+		})
+
+	cfg := testConfig
+	cfg.PasswordPolicy = testPasswordPolicy
+
+	uc := NewAuthUsecase(userRepo, refreshTokenRepo, nil, noLoginLocksMock(ctrl), nil, nil, nil, nil, passwordManager,
+		txManager, cfg)
+	tokens, err := uc.ChangePassword(context.Background(), claims, input)
+	require.NoError(t, err)
+	require.NotEmpty(t, tokens.AccessToken)
+
+	uc.denylist.syncedAt = time.Now()
+
+	parsed, err := uc.ParseToken(context.Background(), tokens.AccessToken)
+	require.NoError(t, err)
+	require.Equal(t, 3, parsed.TokenVersion)
+}
+
+func TestAuthUsecase_ChangePassword_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    model.ChangePasswordInput
+		matches  bool
+		expected error
+	}{
+		{
+			name:     "wrong old password",
+			input:    model.ChangePasswordInput{OldPassword: "wrong", NewPassword: "new-password1"},
+			matches:  false,
+			expected: apperrors.ErrInvalidPassword,
+		},
+		{
+			name:     "same password",
+			input:    model.ChangePasswordInput{OldPassword: "old-password1", NewPassword: "old-password1"},
+			matches:  true,
+			expected: apperrors.ErrSamePassword,
+		},
+		{
+			name:     "weak password",
+			input:    model.ChangePasswordInput{OldPassword: "old-password1", NewPassword: "short"},
+			matches:  true,
+			expected: apperrors.ErrWeakPassword,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			userRepo := mocks.NewMockUser(ctrl)
+			passwordManager := mocks.NewMockPasswordManager(ctrl)
+
+			userRepo.EXPECT().
+				GetUserByID(gomock.Any(), 1).
+				Return(&entity.User{ID: 1, Username: "alice", Hash: "old-hash"}, nil)
+			passwordManager.EXPECT().ComparePassword(tt.input.OldPassword, "old-hash").Return(tt.matches)
+
+			cfg := testConfig
+			cfg.PasswordPolicy = testPasswordPolicy
+
+			uc := NewAuthUsecase(userRepo, nil, nil, noLoginLocksMock(ctrl), nil, nil, nil, nil, passwordManager, nil,
+				cfg)
+			_, err := uc.ChangePassword(context.Background(), model.Claims{UserID: 1}, tt.input)
+
+			require.ErrorIs(t, err, tt.expected)
+		})
+	}
+}
+
+func TestAuthUsecase_CreatePasswordReset_Ok(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userRepo := mocks.NewMockUser(ctrl)
+	passwordResetRepo := mocks.NewMockPasswordReset(ctrl)
+	txManager := mocks.NewMockTxManager(ctrl)
+
+	userRepo.EXPECT().
+		GetUserByUsername(gomock.Any(), "bob").
+		Return(&entity.User{ID: 2, Username: "bob"}, nil)
+
+	readCommittedTxMock(txManager)
+
+	passwordResetRepo.EXPECT().DeleteUnusedPasswordResetTokens(gomock.Any(), 2).Return(nil)
+
+	var storedHash string
+	passwordResetRepo.EXPECT().
+		CreatePasswordResetToken(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, input *entity.CreatePasswordResetTokenInput) error {
+			require.Equal(t, 2, input.UserID)
+			require.Equal(t, 1, input.CreatedBy)
+			storedHash = input.TokenHash
+
+			return nil
+		})
+
+	cfg := testConfig
+	cfg.PasswordResetTTL = time.Hour
+
+	uc := NewAuthUsecase(userRepo, nil, nil, nil, passwordResetRepo, nil, nil, nil, nil, txManager, cfg)
+	reset, err := uc.CreatePasswordReset(context.Background(), model.Claims{UserID: 1}, "bob")
+	require.NoError(t, err)
+
+	require.Equal(t, hashToken(reset.Token), storedHash, "only the token hash must be stored")
+	require.WithinDuration(t, time.Now().Add(time.Hour), reset.ExpiresAt, time.Second)
+}
+
+func TestAuthUsecase_ResetPassword_Ok(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userRepo := mocks.NewMockUser(ctrl)
+	refreshTokenRepo := mocks.NewMockRefreshToken(ctrl)
+	loginAttemptRepo := mocks.NewMockLoginAttempt(ctrl)
+	passwordResetRepo := mocks.NewMockPasswordReset(ctrl)
+	passwordManager := mocks.NewMockPasswordManager(ctrl)
+	txManager := mocks.NewMockTxManager(ctrl)
+
+	readCommittedTxMock(txManager)
+
+	passwordResetRepo.EXPECT().
+		GetPasswordResetTokenByHash(gomock.Any(), hashToken("reset")).
+		Return(&entity.PasswordResetToken{ID: 5, UserID: 2, ExpiresAt: time.Now().Add(time.Minute)}, nil)
+	userRepo.EXPECT().
+		GetUserByID(gomock.Any(), 2).
+		Return(&entity.User{ID: 2, Username: "bob", Hash: "old-hash"}, nil)
+	passwordManager.EXPECT().HashPassword("new-password1").Return("new-hash", nil)
+	passwordResetRepo.EXPECT().MarkPasswordResetTokenUsed(gomock.Any(), 5).Return(nil)
+	userRepo.EXPECT().
+		UpdatePasswordHash(gomock.Any(), &entity.UpdatePasswordHashInput{UserID: 2, OldHash: "old-hash", NewHash: "new-hash"}).
+		Return(nil)
+	userRepo.EXPECT().IncrementTokenVersion(gomock.Any(), 2).Return(1, nil)
+	refreshTokenRepo.EXPECT().RevokeUserRefreshTokens(gomock.Any(), 2).Return(nil)
+	loginAttemptRepo.EXPECT().
+		ResetLoginFailures(gomock.Any(), entity.LoginAttemptKey{KeyType: entity.LoginAttemptKeyUsername, Key: "bob"}).
+		Return(nil)
+
+	cfg := testConfig
+	cfg.PasswordPolicy = testPasswordPolicy
+
+	uc := NewAuthUsecase(userRepo, refreshTokenRepo, nil, loginAttemptRepo, passwordResetRepo, nil, nil, nil,
+		passwordManager, txManager, cfg)
+	input := model.ResetPasswordInput{ResetToken: "reset", NewPassword: "new-password1"}
+
+	require.NoError(t, uc.ResetPassword(context.Background(), input))
+}
+
+func TestAuthUsecase_ResetPassword_InvalidToken(t *testing.T) {
+	usedAt := time.Now().Add(-time.Minute)
+
+	tests := []struct {
+		name     string
+		stored   *entity.PasswordResetToken
+		err      error
+		expected error
+	}{
+		{
+			name:     "unknown token",
+			err:      repoerrors.ErrNotFound,
+			expected: apperrors.ErrInvalidResetToken,
+		},
+		{
+			name:     "used token",
+			stored:   &entity.PasswordResetToken{ID: 5, UserID: 2, ExpiresAt: time.Now().Add(time.Minute), UsedAt: &usedAt},
+			expected: apperrors.ErrInvalidResetToken,
+		},
+		{
+			name:     "expired token",
+			stored:   &entity.PasswordResetToken{ID: 5, UserID: 2, ExpiresAt: time.Now().Add(-time.Minute)},
+			expected: apperrors.ErrResetTokenExpired,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			passwordResetRepo := mocks.NewMockPasswordReset(ctrl)
+			txManager := mocks.NewMockTxManager(ctrl)
+
+			readCommittedTxMock(txManager)
+
+			passwordResetRepo.EXPECT().
+				GetPasswordResetTokenByHash(gomock.Any(), hashToken("reset")).
+				Return(tt.stored, tt.err)
+
+			uc := NewAuthUsecase(nil, nil, nil, nil, passwordResetRepo, nil, nil, nil, nil, txManager, testConfig)
+			err := uc.ResetPassword(context.Background(), model.ResetPasswordInput{ResetToken: "reset", NewPassword: "x"})
+
+			require.ErrorIs(t, err, tt.expected)
+		})
+	}
+}
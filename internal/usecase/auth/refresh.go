@@ -0,0 +1,137 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/resueman/merch-store/internal/entity"
+	"github.com/resueman/merch-store/internal/model"
+	"github.com/resueman/merch-store/internal/repo/repoerrors"
+	"github.com/resueman/merch-store/internal/usecase/apperrors"
+	"github.com/resueman/merch-store/pkg/db"
+)
+
+const (
+	refreshTokenBytes = 32
+	familyIDBytes     = 16
+)
+
+// Обменивает refresh-токен на новую пару токенов. Каждый refresh-токен одноразовый:
+// при обмене он помечается использованным, а новый токен наследует его семейство.
+// Предъявление уже использованного токена означает, что он был украден,
+// поэтому отзывается все семейство, включая последний выданный токен.
+func (u *authUsecase) RefreshTokens(ctx context.Context, refreshToken string) (model.AuthTokens, error) {
+	tokenHash := hashToken(refreshToken)
+
+	tokens := emptyTokens
+	reused := false
+	transaction := func(ctx context.Context) error {
+		stored, err := u.refreshTokenRepo.GetRefreshTokenByHash(ctx, tokenHash)
+		if err != nil {
+			if errors.Is(err, repoerrors.ErrNotFound) {
+				return apperrors.ErrInvalidRefreshToken
+			}
+
+			return err
+		}
+
+		// Отзыв семейства должен закоммититься, поэтому ошибку возвращаем уже после транзакции.
+		if stored.UsedAt != nil {
+			reused = true
+
+			return u.refreshTokenRepo.RevokeRefreshTokenFamily(ctx, stored.FamilyID)
+		}
+
+		if stored.RevokedAt != nil {
+			return apperrors.ErrInvalidRefreshToken
+		}
+
+		if !stored.ExpiresAt.After(time.Now()) {
+			return apperrors.ErrRefreshTokenExpired
+		}
+
+		if err = u.refreshTokenRepo.MarkRefreshTokenUsed(ctx, stored.ID); err != nil {
+			return err
+		}
+
+		user, err := u.userRepo.GetUserByID(ctx, stored.UserID)
+		if err != nil {
+			return err
+		}
+
+		// При деактивации refresh-токены отзываются, но сессию не продлеваем и в случае гонки с ней.
+		if user.DeactivatedAt != nil {
+			return apperrors.ErrUserDeactivated
+		}
+
+		claims := model.Claims{UserID: user.ID, TokenVersion: user.TokenVersion, Role: model.Role(user.Role)}
+
+		// Новая пара токенов получает те же области доступа, с которыми была открыта сессия.
+		if claims.Scopes, err = grantScopes(claims.Role, model.ParseScopes(stored.Scopes)); err != nil {
+			return apperrors.ErrInvalidRefreshToken
+		}
+		tokens, err = u.issueTokens(ctx, claims, stored.FamilyID)
+
+		return err
+	}
+
+	serializable := u.txManager.Serializable(ctx, db.Write, transaction)
+	if err := u.txManager.WithRetry(serializable); err != nil {
+		return emptyTokens, err
+	}
+
+	if reused {
+		return emptyTokens, apperrors.ErrRefreshTokenReused
+	}
+
+	return tokens, nil
+}
+
+func (u *authUsecase) issueRefreshToken(ctx context.Context, claims model.Claims, familyID string) (string, error) {
+	token, err := randomString(refreshTokenBytes)
+	if err != nil {
+		return "", apperrors.ErrGenerateToken
+	}
+
+	if familyID == "" {
+		if familyID, err = randomString(familyIDBytes); err != nil {
+			return "", apperrors.ErrGenerateToken
+		}
+	}
+
+	input := &entity.CreateRefreshTokenInput{
+		UserID:    claims.UserID,
+		FamilyID:  familyID,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(u.refreshTokenTTL),
+		Scopes:    model.FormatScopes(claims.Scopes),
+	}
+
+	if err = u.refreshTokenRepo.CreateRefreshToken(ctx, input); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// Refresh-токены непрозрачные и хранятся только в виде хеша. Токен содержит
+// 256 бит случайности, поэтому медленное хеширование, как для паролей, не нужно.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(sum[:])
+}
+
+func randomString(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
@@ -0,0 +1,177 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/resueman/merch-store/internal/entity"
+	"github.com/resueman/merch-store/internal/repo/repoerrors"
+	"github.com/resueman/merch-store/internal/usecase/apperrors"
+	"github.com/resueman/merch-store/pkg/db"
+	"github.com/resueman/merch-store/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func serializableTxMock(txManager *mocks.MockTxManager) {
+	txManager.EXPECT().
+		Serializable(gomock.Any(), db.Write, gomock.Any()).
+		DoAndReturn(func(ctx context.Context, _ db.Mode, f func(context.Context) error) func() error {
+			return func() error { return f(ctx) }
+		})
+
+	withRetryMock(txManager)
+}
+
+func readCommittedTxMock(txManager *mocks.MockTxManager) {
+	txManager.EXPECT().
+		ReadCommitted(gomock.Any(), db.Write, gomock.Any()).
+		DoAndReturn(func(ctx context.Context, _ db.Mode, f func(context.Context) error) func() error {
+			return func() error { return f(ctx) }
+		})
+
+	withRetryMock(txManager)
+}
+
+func withRetryMock(txManager *mocks.MockTxManager) {
+	txManager.EXPECT().
+		WithRetry(gomock.Any()).
+		DoAndReturn(func(f func() error) error {
+			return f()
+		})
+}
+
+func TestAuthUsecase_RefreshTokens_Ok(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userRepo := mocks.NewMockUser(ctrl)
+	refreshTokenRepo := mocks.NewMockRefreshToken(ctrl)
+	txManager := mocks.NewMockTxManager(ctrl)
+
+	stored := &entity.RefreshToken{
+		ID:        7,
+		UserID:    123,
+		FamilyID:  "family",
+		TokenHash: hashToken("old"),
+		ExpiresAt: time.Now().Add(time.Hour),
+		Scopes:    "info:read",
+	}
+
+	serializableTxMock(txManager)
+
+	refreshTokenRepo.EXPECT().
+		GetRefreshTokenByHash(gomock.Any(), hashToken("old")).
+		Return(stored, nil)
+
+	refreshTokenRepo.EXPECT().
+		MarkRefreshTokenUsed(gomock.Any(), stored.ID).
+		Return(nil)
+
+	userRepo.EXPECT().
+		GetUserByID(gomock.Any(), stored.UserID).
+		Return(&entity.User{ID: stored.UserID, TokenVersion: 2, Role: "user"}, nil)
+
+	refreshTokenRepo.EXPECT().
+		CreateRefreshToken(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, input *entity.CreateRefreshTokenInput) error {
+			require.Equal(t, stored.UserID, input.UserID)
+			require.Equal(t, stored.FamilyID, input.FamilyID)
+			require.NotEqual(t, stored.TokenHash, input.TokenHash)
+			require.Equal(t, stored.Scopes, input.Scopes)
+
+			return nil
+		})
+
+	authUsecase := NewAuthUsecase(userRepo, refreshTokenRepo, nil, nil, nil, nil, nil, nil, nil, txManager, testConfig)
+	tokens, err := authUsecase.RefreshTokens(context.Background(), "old")
+
+	require.NoError(t, err)
+	require.NotEmpty(t, tokens.AccessToken)
+	require.NotEmpty(t, tokens.RefreshToken)
+	require.NotEqual(t, "old", tokens.RefreshToken)
+}
+
+func TestAuthUsecase_RefreshTokens_ReuseRevokesFamily(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userRepo := mocks.NewMockUser(ctrl)
+	refreshTokenRepo := mocks.NewMockRefreshToken(ctrl)
+	txManager := mocks.NewMockTxManager(ctrl)
+
+	usedAt := time.Now().Add(-time.Minute)
+	stored := &entity.RefreshToken{
+		ID:        7,
+		UserID:    123,
+		FamilyID:  "family",
+		ExpiresAt: time.Now().Add(time.Hour),
+		UsedAt:    &usedAt,
+	}
+
+	serializableTxMock(txManager)
+
+	refreshTokenRepo.EXPECT().
+		GetRefreshTokenByHash(gomock.Any(), hashToken("used")).
+		Return(stored, nil)
+
+	refreshTokenRepo.EXPECT().
+		RevokeRefreshTokenFamily(gomock.Any(), stored.FamilyID).
+		Return(nil)
+
+	authUsecase := NewAuthUsecase(userRepo, refreshTokenRepo, nil, nil, nil, nil, nil, nil, nil, txManager, testConfig)
+	_, err := authUsecase.RefreshTokens(context.Background(), "used")
+
+	require.ErrorIs(t, err, apperrors.ErrRefreshTokenReused)
+}
+
+func TestAuthUsecase_RefreshTokens_Errors(t *testing.T) {
+	revokedAt := time.Now().Add(-time.Minute)
+
+	tests := []struct {
+		name   string
+		stored *entity.RefreshToken
+		err    error
+		want   error
+	}{
+		{
+			name: "unknown token",
+			err:  repoerrors.ErrNotFound,
+			want: apperrors.ErrInvalidRefreshToken,
+		},
+		{
+			name:   "revoked token",
+			stored: &entity.RefreshToken{ID: 1, ExpiresAt: time.Now().Add(time.Hour), RevokedAt: &revokedAt},
+			want:   apperrors.ErrInvalidRefreshToken,
+		},
+		{
+			name:   "expired token",
+			stored: &entity.RefreshToken{ID: 1, ExpiresAt: time.Now().Add(-time.Second)},
+			want:   apperrors.ErrRefreshTokenExpired,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			userRepo := mocks.NewMockUser(ctrl)
+			refreshTokenRepo := mocks.NewMockRefreshToken(ctrl)
+			txManager := mocks.NewMockTxManager(ctrl)
+
+			serializableTxMock(txManager)
+
+			refreshTokenRepo.EXPECT().
+				GetRefreshTokenByHash(gomock.Any(), gomock.Any()).
+				Return(tt.stored, tt.err)
+
+			authUsecase := NewAuthUsecase(userRepo, refreshTokenRepo, nil, nil, nil, nil, nil, nil, nil, txManager,
+				testConfig)
+			_, err := authUsecase.RefreshTokens(context.Background(), "token")
+
+			require.ErrorIs(t, err, tt.want)
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/resueman/merch-store/internal/model"
+	"github.com/resueman/merch-store/internal/repo/repoerrors"
+	"github.com/resueman/merch-store/internal/usecase/apperrors"
+)
+
+// Меняет роль пользователя. Роль зашита в токены, поэтому после изменения
+// все сессии пользователя отзываются и новая роль применится при следующем входе.
+func (u *authUsecase) SetUserRole(ctx context.Context, claims model.Claims, input model.SetUserRoleInput) error {
+	if !input.Role.Valid() {
+		return apperrors.ErrInvalidRole
+	}
+
+	user, err := u.userRepo.GetUserByUsername(ctx, input.Username)
+	if err != nil {
+		if errors.Is(err, repoerrors.ErrNotFound) {
+			return apperrors.ErrUserNotFound
+		}
+
+		return err
+	}
+
+	// Иначе последний администратор может случайно лишить себя прав.
+	if user.ID == claims.UserID {
+		return apperrors.ErrSelfRoleChange
+	}
+
+	if model.Role(user.Role) == input.Role {
+		return nil
+	}
+
+	if err = u.userRepo.SetUserRole(ctx, user.ID, string(input.Role)); err != nil {
+		return err
+	}
+
+	return u.revokeUserSessions(ctx, user.ID)
+}
+
+// Назначает пользователя username администратором, если в системе еще нет ни одного администратора.
+// Вызывается при старте приложения, поэтому повторные запуски ничего не меняют.
+func (u *authUsecase) BootstrapAdmin(ctx context.Context, username string) error {
+	if username == "" {
+		return nil
+	}
+
+	exists, err := u.userRepo.HasUsersWithRole(ctx, string(model.RoleAdmin))
+	if err != nil || exists {
+		return err
+	}
+
+	user, err := u.userRepo.GetUserByUsername(ctx, username)
+	if err != nil {
+		if errors.Is(err, repoerrors.ErrNotFound) {
+			return apperrors.ErrUserNotFound
+		}
+
+		return err
+	}
+
+	return u.userRepo.SetUserRole(ctx, user.ID, string(model.RoleAdmin))
+}
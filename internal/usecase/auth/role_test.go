@@ -0,0 +1,128 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/resueman/merch-store/internal/entity"
+	"github.com/resueman/merch-store/internal/model"
+	"github.com/resueman/merch-store/internal/repo/repoerrors"
+	"github.com/resueman/merch-store/internal/usecase/apperrors"
+	"github.com/resueman/merch-store/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthUsecase_SetUserRole_Ok(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userRepo := mocks.NewMockUser(ctrl)
+	refreshTokenRepo := mocks.NewMockRefreshToken(ctrl)
+	txManager := mocks.NewMockTxManager(ctrl)
+
+	mock := func() {
+		userRepo.EXPECT().
+			GetUserByUsername(gomock.Any(), "bob").
+			Return(&entity.User{ID: 2, Username: "bob", Role: "user"}, nil)
+
+		userRepo.EXPECT().
+			SetUserRole(gomock.Any(), 2, "admin").
+			Return(nil)
+
+		readCommittedTxMock(txManager)
+
+		userRepo.EXPECT().
+			IncrementTokenVersion(gomock.Any(), 2).
+			Return(1, nil)
+
+		refreshTokenRepo.EXPECT().
+			RevokeUserRefreshTokens(gomock.Any(), 2).
+			Return(nil)
+	}
+
+	mock()
+
+	uc := NewAuthUsecase(userRepo, refreshTokenRepo, nil, nil, nil, nil, nil, nil, nil, txManager, testConfig)
+	err := uc.SetUserRole(context.Background(), model.Claims{UserID: 1, Role: model.RoleAdmin},
+		model.SetUserRoleInput{Username: "bob", Role: model.RoleAdmin})
+
+	require.NoError(t, err)
+}
+
+func TestAuthUsecase_SetUserRole_Errors(t *testing.T) {
+	admin := model.Claims{UserID: 1, Role: model.RoleAdmin}
+
+	tests := []struct {
+		name    string
+		input   model.SetUserRoleInput
+		mock    func(userRepo *mocks.MockUser)
+		wantErr error
+	}{
+		{
+			name:    "Invalid role",
+			input:   model.SetUserRoleInput{Username: "bob", Role: "root"},
+			mock:    func(_ *mocks.MockUser) {},
+			wantErr: apperrors.ErrInvalidRole,
+		},
+		{
+			name:  "User not found",
+			input: model.SetUserRoleInput{Username: "bob", Role: model.RoleAdmin},
+			mock: func(userRepo *mocks.MockUser) {
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), "bob").Return(nil, repoerrors.ErrNotFound)
+			},
+			wantErr: apperrors.ErrUserNotFound,
+		},
+		{
+			name:  "Self role change",
+			input: model.SetUserRoleInput{Username: "alice", Role: model.RoleUser},
+			mock: func(userRepo *mocks.MockUser) {
+				userRepo.EXPECT().
+					GetUserByUsername(gomock.Any(), "alice").
+					Return(&entity.User{ID: 1, Username: "alice", Role: "admin"}, nil)
+			},
+			wantErr: apperrors.ErrSelfRoleChange,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			userRepo := mocks.NewMockUser(ctrl)
+			tt.mock(userRepo)
+
+			uc := NewAuthUsecase(userRepo, nil, nil, nil, nil, nil, nil, nil, nil, nil, testConfig)
+			err := uc.SetUserRole(context.Background(), admin, tt.input)
+
+			require.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestAuthUsecase_BootstrapAdmin(t *testing.T) {
+	t.Run("Promotes user when there are no admins", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		userRepo := mocks.NewMockUser(ctrl)
+		userRepo.EXPECT().HasUsersWithRole(gomock.Any(), "admin").Return(false, nil)
+		userRepo.EXPECT().GetUserByUsername(gomock.Any(), "alice").Return(&entity.User{ID: 1, Username: "alice"}, nil)
+		userRepo.EXPECT().SetUserRole(gomock.Any(), 1, "admin").Return(nil)
+
+		uc := NewAuthUsecase(userRepo, nil, nil, nil, nil, nil, nil, nil, nil, nil, testConfig)
+		require.NoError(t, uc.BootstrapAdmin(context.Background(), "alice"))
+	})
+
+	t.Run("Does nothing when admin already exists", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		userRepo := mocks.NewMockUser(ctrl)
+		userRepo.EXPECT().HasUsersWithRole(gomock.Any(), "admin").Return(true, nil)
+
+		uc := NewAuthUsecase(userRepo, nil, nil, nil, nil, nil, nil, nil, nil, nil, testConfig)
+		require.NoError(t, uc.BootstrapAdmin(context.Background(), "alice"))
+	})
+}
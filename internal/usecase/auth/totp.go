@@ -0,0 +1,368 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/resueman/merch-store/internal/entity"
+	"github.com/resueman/merch-store/internal/model"
+	"github.com/resueman/merch-store/internal/repo/repoerrors"
+	"github.com/resueman/merch-store/internal/usecase/apperrors"
+	"github.com/resueman/merch-store/pkg/db"
+	"github.com/resueman/merch-store/pkg/totp"
+)
+
+const (
+	// Допуск в один 30-секундный интервал в обе стороны на случай расхождения часов телефона и сервера.
+	totpSkew          = 1
+	recoveryCodeCount = 10
+	recoveryCodeBytes = 5
+)
+
+// Начинает подключение второго фактора: генерирует секрет для приложения-аутентификатора.
+// Второй фактор начинает действовать только после подтверждения кодом из приложения.
+func (u *authUsecase) EnrollTOTP(ctx context.Context, claims model.Claims) (model.TOTPEnrollment, error) {
+	user, err := u.getUser(ctx, claims.UserID)
+	if err != nil {
+		return model.TOTPEnrollment{}, err
+	}
+
+	if user.IsService {
+		return model.TOTPEnrollment{}, apperrors.ErrServiceAccount
+	}
+
+	if user.TOTPEnabled {
+		return model.TOTPEnrollment{}, apperrors.ErrTOTPAlreadyEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return model.TOTPEnrollment{}, apperrors.ErrGenerateToken
+	}
+
+	if err = u.totpRepo.SaveTOTPSecret(ctx, user.ID, secret); err != nil {
+		if errors.Is(err, repoerrors.ErrAlreadyExists) {
+			return model.TOTPEnrollment{}, apperrors.ErrTOTPAlreadyEnabled
+		}
+
+		return model.TOTPEnrollment{}, err
+	}
+
+	return model.TOTPEnrollment{Secret: secret, URI: totp.URI(u.totpIssuer, user.Username, secret)}, nil
+}
+
+// Включает второй фактор после проверки первого кода из приложения и возвращает коды восстановления.
+// Коды восстановления показываются только здесь, в БД хранятся их хеши.
+func (u *authUsecase) ConfirmTOTP(ctx context.Context, claims model.Claims,
+	input model.ConfirmTOTPInput,
+) ([]string, error) {
+	if input.Threshold < 0 {
+		return nil, apperrors.ErrInvalidThreshold
+	}
+
+	user, err := u.getUser(ctx, claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	stored, err := u.totpRepo.GetTOTP(ctx, user.ID)
+	if err != nil {
+		if errors.Is(err, repoerrors.ErrNotFound) {
+			return nil, apperrors.ErrTOTPNotEnabled
+		}
+
+		return nil, err
+	}
+
+	if stored.EnabledAt != nil {
+		return nil, apperrors.ErrTOTPAlreadyEnabled
+	}
+
+	attemptKeys := loginAttemptKeys(model.AuthRequestInput{Username: user.Username})
+	if err = u.checkLoginAllowed(ctx, attemptKeys); err != nil {
+		return nil, err
+	}
+
+	// Коды восстановления еще не выданы, поэтому принимается только код из приложения.
+	if err = u.verifyTOTPCode(ctx, attemptKeys, stored, input.Code); err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for range recoveryCodeCount {
+		code, err := newRecoveryCode()
+		if err != nil {
+			return nil, apperrors.ErrGenerateToken
+		}
+
+		codes = append(codes, code)
+		hashes = append(hashes, hashToken(normalizeRecoveryCode(code)))
+	}
+
+	transaction := func(ctx context.Context) error {
+		if err := u.totpRepo.EnableTOTP(ctx, user.ID, input.Threshold); err != nil {
+			if errors.Is(err, repoerrors.ErrNotFound) {
+				return apperrors.ErrTOTPAlreadyEnabled
+			}
+
+			return err
+		}
+
+		if err := u.totpRepo.DeleteRecoveryCodes(ctx, user.ID); err != nil {
+			return err
+		}
+
+		return u.totpRepo.CreateRecoveryCodes(ctx, user.ID, hashes)
+	}
+
+	readCommitted := u.txManager.ReadCommitted(ctx, db.Write, transaction)
+	if err = u.txManager.WithRetry(readCommitted); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// Отключает второй фактор. Требует кода из приложения или кода восстановления,
+// чтобы украденного access-токена не хватало для отключения защиты.
+func (u *authUsecase) DisableTOTP(ctx context.Context, claims model.Claims, code string) error {
+	user, stored, err := u.getEnabledTOTP(ctx, claims.UserID)
+	if err != nil {
+		return err
+	}
+
+	if err = u.verifyUserSecondFactor(ctx, user, stored, code); err != nil {
+		return err
+	}
+
+	transaction := func(ctx context.Context) error {
+		if err := u.totpRepo.DeleteRecoveryCodes(ctx, user.ID); err != nil {
+			return err
+		}
+
+		return u.totpRepo.DeleteTOTP(ctx, user.ID)
+	}
+
+	readCommitted := u.txManager.ReadCommitted(ctx, db.Write, transaction)
+
+	return u.txManager.WithRetry(readCommitted)
+}
+
+// Меняет порог суммы, начиная с которого переводы и покупки требуют кода. Сам запрос тоже требует кода,
+// иначе с украденным токеном можно было бы поднять порог и обойти проверку.
+func (u *authUsecase) SetTOTPThreshold(ctx context.Context, claims model.Claims,
+	input model.SetTOTPThresholdInput,
+) error {
+	if input.Threshold < 0 {
+		return apperrors.ErrInvalidThreshold
+	}
+
+	user, stored, err := u.getEnabledTOTP(ctx, claims.UserID)
+	if err != nil {
+		return err
+	}
+
+	if err = u.verifyUserSecondFactor(ctx, user, stored, input.Code); err != nil {
+		return err
+	}
+
+	if err = u.totpRepo.SetTOTPThreshold(ctx, user.ID, input.Threshold); err != nil {
+		if errors.Is(err, repoerrors.ErrNotFound) {
+			return apperrors.ErrTOTPNotEnabled
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// Проверяет второй фактор для операции на amount монет. Если второй фактор не включен
+// или сумма не превышает порог пользователя, код не требуется. Вызывается в транзакции операции,
+// поэтому неверный код здесь не учитывается: запись о неудачной попытке откатилась бы вместе
+// с операцией. Вызывающий сообщает о нем через RecordOTPFailure после отката.
+func (u *authUsecase) VerifyOTP(ctx context.Context, userID int, amount int, code string) error {
+	stored, err := u.totpRepo.GetTOTP(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repoerrors.ErrNotFound) {
+			return nil
+		}
+
+		return err
+	}
+
+	if stored.EnabledAt == nil || amount <= stored.Threshold {
+		return nil
+	}
+
+	if code == "" {
+		return apperrors.ErrOTPRequired
+	}
+
+	user, err := u.getUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err = u.checkLoginAllowed(ctx, loginAttemptKeys(model.AuthRequestInput{Username: user.Username})); err != nil {
+		return err
+	}
+
+	return u.useSecondFactor(ctx, stored, code)
+}
+
+// Учитывает неверный код, отклоненный VerifyOTP, как неудачную попытку входа.
+func (u *authUsecase) RecordOTPFailure(ctx context.Context, userID int) error {
+	user, err := u.getUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	return u.recordLoginFailure(ctx, loginAttemptKeys(model.AuthRequestInput{Username: user.Username}))
+}
+
+// Вызывается при входе после проверки пароля. Отсутствие кода не считается неудачной попыткой:
+// клиент узнает, что нужен второй шаг, и повторяет запрос с кодом.
+func (u *authUsecase) checkLoginSecondFactor(ctx context.Context, user *entity.User,
+	attemptKeys []entity.LoginAttemptKey, code string,
+) error {
+	if !user.TOTPEnabled {
+		return nil
+	}
+
+	if code == "" {
+		return apperrors.ErrOTPRequired
+	}
+
+	stored, err := u.totpRepo.GetTOTP(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	return u.verifySecondFactor(ctx, attemptKeys, stored, code)
+}
+
+// Неверные коды учитываются так же, как неудачные попытки входа по имени пользователя,
+// поэтому перебрать шестизначный код не получится.
+func (u *authUsecase) verifyUserSecondFactor(ctx context.Context, user *entity.User, stored *entity.TOTP,
+	code string,
+) error {
+	attemptKeys := loginAttemptKeys(model.AuthRequestInput{Username: user.Username})
+	if err := u.checkLoginAllowed(ctx, attemptKeys); err != nil {
+		return err
+	}
+
+	return u.verifySecondFactor(ctx, attemptKeys, stored, code)
+}
+
+// Принимает код из приложения или код восстановления. Неверный код учитывается как неудачная попытка входа.
+func (u *authUsecase) verifySecondFactor(ctx context.Context, attemptKeys []entity.LoginAttemptKey,
+	stored *entity.TOTP, code string,
+) error {
+	return u.recordInvalidOTP(ctx, attemptKeys, u.useSecondFactor(ctx, stored, code))
+}
+
+func (u *authUsecase) verifyTOTPCode(ctx context.Context, attemptKeys []entity.LoginAttemptKey,
+	stored *entity.TOTP, code string,
+) error {
+	return u.recordInvalidOTP(ctx, attemptKeys, u.useTOTPCode(ctx, stored, code))
+}
+
+func (u *authUsecase) recordInvalidOTP(ctx context.Context, attemptKeys []entity.LoginAttemptKey, err error) error {
+	if !errors.Is(err, apperrors.ErrInvalidOTP) {
+		return err
+	}
+
+	if err = u.recordLoginFailure(ctx, attemptKeys); err != nil {
+		return err
+	}
+
+	return apperrors.ErrInvalidOTP
+}
+
+// Погашает код из приложения или код восстановления. Неверный код не учитывается, это делает вызывающий.
+func (u *authUsecase) useSecondFactor(ctx context.Context, stored *entity.TOTP, code string) error {
+	if len(code) == totp.Digits {
+		return u.useTOTPCode(ctx, stored, code)
+	}
+
+	err := u.totpRepo.UseRecoveryCode(ctx, stored.UserID, hashToken(normalizeRecoveryCode(code)))
+	if errors.Is(err, repoerrors.ErrNotFound) {
+		return apperrors.ErrInvalidOTP
+	}
+
+	return err
+}
+
+func (u *authUsecase) useTOTPCode(ctx context.Context, stored *entity.TOTP, code string) error {
+	step, ok := totp.Validate(stored.Secret, code, time.Now(), totpSkew)
+	if !ok {
+		return apperrors.ErrInvalidOTP
+	}
+
+	// Код принимается один раз: перехваченный код нельзя повторить в течение его интервала.
+	err := u.totpRepo.UseTOTPStep(ctx, stored.UserID, step)
+	if errors.Is(err, repoerrors.ErrNotFound) {
+		return apperrors.ErrInvalidOTP
+	}
+
+	return err
+}
+
+func (u *authUsecase) getEnabledTOTP(ctx context.Context, userID int) (*entity.User, *entity.TOTP, error) {
+	user, err := u.getUser(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !user.TOTPEnabled {
+		return nil, nil, apperrors.ErrTOTPNotEnabled
+	}
+
+	stored, err := u.totpRepo.GetTOTP(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repoerrors.ErrNotFound) {
+			return nil, nil, apperrors.ErrTOTPNotEnabled
+		}
+
+		return nil, nil, err
+	}
+
+	return user, stored, nil
+}
+
+func (u *authUsecase) getUser(ctx context.Context, userID int) (*entity.User, error) {
+	user, err := u.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, repoerrors.ErrNotFound) {
+			return nil, apperrors.ErrUserNotFound
+		}
+
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// Код восстановления вида XXXX-XXXX из заглавных букв и цифр base32, его удобно переписать вручную.
+func newRecoveryCode() (string, error) {
+	buf := make([]byte, recoveryCodeBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	code := base32.StdEncoding.EncodeToString(buf)
+
+	return code[:4] + "-" + code[4:], nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToUpper(strings.TrimSpace(code))
+
+	return strings.ReplaceAll(code, "-", "")
+}
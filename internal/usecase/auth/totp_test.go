@@ -0,0 +1,260 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/resueman/merch-store/internal/entity"
+	"github.com/resueman/merch-store/internal/model"
+	"github.com/resueman/merch-store/internal/repo/repoerrors"
+	"github.com/resueman/merch-store/internal/usecase/apperrors"
+	"github.com/resueman/merch-store/pkg/totp"
+	"github.com/resueman/merch-store/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+const testTOTPSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func enabledTOTP(threshold int) *entity.TOTP {
+	enabledAt := time.Now().Add(-time.Hour)
+
+	return &entity.TOTP{UserID: 1, Secret: testTOTPSecret, Threshold: threshold, EnabledAt: &enabledAt}
+}
+
+func currentTOTPCode(t *testing.T) (string, int64) {
+	step := totp.Step(time.Now())
+	code, err := totp.Code(testTOTPSecret, step)
+	require.NoError(t, err)
+
+	return code, step
+}
+
+func TestAuthUsecase_GenerateToken_SecondFactor(t *testing.T) {
+	code, step := currentTOTPCode(t)
+	user := &entity.User{ID: 1, Username: "alice", Hash: "hash", Role: "user", TOTPEnabled: true}
+
+	t.Run("code required", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		userRepo := mocks.NewMockUser(ctrl)
+		passwordManager := mocks.NewMockPasswordManager(ctrl)
+		loginAttemptRepo := mocks.NewMockLoginAttempt(ctrl)
+
+		userRepo.EXPECT().GetUserByUsername(gomock.Any(), "alice").Return(user, nil)
+		passwordManager.EXPECT().ComparePassword("password", "hash").Return(true)
+		// Запрос без кода - это первый шаг входа, а не неудачная попытка.
+		loginAttemptRepo.EXPECT().GetLoginLockedUntil(gomock.Any(), gomock.Any()).Return(nil, nil)
+
+		uc := NewAuthUsecase(userRepo, nil, nil, loginAttemptRepo, nil, nil, nil, nil, passwordManager, nil, testConfig)
+		input := model.AuthRequestInput{Username: "alice", Password: "password"}
+
+		_, err := uc.GenerateToken(context.Background(), input)
+		require.ErrorIs(t, err, apperrors.ErrOTPRequired)
+	})
+
+	t.Run("valid code", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		userRepo := mocks.NewMockUser(ctrl)
+		refreshTokenRepo := mocks.NewMockRefreshToken(ctrl)
+		passwordManager := mocks.NewMockPasswordManager(ctrl)
+		totpRepo := mocks.NewMockTOTP(ctrl)
+
+		userRepo.EXPECT().GetUserByUsername(gomock.Any(), "alice").Return(user, nil)
+		passwordManager.EXPECT().ComparePassword("password", "hash").Return(true)
+		passwordManager.EXPECT().NeedsRehash("hash").Return(false)
+		totpRepo.EXPECT().GetTOTP(gomock.Any(), 1).Return(enabledTOTP(0), nil)
+		totpRepo.EXPECT().UseTOTPStep(gomock.Any(), 1, step).Return(nil)
+		refreshTokenRepo.EXPECT().CreateRefreshToken(gomock.Any(), gomock.Any()).Return(nil)
+
+		uc := NewAuthUsecase(userRepo, refreshTokenRepo, nil, noLoginLocksMock(ctrl), nil, nil, totpRepo, nil,
+			passwordManager, nil, testConfig)
+		input := model.AuthRequestInput{Username: "alice", Password: "password", OTPCode: code}
+
+		tokens, err := uc.GenerateToken(context.Background(), input)
+		require.NoError(t, err)
+		require.NotEmpty(t, tokens.AccessToken)
+	})
+
+	t.Run("replayed code", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		userRepo := mocks.NewMockUser(ctrl)
+		passwordManager := mocks.NewMockPasswordManager(ctrl)
+		loginAttemptRepo := mocks.NewMockLoginAttempt(ctrl)
+		totpRepo := mocks.NewMockTOTP(ctrl)
+
+		userRepo.EXPECT().GetUserByUsername(gomock.Any(), "alice").Return(user, nil)
+		passwordManager.EXPECT().ComparePassword("password", "hash").Return(true)
+		loginAttemptRepo.EXPECT().GetLoginLockedUntil(gomock.Any(), gomock.Any()).Return(nil, nil)
+		totpRepo.EXPECT().GetTOTP(gomock.Any(), 1).Return(enabledTOTP(0), nil)
+		totpRepo.EXPECT().UseTOTPStep(gomock.Any(), 1, step).Return(repoerrors.ErrNotFound)
+		// Повтор кода учитывается как неудачная попытка входа.
+		loginAttemptRepo.EXPECT().RecordLoginFailure(gomock.Any(), gomock.Any()).Return(1, nil)
+
+		uc := NewAuthUsecase(userRepo, nil, nil, loginAttemptRepo, nil, nil, totpRepo, nil, passwordManager, nil,
+			testConfig)
+		input := model.AuthRequestInput{Username: "alice", Password: "password", OTPCode: code}
+
+		_, err := uc.GenerateToken(context.Background(), input)
+		require.ErrorIs(t, err, apperrors.ErrInvalidOTP)
+	})
+}
+
+func TestAuthUsecase_VerifyOTP(t *testing.T) {
+	code, step := currentTOTPCode(t)
+
+	t.Run("totp not enabled", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		totpRepo := mocks.NewMockTOTP(ctrl)
+		totpRepo.EXPECT().GetTOTP(gomock.Any(), 1).Return(nil, repoerrors.ErrNotFound)
+
+		uc := NewAuthUsecase(nil, nil, nil, nil, nil, nil, totpRepo, nil, nil, nil, testConfig)
+		require.NoError(t, uc.VerifyOTP(context.Background(), 1, 1000, ""))
+	})
+
+	t.Run("amount within threshold", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		totpRepo := mocks.NewMockTOTP(ctrl)
+		totpRepo.EXPECT().GetTOTP(gomock.Any(), 1).Return(enabledTOTP(100), nil)
+
+		uc := NewAuthUsecase(nil, nil, nil, nil, nil, nil, totpRepo, nil, nil, nil, testConfig)
+		require.NoError(t, uc.VerifyOTP(context.Background(), 1, 100, ""))
+	})
+
+	t.Run("amount above threshold without code", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		totpRepo := mocks.NewMockTOTP(ctrl)
+		totpRepo.EXPECT().GetTOTP(gomock.Any(), 1).Return(enabledTOTP(100), nil)
+
+		uc := NewAuthUsecase(nil, nil, nil, nil, nil, nil, totpRepo, nil, nil, nil, testConfig)
+		require.ErrorIs(t, uc.VerifyOTP(context.Background(), 1, 101, ""), apperrors.ErrOTPRequired)
+	})
+
+	t.Run("fresh code", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		userRepo := mocks.NewMockUser(ctrl)
+		totpRepo := mocks.NewMockTOTP(ctrl)
+		userRepo.EXPECT().GetUserByID(gomock.Any(), 1).Return(&entity.User{ID: 1, Username: "alice"}, nil)
+		totpRepo.EXPECT().GetTOTP(gomock.Any(), 1).Return(enabledTOTP(100), nil)
+		totpRepo.EXPECT().UseTOTPStep(gomock.Any(), 1, step).Return(nil)
+
+		uc := NewAuthUsecase(userRepo, nil, nil, noLoginLocksMock(ctrl), nil, nil, totpRepo, nil, nil, nil, testConfig)
+		require.NoError(t, uc.VerifyOTP(context.Background(), 1, 500, code))
+	})
+
+	t.Run("recovery code", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		userRepo := mocks.NewMockUser(ctrl)
+		totpRepo := mocks.NewMockTOTP(ctrl)
+		userRepo.EXPECT().GetUserByID(gomock.Any(), 1).Return(&entity.User{ID: 1, Username: "alice"}, nil)
+		totpRepo.EXPECT().GetTOTP(gomock.Any(), 1).Return(enabledTOTP(0), nil)
+		totpRepo.EXPECT().UseRecoveryCode(gomock.Any(), 1, hashToken("ABCDEFGH")).Return(nil)
+
+		uc := NewAuthUsecase(userRepo, nil, nil, noLoginLocksMock(ctrl), nil, nil, totpRepo, nil, nil, nil, testConfig)
+		require.NoError(t, uc.VerifyOTP(context.Background(), 1, 500, "abcd-efgh"))
+	})
+
+	// Неверный код не записывается в транзакции операции: это делает RecordOTPFailure после ее отката.
+	t.Run("invalid code is not recorded", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		userRepo := mocks.NewMockUser(ctrl)
+		totpRepo := mocks.NewMockTOTP(ctrl)
+		loginAttemptRepo := mocks.NewMockLoginAttempt(ctrl)
+		userRepo.EXPECT().GetUserByID(gomock.Any(), 1).Return(&entity.User{ID: 1, Username: "alice"}, nil)
+		totpRepo.EXPECT().GetTOTP(gomock.Any(), 1).Return(enabledTOTP(0), nil)
+		totpRepo.EXPECT().UseRecoveryCode(gomock.Any(), 1, hashToken("ABCDEFGH")).Return(repoerrors.ErrNotFound)
+		loginAttemptRepo.EXPECT().GetLoginLockedUntil(gomock.Any(), gomock.Any()).Return(nil, nil)
+
+		uc := NewAuthUsecase(userRepo, nil, nil, loginAttemptRepo, nil, nil, totpRepo, nil, nil, nil, testConfig)
+		require.ErrorIs(t, uc.VerifyOTP(context.Background(), 1, 500, "abcd-efgh"), apperrors.ErrInvalidOTP)
+	})
+}
+
+func TestAuthUsecase_RecordOTPFailure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userRepo := mocks.NewMockUser(ctrl)
+	loginAttemptRepo := mocks.NewMockLoginAttempt(ctrl)
+	userRepo.EXPECT().GetUserByID(gomock.Any(), 1).Return(&entity.User{ID: 1, Username: "alice"}, nil)
+	loginAttemptRepo.EXPECT().RecordLoginFailure(gomock.Any(), gomock.Any()).Return(1, nil)
+
+	uc := NewAuthUsecase(userRepo, nil, nil, loginAttemptRepo, nil, nil, nil, nil, nil, nil, testConfig)
+	require.NoError(t, uc.RecordOTPFailure(context.Background(), 1))
+}
+
+func TestAuthUsecase_ConfirmTOTP(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	code, step := currentTOTPCode(t)
+
+	userRepo := mocks.NewMockUser(ctrl)
+	totpRepo := mocks.NewMockTOTP(ctrl)
+	txManager := mocks.NewMockTxManager(ctrl)
+
+	userRepo.EXPECT().GetUserByID(gomock.Any(), 1).Return(&entity.User{ID: 1, Username: "alice"}, nil)
+	totpRepo.EXPECT().GetTOTP(gomock.Any(), 1).Return(&entity.TOTP{UserID: 1, Secret: testTOTPSecret}, nil)
+	totpRepo.EXPECT().UseTOTPStep(gomock.Any(), 1, step).Return(nil)
+
+	readCommittedTxMock(txManager)
+
+	totpRepo.EXPECT().EnableTOTP(gomock.Any(), 1, 50).Return(nil)
+	totpRepo.EXPECT().DeleteRecoveryCodes(gomock.Any(), 1).Return(nil)
+
+	var storedHashes []string
+	totpRepo.EXPECT().
+		CreateRecoveryCodes(gomock.Any(), 1, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ int, hashes []string) error {
+			storedHashes = hashes
+
+			return nil
+		})
+
+	uc := NewAuthUsecase(userRepo, nil, nil, noLoginLocksMock(ctrl), nil, nil, totpRepo, nil, nil, txManager,
+		testConfig)
+
+	input := model.ConfirmTOTPInput{Code: code, Threshold: 50}
+
+	codes, err := uc.ConfirmTOTP(context.Background(), model.Claims{UserID: 1}, input)
+	require.NoError(t, err)
+	require.Len(t, codes, recoveryCodeCount)
+	require.Len(t, storedHashes, recoveryCodeCount)
+
+	// В БД попадают только хеши кодов восстановления.
+	for i, c := range codes {
+		require.Equal(t, hashToken(normalizeRecoveryCode(c)), storedHashes[i])
+		require.NotContains(t, storedHashes, c)
+	}
+}
+
+func TestAuthUsecase_EnrollTOTP_ServiceAccount(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userRepo := mocks.NewMockUser(ctrl)
+	userRepo.EXPECT().GetUserByID(gomock.Any(), 5).Return(&entity.User{ID: 5, Username: "bot", IsService: true}, nil)
+
+	uc := NewAuthUsecase(userRepo, nil, nil, nil, nil, nil, mocks.NewMockTOTP(ctrl), nil, nil, nil, testConfig)
+
+	_, err := uc.EnrollTOTP(context.Background(), model.Claims{UserID: 5})
+	require.ErrorIs(t, err, apperrors.ErrServiceAccount)
+}
@@ -1,6 +1,8 @@
 package converter
 
 import (
+	"time"
+
 	"github.com/resueman/merch-store/internal/entity"
 	"github.com/resueman/merch-store/internal/model"
 )
@@ -40,3 +42,156 @@ func ConvertTransfersToIncomingTransfers(transfers []entity.Transfer) []model.In
 
 	return incomingTransfers
 }
+
+func ConvertRefund(refund entity.Refund) model.Refund {
+	return model.Refund{
+		ID:         refund.ID,
+		PurchaseID: refund.PurchaseID,
+		Username:   refund.CustomerUsername,
+		ItemName:   refund.ProductName,
+		Quantity:   refund.Quantity,
+		Amount:     refund.Amount,
+		Status:     model.RefundStatus(refund.Status),
+		CreatedAt:  refund.CreatedAt,
+		DecidedAt:  refund.DecidedAt,
+	}
+}
+
+func ConvertRefunds(refunds []entity.Refund) []model.Refund {
+	result := make([]model.Refund, 0, len(refunds))
+	for _, refund := range refunds {
+		result = append(result, ConvertRefund(refund))
+	}
+
+	return result
+}
+
+// window - срок, в течение которого после покупки можно подать заявку на возврат.
+func ConvertRefundablePurchases(purchases []entity.RefundablePurchase, window time.Duration,
+) []model.RefundablePurchase {
+	result := make([]model.RefundablePurchase, 0, len(purchases))
+	for _, purchase := range purchases {
+		result = append(result, model.RefundablePurchase{
+			ID:                 purchase.ID,
+			ItemName:           purchase.ProductName,
+			Quantity:           purchase.Quantity,
+			RefundableQuantity: purchase.Quantity - purchase.RefundedQuantity,
+			TotalPrice:         purchase.TotalPrice,
+			PurchasedAt:        purchase.CreatedAt,
+			RefundableUntil:    purchase.CreatedAt.Add(window),
+		})
+	}
+
+	return result
+}
+
+func ConvertProduct(product entity.Product) model.Product {
+	return model.Product{
+		Name:        product.Name,
+		Price:       product.Price,
+		Description: product.Description,
+		Stock:       product.Stock,
+	}
+}
+
+func ConvertProducts(products []entity.Product) []model.Product {
+	result := make([]model.Product, 0, len(products))
+	for _, product := range products {
+		result = append(result, ConvertProduct(product))
+	}
+
+	return result
+}
+
+// Цена и остаток варианта, если они не заданы, берутся у товара.
+func ConvertProductVariant(product entity.Product, variant entity.ProductVariant) model.ProductVariant {
+	result := model.ProductVariant{
+		Name:  variant.Name,
+		SKU:   variant.SKU,
+		Price: product.Price,
+		Stock: product.Stock,
+	}
+
+	if variant.Price != nil {
+		result.Price = *variant.Price
+	}
+
+	if variant.Stock != nil {
+		result.Stock = *variant.Stock
+	}
+
+	return result
+}
+
+func ConvertProductVariants(product entity.Product, variants []entity.ProductVariant) []model.ProductVariant {
+	result := make([]model.ProductVariant, 0, len(variants))
+	for _, variant := range variants {
+		result = append(result, ConvertProductVariant(product, variant))
+	}
+
+	return result
+}
+
+func ConvertProductAudit(entries []entity.ProductAudit) []model.ProductAuditEntry {
+	result := make([]model.ProductAuditEntry, 0, len(entries))
+	for _, entry := range entries {
+		auditEntry := model.ProductAuditEntry{
+			Action:    model.ProductAuditAction(entry.Action),
+			CreatedAt: entry.CreatedAt,
+		}
+
+		if entry.Field != nil {
+			auditEntry.Field = *entry.Field
+		}
+
+		if entry.OldValue != nil {
+			auditEntry.OldValue = *entry.OldValue
+		}
+
+		if entry.NewValue != nil {
+			auditEntry.NewValue = *entry.NewValue
+		}
+
+		if entry.ChangedByUsername != nil {
+			auditEntry.ChangedBy = *entry.ChangedByUsername
+		}
+
+		result = append(result, auditEntry)
+	}
+
+	return result
+}
+
+func ConvertHistoryEntries(entries []entity.HistoryEntry) []model.HistoryEntry {
+	result := make([]model.HistoryEntry, 0, len(entries))
+	for _, entry := range entries {
+		result = append(result, model.HistoryEntry{
+			ID:           entry.OperationID,
+			Type:         model.HistoryOperationType(entry.OperationType),
+			Direction:    model.HistoryDirection(entry.Direction),
+			Amount:       entry.Amount,
+			Counterparty: entry.Counterparty,
+			ItemName:     entry.ProductName,
+			Quantity:     entry.Quantity,
+			RefundStatus: model.RefundStatus(entry.RefundStatus),
+			CreatedAt:    entry.CreatedAt,
+		})
+	}
+
+	return result
+}
+
+func ConvertOperation(operation entity.OperationDetails) model.Operation {
+	return model.Operation{
+		ID:                operation.ID,
+		Type:              model.HistoryOperationType(operation.OperationType),
+		Username:          operation.Username,
+		RecipientUsername: operation.RecipientUsername,
+		Amount:            operation.Amount,
+		ItemName:          operation.ProductName,
+		Quantity:          operation.Quantity,
+		TotalPrice:        operation.TotalPrice,
+		RefundStatus:      model.RefundStatus(operation.RefundStatus),
+		CreatedAt:         operation.CreatedAt,
+	}
+}
@@ -0,0 +1,112 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/resueman/merch-store/internal/entity"
+	"github.com/resueman/merch-store/internal/model"
+	"github.com/resueman/merch-store/internal/repo"
+	"github.com/resueman/merch-store/internal/repo/repoerrors"
+	"github.com/resueman/merch-store/internal/usecase/apperrors"
+	"github.com/resueman/merch-store/pkg/db"
+)
+
+type Config struct {
+	// Сколько хранится результат запроса. После этого ключ можно использовать снова.
+	KeyTTL time.Duration
+}
+
+type idempotencyUsecase struct {
+	idempotencyRepo repo.Idempotency
+	txManager       db.TxManager
+	keyTTL          time.Duration
+}
+
+func NewIdempotencyUsecase(idempotencyRepo repo.Idempotency, txManager db.TxManager, cfg Config) *idempotencyUsecase {
+	return &idempotencyUsecase{
+		idempotencyRepo: idempotencyRepo,
+		txManager:       txManager,
+		keyTTL:          cfg.KeyTTL,
+	}
+}
+
+// Выполняет handle не больше одного раза для ключа пользователя и возвращает сохраненный ответ при повторе.
+// handle получает контекст с транзакцией, в которой сохраняется ключ, поэтому операция и ее результат
+// фиксируются вместе, а параллельный запрос с тем же ключом дожидается первого.
+// Неуспешный ответ не сохраняется: запрос с тем же ключом можно повторить, например с кодом второго фактора.
+func (u *idempotencyUsecase) Execute(
+	ctx context.Context,
+	claims model.Claims,
+	request model.IdempotentRequest,
+	handle func(ctx context.Context) (model.IdempotentResponse, error),
+) (model.IdempotentResponse, error) {
+	var result model.IdempotentResponse
+
+	transaction := func(ctx context.Context) error {
+		now := time.Now()
+		if err := u.idempotencyRepo.DeleteExpiredIdempotencyKeys(ctx, claims.UserID, now); err != nil {
+			return err
+		}
+
+		key := entity.IdempotencyKey{
+			UserID:      claims.UserID,
+			Key:         request.Key,
+			Fingerprint: request.Fingerprint,
+			ExpiresAt:   now.Add(u.keyTTL),
+		}
+
+		err := u.idempotencyRepo.CreateIdempotencyKey(ctx, key)
+		if errors.Is(err, repoerrors.ErrAlreadyExists) {
+			result, err = u.replay(ctx, claims.UserID, request)
+			return err
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if result, err = handle(ctx); err != nil {
+			return err
+		}
+
+		if result.StatusCode < http.StatusOK || result.StatusCode >= http.StatusMultipleChoices {
+			return u.idempotencyRepo.DeleteIdempotencyKey(ctx, claims.UserID, request.Key)
+		}
+
+		key.StatusCode = result.StatusCode
+		key.ContentType = result.ContentType
+		key.ResponseBody = result.Body
+
+		return u.idempotencyRepo.SaveIdempotencyResponse(ctx, key)
+	}
+
+	serializable := u.txManager.Serializable(ctx, db.Write, transaction)
+	if err := u.txManager.WithRetry(serializable); err != nil {
+		return model.IdempotentResponse{}, err
+	}
+
+	return result, nil
+}
+
+func (u *idempotencyUsecase) replay(ctx context.Context, userID int, request model.IdempotentRequest) (
+	model.IdempotentResponse, error,
+) {
+	stored, err := u.idempotencyRepo.GetIdempotencyKey(ctx, userID, request.Key)
+	if err != nil {
+		return model.IdempotentResponse{}, err
+	}
+
+	if stored.Fingerprint != request.Fingerprint {
+		return model.IdempotentResponse{}, apperrors.ErrIdempotencyKeyReused
+	}
+
+	return model.IdempotentResponse{
+		StatusCode:  stored.StatusCode,
+		ContentType: stored.ContentType,
+		Body:        stored.ResponseBody,
+		Replayed:    true,
+	}, nil
+}
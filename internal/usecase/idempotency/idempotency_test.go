@@ -0,0 +1,120 @@
+package idempotency
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/resueman/merch-store/internal/entity"
+	"github.com/resueman/merch-store/internal/model"
+	"github.com/resueman/merch-store/internal/repo/repoerrors"
+	"github.com/resueman/merch-store/internal/usecase/apperrors"
+	"github.com/resueman/merch-store/pkg/db"
+	"github.com/resueman/merch-store/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func txMock(txManager *mocks.MockTxManager) {
+	txManager.EXPECT().
+		Serializable(gomock.Any(), db.Write, gomock.Any()).
+		DoAndReturn(func(ctx context.Context, _ db.Mode, f func(context.Context) error) func() error {
+			return func() error { return f(ctx) }
+		})
+
+	txManager.EXPECT().
+		WithRetry(gomock.Any()).
+		DoAndReturn(func(f func() error) error {
+			return f()
+		})
+}
+
+func TestExecute(t *testing.T) {
+	claims := model.Claims{UserID: 111}
+	request := model.IdempotentRequest{Key: "key", Fingerprint: "print"}
+	created := gomock.AssignableToTypeOf(entity.IdempotencyKey{})
+
+	tests := []struct {
+		name      string
+		mock      func(repo *mocks.MockIdempotency)
+		handled   model.IdempotentResponse
+		wantCalls int
+		want      model.IdempotentResponse
+		wantErr   error
+	}{
+		{
+			name: "first request saves the response",
+			mock: func(repo *mocks.MockIdempotency) {
+				repo.EXPECT().CreateIdempotencyKey(gomock.Any(), created).Return(nil)
+				repo.EXPECT().SaveIdempotencyResponse(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, key entity.IdempotencyKey) error {
+						require.Equal(t, "key", key.Key)
+						require.Equal(t, http.StatusOK, key.StatusCode)
+						require.Equal(t, []byte("{}"), key.ResponseBody)
+						return nil
+					})
+			},
+			handled:   model.IdempotentResponse{StatusCode: http.StatusOK, ContentType: "application/json", Body: []byte("{}")},
+			wantCalls: 1,
+			want:      model.IdempotentResponse{StatusCode: http.StatusOK, ContentType: "application/json", Body: []byte("{}")},
+		},
+		{
+			name: "failed request releases the key",
+			mock: func(repo *mocks.MockIdempotency) {
+				repo.EXPECT().CreateIdempotencyKey(gomock.Any(), created).Return(nil)
+				repo.EXPECT().DeleteIdempotencyKey(gomock.Any(), 111, "key").Return(nil)
+			},
+			handled:   model.IdempotentResponse{StatusCode: http.StatusBadRequest},
+			wantCalls: 1,
+			want:      model.IdempotentResponse{StatusCode: http.StatusBadRequest},
+		},
+		{
+			name: "duplicate replays the stored response",
+			mock: func(repo *mocks.MockIdempotency) {
+				repo.EXPECT().CreateIdempotencyKey(gomock.Any(), created).Return(repoerrors.ErrAlreadyExists)
+				repo.EXPECT().GetIdempotencyKey(gomock.Any(), 111, "key").Return(&entity.IdempotencyKey{
+					Fingerprint: "print", StatusCode: http.StatusOK, ContentType: "application/json",
+					ResponseBody: []byte("{}"),
+				}, nil)
+			},
+			want: model.IdempotentResponse{
+				StatusCode: http.StatusOK, ContentType: "application/json", Body: []byte("{}"), Replayed: true,
+			},
+		},
+		{
+			name: "key reused with another request",
+			mock: func(repo *mocks.MockIdempotency) {
+				repo.EXPECT().CreateIdempotencyKey(gomock.Any(), created).Return(repoerrors.ErrAlreadyExists)
+				repo.EXPECT().GetIdempotencyKey(gomock.Any(), 111, "key").
+					Return(&entity.IdempotencyKey{Fingerprint: "other"}, nil)
+			},
+			wantErr: apperrors.ErrIdempotencyKeyReused,
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo, txManager := mocks.NewMockIdempotency(ctrl), mocks.NewMockTxManager(ctrl)
+			repo.EXPECT().DeleteExpiredIdempotencyKeys(gomock.Any(), 111, gomock.Any()).Return(nil)
+			testCase.mock(repo)
+			txMock(txManager)
+
+			calls := 0
+			handle := func(_ context.Context) (model.IdempotentResponse, error) {
+				calls++
+				return testCase.handled, nil
+			}
+
+			uc := NewIdempotencyUsecase(repo, txManager, Config{KeyTTL: time.Hour})
+			result, err := uc.Execute(context.Background(), claims, request, handle)
+
+			require.ErrorIs(t, err, testCase.wantErr)
+			require.Equal(t, testCase.wantCalls, calls)
+			require.Equal(t, testCase.want, result)
+		})
+	}
+}
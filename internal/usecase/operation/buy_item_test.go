@@ -3,6 +3,7 @@ package operation
 import (
 	"context"
 	"errors"
+	"math"
 	"testing"
 
 	"github.com/golang/mock/gomock"
@@ -28,6 +29,7 @@ func TestBuyItem_BadInputError(t *testing.T) {
 		claims   model.Claims
 		itemName string
 		mock     func(accountRepo *mocks.MockAccount, productRepo *mocks.MockProduct)
+		inTx     bool
 		want     error
 	}{
 		{
@@ -53,6 +55,7 @@ func TestBuyItem_BadInputError(t *testing.T) {
 					GetProductByName(gomock.Any(), "qwerty").
 					Return(nil, errUnknownGetProductByName)
 			},
+			inTx: true,
 			want: errUnknownGetProductByName,
 		},
 		{
@@ -67,6 +70,7 @@ func TestBuyItem_BadInputError(t *testing.T) {
 					GetProductByName(gomock.Any(), "pen").
 					Return(nil, repoerrors.ErrNotFound)
 			},
+			inTx: true,
 			want: apperrors.ErrProductNotFound,
 		},
 	}
@@ -78,8 +82,15 @@ func TestBuyItem_BadInputError(t *testing.T) {
 			accountRepo, productRepo := mocks.NewMockAccount(ctrl), mocks.NewMockProduct(ctrl)
 			testCase.mock(accountRepo, productRepo)
 
-			uc := NewOperationUsecase(accountRepo, nil, productRepo, nil)
-			err := uc.BuyItem(context.Background(), testCase.claims, testCase.itemName)
+			// Товар читается уже в транзакции покупки.
+			txManager := mocks.NewMockTxManager(ctrl)
+			if testCase.inTx {
+				cartTxMock(txManager, true)
+			}
+
+			uc := NewOperationUsecase(accountRepo, nil, productRepo, nil, noSecondFactorMock(ctrl), txManager,
+				Config{})
+			err := uc.BuyItem(context.Background(), testCase.claims, testCase.itemName, "", 1, "")
 
 			require.ErrorIs(t, err, testCase.want)
 		})
@@ -104,6 +115,10 @@ func purchaseWithdrawErrorMock(
 		GetProductByName(gomock.Any(), product.Name).
 		Return(&product, nil)
 
+	productRepo.EXPECT().
+		ReserveStock(gomock.Any(), product.ID, 1).
+		Return(nil)
+
 	accountRepo.EXPECT().
 		Withdraw(gomock.Any(), gomock.Any(), gomock.Any()).
 		Return(withdrawErr)
@@ -162,8 +177,8 @@ func TestBuyItems_WithdrawErrorInOperation(t *testing.T) {
 
 			testCase.mock(accountRepo, productRepo, txManager)
 
-			uc := NewOperationUsecase(accountRepo, nil, productRepo, txManager)
-			err := uc.BuyItem(context.Background(), testCase.claims, testCase.itemName)
+			uc := NewOperationUsecase(accountRepo, nil, productRepo, nil, noSecondFactorMock(ctrl), txManager, Config{})
+			err := uc.BuyItem(context.Background(), testCase.claims, testCase.itemName, "", 1, "")
 
 			require.ErrorIs(t, err, testCase.want)
 		})
@@ -206,6 +221,10 @@ func TestBuyItems_PurchaseErrorInOperation(t *testing.T) {
 					GetProductByName(gomock.Any(), product.Name).
 					Return(&product, nil)
 
+				productRepo.EXPECT().
+					ReserveStock(gomock.Any(), product.ID, 1).
+					Return(nil)
+
 				accountRepo.EXPECT().
 					Withdraw(gomock.Any(), accountID, product.Price).
 					Return(nil)
@@ -239,8 +258,9 @@ func TestBuyItems_PurchaseErrorInOperation(t *testing.T) {
 
 			testCase.mock(accountRepo, operationRepo, productRepo, txManager)
 
-			uc := NewOperationUsecase(accountRepo, operationRepo, productRepo, txManager)
-			err := uc.BuyItem(context.Background(), testCase.claims, testCase.itemName)
+			uc := NewOperationUsecase(accountRepo, operationRepo, productRepo, nil, noSecondFactorMock(ctrl), txManager,
+				Config{})
+			err := uc.BuyItem(context.Background(), testCase.claims, testCase.itemName, "", 1, "")
 
 			require.ErrorIs(t, err, testCase.want)
 		})
@@ -276,11 +296,6 @@ func TestBuyItems_TxManagerError(t *testing.T) {
 					GetIDByUserID(gomock.Any(), 111).
 					Return(accountID, nil)
 
-				product := entity.Product{ID: 120, Name: "pen", Price: 100}
-				productRepo.EXPECT().
-					GetProductByName(gomock.Any(), "pen").
-					Return(&product, nil)
-
 				txManager.EXPECT().
 					Serializable(gomock.Any(), db.Write, gomock.Any()).
 					DoAndReturn(func(ctx context.Context, _ db.Mode, f func(context.Context) error) func() error {
@@ -304,8 +319,9 @@ func TestBuyItems_TxManagerError(t *testing.T) {
 
 			testCase.mock(accountRepo, operationRepo, productRepo, txManager)
 
-			uc := NewOperationUsecase(accountRepo, operationRepo, productRepo, txManager)
-			err := uc.BuyItem(context.Background(), testCase.claims, testCase.itemName)
+			uc := NewOperationUsecase(accountRepo, operationRepo, productRepo, nil, noSecondFactorMock(ctrl), txManager,
+				Config{})
+			err := uc.BuyItem(context.Background(), testCase.claims, testCase.itemName, "", 1, "")
 
 			require.ErrorIs(t, err, testCase.want)
 		})
@@ -345,6 +361,10 @@ func TestBuyItems_Ok(t *testing.T) {
 					GetProductByName(gomock.Any(), "pen").
 					Return(&product, nil)
 
+				productRepo.EXPECT().
+					ReserveStock(gomock.Any(), product.ID, 1).
+					Return(nil)
+
 				accountRepo.EXPECT().
 					Withdraw(gomock.Any(), accountID, product.Price).
 					Return(nil)
@@ -377,10 +397,249 @@ func TestBuyItems_Ok(t *testing.T) {
 
 			testCase.mock(accountRepo, operationRepo, productRepo, txManager)
 
-			uc := NewOperationUsecase(accountRepo, operationRepo, productRepo, txManager)
-			err := uc.BuyItem(context.Background(), testCase.claims, testCase.itemName)
+			uc := NewOperationUsecase(accountRepo, operationRepo, productRepo, nil, noSecondFactorMock(ctrl), txManager,
+				Config{})
+			err := uc.BuyItem(context.Background(), testCase.claims, testCase.itemName, "", 1, "")
 
 			require.NoError(t, err)
 		})
 	}
 }
+
+func TestBuyItem_SecondFactorUsesPrice(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	accountRepo, productRepo := mocks.NewMockAccount(ctrl), mocks.NewMockProduct(ctrl)
+	accountRepo.EXPECT().GetIDByUserID(gomock.Any(), 111).Return(1, nil)
+	productRepo.EXPECT().GetProductByName(gomock.Any(), "hoody").Return(&entity.Product{ID: 7, Price: 300}, nil)
+
+	secondFactor := mocks.NewMockSecondFactor(ctrl)
+	secondFactor.EXPECT().VerifyOTP(gomock.Any(), 111, 300, "").Return(apperrors.ErrOTPRequired)
+
+	// Код проверяется в транзакции покупки, до резервирования товара.
+	txManager := mocks.NewMockTxManager(ctrl)
+	cartTxMock(txManager, true)
+
+	uc := NewOperationUsecase(accountRepo, nil, productRepo, nil, secondFactor, txManager, Config{})
+	err := uc.BuyItem(context.Background(), model.Claims{UserID: 111}, "hoody", "", 1, "")
+
+	require.ErrorIs(t, err, apperrors.ErrOTPRequired)
+}
+
+func TestBuyItem_InvalidQuantity(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name     string
+		quantity int
+		price    int
+		want     error
+	}{
+		{name: "zero quantity", quantity: 0, want: apperrors.ErrInvalidQuantity},
+		{name: "negative quantity", quantity: -3, want: apperrors.ErrInvalidQuantity},
+		{name: "default limit exceeded", quantity: 101, want: apperrors.ErrQuantityLimitExceeded},
+		{name: "total price overflow", quantity: 100, price: math.MaxInt32 / 50, want: apperrors.ErrTotalPriceOverflow},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			accountRepo, productRepo := mocks.NewMockAccount(ctrl), mocks.NewMockProduct(ctrl)
+			txManager := mocks.NewMockTxManager(ctrl)
+			if testCase.price != 0 {
+				accountRepo.EXPECT().GetIDByUserID(gomock.Any(), 111).Return(1, nil)
+				productRepo.EXPECT().
+					GetProductByName(gomock.Any(), "pen").
+					Return(&entity.Product{ID: 1, Name: "pen", Price: testCase.price}, nil)
+				cartTxMock(txManager, true)
+			}
+
+			uc := NewOperationUsecase(accountRepo, nil, productRepo, nil, noSecondFactorMock(ctrl), txManager,
+				Config{})
+			err := uc.BuyItem(context.Background(), model.Claims{UserID: 111}, "pen", "", testCase.quantity, "")
+
+			require.ErrorIs(t, err, testCase.want)
+		})
+	}
+}
+
+func TestBuyItem_SeveralUnits(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	accountRepo, productRepo := mocks.NewMockAccount(ctrl), mocks.NewMockProduct(ctrl)
+	operationRepo, txManager := mocks.NewMockOperation(ctrl), mocks.NewMockTxManager(ctrl)
+
+	accountRepo.EXPECT().GetIDByUserID(gomock.Any(), 111).Return(5, nil)
+	productRepo.EXPECT().
+		GetProductByName(gomock.Any(), "socks").
+		Return(&entity.Product{ID: 9, Name: "socks", Price: 10}, nil)
+
+	secondFactor := mocks.NewMockSecondFactor(ctrl)
+	secondFactor.EXPECT().VerifyOTP(gomock.Any(), 111, 150, "").Return(nil)
+
+	productRepo.EXPECT().ReserveStock(gomock.Any(), 9, 15).Return(nil)
+	accountRepo.EXPECT().Withdraw(gomock.Any(), 5, 150).Return(nil)
+	operationRepo.EXPECT().
+		ExecPurchaseOperation(gomock.Any(), entity.PurchaseOperation{
+			ItemID:            9,
+			CustomerAccountID: 5,
+			Quantity:          15,
+			TotalPrice:        150,
+		}).
+		Return(nil)
+
+	txManager.EXPECT().
+		Serializable(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, _ db.Mode, f func(context.Context) error) func() error {
+			return func() error { return f(ctx) }
+		})
+	txManager.EXPECT().
+		WithRetry(gomock.Any()).
+		DoAndReturn(func(f func() error) error {
+			return f()
+		})
+
+	uc := NewOperationUsecase(accountRepo, operationRepo, productRepo, nil, secondFactor, txManager,
+		Config{MaxPurchaseQuantity: 20})
+	err := uc.BuyItem(context.Background(), model.Claims{UserID: 111}, "socks", "", 15, "")
+
+	require.NoError(t, err)
+}
+
+func TestBuyItem_OutOfStock(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	accountRepo, productRepo := mocks.NewMockAccount(ctrl), mocks.NewMockProduct(ctrl)
+	txManager := mocks.NewMockTxManager(ctrl)
+
+	accountRepo.EXPECT().GetIDByUserID(gomock.Any(), 111).Return(5, nil)
+	productRepo.EXPECT().
+		GetProductByName(gomock.Any(), "pink-hoody").
+		Return(&entity.Product{ID: 10, Name: "pink-hoody", Price: 500, Stock: 1}, nil)
+
+	// Если товара не хватает, монеты не списываются.
+	productRepo.EXPECT().ReserveStock(gomock.Any(), 10, 2).Return(repoerrors.ErrNotEnoughStock)
+
+	txManager.EXPECT().
+		Serializable(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, _ db.Mode, f func(context.Context) error) func() error {
+			return func() error { return f(ctx) }
+		})
+	txManager.EXPECT().
+		WithRetry(gomock.Any()).
+		DoAndReturn(func(f func() error) error {
+			return f()
+		})
+
+	uc := NewOperationUsecase(accountRepo, nil, productRepo, nil, noSecondFactorMock(ctrl), txManager, Config{})
+	err := uc.BuyItem(context.Background(), model.Claims{UserID: 111}, "pink-hoody", "", 2, "")
+
+	require.ErrorIs(t, err, apperrors.ErrOutOfStock)
+}
+
+func TestBuyItem_Variant(t *testing.T) {
+	ownPrice, ownStock := 350, 4
+
+	tests := []struct {
+		name string
+		mock func(productRepo *mocks.MockProduct, accountRepo *mocks.MockAccount,
+			operationRepo *mocks.MockOperation, txManager *mocks.MockTxManager)
+		want error
+	}{
+		{
+			name: "unknown variant",
+			mock: func(productRepo *mocks.MockProduct, _ *mocks.MockAccount, _ *mocks.MockOperation,
+				txManager *mocks.MockTxManager) {
+				productRepo.EXPECT().GetProductVariant(gomock.Any(), 6, "XL").Return(nil, repoerrors.ErrNotFound)
+				cartTxMock(txManager, true)
+			},
+			want: apperrors.ErrVariantNotFound,
+		},
+		{
+			name: "variant with its own price and stock",
+			mock: func(productRepo *mocks.MockProduct, accountRepo *mocks.MockAccount,
+				operationRepo *mocks.MockOperation, txManager *mocks.MockTxManager) {
+				productRepo.EXPECT().GetProductVariant(gomock.Any(), 6, "XL").
+					Return(&entity.ProductVariant{ID: 3, ProductID: 6, Name: "XL", Price: &ownPrice, Stock: &ownStock},
+						nil)
+				productRepo.EXPECT().ReserveVariantStock(gomock.Any(), 3, 2).Return(nil)
+				accountRepo.EXPECT().Withdraw(gomock.Any(), 5, 700).Return(nil)
+				variantID := 3
+				operationRepo.EXPECT().ExecPurchaseOperation(gomock.Any(), entity.PurchaseOperation{
+					ItemID: 6, VariantID: &variantID, CustomerAccountID: 5, Quantity: 2, TotalPrice: 700,
+				}).Return(nil)
+				cartTxMock(txManager, true)
+			},
+		},
+		{
+			name: "variant sharing the product price and stock",
+			mock: func(productRepo *mocks.MockProduct, accountRepo *mocks.MockAccount,
+				operationRepo *mocks.MockOperation, txManager *mocks.MockTxManager) {
+				productRepo.EXPECT().GetProductVariant(gomock.Any(), 6, "XL").
+					Return(&entity.ProductVariant{ID: 3, ProductID: 6, Name: "XL"}, nil)
+				productRepo.EXPECT().ReserveStock(gomock.Any(), 6, 2).Return(nil)
+				accountRepo.EXPECT().Withdraw(gomock.Any(), 5, 600).Return(nil)
+				operationRepo.EXPECT().ExecPurchaseOperation(gomock.Any(), gomock.Any()).Return(nil)
+				cartTxMock(txManager, true)
+			},
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			accountRepo, productRepo := mocks.NewMockAccount(ctrl), mocks.NewMockProduct(ctrl)
+			operationRepo, txManager := mocks.NewMockOperation(ctrl), mocks.NewMockTxManager(ctrl)
+
+			accountRepo.EXPECT().GetIDByUserID(gomock.Any(), 111).Return(5, nil)
+			productRepo.EXPECT().GetProductByName(gomock.Any(), "hoody").
+				Return(&entity.Product{ID: 6, Name: "hoody", Price: 300, Stock: 10}, nil)
+			testCase.mock(productRepo, accountRepo, operationRepo, txManager)
+
+			uc := NewOperationUsecase(accountRepo, operationRepo, productRepo, nil, noSecondFactorMock(ctrl), txManager,
+				Config{})
+			err := uc.BuyItem(context.Background(), model.Claims{UserID: 111}, "hoody", "XL", 2, "")
+
+			require.ErrorIs(t, err, testCase.want)
+		})
+	}
+}
+
+func TestBuyItem_PriceReadInTransaction(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	type txKey struct{}
+
+	accountRepo, productRepo := mocks.NewMockAccount(ctrl), mocks.NewMockProduct(ctrl)
+	operationRepo, txManager := mocks.NewMockOperation(ctrl), mocks.NewMockTxManager(ctrl)
+
+	accountRepo.EXPECT().GetIDByUserID(gomock.Any(), 111).Return(5, nil)
+
+	// Цена, по которой списываются монеты, читается в той же транзакции, что и списание.
+	productRepo.EXPECT().GetProductByName(gomock.Any(), "hoody").
+		DoAndReturn(func(ctx context.Context, _ string) (*entity.Product, error) {
+			require.NotNil(t, ctx.Value(txKey{}))
+			return &entity.Product{ID: 6, Name: "hoody", Price: 350}, nil
+		})
+	productRepo.EXPECT().ReserveStock(gomock.Any(), 6, 1).Return(nil)
+	accountRepo.EXPECT().Withdraw(gomock.Any(), 5, 350).Return(nil)
+	operationRepo.EXPECT().ExecPurchaseOperation(gomock.Any(), gomock.Any()).Return(nil)
+
+	txManager.EXPECT().Serializable(gomock.Any(), db.Write, gomock.Any()).
+		DoAndReturn(func(ctx context.Context, _ db.Mode, f func(context.Context) error) func() error {
+			return func() error { return f(context.WithValue(ctx, txKey{}, true)) }
+		})
+	txManager.EXPECT().WithRetry(gomock.Any()).DoAndReturn(func(f func() error) error { return f() })
+
+	uc := NewOperationUsecase(accountRepo, operationRepo, productRepo, nil, noSecondFactorMock(ctrl), txManager,
+		Config{})
+	err := uc.BuyItem(context.Background(), model.Claims{UserID: 111}, "hoody", "", 1, "")
+
+	require.NoError(t, err)
+}
@@ -0,0 +1,269 @@
+package operation
+
+import (
+	"context"
+	"errors"
+
+	"github.com/resueman/merch-store/internal/entity"
+	"github.com/resueman/merch-store/internal/model"
+	"github.com/resueman/merch-store/internal/repo/repoerrors"
+	"github.com/resueman/merch-store/internal/usecase/apperrors"
+	"github.com/resueman/merch-store/pkg/db"
+)
+
+// Количество товара в строке корзины ограничено тем же лимитом, что и одна покупка.
+func (u *operationUsecase) AddToCart(ctx context.Context, claims model.Claims, input model.CartItemInput) error {
+	if input.Quantity <= 0 {
+		return apperrors.ErrInvalidQuantity
+	}
+
+	if input.Quantity > u.maxPurchaseQuantity {
+		return apperrors.ErrQuantityLimitExceeded
+	}
+
+	product, variant, _, err := u.getPurchaseItem(ctx, input.ItemName, input.Variant)
+	if err != nil {
+		return err
+	}
+
+	transaction := func(ctx context.Context) error {
+		quantity, err := u.cartRepo.AddCartItem(ctx, claims.UserID, product.ID, variantID(variant), input.Quantity)
+		if err != nil {
+			return err
+		}
+
+		if quantity > u.maxPurchaseQuantity {
+			return apperrors.ErrQuantityLimitExceeded
+		}
+
+		return nil
+	}
+
+	readCommitted := u.txManager.ReadCommitted(ctx, db.Write, transaction)
+	if err = u.txManager.WithRetry(readCommitted); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (u *operationUsecase) RemoveFromCart(ctx context.Context, claims model.Claims, itemName string,
+	variant string,
+) error {
+	if err := u.cartRepo.RemoveCartItem(ctx, claims.UserID, itemName, variant); err != nil {
+		if errors.Is(err, repoerrors.ErrNotFound) {
+			return apperrors.ErrCartItemNotFound
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+// Суммы строк и корзины проверяются на переполнение так же, как при покупке.
+func (u *operationUsecase) GetCart(ctx context.Context, claims model.Claims) (model.Cart, error) {
+	items, err := u.cartRepo.GetCartItems(ctx, claims.UserID)
+	if err != nil {
+		return model.Cart{}, err
+	}
+
+	cart := model.Cart{Items: make([]model.CartItem, 0, len(items))}
+	for _, item := range items {
+		totalPrice, err := calculateTotalPrice(item.Price, item.Quantity)
+		if err != nil {
+			return model.Cart{}, err
+		}
+
+		cart.Items = append(cart.Items, model.CartItem{
+			Name:       item.ProductName,
+			Variant:    item.VariantName,
+			Price:      item.Price,
+			Quantity:   item.Quantity,
+			TotalPrice: totalPrice,
+		})
+
+		if cart.TotalPrice > maxTotalPrice-totalPrice {
+			return model.Cart{}, apperrors.ErrTotalPriceOverflow
+		}
+
+		cart.TotalPrice += totalPrice
+	}
+
+	return cart, nil
+}
+
+// Проверить:
+// 1. Корзина не пуста
+// 2. Каждый товар из корзины существует, количество в пределах лимита, сумма строки не переполняется,
+// иначе вернуть CheckoutError со всеми проблемными строками
+// 3. Итоговая сумма не переполняет int32 (колонки в бд)
+// 4. Заказ дороже порога пользователя подтвержден кодом второго фактора
+// 5. Каждого товара достаточно на складе (резервируется в бд), иначе вернуть CheckoutError
+// со всеми строками, которых не хватает
+// 6. Кол-во монет достаточно для оплаты всей корзины (проверяется в бд)
+// Корзина читается и оценивается в той же транзакции, в которой оплачивается: заказ всегда соответствует
+// тому, что в ней лежало, а все строки покупаются вместе — либо оформляется весь заказ, либо ничего.
+func (u *operationUsecase) Checkout(ctx context.Context, claims model.Claims, otpCode string) (model.Cart, error) {
+	customerAccountID, err := u.accountRepo.GetIDByUserID(ctx, claims.UserID)
+	if err != nil {
+		return model.Cart{}, err
+	}
+
+	var cart model.Cart
+
+	transaction := func(ctx context.Context) error {
+		items, err := u.cartRepo.GetCartItems(ctx, claims.UserID)
+		if err != nil {
+			return err
+		}
+
+		if len(items) == 0 {
+			return apperrors.ErrCartEmpty
+		}
+
+		var lines []checkoutLine
+		if cart, lines, err = u.priceCart(ctx, customerAccountID, items); err != nil {
+			return err
+		}
+
+		// Код второго фактора погашается только вместе с заказом.
+		if err := u.secondFactor.VerifyOTP(ctx, claims.UserID, cart.TotalPrice, otpCode); err != nil {
+			return err
+		}
+
+		if err := u.reserveCart(ctx, cart, lines); err != nil {
+			return err
+		}
+
+		if err := u.accountRepo.Withdraw(ctx, customerAccountID, cart.TotalPrice); err != nil {
+			if errors.Is(err, repoerrors.ErrNotEnoughBalance) {
+				return apperrors.ErrNotEnoughBalance
+			}
+
+			return err
+		}
+
+		for _, line := range lines {
+			if err := u.operationRepo.ExecPurchaseOperation(ctx, line.operation); err != nil {
+				return err
+			}
+		}
+
+		// Из корзины убирается только оплаченное количество, чтобы не потерять товары,
+		// добавленные во время оформления.
+		return u.cartRepo.RemoveCartItems(ctx, claims.UserID, items)
+	}
+
+	serializable := u.txManager.Serializable(ctx, db.Write, transaction)
+	if err = u.txManager.WithRetry(serializable); err != nil {
+		return model.Cart{}, u.recordOTPFailure(ctx, claims.UserID, err)
+	}
+
+	return cart, nil
+}
+
+// Строка заказа: операция покупки и вариант, остаток которого она резервирует.
+type checkoutLine struct {
+	operation entity.PurchaseOperation
+	variant   *entity.ProductVariant
+}
+
+// Резервирует товары всех строк заказа. Строки, которых не хватает на складе, собираются в CheckoutError,
+// чтобы пользователь сразу увидел все проблемные товары. lines идут в том же порядке, что и cart.Items.
+func (u *operationUsecase) reserveCart(ctx context.Context, cart model.Cart, lines []checkoutLine) error {
+	checkoutErr := &apperrors.CheckoutError{}
+
+	for i, line := range lines {
+		err := u.reserveStock(ctx, line.operation.ItemID, line.variant, line.operation.Quantity)
+		if err != nil {
+			if errors.Is(err, apperrors.ErrOutOfStock) {
+				checkoutErr.Lines = append(checkoutErr.Lines, apperrors.CheckoutLineError{
+					ItemName: cart.Items[i].Name, Variant: cart.Items[i].Variant, Err: err,
+				})
+
+				continue
+			}
+
+			return err
+		}
+	}
+
+	if len(checkoutErr.Lines) > 0 {
+		return checkoutErr
+	}
+
+	return nil
+}
+
+// Считает стоимость корзины по текущим ценам товаров и их вариантов и готовит операции покупки.
+func (u *operationUsecase) priceCart(
+	ctx context.Context,
+	customerAccountID int,
+	items []entity.CartItem,
+) (model.Cart, []checkoutLine, error) {
+	cart := model.Cart{Items: make([]model.CartItem, 0, len(items))}
+	lines := make([]checkoutLine, 0, len(items))
+	checkoutErr := &apperrors.CheckoutError{}
+
+	for _, item := range items {
+		lineErr := apperrors.CheckoutLineError{ItemName: item.ProductName, Variant: item.VariantName}
+
+		product, variant, price, err := u.getPurchaseItem(ctx, item.ProductName, item.VariantName)
+		if err != nil {
+			if errors.Is(err, apperrors.ErrProductNotFound) || errors.Is(err, apperrors.ErrVariantNotFound) {
+				lineErr.Err = err
+				checkoutErr.Lines = append(checkoutErr.Lines, lineErr)
+
+				continue
+			}
+
+			return model.Cart{}, nil, err
+		}
+
+		if item.Quantity > u.maxPurchaseQuantity {
+			lineErr.Err = apperrors.ErrQuantityLimitExceeded
+			checkoutErr.Lines = append(checkoutErr.Lines, lineErr)
+
+			continue
+		}
+
+		totalPrice, err := calculateTotalPrice(price, item.Quantity)
+		if err != nil {
+			lineErr.Err = err
+			checkoutErr.Lines = append(checkoutErr.Lines, lineErr)
+
+			continue
+		}
+
+		cart.Items = append(cart.Items, model.CartItem{
+			Name:       product.Name,
+			Variant:    item.VariantName,
+			Price:      price,
+			Quantity:   item.Quantity,
+			TotalPrice: totalPrice,
+		})
+		cart.TotalPrice += totalPrice
+
+		lines = append(lines, checkoutLine{
+			operation: entity.PurchaseOperation{
+				ItemID:            product.ID,
+				VariantID:         variantID(variant),
+				CustomerAccountID: customerAccountID,
+				Quantity:          item.Quantity,
+				TotalPrice:        totalPrice,
+			},
+			variant: variant,
+		})
+	}
+
+	if len(checkoutErr.Lines) > 0 {
+		return model.Cart{}, nil, checkoutErr
+	}
+
+	if cart.TotalPrice > maxTotalPrice {
+		return model.Cart{}, nil, apperrors.ErrTotalPriceOverflow
+	}
+
+	return cart, lines, nil
+}
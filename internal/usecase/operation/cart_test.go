@@ -0,0 +1,432 @@
+package operation
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/resueman/merch-store/internal/entity"
+	"github.com/resueman/merch-store/internal/model"
+	"github.com/resueman/merch-store/internal/repo/repoerrors"
+	"github.com/resueman/merch-store/internal/usecase/apperrors"
+	"github.com/resueman/merch-store/pkg/db"
+	"github.com/resueman/merch-store/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func cartTxMock(txManager *mocks.MockTxManager, serializable bool) {
+	runTx := func(ctx context.Context, _ db.Mode, f func(context.Context) error) func() error {
+		return func() error { return f(ctx) }
+	}
+
+	if serializable {
+		txManager.EXPECT().Serializable(gomock.Any(), db.Write, gomock.Any()).DoAndReturn(runTx)
+	} else {
+		txManager.EXPECT().ReadCommitted(gomock.Any(), db.Write, gomock.Any()).DoAndReturn(runTx)
+	}
+
+	txManager.EXPECT().
+		WithRetry(gomock.Any()).
+		DoAndReturn(func(f func() error) error {
+			return f()
+		})
+}
+
+func TestAddToCart(t *testing.T) {
+	intPtr := func(v int) *int { return &v }
+	pen := &entity.Product{ID: 4, Name: "pen", Price: 10}
+
+	tests := []struct {
+		name  string
+		input model.CartItemInput
+		mock  func(productRepo *mocks.MockProduct, cartRepo *mocks.MockCart, txManager *mocks.MockTxManager)
+		want  error
+	}{
+		{
+			name:  "invalid quantity",
+			input: model.CartItemInput{ItemName: "pen", Quantity: 0},
+			mock:  func(_ *mocks.MockProduct, _ *mocks.MockCart, _ *mocks.MockTxManager) {},
+			want:  apperrors.ErrInvalidQuantity,
+		},
+		{
+			name:  "unknown product",
+			input: model.CartItemInput{ItemName: "jujuju", Quantity: 1},
+			mock: func(productRepo *mocks.MockProduct, _ *mocks.MockCart, _ *mocks.MockTxManager) {
+				productRepo.EXPECT().GetProductByName(gomock.Any(), "jujuju").Return(nil, repoerrors.ErrNotFound)
+			},
+			want: apperrors.ErrProductNotFound,
+		},
+		{
+			name:  "limit exceeded together with items already in the cart",
+			input: model.CartItemInput{ItemName: "pen", Quantity: 5},
+			mock: func(productRepo *mocks.MockProduct, cartRepo *mocks.MockCart, txManager *mocks.MockTxManager) {
+				productRepo.EXPECT().GetProductByName(gomock.Any(), "pen").Return(pen, nil)
+				cartRepo.EXPECT().AddCartItem(gomock.Any(), 111, 4, nil, 5).Return(12, nil)
+				cartTxMock(txManager, false)
+			},
+			want: apperrors.ErrQuantityLimitExceeded,
+		},
+		{
+			name:  "unknown variant",
+			input: model.CartItemInput{ItemName: "pen", Variant: "XXL", Quantity: 1},
+			mock: func(productRepo *mocks.MockProduct, _ *mocks.MockCart, _ *mocks.MockTxManager) {
+				productRepo.EXPECT().GetProductByName(gomock.Any(), "pen").Return(pen, nil)
+				productRepo.EXPECT().GetProductVariant(gomock.Any(), 4, "XXL").Return(nil, repoerrors.ErrNotFound)
+			},
+			want: apperrors.ErrVariantNotFound,
+		},
+		{
+			name:  "variant",
+			input: model.CartItemInput{ItemName: "pen", Variant: "red", Quantity: 2},
+			mock: func(productRepo *mocks.MockProduct, cartRepo *mocks.MockCart, txManager *mocks.MockTxManager) {
+				productRepo.EXPECT().GetProductByName(gomock.Any(), "pen").Return(pen, nil)
+				productRepo.EXPECT().GetProductVariant(gomock.Any(), 4, "red").
+					Return(&entity.ProductVariant{ID: 9, ProductID: 4, Name: "red"}, nil)
+				cartRepo.EXPECT().AddCartItem(gomock.Any(), 111, 4, intPtr(9), 2).Return(2, nil)
+				cartTxMock(txManager, false)
+			},
+		},
+		{
+			name:  "ok",
+			input: model.CartItemInput{ItemName: "pen", Quantity: 5},
+			mock: func(productRepo *mocks.MockProduct, cartRepo *mocks.MockCart, txManager *mocks.MockTxManager) {
+				productRepo.EXPECT().GetProductByName(gomock.Any(), "pen").Return(pen, nil)
+				cartRepo.EXPECT().AddCartItem(gomock.Any(), 111, 4, nil, 5).Return(7, nil)
+				cartTxMock(txManager, false)
+			},
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			productRepo, cartRepo := mocks.NewMockProduct(ctrl), mocks.NewMockCart(ctrl)
+			txManager := mocks.NewMockTxManager(ctrl)
+			testCase.mock(productRepo, cartRepo, txManager)
+
+			uc := NewOperationUsecase(nil, nil, productRepo, cartRepo, noSecondFactorMock(ctrl), txManager,
+				Config{MaxPurchaseQuantity: 10})
+			err := uc.AddToCart(context.Background(), model.Claims{UserID: 111}, testCase.input)
+
+			require.ErrorIs(t, err, testCase.want)
+		})
+	}
+}
+
+func TestRemoveFromCart_NotInCart(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cartRepo := mocks.NewMockCart(ctrl)
+	cartRepo.EXPECT().RemoveCartItem(gomock.Any(), 111, "pen", "").Return(repoerrors.ErrNotFound)
+
+	uc := NewOperationUsecase(nil, nil, nil, cartRepo, noSecondFactorMock(ctrl), nil, Config{})
+	err := uc.RemoveFromCart(context.Background(), model.Claims{UserID: 111}, "pen", "")
+
+	require.ErrorIs(t, err, apperrors.ErrCartItemNotFound)
+}
+
+func TestGetCart_TotalPriceOverflow(t *testing.T) {
+	tests := []struct {
+		name  string
+		items []entity.CartItem
+	}{
+		{
+			name:  "line total",
+			items: []entity.CartItem{{ProductID: 1, ProductName: "yacht", Price: maxTotalPrice, Quantity: 2}},
+		},
+		{
+			name: "cart total",
+			items: []entity.CartItem{
+				{ProductID: 1, ProductName: "yacht", Price: maxTotalPrice, Quantity: 1},
+				{ProductID: 2, ProductName: "pen", Price: 10, Quantity: 1},
+			},
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			cartRepo := mocks.NewMockCart(ctrl)
+			cartRepo.EXPECT().GetCartItems(gomock.Any(), 111).Return(testCase.items, nil)
+
+			uc := NewOperationUsecase(nil, nil, nil, cartRepo, noSecondFactorMock(ctrl), nil, Config{})
+			_, err := uc.GetCart(context.Background(), model.Claims{UserID: 111})
+
+			require.ErrorIs(t, err, apperrors.ErrTotalPriceOverflow)
+		})
+	}
+}
+
+func TestCheckout_Errors(t *testing.T) {
+	var errGetCartItems = errors.New("get cart items error")
+
+	tests := []struct {
+		name      string
+		mock      func(accountRepo *mocks.MockAccount, productRepo *mocks.MockProduct, cartRepo *mocks.MockCart)
+		want      error
+		wantLines []apperrors.CheckoutLineError
+	}{
+		{
+			name: "unknown error getting cart items",
+			mock: func(_ *mocks.MockAccount, _ *mocks.MockProduct, cartRepo *mocks.MockCart) {
+				cartRepo.EXPECT().GetCartItems(gomock.Any(), 111).Return(nil, errGetCartItems)
+			},
+			want: errGetCartItems,
+		},
+		{
+			name: "empty cart",
+			mock: func(_ *mocks.MockAccount, _ *mocks.MockProduct, cartRepo *mocks.MockCart) {
+				cartRepo.EXPECT().GetCartItems(gomock.Any(), 111).Return([]entity.CartItem{}, nil)
+			},
+			want: apperrors.ErrCartEmpty,
+		},
+		{
+			name: "every bad line is reported",
+			mock: func(_ *mocks.MockAccount, productRepo *mocks.MockProduct, cartRepo *mocks.MockCart) {
+				cartRepo.EXPECT().GetCartItems(gomock.Any(), 111).Return([]entity.CartItem{
+					{ProductID: 1, ProductName: "book", Price: 50, Quantity: 1},
+					{ProductID: 2, ProductName: "cup", Price: 20, Quantity: 11},
+					{ProductID: 3, ProductName: "retired", Price: 5, Quantity: 1},
+				}, nil)
+				productRepo.EXPECT().GetProductByName(gomock.Any(), "book").
+					Return(&entity.Product{ID: 1, Name: "book", Price: 50}, nil)
+				productRepo.EXPECT().GetProductByName(gomock.Any(), "cup").
+					Return(&entity.Product{ID: 2, Name: "cup", Price: 20}, nil)
+				productRepo.EXPECT().GetProductByName(gomock.Any(), "retired").Return(nil, repoerrors.ErrNotFound)
+			},
+			want: apperrors.ErrCheckoutFailed,
+			wantLines: []apperrors.CheckoutLineError{
+				{ItemName: "cup", Err: apperrors.ErrQuantityLimitExceeded},
+				{ItemName: "retired", Err: apperrors.ErrProductNotFound},
+			},
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			accountRepo, productRepo := mocks.NewMockAccount(ctrl), mocks.NewMockProduct(ctrl)
+			cartRepo := mocks.NewMockCart(ctrl)
+			accountRepo.EXPECT().GetIDByUserID(gomock.Any(), 111).Return(5, nil)
+			testCase.mock(accountRepo, productRepo, cartRepo)
+
+			// Корзина читается и проверяется уже внутри транзакции оформления.
+			txManager := mocks.NewMockTxManager(ctrl)
+			cartTxMock(txManager, true)
+
+			uc := NewOperationUsecase(accountRepo, nil, productRepo, cartRepo, noSecondFactorMock(ctrl), txManager,
+				Config{MaxPurchaseQuantity: 10})
+			_, err := uc.Checkout(context.Background(), model.Claims{UserID: 111}, "")
+
+			require.ErrorIs(t, err, testCase.want)
+
+			if testCase.wantLines != nil {
+				var checkoutErr *apperrors.CheckoutError
+				require.ErrorAs(t, err, &checkoutErr)
+				require.Equal(t, testCase.wantLines, checkoutErr.Lines)
+			}
+		})
+	}
+}
+
+func TestCheckout_NotEnoughBalance(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	accountRepo, productRepo := mocks.NewMockAccount(ctrl), mocks.NewMockProduct(ctrl)
+	cartRepo, txManager := mocks.NewMockCart(ctrl), mocks.NewMockTxManager(ctrl)
+
+	accountRepo.EXPECT().GetIDByUserID(gomock.Any(), 111).Return(5, nil)
+	cartRepo.EXPECT().GetCartItems(gomock.Any(), 111).Return([]entity.CartItem{
+		{ProductID: 8, ProductName: "socks", Price: 10, Quantity: 30},
+	}, nil)
+	productRepo.EXPECT().GetProductByName(gomock.Any(), "socks").
+		Return(&entity.Product{ID: 8, Name: "socks", Price: 10}, nil)
+	productRepo.EXPECT().ReserveStock(gomock.Any(), 8, 30).Return(nil)
+	accountRepo.EXPECT().Withdraw(gomock.Any(), 5, 300).Return(repoerrors.ErrNotEnoughBalance)
+	cartTxMock(txManager, true)
+
+	uc := NewOperationUsecase(accountRepo, nil, productRepo, cartRepo, noSecondFactorMock(ctrl), txManager, Config{})
+	_, err := uc.Checkout(context.Background(), model.Claims{UserID: 111}, "")
+
+	require.ErrorIs(t, err, apperrors.ErrNotEnoughBalance)
+}
+
+func TestCheckout_OutOfStock(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	accountRepo, productRepo := mocks.NewMockAccount(ctrl), mocks.NewMockProduct(ctrl)
+	cartRepo, txManager := mocks.NewMockCart(ctrl), mocks.NewMockTxManager(ctrl)
+
+	accountRepo.EXPECT().GetIDByUserID(gomock.Any(), 111).Return(5, nil)
+	cartRepo.EXPECT().GetCartItems(gomock.Any(), 111).Return([]entity.CartItem{
+		{ProductID: 10, ProductName: "pink-hoody", Price: 500, Quantity: 2},
+		{ProductID: 4, ProductName: "pen", Price: 10, Quantity: 1},
+		{ProductID: 6, ProductName: "hoody", Price: 300, Quantity: 1},
+	}, nil)
+	productRepo.EXPECT().GetProductByName(gomock.Any(), "pink-hoody").
+		Return(&entity.Product{ID: 10, Name: "pink-hoody", Price: 500}, nil)
+	productRepo.EXPECT().GetProductByName(gomock.Any(), "pen").
+		Return(&entity.Product{ID: 4, Name: "pen", Price: 10}, nil)
+	productRepo.EXPECT().GetProductByName(gomock.Any(), "hoody").
+		Return(&entity.Product{ID: 6, Name: "hoody", Price: 300}, nil)
+
+	// Монеты не списываются, если хотя бы одного товара не хватает.
+	productRepo.EXPECT().ReserveStock(gomock.Any(), 10, 2).Return(repoerrors.ErrNotEnoughStock)
+	productRepo.EXPECT().ReserveStock(gomock.Any(), 4, 1).Return(nil)
+	productRepo.EXPECT().ReserveStock(gomock.Any(), 6, 1).Return(repoerrors.ErrNotEnoughStock)
+	cartTxMock(txManager, true)
+
+	uc := NewOperationUsecase(accountRepo, nil, productRepo, cartRepo, noSecondFactorMock(ctrl), txManager, Config{})
+	_, err := uc.Checkout(context.Background(), model.Claims{UserID: 111}, "")
+
+	var checkoutErr *apperrors.CheckoutError
+	require.ErrorAs(t, err, &checkoutErr)
+	require.Equal(t, []apperrors.CheckoutLineError{
+		{ItemName: "pink-hoody", Err: apperrors.ErrOutOfStock},
+		{ItemName: "hoody", Err: apperrors.ErrOutOfStock},
+	}, checkoutErr.Lines)
+}
+
+func TestCheckout_Ok(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	accountRepo, productRepo := mocks.NewMockAccount(ctrl), mocks.NewMockProduct(ctrl)
+	cartRepo, operationRepo := mocks.NewMockCart(ctrl), mocks.NewMockOperation(ctrl)
+	txManager := mocks.NewMockTxManager(ctrl)
+
+	items := []entity.CartItem{
+		{ProductID: 8, ProductName: "socks", Price: 10, Quantity: 3},
+		{ProductID: 4, ProductName: "pen", Price: 10, Quantity: 2},
+	}
+
+	accountRepo.EXPECT().GetIDByUserID(gomock.Any(), 111).Return(5, nil)
+	cartRepo.EXPECT().GetCartItems(gomock.Any(), 111).Return(items, nil)
+
+	// Цена берется из каталога на момент оформления, а не из корзины.
+	productRepo.EXPECT().GetProductByName(gomock.Any(), "socks").
+		Return(&entity.Product{ID: 8, Name: "socks", Price: 12}, nil)
+	productRepo.EXPECT().GetProductByName(gomock.Any(), "pen").
+		Return(&entity.Product{ID: 4, Name: "pen", Price: 10}, nil)
+
+	secondFactor := mocks.NewMockSecondFactor(ctrl)
+	secondFactor.EXPECT().VerifyOTP(gomock.Any(), 111, 56, "123456").Return(nil)
+
+	productRepo.EXPECT().ReserveStock(gomock.Any(), 8, 3).Return(nil)
+	productRepo.EXPECT().ReserveStock(gomock.Any(), 4, 2).Return(nil)
+	accountRepo.EXPECT().Withdraw(gomock.Any(), 5, 56).Return(nil)
+	operationRepo.EXPECT().ExecPurchaseOperation(gomock.Any(), entity.PurchaseOperation{
+		ItemID: 8, CustomerAccountID: 5, Quantity: 3, TotalPrice: 36,
+	}).Return(nil)
+	operationRepo.EXPECT().ExecPurchaseOperation(gomock.Any(), entity.PurchaseOperation{
+		ItemID: 4, CustomerAccountID: 5, Quantity: 2, TotalPrice: 20,
+	}).Return(nil)
+	cartRepo.EXPECT().RemoveCartItems(gomock.Any(), 111, items).Return(nil)
+	cartTxMock(txManager, true)
+
+	uc := NewOperationUsecase(accountRepo, operationRepo, productRepo, cartRepo, secondFactor, txManager, Config{})
+	cart, err := uc.Checkout(context.Background(), model.Claims{UserID: 111}, "123456")
+
+	require.NoError(t, err)
+	require.Equal(t, model.Cart{
+		Items: []model.CartItem{
+			{Name: "socks", Price: 12, Quantity: 3, TotalPrice: 36},
+			{Name: "pen", Price: 10, Quantity: 2, TotalPrice: 20},
+		},
+		TotalPrice: 56,
+	}, cart)
+}
+
+func TestCheckout_Variants(t *testing.T) {
+	intPtr := func(v int) *int { return &v }
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	accountRepo, productRepo := mocks.NewMockAccount(ctrl), mocks.NewMockProduct(ctrl)
+	cartRepo, operationRepo := mocks.NewMockCart(ctrl), mocks.NewMockOperation(ctrl)
+	txManager := mocks.NewMockTxManager(ctrl)
+
+	hoody := &entity.Product{ID: 6, Name: "hoody", Price: 300}
+	xl := &entity.ProductVariant{ID: 21, ProductID: 6, Name: "XL", Price: intPtr(350), Stock: intPtr(4)}
+	m := &entity.ProductVariant{ID: 22, ProductID: 6, Name: "M"}
+	items := []entity.CartItem{
+		{ProductID: 6, VariantID: intPtr(21), ProductName: "hoody", VariantName: "XL", Price: 350, Quantity: 2},
+		{ProductID: 6, VariantID: intPtr(22), ProductName: "hoody", VariantName: "M", Price: 300, Quantity: 1},
+	}
+
+	accountRepo.EXPECT().GetIDByUserID(gomock.Any(), 111).Return(5, nil)
+	cartRepo.EXPECT().GetCartItems(gomock.Any(), 111).Return(items, nil)
+	productRepo.EXPECT().GetProductByName(gomock.Any(), "hoody").Return(hoody, nil).Times(2)
+	productRepo.EXPECT().GetProductVariant(gomock.Any(), 6, "XL").Return(xl, nil)
+	productRepo.EXPECT().GetProductVariant(gomock.Any(), 6, "M").Return(m, nil)
+
+	// Вариант с собственным остатком резервирует его, вариант без остатка - остаток товара.
+	productRepo.EXPECT().ReserveVariantStock(gomock.Any(), 21, 2).Return(nil)
+	productRepo.EXPECT().ReserveStock(gomock.Any(), 6, 1).Return(nil)
+	accountRepo.EXPECT().Withdraw(gomock.Any(), 5, 1000).Return(nil)
+	operationRepo.EXPECT().ExecPurchaseOperation(gomock.Any(), entity.PurchaseOperation{
+		ItemID: 6, VariantID: intPtr(21), CustomerAccountID: 5, Quantity: 2, TotalPrice: 700,
+	}).Return(nil)
+	operationRepo.EXPECT().ExecPurchaseOperation(gomock.Any(), entity.PurchaseOperation{
+		ItemID: 6, VariantID: intPtr(22), CustomerAccountID: 5, Quantity: 1, TotalPrice: 300,
+	}).Return(nil)
+	cartRepo.EXPECT().RemoveCartItems(gomock.Any(), 111, items).Return(nil)
+	cartTxMock(txManager, true)
+
+	uc := NewOperationUsecase(accountRepo, operationRepo, productRepo, cartRepo, noSecondFactorMock(ctrl), txManager,
+		Config{MaxPurchaseQuantity: 10})
+	cart, err := uc.Checkout(context.Background(), model.Claims{UserID: 111}, "")
+
+	require.NoError(t, err)
+	require.Equal(t, model.Cart{
+		Items: []model.CartItem{
+			{Name: "hoody", Variant: "XL", Price: 350, Quantity: 2, TotalPrice: 700},
+			{Name: "hoody", Variant: "M", Price: 300, Quantity: 1, TotalPrice: 300},
+		},
+		TotalPrice: 1000,
+	}, cart)
+}
+
+func TestCheckout_VariantOutOfStock(t *testing.T) {
+	intPtr := func(v int) *int { return &v }
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	accountRepo, productRepo := mocks.NewMockAccount(ctrl), mocks.NewMockProduct(ctrl)
+	cartRepo, txManager := mocks.NewMockCart(ctrl), mocks.NewMockTxManager(ctrl)
+
+	items := []entity.CartItem{
+		{ProductID: 6, VariantID: intPtr(21), ProductName: "hoody", VariantName: "XL", Price: 350, Quantity: 2},
+	}
+
+	accountRepo.EXPECT().GetIDByUserID(gomock.Any(), 111).Return(5, nil)
+	cartRepo.EXPECT().GetCartItems(gomock.Any(), 111).Return(items, nil)
+	productRepo.EXPECT().GetProductByName(gomock.Any(), "hoody").
+		Return(&entity.Product{ID: 6, Name: "hoody", Price: 300}, nil)
+	productRepo.EXPECT().GetProductVariant(gomock.Any(), 6, "XL").
+		Return(&entity.ProductVariant{ID: 21, ProductID: 6, Name: "XL", Stock: intPtr(1)}, nil)
+	productRepo.EXPECT().ReserveVariantStock(gomock.Any(), 21, 2).Return(repoerrors.ErrNotEnoughStock)
+	cartTxMock(txManager, true)
+
+	uc := NewOperationUsecase(accountRepo, nil, productRepo, cartRepo, noSecondFactorMock(ctrl), txManager,
+		Config{MaxPurchaseQuantity: 10})
+	_, err := uc.Checkout(context.Background(), model.Claims{UserID: 111}, "")
+
+	var checkoutErr *apperrors.CheckoutError
+	require.ErrorAs(t, err, &checkoutErr)
+	require.Equal(t, []apperrors.CheckoutLineError{
+		{ItemName: "hoody", Variant: "XL", Err: apperrors.ErrOutOfStock},
+	}, checkoutErr.Lines)
+}
@@ -0,0 +1,104 @@
+package operation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/resueman/merch-store/internal/entity"
+	"github.com/resueman/merch-store/internal/model"
+	"github.com/resueman/merch-store/internal/repo/repoerrors"
+	"github.com/resueman/merch-store/internal/usecase/apperrors"
+	"github.com/resueman/merch-store/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetOperation(t *testing.T) {
+	createdAt := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	recipientAccountID := 2
+	transfer := &entity.OperationDetails{
+		ID:                 42,
+		OperationType:      "transfer",
+		AccountID:          1,
+		Username:           "A",
+		RecipientAccountID: &recipientAccountID,
+		RecipientUsername:  "B",
+		Amount:             50,
+		CreatedAt:          createdAt,
+	}
+	want := model.Operation{
+		ID:                42,
+		Type:              model.HistoryTransfer,
+		Username:          "A",
+		RecipientUsername: "B",
+		Amount:            50,
+		CreatedAt:         createdAt,
+	}
+
+	tests := []struct {
+		name      string
+		claims    model.Claims
+		accountID int
+		want      model.Operation
+		wantErr   error
+	}{
+		{
+			name:      "sender sees the transfer",
+			claims:    model.Claims{UserID: 10, Role: model.RoleUser},
+			accountID: 1,
+			want:      want,
+		},
+		{
+			name:      "recipient sees the transfer",
+			claims:    model.Claims{UserID: 20, Role: model.RoleUser},
+			accountID: 2,
+			want:      want,
+		},
+		{
+			name:      "other user gets not found",
+			claims:    model.Claims{UserID: 30, Role: model.RoleUser},
+			accountID: 3,
+			wantErr:   apperrors.ErrOperationNotFound,
+		},
+		{
+			name:   "admin sees any operation",
+			claims: model.Claims{UserID: 40, Role: model.RoleAdmin},
+			want:   want,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			accountRepo := mocks.NewMockAccount(ctrl)
+			operationRepo := mocks.NewMockOperation(ctrl)
+
+			operationRepo.EXPECT().GetOperationByID(gomock.Any(), 42).Return(transfer, nil)
+			if tt.claims.Role != model.RoleAdmin {
+				accountRepo.EXPECT().GetIDByUserID(gomock.Any(), tt.claims.UserID).Return(tt.accountID, nil)
+			}
+
+			uc := NewOperationUsecase(accountRepo, operationRepo, nil, nil, nil, nil, Config{})
+
+			operation, err := uc.GetOperation(context.Background(), tt.claims, 42)
+			require.ErrorIs(t, err, tt.wantErr)
+			require.Equal(t, tt.want, operation)
+		})
+	}
+}
+
+func TestGetOperation_NotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	operationRepo := mocks.NewMockOperation(ctrl)
+	operationRepo.EXPECT().GetOperationByID(gomock.Any(), 42).Return(nil, repoerrors.ErrNotFound)
+
+	uc := NewOperationUsecase(nil, operationRepo, nil, nil, nil, nil, Config{})
+
+	_, err := uc.GetOperation(context.Background(), model.Claims{UserID: 10}, 42)
+	require.ErrorIs(t, err, apperrors.ErrOperationNotFound)
+}
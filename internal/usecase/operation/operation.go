@@ -3,55 +3,111 @@ package operation
 import (
 	"context"
 	"errors"
+	"math"
 
 	"github.com/resueman/merch-store/internal/entity"
 	"github.com/resueman/merch-store/internal/model"
 	"github.com/resueman/merch-store/internal/repo"
 	"github.com/resueman/merch-store/internal/repo/repoerrors"
 	"github.com/resueman/merch-store/internal/usecase/apperrors"
+	"github.com/resueman/merch-store/internal/usecase/converter"
 	"github.com/resueman/merch-store/pkg/db"
 )
 
+const (
+	defaultMaxPurchaseQuantity = 100
+	// Сумма покупки хранится в колонке INT, поэтому не может превышать int32.
+	maxTotalPrice = math.MaxInt32
+)
+
+type Config struct {
+	// Максимальное количество единиц товара в одной покупке. Если не задано, используется 100.
+	MaxPurchaseQuantity int
+}
+
 type operationUsecase struct {
-	accountRepo   repo.Account
-	operationRepo repo.Operation
-	productRepo   repo.Product
-	txManager     db.TxManager
+	accountRepo         repo.Account
+	operationRepo       repo.Operation
+	productRepo         repo.Product
+	cartRepo            repo.Cart
+	secondFactor        SecondFactor
+	txManager           db.TxManager
+	maxPurchaseQuantity int
 }
 
-func NewOperationUsecase(account repo.Account, operation repo.Operation, product repo.Product, txManager db.TxManager) *operationUsecase {
+func NewOperationUsecase(account repo.Account, operation repo.Operation, product repo.Product, cart repo.Cart,
+	secondFactor SecondFactor, txManager db.TxManager, cfg Config,
+) *operationUsecase {
+	maxPurchaseQuantity := cfg.MaxPurchaseQuantity
+	if maxPurchaseQuantity <= 0 {
+		maxPurchaseQuantity = defaultMaxPurchaseQuantity
+	}
+
 	return &operationUsecase{
-		accountRepo:   account,
-		operationRepo: operation,
-		productRepo:   product,
-		txManager:     txManager,
+		accountRepo:         account,
+		operationRepo:       operation,
+		productRepo:         product,
+		cartRepo:            cart,
+		secondFactor:        secondFactor,
+		txManager:           txManager,
+		maxPurchaseQuantity: maxPurchaseQuantity,
 	}
 }
 
 // Проверить:
-// 1. Товар с заданным именем существует
-// 2. Покупатель существует (уже проверено в middleware?)
-// 3. Кол-во монет достаточно для покупки товара (проверяется в бд, надо вернуть соответствующую ошибку)
-func (u *operationUsecase) BuyItem(ctx context.Context, claims model.Claims, itemName string) error {
+// 1. Количество положительное и не больше лимита на одну покупку
+// 2. Товар с заданным именем существует, а если указан вариант — он есть у этого товара
+// 3. Покупатель существует (уже проверено в middleware?)
+// 4. Итоговая сумма не переполняет int32 (колонки в бд)
+// 5. Товара на складе достаточно (резервируется в бд в начале транзакции)
+// 6. Кол-во монет достаточно для покупки товара (проверяется в бд, надо вернуть соответствующую ошибку)
+// 7. Покупка дороже порога пользователя подтверждена кодом второго фактора
+func (u *operationUsecase) BuyItem(
+	ctx context.Context,
+	claims model.Claims,
+	itemName string,
+	variantName string,
+	quantity int,
+	otpCode string,
+) error {
+	if quantity <= 0 {
+		return apperrors.ErrInvalidQuantity
+	}
+
+	if quantity > u.maxPurchaseQuantity {
+		return apperrors.ErrQuantityLimitExceeded
+	}
+
 	customerAccountID, err := u.accountRepo.GetIDByUserID(ctx, claims.UserID)
 	if err != nil {
 		return err
 	}
 
-	product, err := u.productRepo.GetProductByName(ctx, itemName)
-	if err != nil {
-		if errors.Is(err, repoerrors.ErrNotFound) {
-			return apperrors.ErrProductNotFound
+	// Товар и цена читаются в транзакции покупки: изменение цены администратором либо видно целиком,
+	// либо приводит к повтору транзакции, поэтому покупатель не платит устаревшую цену.
+	// Код второго фактора погашается в той же транзакции: если покупка не состоится, он останется
+	// действительным. Сразу после проверки кода резервируем товар: если его нет в нужном количестве,
+	// списывать монеты уже не нужно.
+	transaction := func(ctx context.Context) error {
+		product, variant, price, err := u.getPurchaseItem(ctx, itemName, variantName)
+		if err != nil {
+			return err
 		}
 
-		return err
-	}
+		totalPrice, err := calculateTotalPrice(price, quantity)
+		if err != nil {
+			return err
+		}
 
-	// В самом начале транзакции можно было бы зарезервировать продукт,
-	// вернуть ошибку, если его нет в нужном количестве.
-	// Но по условию мерч бесконечен, поэтому пропускаем этот шаг.
-	transaction := func(ctx context.Context) error {
-		if err := u.accountRepo.Withdraw(ctx, customerAccountID, product.Price); err != nil {
+		if err := u.secondFactor.VerifyOTP(ctx, claims.UserID, totalPrice, otpCode); err != nil {
+			return err
+		}
+
+		if err := u.reserveStock(ctx, product.ID, variant, quantity); err != nil {
+			return err
+		}
+
+		if err := u.accountRepo.Withdraw(ctx, customerAccountID, totalPrice); err != nil {
 			if errors.Is(err, repoerrors.ErrNotEnoughBalance) {
 				return apperrors.ErrNotEnoughBalance
 			}
@@ -61,9 +117,10 @@ func (u *operationUsecase) BuyItem(ctx context.Context, claims model.Claims, ite
 
 		operation := entity.PurchaseOperation{
 			ItemID:            product.ID,
+			VariantID:         variantID(variant),
 			CustomerAccountID: customerAccountID,
-			Quantity:          1,
-			TotalPrice:        product.Price,
+			Quantity:          quantity,
+			TotalPrice:        totalPrice,
 		}
 
 		if err := u.operationRepo.ExecPurchaseOperation(ctx, operation); err != nil {
@@ -79,23 +136,107 @@ func (u *operationUsecase) BuyItem(ctx context.Context, claims model.Claims, ite
 
 	serializable := u.txManager.Serializable(ctx, db.Write, transaction)
 	if err = u.txManager.WithRetry(serializable); err != nil {
-		return err
+		return u.recordOTPFailure(ctx, claims.UserID, err)
 	}
 
 	return nil
 }
 
+// Товар и, если указан, его вариант с действующей ценой за единицу: у варианта может быть своя цена,
+// иначе действует цена товара.
+func (u *operationUsecase) getPurchaseItem(ctx context.Context, itemName string, variantName string) (
+	*entity.Product, *entity.ProductVariant, int, error,
+) {
+	product, err := u.productRepo.GetProductByName(ctx, itemName)
+	if err != nil {
+		if errors.Is(err, repoerrors.ErrNotFound) {
+			return nil, nil, 0, apperrors.ErrProductNotFound
+		}
+
+		return nil, nil, 0, err
+	}
+
+	if variantName == "" {
+		return product, nil, product.Price, nil
+	}
+
+	variant, err := u.productRepo.GetProductVariant(ctx, product.ID, variantName)
+	if err != nil {
+		if errors.Is(err, repoerrors.ErrNotFound) {
+			return nil, nil, 0, apperrors.ErrVariantNotFound
+		}
+
+		return nil, nil, 0, err
+	}
+
+	if variant.Price != nil {
+		return product, variant, *variant.Price, nil
+	}
+
+	return product, variant, product.Price, nil
+}
+
+// У варианта с собственным остатком списывается он, иначе общий остаток товара.
+func (u *operationUsecase) reserveStock(ctx context.Context, productID int, variant *entity.ProductVariant,
+	quantity int,
+) error {
+	var err error
+	if variant != nil && variant.Stock != nil {
+		err = u.productRepo.ReserveVariantStock(ctx, variant.ID, quantity)
+	} else {
+		err = u.productRepo.ReserveStock(ctx, productID, quantity)
+	}
+
+	if errors.Is(err, repoerrors.ErrNotEnoughStock) {
+		return apperrors.ErrOutOfStock
+	}
+
+	return err
+}
+
+func variantID(variant *entity.ProductVariant) *int {
+	if variant == nil {
+		return nil
+	}
+
+	return &variant.ID
+}
+
+// Неверный код второго фактора учитывается только после отката транзакции операции,
+// иначе запись о неудачной попытке откатилась бы вместе с ней.
+func (u *operationUsecase) recordOTPFailure(ctx context.Context, userID int, err error) error {
+	if !errors.Is(err, apperrors.ErrInvalidOTP) {
+		return err
+	}
+
+	if recordErr := u.secondFactor.RecordOTPFailure(ctx, userID); recordErr != nil {
+		return recordErr
+	}
+
+	return err
+}
+
+func calculateTotalPrice(price int, quantity int) (int, error) {
+	if price > 0 && quantity > maxTotalPrice/price {
+		return 0, apperrors.ErrTotalPriceOverflow
+	}
+
+	return price * quantity, nil
+}
+
 // Проверить:
 // 1. Пользователь отправляет монеты не себе
 // 2. Пользователь отправляет положительное кол-во монет
-// 3. Получатель существует
+// 3. Получатель существует и не деактивирован (проверяется в бд при зачислении)
 // 4. Отправитель существует (уже проверено в middleware?)
 // 5. Кол-во монет достаточно для перевода (проверяется в бд, надо вернуть соответствующую ошибку)
+// 6. Перевод больше порога пользователя подтвержден кодом второго фактора
 func (u *operationUsecase) SendCoin(
 	ctx context.Context,
 	claims model.Claims,
 	receiverUsername string,
 	amount int,
+	otpCode string,
 ) error {
 	if amount <= 0 {
 		return apperrors.ErrInvalidAmount
@@ -119,7 +260,12 @@ func (u *operationUsecase) SendCoin(
 		return apperrors.ErrSelfTransfer
 	}
 
+	// Код второго фактора погашается только вместе с переводом.
 	transaction := func(ctx context.Context) error {
+		if err := u.secondFactor.VerifyOTP(ctx, claims.UserID, amount, otpCode); err != nil {
+			return err
+		}
+
 		if err := u.accountRepo.Withdraw(ctx, senderAccountID, amount); err != nil {
 			if errors.Is(err, repoerrors.ErrNotEnoughBalance) {
 				return apperrors.ErrNotEnoughBalance
@@ -129,6 +275,10 @@ func (u *operationUsecase) SendCoin(
 		}
 
 		if err := u.accountRepo.Deposit(ctx, receiverAccountID, amount); err != nil {
+			if errors.Is(err, repoerrors.ErrNotFound) {
+				return apperrors.ErrRecipientDeactivated
+			}
+
 			return err
 		}
 
@@ -151,8 +301,37 @@ func (u *operationUsecase) SendCoin(
 
 	serializable := u.txManager.Serializable(ctx, db.Write, transaction)
 	if err = u.txManager.WithRetry(serializable); err != nil {
-		return err
+		return u.recordOTPFailure(ctx, claims.UserID, err)
 	}
 
 	return nil
 }
+
+// Операцию видят только ее участники и администраторы. Для остальных она не существует,
+// чтобы по ответу нельзя было перебирать чужие операции.
+func (u *operationUsecase) GetOperation(ctx context.Context, claims model.Claims, operationID int) (
+	model.Operation, error,
+) {
+	operation, err := u.operationRepo.GetOperationByID(ctx, operationID)
+	if err != nil {
+		if errors.Is(err, repoerrors.ErrNotFound) {
+			return model.Operation{}, apperrors.ErrOperationNotFound
+		}
+
+		return model.Operation{}, err
+	}
+
+	if claims.Role != model.RoleAdmin {
+		accountID, err := u.accountRepo.GetIDByUserID(ctx, claims.UserID)
+		if err != nil {
+			return model.Operation{}, err
+		}
+
+		isRecipient := operation.RecipientAccountID != nil && *operation.RecipientAccountID == accountID
+		if operation.AccountID != accountID && !isRecipient {
+			return model.Operation{}, apperrors.ErrOperationNotFound
+		}
+	}
+
+	return converter.ConvertOperation(*operation), nil
+}
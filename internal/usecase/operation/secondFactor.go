@@ -0,0 +1,11 @@
+package operation
+
+import "context"
+
+// SecondFactor проверяет код второго фактора для операций на крупную сумму.
+// VerifyOTP возвращает nil, если второй фактор не включен или сумма не превышает порог пользователя.
+// Неверный код VerifyOTP не учитывает: после отката транзакции операции нужно вызвать RecordOTPFailure.
+type SecondFactor interface {
+	VerifyOTP(ctx context.Context, userID int, amount int, code string) error
+	RecordOTPFailure(ctx context.Context, userID int) error
+}
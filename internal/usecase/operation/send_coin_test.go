@@ -113,8 +113,8 @@ func TestSendCoin_BadInputError(t *testing.T) {
 			accountRepo := mocks.NewMockAccount(ctrl)
 			testCase.mock(accountRepo, claims, receiverUsername)
 
-			uc := NewOperationUsecase(accountRepo, nil, nil, nil)
-			err := uc.SendCoin(context.Background(), claims, receiverUsername, testCase.amount)
+			uc := NewOperationUsecase(accountRepo, nil, nil, nil, noSecondFactorMock(ctrl), nil, Config{})
+			err := uc.SendCoin(context.Background(), claims, receiverUsername, testCase.amount, "")
 
 			require.ErrorIs(t, err, testCase.want)
 		})
@@ -227,8 +227,8 @@ func TestSendCoin_WithdrawError(t *testing.T) {
 			txManager := mocks.NewMockTxManager(ctrl)
 			tt.mock(accountRepo, txManager, claims, receiverUsername, amount, tt.returnedError)
 
-			uc := NewOperationUsecase(accountRepo, nil, nil, txManager)
-			err := uc.SendCoin(context.Background(), claims, receiverUsername, amount)
+			uc := NewOperationUsecase(accountRepo, nil, nil, nil, noSecondFactorMock(ctrl), txManager, Config{})
+			err := uc.SendCoin(context.Background(), claims, receiverUsername, amount, "")
 
 			require.ErrorIs(t, err, tt.want)
 		})
@@ -295,6 +295,48 @@ func TestSendCoin_DepositError(t *testing.T) {
 			returnedError: accountRepoUnknownDepositError,
 			want:          accountRepoUnknownDepositError,
 		},
+		{
+			name: "receiver deactivated",
+			mock: func(accountRepo *mocks.MockAccount,
+				txManager *mocks.MockTxManager,
+				claims model.Claims,
+				receiverUsername string,
+				amount int,
+				repoDepositErr error,
+			) {
+				senderAccountID, receiverAccountID := 123, 456
+
+				accountRepo.EXPECT().
+					GetIDByUserID(gomock.Any(), claims.UserID).
+					Return(senderAccountID, nil)
+
+				accountRepo.EXPECT().
+					GetIDByUsername(gomock.Any(), receiverUsername).
+					Return(receiverAccountID, nil)
+
+				accountRepo.EXPECT().
+					Withdraw(gomock.Any(), senderAccountID, amount).
+					Return(nil)
+
+				accountRepo.EXPECT().
+					Deposit(gomock.Any(), receiverAccountID, amount).
+					Return(repoDepositErr)
+
+				txManager.EXPECT().
+					Serializable(gomock.Any(), gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, _ db.Mode, f func(context.Context) error) func() error {
+						return func() error { return f(ctx) }
+					})
+
+				txManager.EXPECT().
+					WithRetry(gomock.Any()).
+					DoAndReturn(func(f func() error) error {
+						return f()
+					})
+			},
+			returnedError: repoerrors.ErrNotFound,
+			want:          apperrors.ErrRecipientDeactivated,
+		},
 	}
 
 	claims := model.Claims{UserID: 111}
@@ -308,8 +350,8 @@ func TestSendCoin_DepositError(t *testing.T) {
 			txManager := mocks.NewMockTxManager(ctrl)
 			tt.mock(accountRepo, txManager, claims, receiverUsername, amount, tt.returnedError)
 
-			uc := NewOperationUsecase(accountRepo, nil, nil, txManager)
-			err := uc.SendCoin(context.Background(), claims, receiverUsername, amount)
+			uc := NewOperationUsecase(accountRepo, nil, nil, nil, noSecondFactorMock(ctrl), txManager, Config{})
+			err := uc.SendCoin(context.Background(), claims, receiverUsername, amount, "")
 
 			require.ErrorIs(t, err, tt.want)
 		})
@@ -397,8 +439,8 @@ func TestSendCoin_TxManagerError(t *testing.T) {
 			txManager := mocks.NewMockTxManager(ctrl)
 			tt.mock(accountRepo, operationRepo, txManager, claims, receiverUsername, amount)
 
-			uc := NewOperationUsecase(accountRepo, nil, nil, txManager)
-			err := uc.SendCoin(context.Background(), claims, receiverUsername, amount)
+			uc := NewOperationUsecase(accountRepo, nil, nil, nil, noSecondFactorMock(ctrl), txManager, Config{})
+			err := uc.SendCoin(context.Background(), claims, receiverUsername, amount, "")
 
 			require.ErrorIs(t, err, tt.want)
 		})
@@ -500,8 +542,9 @@ func TestSendCoin_TransferOperationError(t *testing.T) {
 			txManager := mocks.NewMockTxManager(ctrl)
 			tt.mock(accountRepo, operationRepo, txManager, claims, receiverUsername, amount)
 
-			uc := NewOperationUsecase(accountRepo, operationRepo, nil, txManager)
-			err := uc.SendCoin(context.Background(), claims, receiverUsername, amount)
+			uc := NewOperationUsecase(accountRepo, operationRepo, nil, nil, noSecondFactorMock(ctrl), txManager,
+				Config{})
+			err := uc.SendCoin(context.Background(), claims, receiverUsername, amount, "")
 
 			require.ErrorIs(t, err, tt.want)
 		})
@@ -550,10 +593,73 @@ func TestSendCoin_Ok(t *testing.T) {
 			txManager := mocks.NewMockTxManager(ctrl)
 			tt.mock(accountRepo, operationRepo, txManager, claims, receiverUsername, amount)
 
-			uc := NewOperationUsecase(accountRepo, operationRepo, nil, txManager)
-			err := uc.SendCoin(context.Background(), claims, receiverUsername, amount)
+			uc := NewOperationUsecase(accountRepo, operationRepo, nil, nil, noSecondFactorMock(ctrl), txManager,
+				Config{})
+			err := uc.SendCoin(context.Background(), claims, receiverUsername, amount, "")
 
 			require.NoError(t, err)
 		})
 	}
 }
+
+func noSecondFactorMock(ctrl *gomock.Controller) *mocks.MockSecondFactor {
+	secondFactor := mocks.NewMockSecondFactor(ctrl)
+	secondFactor.EXPECT().VerifyOTP(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	return secondFactor
+}
+
+func TestSendCoin_SecondFactor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name     string
+		otpCode  string
+		otpErr   error
+		recorded bool
+	}{
+		{name: "code required above threshold", otpCode: "", otpErr: apperrors.ErrOTPRequired},
+		{name: "invalid code", otpCode: "000000", otpErr: apperrors.ErrInvalidOTP, recorded: true},
+	}
+
+	claims := model.Claims{UserID: 111}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accountRepo := mocks.NewMockAccount(ctrl)
+			accountRepo.EXPECT().GetIDByUserID(gomock.Any(), 111).Return(1, nil)
+			accountRepo.EXPECT().GetIDByUsername(gomock.Any(), "receiver").Return(2, nil)
+
+			// Без подтверждения деньги не списываются: код проверяется в транзакции перевода до списания.
+			inTx := false
+			txManager := mocks.NewMockTxManager(ctrl)
+			txManager.EXPECT().Serializable(gomock.Any(), db.Write, gomock.Any()).
+				DoAndReturn(func(ctx context.Context, _ db.Mode, f func(context.Context) error) func() error {
+					return func() error {
+						inTx = true
+						defer func() { inTx = false }()
+
+						return f(ctx)
+					}
+				})
+			txManager.EXPECT().WithRetry(gomock.Any()).DoAndReturn(func(f func() error) error { return f() })
+
+			secondFactor := mocks.NewMockSecondFactor(ctrl)
+			secondFactor.EXPECT().VerifyOTP(gomock.Any(), 111, 500, tt.otpCode).Return(tt.otpErr)
+
+			// Неверный код учитывается уже после отката транзакции, чтобы запись о попытке сохранилась.
+			if tt.recorded {
+				secondFactor.EXPECT().RecordOTPFailure(gomock.Any(), 111).DoAndReturn(
+					func(context.Context, int) error {
+						require.False(t, inTx)
+						return nil
+					})
+			}
+
+			uc := NewOperationUsecase(accountRepo, nil, nil, nil, secondFactor, txManager, Config{})
+			err := uc.SendCoin(context.Background(), claims, "receiver", 500, tt.otpCode)
+
+			require.ErrorIs(t, err, tt.otpErr)
+		})
+	}
+}
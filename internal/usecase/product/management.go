@@ -0,0 +1,279 @@
+package product
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/resueman/merch-store/internal/entity"
+	"github.com/resueman/merch-store/internal/model"
+	"github.com/resueman/merch-store/internal/repo/repoerrors"
+	"github.com/resueman/merch-store/internal/usecase/apperrors"
+	"github.com/resueman/merch-store/internal/usecase/converter"
+	"github.com/resueman/merch-store/pkg/db"
+)
+
+const (
+	auditFieldPrice       = "price"
+	auditFieldDescription = "description"
+	auditFieldStock       = "stock"
+	auditFieldVariant     = "variant"
+)
+
+func validatePrice(price int) error {
+	if price < 0 {
+		return apperrors.ErrInvalidPrice
+	}
+
+	return nil
+}
+
+func auditEntry(productID int, claims model.Claims, action model.ProductAuditAction, field string,
+	oldValue, newValue *string,
+) entity.ProductAudit {
+	return entity.ProductAudit{
+		ProductID: productID,
+		ChangedBy: claims.UserID,
+		Action:    string(action),
+		Field:     &field,
+		OldValue:  oldValue,
+		NewValue:  newValue,
+	}
+}
+
+func ptr(value string) *string {
+	return &value
+}
+
+// Проверить:
+// 1. Название не пустое (пробелы по краям отбрасываются)
+// 2. Цена и начальный остаток не отрицательные
+// 3. Товара с таким названием еще нет, в том числе среди снятых с продажи
+// Начальные значения полей записываются в журнал изменений.
+func (u *productUsecase) CreateProduct(ctx context.Context, claims model.Claims, input model.CreateProductInput) (
+	model.Product, error,
+) {
+	input.Name = strings.TrimSpace(input.Name)
+	if input.Name == "" {
+		return model.Product{}, apperrors.ErrInvalidProductName
+	}
+
+	if err := validatePrice(input.Price); err != nil {
+		return model.Product{}, err
+	}
+
+	if input.Stock < 0 {
+		return model.Product{}, apperrors.ErrInvalidStock
+	}
+
+	transaction := func(ctx context.Context) error {
+		productID, err := u.productRepo.CreateProduct(ctx, entity.CreateProductInput{
+			Name:        input.Name,
+			Price:       input.Price,
+			Description: input.Description,
+			Stock:       input.Stock,
+		})
+		if err != nil {
+			if errors.Is(err, repoerrors.ErrAlreadyExists) {
+				return apperrors.ErrProductAlreadyExists
+			}
+
+			return err
+		}
+
+		return u.productRepo.CreateProductAudit(ctx, []entity.ProductAudit{
+			auditEntry(productID, claims, model.ProductAuditCreate, auditFieldPrice, nil,
+				ptr(strconv.Itoa(input.Price))),
+			auditEntry(productID, claims, model.ProductAuditCreate, auditFieldDescription, nil,
+				ptr(input.Description)),
+			auditEntry(productID, claims, model.ProductAuditCreate, auditFieldStock, nil,
+				ptr(strconv.Itoa(input.Stock))),
+		})
+	}
+
+	serializable := u.txManager.Serializable(ctx, db.Write, transaction)
+	if err := u.txManager.WithRetry(serializable); err != nil {
+		return model.Product{}, err
+	}
+
+	return model.Product{
+		Name:        input.Name,
+		Price:       input.Price,
+		Description: input.Description,
+		Stock:       input.Stock,
+	}, nil
+}
+
+// Меняет цену и описание товара. В журнал попадают только поля, значение которых действительно изменилось.
+// Новая цена действует для следующих покупок и оформления корзин, уже совершенные покупки не пересчитываются.
+func (u *productUsecase) UpdateProduct(ctx context.Context, claims model.Claims, name string,
+	input model.UpdateProductInput,
+) (model.Product, error) {
+	if input.Price != nil {
+		if err := validatePrice(*input.Price); err != nil {
+			return model.Product{}, err
+		}
+	}
+
+	var product *entity.Product
+
+	transaction := func(ctx context.Context) error {
+		var err error
+		if product, err = u.productRepo.GetProductByName(ctx, name); err != nil {
+			if errors.Is(err, repoerrors.ErrNotFound) {
+				return apperrors.ErrProductNotFound
+			}
+
+			return err
+		}
+
+		update := entity.UpdateProductInput{}
+		entries := []entity.ProductAudit{}
+
+		if input.Price != nil && *input.Price != product.Price {
+			update.Price = input.Price
+			entries = append(entries, auditEntry(product.ID, claims, model.ProductAuditUpdate, auditFieldPrice,
+				ptr(strconv.Itoa(product.Price)), ptr(strconv.Itoa(*input.Price))))
+			product.Price = *input.Price
+		}
+
+		if input.Description != nil && *input.Description != product.Description {
+			update.Description = input.Description
+			entries = append(entries, auditEntry(product.ID, claims, model.ProductAuditUpdate,
+				auditFieldDescription, ptr(product.Description), input.Description))
+			product.Description = *input.Description
+		}
+
+		if len(entries) == 0 {
+			return nil
+		}
+
+		if err = u.productRepo.UpdateProduct(ctx, product.ID, update); err != nil {
+			if errors.Is(err, repoerrors.ErrNotFound) {
+				return apperrors.ErrProductNotFound
+			}
+
+			return err
+		}
+
+		return u.productRepo.CreateProductAudit(ctx, entries)
+	}
+
+	serializable := u.txManager.Serializable(ctx, db.Write, transaction)
+	if err := u.txManager.WithRetry(serializable); err != nil {
+		return model.Product{}, err
+	}
+
+	return converter.ConvertProduct(*product), nil
+}
+
+// Снимает товар с продажи: он пропадает из каталога, его нельзя купить или добавить в корзину.
+// История покупок и инвентарь пользователей сохраняются.
+func (u *productUsecase) RetireProduct(ctx context.Context, claims model.Claims, name string) error {
+	transaction := func(ctx context.Context) error {
+		product, err := u.productRepo.GetProductByName(ctx, name)
+		if err != nil {
+			if errors.Is(err, repoerrors.ErrNotFound) {
+				return apperrors.ErrProductNotFound
+			}
+
+			return err
+		}
+
+		if err = u.productRepo.RetireProduct(ctx, product.ID); err != nil {
+			if errors.Is(err, repoerrors.ErrNotFound) {
+				return apperrors.ErrProductNotFound
+			}
+
+			return err
+		}
+
+		return u.productRepo.CreateProductAudit(ctx, []entity.ProductAudit{{
+			ProductID: product.ID,
+			ChangedBy: claims.UserID,
+			Action:    string(model.ProductAuditRetire),
+		}})
+	}
+
+	serializable := u.txManager.Serializable(ctx, db.Write, transaction)
+
+	return u.txManager.WithRetry(serializable)
+}
+
+// Проверить:
+// 1. Название и SKU варианта не пустые (пробелы по краям отбрасываются)
+// 2. Цена и остаток варианта, если заданы, не отрицательные
+// 3. Товар существует, у него нет варианта с таким названием, а SKU не занят другим вариантом
+// Новый вариант записывается в журнал изменений товара.
+func (u *productUsecase) CreateProductVariant(ctx context.Context, claims model.Claims, itemName string,
+	input model.CreateProductVariantInput,
+) (model.ProductVariant, error) {
+	input.Name, input.SKU = strings.TrimSpace(input.Name), strings.TrimSpace(input.SKU)
+	if input.Name == "" || input.SKU == "" {
+		return model.ProductVariant{}, apperrors.ErrInvalidVariant
+	}
+
+	if input.Price != nil {
+		if err := validatePrice(*input.Price); err != nil {
+			return model.ProductVariant{}, err
+		}
+	}
+
+	if input.Stock != nil && *input.Stock < 0 {
+		return model.ProductVariant{}, apperrors.ErrInvalidStock
+	}
+
+	var variant model.ProductVariant
+
+	transaction := func(ctx context.Context) error {
+		product, err := u.productRepo.GetProductByName(ctx, itemName)
+		if err != nil {
+			if errors.Is(err, repoerrors.ErrNotFound) {
+				return apperrors.ErrProductNotFound
+			}
+
+			return err
+		}
+
+		if _, err = u.productRepo.CreateProductVariant(ctx, entity.CreateProductVariantInput{
+			ProductID: product.ID,
+			Name:      input.Name,
+			SKU:       input.SKU,
+			Price:     input.Price,
+			Stock:     input.Stock,
+		}); err != nil {
+			if errors.Is(err, repoerrors.ErrAlreadyExists) {
+				return apperrors.ErrVariantAlreadyExists
+			}
+
+			return err
+		}
+
+		variant = converter.ConvertProductVariant(*product, entity.ProductVariant{
+			Name: input.Name, SKU: input.SKU, Price: input.Price, Stock: input.Stock,
+		})
+
+		return u.productRepo.CreateProductAudit(ctx, []entity.ProductAudit{
+			auditEntry(product.ID, claims, model.ProductAuditUpdate, auditFieldVariant, nil,
+				ptr(input.Name+" ("+input.SKU+")")),
+		})
+	}
+
+	serializable := u.txManager.Serializable(ctx, db.Write, transaction)
+	if err := u.txManager.WithRetry(serializable); err != nil {
+		return model.ProductVariant{}, err
+	}
+
+	return variant, nil
+}
+
+// Журнал изменений товара, в том числе снятого с продажи.
+func (u *productUsecase) GetProductAudit(ctx context.Context, name string) ([]model.ProductAuditEntry, error) {
+	entries, err := u.productRepo.GetProductAudit(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return converter.ConvertProductAudit(entries), nil
+}
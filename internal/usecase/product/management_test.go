@@ -0,0 +1,257 @@
+package product
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/resueman/merch-store/internal/entity"
+	"github.com/resueman/merch-store/internal/model"
+	"github.com/resueman/merch-store/internal/repo/repoerrors"
+	"github.com/resueman/merch-store/internal/usecase/apperrors"
+	"github.com/resueman/merch-store/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateProduct(t *testing.T) {
+	tests := []struct {
+		name  string
+		input model.CreateProductInput
+		mock  func(productRepo *mocks.MockProduct, txManager *mocks.MockTxManager)
+		want  error
+	}{
+		{
+			name:  "blank name",
+			input: model.CreateProductInput{Name: "  ", Price: 100},
+			mock:  func(_ *mocks.MockProduct, _ *mocks.MockTxManager) {},
+			want:  apperrors.ErrInvalidProductName,
+		},
+		{
+			name:  "negative price",
+			input: model.CreateProductInput{Name: "mug", Price: -1},
+			mock:  func(_ *mocks.MockProduct, _ *mocks.MockTxManager) {},
+			want:  apperrors.ErrInvalidPrice,
+		},
+		{
+			name:  "negative stock",
+			input: model.CreateProductInput{Name: "mug", Price: 100, Stock: -1},
+			mock:  func(_ *mocks.MockProduct, _ *mocks.MockTxManager) {},
+			want:  apperrors.ErrInvalidStock,
+		},
+		{
+			name:  "duplicate name",
+			input: model.CreateProductInput{Name: "cup", Price: 100},
+			mock: func(productRepo *mocks.MockProduct, txManager *mocks.MockTxManager) {
+				productRepo.EXPECT().CreateProduct(gomock.Any(), gomock.Any()).Return(0, repoerrors.ErrAlreadyExists)
+				txMock(txManager)
+			},
+			want: apperrors.ErrProductAlreadyExists,
+		},
+		{
+			name:  "product is created and initial values are logged",
+			input: model.CreateProductInput{Name: " mug ", Price: 100, Description: "white", Stock: 3},
+			mock: func(productRepo *mocks.MockProduct, txManager *mocks.MockTxManager) {
+				productRepo.EXPECT().CreateProduct(gomock.Any(), entity.CreateProductInput{
+					Name: "mug", Price: 100, Description: "white", Stock: 3,
+				}).Return(11, nil)
+				productRepo.EXPECT().CreateProductAudit(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, entries []entity.ProductAudit) error {
+						require.Len(t, entries, 3)
+						for _, entry := range entries {
+							require.Equal(t, 11, entry.ProductID)
+							require.Equal(t, 1, entry.ChangedBy)
+							require.Equal(t, string(model.ProductAuditCreate), entry.Action)
+							require.Nil(t, entry.OldValue)
+						}
+
+						return nil
+					})
+				txMock(txManager)
+			},
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			productRepo, txManager := mocks.NewMockProduct(ctrl), mocks.NewMockTxManager(ctrl)
+			testCase.mock(productRepo, txManager)
+
+			uc := NewProductUsecase(productRepo, txManager, Config{})
+			product, err := uc.CreateProduct(context.Background(), model.Claims{UserID: 1}, testCase.input)
+			require.ErrorIs(t, err, testCase.want)
+
+			if testCase.want == nil {
+				require.Equal(t, "mug", product.Name)
+			}
+		})
+	}
+}
+
+func TestUpdateProduct(t *testing.T) {
+	price, negativePrice, sameDescription := 700, -5, "warm"
+
+	tests := []struct {
+		name  string
+		input model.UpdateProductInput
+		mock  func(productRepo *mocks.MockProduct, txManager *mocks.MockTxManager)
+		want  error
+	}{
+		{
+			name:  "negative price",
+			input: model.UpdateProductInput{Price: &negativePrice},
+			mock:  func(_ *mocks.MockProduct, _ *mocks.MockTxManager) {},
+			want:  apperrors.ErrInvalidPrice,
+		},
+		{
+			name:  "unknown product",
+			input: model.UpdateProductInput{Price: &price},
+			mock: func(productRepo *mocks.MockProduct, txManager *mocks.MockTxManager) {
+				productRepo.EXPECT().GetProductByName(gomock.Any(), "hoody").Return(nil, repoerrors.ErrNotFound)
+				txMock(txManager)
+			},
+			want: apperrors.ErrProductNotFound,
+		},
+		{
+			name:  "only changed fields are updated and logged",
+			input: model.UpdateProductInput{Price: &price, Description: &sameDescription},
+			mock: func(productRepo *mocks.MockProduct, txManager *mocks.MockTxManager) {
+				productRepo.EXPECT().GetProductByName(gomock.Any(), "hoody").
+					Return(&entity.Product{ID: 7, Name: "hoody", Price: 300, Description: "warm"}, nil)
+				productRepo.EXPECT().UpdateProduct(gomock.Any(), 7, entity.UpdateProductInput{Price: &price}).
+					Return(nil)
+				productRepo.EXPECT().CreateProductAudit(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, entries []entity.ProductAudit) error {
+						require.Len(t, entries, 1)
+						require.Equal(t, "price", *entries[0].Field)
+						require.Equal(t, "300", *entries[0].OldValue)
+						require.Equal(t, "700", *entries[0].NewValue)
+
+						return nil
+					})
+				txMock(txManager)
+			},
+		},
+		{
+			name:  "nothing changed",
+			input: model.UpdateProductInput{Description: &sameDescription},
+			mock: func(productRepo *mocks.MockProduct, txManager *mocks.MockTxManager) {
+				productRepo.EXPECT().GetProductByName(gomock.Any(), "hoody").
+					Return(&entity.Product{ID: 7, Name: "hoody", Price: 700, Description: "warm"}, nil)
+				txMock(txManager)
+			},
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			productRepo, txManager := mocks.NewMockProduct(ctrl), mocks.NewMockTxManager(ctrl)
+			testCase.mock(productRepo, txManager)
+
+			uc := NewProductUsecase(productRepo, txManager, Config{})
+			product, err := uc.UpdateProduct(context.Background(), model.Claims{UserID: 1}, "hoody", testCase.input)
+			require.ErrorIs(t, err, testCase.want)
+
+			if testCase.want == nil {
+				require.Equal(t, 700, product.Price)
+			}
+		})
+	}
+}
+
+func TestRetireProduct(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	productRepo, txManager := mocks.NewMockProduct(ctrl), mocks.NewMockTxManager(ctrl)
+	productRepo.EXPECT().GetProductByName(gomock.Any(), "umbrella").
+		Return(&entity.Product{ID: 9, Name: "umbrella"}, nil)
+	productRepo.EXPECT().RetireProduct(gomock.Any(), 9).Return(nil)
+	productRepo.EXPECT().CreateProductAudit(gomock.Any(), []entity.ProductAudit{{
+		ProductID: 9, ChangedBy: 1, Action: string(model.ProductAuditRetire),
+	}}).Return(nil)
+	txMock(txManager)
+
+	uc := NewProductUsecase(productRepo, txManager, Config{})
+	require.NoError(t, uc.RetireProduct(context.Background(), model.Claims{UserID: 1}, "umbrella"))
+}
+
+func TestCreateProductVariant(t *testing.T) {
+	price := -1
+
+	tests := []struct {
+		name  string
+		input model.CreateProductVariantInput
+		mock  func(productRepo *mocks.MockProduct, txManager *mocks.MockTxManager)
+		want  error
+	}{
+		{
+			name:  "blank sku",
+			input: model.CreateProductVariantInput{Name: "XL", SKU: " "},
+			mock:  func(_ *mocks.MockProduct, _ *mocks.MockTxManager) {},
+			want:  apperrors.ErrInvalidVariant,
+		},
+		{
+			name:  "negative price",
+			input: model.CreateProductVariantInput{Name: "XL", SKU: "HOODY-XL", Price: &price},
+			mock:  func(_ *mocks.MockProduct, _ *mocks.MockTxManager) {},
+			want:  apperrors.ErrInvalidPrice,
+		},
+		{
+			name:  "duplicate sku",
+			input: model.CreateProductVariantInput{Name: "XL", SKU: "HOODY-XL"},
+			mock: func(productRepo *mocks.MockProduct, txManager *mocks.MockTxManager) {
+				productRepo.EXPECT().GetProductByName(gomock.Any(), "hoody").
+					Return(&entity.Product{ID: 6, Name: "hoody", Price: 300}, nil)
+				productRepo.EXPECT().CreateProductVariant(gomock.Any(), gomock.Any()).
+					Return(0, repoerrors.ErrAlreadyExists)
+				txMock(txManager)
+			},
+			want: apperrors.ErrVariantAlreadyExists,
+		},
+		{
+			name:  "variant is created and logged",
+			input: model.CreateProductVariantInput{Name: "XL", SKU: "HOODY-XL"},
+			mock: func(productRepo *mocks.MockProduct, txManager *mocks.MockTxManager) {
+				productRepo.EXPECT().GetProductByName(gomock.Any(), "hoody").
+					Return(&entity.Product{ID: 6, Name: "hoody", Price: 300, Stock: 40}, nil)
+				productRepo.EXPECT().CreateProductVariant(gomock.Any(), entity.CreateProductVariantInput{
+					ProductID: 6, Name: "XL", SKU: "HOODY-XL",
+				}).Return(2, nil)
+				productRepo.EXPECT().CreateProductAudit(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, entries []entity.ProductAudit) error {
+						require.Len(t, entries, 1)
+						require.Equal(t, "variant", *entries[0].Field)
+						require.Equal(t, "XL (HOODY-XL)", *entries[0].NewValue)
+
+						return nil
+					})
+				txMock(txManager)
+			},
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			productRepo, txManager := mocks.NewMockProduct(ctrl), mocks.NewMockTxManager(ctrl)
+			testCase.mock(productRepo, txManager)
+
+			uc := NewProductUsecase(productRepo, txManager, Config{})
+			variant, err := uc.CreateProductVariant(context.Background(), model.Claims{UserID: 1}, "hoody",
+				testCase.input)
+			require.ErrorIs(t, err, testCase.want)
+
+			if testCase.want == nil {
+				require.Equal(t, model.ProductVariant{Name: "XL", SKU: "HOODY-XL", Price: 300, Stock: 40}, variant)
+			}
+		})
+	}
+}
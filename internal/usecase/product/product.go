@@ -0,0 +1,218 @@
+package product
+
+import (
+	"context"
+	"errors"
+
+	"github.com/resueman/merch-store/internal/entity"
+	"github.com/resueman/merch-store/internal/model"
+	"github.com/resueman/merch-store/internal/repo"
+	"github.com/resueman/merch-store/internal/repo/repoerrors"
+	"github.com/resueman/merch-store/internal/usecase/apperrors"
+	"github.com/resueman/merch-store/internal/usecase/converter"
+	"github.com/resueman/merch-store/pkg/db"
+)
+
+const (
+	defaultLowStockThreshold = 5
+	defaultPageLimit         = 20
+	maxPageLimit             = 100
+)
+
+type Config struct {
+	// Товары с остатком не больше порога попадают в отчет для администраторов. Если не задан, используется 5.
+	LowStockThreshold int
+}
+
+type productUsecase struct {
+	productRepo       repo.Product
+	txManager         db.TxManager
+	lowStockThreshold int
+}
+
+func NewProductUsecase(product repo.Product, txManager db.TxManager, cfg Config) *productUsecase {
+	lowStockThreshold := cfg.LowStockThreshold
+	if lowStockThreshold <= 0 {
+		lowStockThreshold = defaultLowStockThreshold
+	}
+
+	return &productUsecase{
+		productRepo:       product,
+		txManager:         txManager,
+		lowStockThreshold: lowStockThreshold,
+	}
+}
+
+// Пополнение склада: остаток увеличивается на quantity, а пополнение записывается в журнал
+// вместе с администратором, который его выполнил. Если указан вариант с собственным остатком,
+// пополняется он, а в ответе возвращается товар с этим вариантом.
+func (u *productUsecase) Restock(ctx context.Context, claims model.Claims, itemName string, variantName string,
+	quantity int,
+) (model.Product, error) {
+	if quantity <= 0 {
+		return model.Product{}, apperrors.ErrInvalidQuantity
+	}
+
+	product, err := u.productRepo.GetProductByName(ctx, itemName)
+	if err != nil {
+		if errors.Is(err, repoerrors.ErrNotFound) {
+			return model.Product{}, apperrors.ErrProductNotFound
+		}
+
+		return model.Product{}, err
+	}
+
+	var variant *entity.ProductVariant
+	if variantName != "" {
+		if variant, err = u.productRepo.GetProductVariant(ctx, product.ID, variantName); err != nil {
+			if errors.Is(err, repoerrors.ErrNotFound) {
+				return model.Product{}, apperrors.ErrVariantNotFound
+			}
+
+			return model.Product{}, err
+		}
+	}
+
+	transaction := func(ctx context.Context) error {
+		restock := entity.Restock{ProductID: product.ID, Quantity: quantity, RestockedBy: claims.UserID}
+
+		if variant != nil && variant.Stock != nil {
+			stock, err := u.productRepo.AddVariantStock(ctx, variant.ID, quantity)
+			if err != nil {
+				return err
+			}
+
+			variant.Stock, restock.VariantID = &stock, &variant.ID
+		} else {
+			stock, err := u.productRepo.AddStock(ctx, product.ID, quantity)
+			if err != nil {
+				if errors.Is(err, repoerrors.ErrNotFound) {
+					return apperrors.ErrProductNotFound
+				}
+
+				return err
+			}
+
+			product.Stock = stock
+		}
+
+		return u.productRepo.CreateRestock(ctx, restock)
+	}
+
+	serializable := u.txManager.Serializable(ctx, db.Write, transaction)
+	if err = u.txManager.WithRetry(serializable); err != nil {
+		return model.Product{}, err
+	}
+
+	result := converter.ConvertProduct(*product)
+	if variant != nil {
+		result.Variants = []model.ProductVariant{converter.ConvertProductVariant(*product, *variant)}
+	}
+
+	return result, nil
+}
+
+// Товары, которые скоро закончатся или уже закончились. Варианты с собственным остатком, которые
+// заканчиваются, перечисляются в Variants своего товара; товар попадает в отчет и тогда,
+// когда его общий остаток еще велик.
+func (u *productUsecase) GetLowStockProducts(ctx context.Context) ([]model.Product, error) {
+	items, err := u.productRepo.GetLowStockProducts(ctx, u.lowStockThreshold)
+	if err != nil {
+		return nil, err
+	}
+
+	products := make([]model.Product, 0, len(items))
+	positions := make(map[int]int, len(items))
+
+	for _, item := range items {
+		i, ok := positions[item.Product.ID]
+		if !ok {
+			i = len(products)
+			positions[item.Product.ID] = i
+			products = append(products, converter.ConvertProduct(item.Product))
+		}
+
+		if item.Variant != nil {
+			products[i].Variants = append(products[i].Variants,
+				converter.ConvertProductVariant(item.Product, *item.Variant))
+		}
+	}
+
+	return products, nil
+}
+
+// Проверить:
+// 1. Поле сортировки известно, по умолчанию товары сортируются по имени
+// 2. Размер страницы от 1 до 100 (0 означает размер по умолчанию), смещение не отрицательное
+// 3. Границы цены не отрицательные, нижняя не больше верхней
+func (u *productUsecase) GetProducts(ctx context.Context, filter model.ProductFilter) (model.ProductPage, error) {
+	if filter.SortBy == "" {
+		filter.SortBy = model.ProductSortName
+	}
+
+	if !filter.SortBy.Valid() {
+		return model.ProductPage{}, apperrors.ErrInvalidProductSort
+	}
+
+	if filter.Limit == 0 {
+		filter.Limit = defaultPageLimit
+	}
+
+	if filter.Limit < 0 || filter.Limit > maxPageLimit || filter.Offset < 0 {
+		return model.ProductPage{}, apperrors.ErrInvalidPagination
+	}
+
+	if (filter.MinPrice != nil && *filter.MinPrice < 0) || (filter.MaxPrice != nil && *filter.MaxPrice < 0) ||
+		(filter.MinPrice != nil && filter.MaxPrice != nil && *filter.MinPrice > *filter.MaxPrice) {
+		return model.ProductPage{}, apperrors.ErrInvalidPriceRange
+	}
+
+	entityFilter := entity.ProductFilter{
+		NameSubstring: filter.Query,
+		MinPrice:      filter.MinPrice,
+		MaxPrice:      filter.MaxPrice,
+		OrderBy:       string(filter.SortBy),
+		Desc:          filter.Desc,
+		Limit:         filter.Limit,
+		Offset:        filter.Offset,
+	}
+
+	products, err := u.productRepo.GetProducts(ctx, entityFilter)
+	if err != nil {
+		return model.ProductPage{}, err
+	}
+
+	total, err := u.productRepo.CountProducts(ctx, entityFilter)
+	if err != nil {
+		return model.ProductPage{}, err
+	}
+
+	return model.ProductPage{
+		Items:  converter.ConvertProducts(products),
+		Total:  total,
+		Limit:  filter.Limit,
+		Offset: filter.Offset,
+	}, nil
+}
+
+// Карточка товара вместе с его вариантами.
+func (u *productUsecase) GetProduct(ctx context.Context, name string) (model.Product, error) {
+	product, err := u.productRepo.GetProductByName(ctx, name)
+	if err != nil {
+		if errors.Is(err, repoerrors.ErrNotFound) {
+			return model.Product{}, apperrors.ErrProductNotFound
+		}
+
+		return model.Product{}, err
+	}
+
+	variants, err := u.productRepo.GetProductVariants(ctx, product.ID)
+	if err != nil {
+		return model.Product{}, err
+	}
+
+	result := converter.ConvertProduct(*product)
+	result.Variants = converter.ConvertProductVariants(*product, variants)
+
+	return result, nil
+}
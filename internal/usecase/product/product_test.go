@@ -0,0 +1,275 @@
+package product
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/resueman/merch-store/internal/entity"
+	"github.com/resueman/merch-store/internal/model"
+	"github.com/resueman/merch-store/internal/repo/repoerrors"
+	"github.com/resueman/merch-store/internal/usecase/apperrors"
+	"github.com/resueman/merch-store/pkg/db"
+	"github.com/resueman/merch-store/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func txMock(txManager *mocks.MockTxManager) {
+	txManager.EXPECT().
+		Serializable(gomock.Any(), db.Write, gomock.Any()).
+		DoAndReturn(func(ctx context.Context, _ db.Mode, f func(context.Context) error) func() error {
+			return func() error { return f(ctx) }
+		})
+
+	txManager.EXPECT().
+		WithRetry(gomock.Any()).
+		DoAndReturn(func(f func() error) error {
+			return f()
+		})
+}
+
+func TestRestock(t *testing.T) {
+	tests := []struct {
+		name     string
+		itemName string
+		quantity int
+		mock     func(productRepo *mocks.MockProduct, txManager *mocks.MockTxManager)
+		want     error
+	}{
+		{
+			name:     "non-positive quantity",
+			itemName: "pen",
+			quantity: 0,
+			mock:     func(_ *mocks.MockProduct, _ *mocks.MockTxManager) {},
+			want:     apperrors.ErrInvalidQuantity,
+		},
+		{
+			name:     "unknown product",
+			itemName: "jujuju",
+			quantity: 5,
+			mock: func(productRepo *mocks.MockProduct, _ *mocks.MockTxManager) {
+				productRepo.EXPECT().GetProductByName(gomock.Any(), "jujuju").Return(nil, repoerrors.ErrNotFound)
+			},
+			want: apperrors.ErrProductNotFound,
+		},
+		{
+			name:     "stock is increased and the restock is logged",
+			itemName: "pink-hoody",
+			quantity: 20,
+			mock: func(productRepo *mocks.MockProduct, txManager *mocks.MockTxManager) {
+				productRepo.EXPECT().GetProductByName(gomock.Any(), "pink-hoody").
+					Return(&entity.Product{ID: 10, Name: "pink-hoody", Price: 500, Stock: 1}, nil)
+				productRepo.EXPECT().AddStock(gomock.Any(), 10, 20).Return(21, nil)
+				productRepo.EXPECT().CreateRestock(gomock.Any(), entity.Restock{
+					ProductID: 10, Quantity: 20, RestockedBy: 1,
+				}).Return(nil)
+				txMock(txManager)
+			},
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			productRepo, txManager := mocks.NewMockProduct(ctrl), mocks.NewMockTxManager(ctrl)
+			testCase.mock(productRepo, txManager)
+
+			uc := NewProductUsecase(productRepo, txManager, Config{})
+			product, err := uc.Restock(context.Background(), model.Claims{UserID: 1}, testCase.itemName, "",
+				testCase.quantity)
+
+			require.ErrorIs(t, err, testCase.want)
+
+			if testCase.want == nil {
+				require.Equal(t, model.Product{Name: "pink-hoody", Price: 500, Stock: 21}, product)
+			}
+		})
+	}
+}
+
+func TestRestock_VariantWithOwnStock(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	stock, variantID := 2, 3
+
+	productRepo, txManager := mocks.NewMockProduct(ctrl), mocks.NewMockTxManager(ctrl)
+	productRepo.EXPECT().GetProductByName(gomock.Any(), "hoody").
+		Return(&entity.Product{ID: 6, Name: "hoody", Price: 300, Stock: 50}, nil)
+	productRepo.EXPECT().GetProductVariant(gomock.Any(), 6, "XL").
+		Return(&entity.ProductVariant{ID: variantID, ProductID: 6, Name: "XL", SKU: "HOODY-XL", Stock: &stock}, nil)
+	productRepo.EXPECT().AddVariantStock(gomock.Any(), variantID, 10).Return(12, nil)
+	productRepo.EXPECT().CreateRestock(gomock.Any(), entity.Restock{
+		ProductID: 6, VariantID: &variantID, Quantity: 10, RestockedBy: 1,
+	}).Return(nil)
+	txMock(txManager)
+
+	uc := NewProductUsecase(productRepo, txManager, Config{})
+	product, err := uc.Restock(context.Background(), model.Claims{UserID: 1}, "hoody", "XL", 10)
+
+	require.NoError(t, err)
+	require.Equal(t, 50, product.Stock)
+	require.Equal(t, []model.ProductVariant{{Name: "XL", SKU: "HOODY-XL", Price: 300, Stock: 12}}, product.Variants)
+}
+
+func TestGetLowStockProducts_DefaultThreshold(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	productRepo := mocks.NewMockProduct(ctrl)
+	productRepo.EXPECT().GetLowStockProducts(gomock.Any(), defaultLowStockThreshold).
+		Return([]entity.LowStockProduct{
+			{Product: entity.Product{ID: 10, Name: "pink-hoody", Price: 500, Stock: 2}},
+		}, nil)
+
+	uc := NewProductUsecase(productRepo, nil, Config{})
+	products, err := uc.GetLowStockProducts(context.Background())
+
+	require.NoError(t, err)
+	require.Equal(t, []model.Product{{Name: "pink-hoody", Price: 500, Stock: 2}}, products)
+}
+
+func TestGetLowStockProducts_Variants(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	intPtr := func(v int) *int { return &v }
+	hoody := entity.Product{ID: 6, Name: "hoody", Price: 300, Stock: 40}
+	cup := entity.Product{ID: 3, Name: "cup", Price: 20, Stock: 3}
+
+	// Вариант с собственным остатком попадает в отчет, даже если общий остаток товара велик.
+	productRepo := mocks.NewMockProduct(ctrl)
+	productRepo.EXPECT().GetLowStockProducts(gomock.Any(), 5).Return([]entity.LowStockProduct{
+		{Product: hoody, Variant: &entity.ProductVariant{ID: 1, ProductID: 6, Name: "XL", SKU: "HOODY-XL",
+			Price: intPtr(350), Stock: intPtr(0)}},
+		{Product: cup},
+		{Product: hoody, Variant: &entity.ProductVariant{ID: 2, ProductID: 6, Name: "S", SKU: "HOODY-S",
+			Stock: intPtr(4)}},
+	}, nil)
+
+	uc := NewProductUsecase(productRepo, nil, Config{LowStockThreshold: 5})
+	products, err := uc.GetLowStockProducts(context.Background())
+
+	require.NoError(t, err)
+	require.Equal(t, []model.Product{
+		{Name: "hoody", Price: 300, Stock: 40, Variants: []model.ProductVariant{
+			{Name: "XL", SKU: "HOODY-XL", Price: 350, Stock: 0},
+			{Name: "S", SKU: "HOODY-S", Price: 300, Stock: 4},
+		}},
+		{Name: "cup", Price: 20, Stock: 3},
+	}, products)
+}
+
+func TestGetProducts(t *testing.T) {
+	intPtr := func(v int) *int { return &v }
+
+	tests := []struct {
+		name   string
+		filter model.ProductFilter
+		mock   func(productRepo *mocks.MockProduct)
+		want   error
+	}{
+		{
+			name:   "unknown sort",
+			filter: model.ProductFilter{SortBy: "stock"},
+			mock:   func(_ *mocks.MockProduct) {},
+			want:   apperrors.ErrInvalidProductSort,
+		},
+		{
+			name:   "page is too large",
+			filter: model.ProductFilter{Limit: maxPageLimit + 1},
+			mock:   func(_ *mocks.MockProduct) {},
+			want:   apperrors.ErrInvalidPagination,
+		},
+		{
+			name:   "negative offset",
+			filter: model.ProductFilter{Offset: -1},
+			mock:   func(_ *mocks.MockProduct) {},
+			want:   apperrors.ErrInvalidPagination,
+		},
+		{
+			name:   "min price above max price",
+			filter: model.ProductFilter{MinPrice: intPtr(100), MaxPrice: intPtr(50)},
+			mock:   func(_ *mocks.MockProduct) {},
+			want:   apperrors.ErrInvalidPriceRange,
+		},
+		{
+			name:   "defaults are applied",
+			filter: model.ProductFilter{Query: "hoody", MaxPrice: intPtr(400), Desc: true},
+			mock: func(productRepo *mocks.MockProduct) {
+				filter := entity.ProductFilter{
+					NameSubstring: "hoody",
+					MaxPrice:      intPtr(400),
+					OrderBy:       "name",
+					Desc:          true,
+					Limit:         defaultPageLimit,
+				}
+				productRepo.EXPECT().GetProducts(gomock.Any(), filter).
+					Return([]entity.Product{{ID: 6, Name: "hoody", Price: 300, Stock: 7}}, nil)
+				productRepo.EXPECT().CountProducts(gomock.Any(), filter).Return(1, nil)
+			},
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			productRepo := mocks.NewMockProduct(ctrl)
+			testCase.mock(productRepo)
+
+			uc := NewProductUsecase(productRepo, nil, Config{})
+			page, err := uc.GetProducts(context.Background(), testCase.filter)
+
+			require.ErrorIs(t, err, testCase.want)
+
+			if testCase.want == nil {
+				require.Equal(t, model.ProductPage{
+					Items: []model.Product{{Name: "hoody", Price: 300, Stock: 7}},
+					Total: 1,
+					Limit: defaultPageLimit,
+				}, page)
+			}
+		})
+	}
+}
+
+func TestGetProduct_NotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	productRepo := mocks.NewMockProduct(ctrl)
+	productRepo.EXPECT().GetProductByName(gomock.Any(), "jujuju").Return(nil, repoerrors.ErrNotFound)
+
+	uc := NewProductUsecase(productRepo, nil, Config{})
+	_, err := uc.GetProduct(context.Background(), "jujuju")
+
+	require.ErrorIs(t, err, apperrors.ErrProductNotFound)
+}
+
+func TestGetProduct_Variants(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	price, stock := 350, 4
+
+	productRepo := mocks.NewMockProduct(ctrl)
+	productRepo.EXPECT().GetProductByName(gomock.Any(), "hoody").
+		Return(&entity.Product{ID: 6, Name: "hoody", Price: 300, Stock: 50}, nil)
+	productRepo.EXPECT().GetProductVariants(gomock.Any(), 6).Return([]entity.ProductVariant{
+		{ID: 1, ProductID: 6, Name: "M", SKU: "HOODY-M"},
+		{ID: 2, ProductID: 6, Name: "XL", SKU: "HOODY-XL", Price: &price, Stock: &stock},
+	}, nil)
+
+	uc := NewProductUsecase(productRepo, nil, Config{})
+	product, err := uc.GetProduct(context.Background(), "hoody")
+
+	require.NoError(t, err)
+	require.Equal(t, []model.ProductVariant{
+		{Name: "M", SKU: "HOODY-M", Price: 300, Stock: 50},
+		{Name: "XL", SKU: "HOODY-XL", Price: 350, Stock: 4},
+	}, product.Variants)
+}
@@ -0,0 +1,242 @@
+package refund
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/resueman/merch-store/internal/entity"
+	"github.com/resueman/merch-store/internal/model"
+	"github.com/resueman/merch-store/internal/repo"
+	"github.com/resueman/merch-store/internal/repo/repoerrors"
+	"github.com/resueman/merch-store/internal/usecase/apperrors"
+	"github.com/resueman/merch-store/internal/usecase/converter"
+	"github.com/resueman/merch-store/pkg/db"
+)
+
+type Config struct {
+	// Сколько времени после покупки можно подать заявку на возврат.
+	Window time.Duration
+}
+
+type refundUsecase struct {
+	accountRepo   repo.Account
+	operationRepo repo.Operation
+	productRepo   repo.Product
+	refundRepo    repo.Refund
+	txManager     db.TxManager
+	window        time.Duration
+}
+
+func NewRefundUsecase(account repo.Account, operation repo.Operation, product repo.Product, refund repo.Refund,
+	txManager db.TxManager, cfg Config) *refundUsecase {
+	return &refundUsecase{
+		accountRepo:   account,
+		operationRepo: operation,
+		productRepo:   product,
+		refundRepo:    refund,
+		txManager:     txManager,
+		window:        cfg.Window,
+	}
+}
+
+// Заявка на возврат всей покупки или ее части. Монеты начисляются только после одобрения администратором,
+// но заявленные единицы сразу перестают быть доступными для следующих заявок.
+func (u *refundUsecase) RequestRefund(ctx context.Context, claims model.Claims, input model.RefundInput) (
+	model.Refund, error,
+) {
+	if input.Quantity < 0 {
+		return model.Refund{}, apperrors.ErrInvalidQuantity
+	}
+
+	accountID, err := u.accountRepo.GetIDByUserID(ctx, claims.UserID)
+	if err != nil {
+		return model.Refund{}, err
+	}
+
+	var refund *entity.Refund
+
+	transaction := func(ctx context.Context) error {
+		purchase, err := u.refundRepo.GetPurchaseForRefund(ctx, input.PurchaseID)
+		if err != nil {
+			if errors.Is(err, repoerrors.ErrNotFound) {
+				return apperrors.ErrPurchaseNotFound
+			}
+
+			return err
+		}
+
+		// Чужая покупка для пользователя неотличима от несуществующей.
+		if purchase.CustomerAccountID != accountID {
+			return apperrors.ErrPurchaseNotFound
+		}
+
+		if time.Since(purchase.CreatedAt) > u.window {
+			return apperrors.ErrRefundWindowExpired
+		}
+
+		remaining := purchase.Quantity - purchase.RefundedQuantity
+		quantity := input.Quantity
+		if quantity == 0 {
+			quantity = remaining
+		}
+
+		if quantity == 0 || quantity > remaining {
+			return apperrors.ErrRefundQuantityExceeded
+		}
+
+		// Сумма покупки всегда равна цене, умноженной на количество, поэтому деление без остатка.
+		refundInput := &entity.CreateRefundInput{
+			PurchaseID:        purchase.ID,
+			CustomerAccountID: accountID,
+			Quantity:          quantity,
+			Amount:            purchase.TotalPrice / purchase.Quantity * quantity,
+		}
+
+		refundID, err := u.refundRepo.CreateRefund(ctx, refundInput)
+		if err != nil {
+			return err
+		}
+
+		if refund, err = u.recordStep(ctx, refundID, accountID, model.RefundRequested); err != nil {
+			return err
+		}
+
+		return nil
+	}
+
+	serializable := u.txManager.Serializable(ctx, db.Write, transaction)
+	if err = u.txManager.WithRetry(serializable); err != nil {
+		return model.Refund{}, err
+	}
+
+	return converter.ConvertRefund(*refund), nil
+}
+
+// Одобрение заявки: сумма возврата начисляется покупателю, а возвращенные единицы пропадают из инвентаря
+// и снова появляются на складе.
+func (u *refundUsecase) ApproveRefund(ctx context.Context, claims model.Claims, refundID int) (model.Refund, error) {
+	return u.decide(ctx, claims, refundID, model.RefundApproved)
+}
+
+func (u *refundUsecase) RejectRefund(ctx context.Context, claims model.Claims, refundID int) (model.Refund, error) {
+	return u.decide(ctx, claims, refundID, model.RefundRejected)
+}
+
+func (u *refundUsecase) decide(ctx context.Context, claims model.Claims, refundID int, status model.RefundStatus) (
+	model.Refund, error,
+) {
+	var refund *entity.Refund
+
+	transaction := func(ctx context.Context) error {
+		requested, err := u.refundRepo.GetRefundByID(ctx, refundID)
+		if err != nil {
+			if errors.Is(err, repoerrors.ErrNotFound) {
+				return apperrors.ErrRefundNotFound
+			}
+
+			return err
+		}
+
+		if err = u.refundRepo.DecideRefund(ctx, refundID, string(status), claims.UserID); err != nil {
+			if errors.Is(err, repoerrors.ErrNotFound) {
+				return apperrors.ErrRefundAlreadyDecided
+			}
+
+			return err
+		}
+
+		if status == model.RefundApproved {
+			if err = u.accountRepo.Deposit(ctx, requested.CustomerAccountID, requested.Amount); err != nil {
+				return err
+			}
+
+			if err = u.returnToStock(ctx, requested); err != nil {
+				return err
+			}
+		}
+
+		if refund, err = u.recordStep(ctx, refundID, requested.CustomerAccountID, status); err != nil {
+			return err
+		}
+
+		return nil
+	}
+
+	serializable := u.txManager.Serializable(ctx, db.Write, transaction)
+	if err := u.txManager.WithRetry(serializable); err != nil {
+		return model.Refund{}, err
+	}
+
+	return converter.ConvertRefund(*refund), nil
+}
+
+// Единицы возвращаются туда, откуда были списаны при покупке: на собственный остаток варианта,
+// если он есть, иначе на остаток товара.
+func (u *refundUsecase) returnToStock(ctx context.Context, refund *entity.Refund) error {
+	if refund.VariantID != nil && refund.VariantHasStock {
+		_, err := u.productRepo.AddVariantStock(ctx, *refund.VariantID, refund.Quantity)
+		return err
+	}
+
+	_, err := u.productRepo.AddStock(ctx, refund.ProductID, refund.Quantity)
+
+	return err
+}
+
+// Записывает шаг в историю операций покупателя и возвращает заявку в новом состоянии.
+func (u *refundUsecase) recordStep(ctx context.Context, refundID, accountID int, status model.RefundStatus) (
+	*entity.Refund, error,
+) {
+	operation := entity.RefundOperation{RefundID: refundID, CustomerAccountID: accountID, Status: string(status)}
+	if err := u.operationRepo.ExecRefundOperation(ctx, operation); err != nil {
+		return nil, err
+	}
+
+	return u.refundRepo.GetRefundByID(ctx, refundID)
+}
+
+func (u *refundUsecase) GetRefunds(ctx context.Context, claims model.Claims) ([]model.Refund, error) {
+	accountID, err := u.accountRepo.GetIDByUserID(ctx, claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	refunds, err := u.refundRepo.GetRefundsByAccountID(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	return converter.ConvertRefunds(refunds), nil
+}
+
+// Заявки с заданным статусом, например ожидающие решения администратора.
+func (u *refundUsecase) GetRefundsByStatus(ctx context.Context, status model.RefundStatus) ([]model.Refund, error) {
+	if !status.Valid() {
+		return nil, apperrors.ErrInvalidRefundStatus
+	}
+
+	refunds, err := u.refundRepo.GetRefundsByStatus(ctx, string(status))
+	if err != nil {
+		return nil, err
+	}
+
+	return converter.ConvertRefunds(refunds), nil
+}
+
+// Покупки, по которым еще можно подать заявку: окно возврата не истекло и остались невозвращенные единицы.
+func (u *refundUsecase) GetRefundablePurchases(ctx context.Context, claims model.Claims) (
+	[]model.RefundablePurchase, error,
+) {
+	accountID, err := u.accountRepo.GetIDByUserID(ctx, claims.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	purchases, err := u.refundRepo.GetRefundablePurchases(ctx, accountID, time.Now().Add(-u.window))
+	if err != nil {
+		return nil, err
+	}
+
+	return converter.ConvertRefundablePurchases(purchases, u.window), nil
+}
@@ -0,0 +1,255 @@
+package refund
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/resueman/merch-store/internal/entity"
+	"github.com/resueman/merch-store/internal/model"
+	"github.com/resueman/merch-store/internal/repo/repoerrors"
+	"github.com/resueman/merch-store/internal/usecase/apperrors"
+	"github.com/resueman/merch-store/pkg/db"
+	"github.com/resueman/merch-store/test/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func txMock(txManager *mocks.MockTxManager) {
+	txManager.EXPECT().
+		Serializable(gomock.Any(), db.Write, gomock.Any()).
+		DoAndReturn(func(ctx context.Context, _ db.Mode, f func(context.Context) error) func() error {
+			return func() error { return f(ctx) }
+		})
+
+	txManager.EXPECT().
+		WithRetry(gomock.Any()).
+		DoAndReturn(func(f func() error) error {
+			return f()
+		})
+}
+
+func TestRequestRefund(t *testing.T) {
+	purchase := func(createdAt time.Time) *entity.RefundablePurchase {
+		return &entity.RefundablePurchase{
+			ID: 7, CustomerAccountID: 5, ProductName: "pen", Quantity: 4, TotalPrice: 40, RefundedQuantity: 1,
+			CreatedAt: createdAt,
+		}
+	}
+
+	tests := []struct {
+		name  string
+		input model.RefundInput
+		mock  func(refundRepo *mocks.MockRefund, operationRepo *mocks.MockOperation)
+		want  error
+	}{
+		{
+			name:  "unknown purchase",
+			input: model.RefundInput{PurchaseID: 7},
+			mock: func(refundRepo *mocks.MockRefund, _ *mocks.MockOperation) {
+				refundRepo.EXPECT().GetPurchaseForRefund(gomock.Any(), 7).Return(nil, repoerrors.ErrNotFound)
+			},
+			want: apperrors.ErrPurchaseNotFound,
+		},
+		{
+			name:  "purchase of another user",
+			input: model.RefundInput{PurchaseID: 7},
+			mock: func(refundRepo *mocks.MockRefund, _ *mocks.MockOperation) {
+				other := purchase(time.Now())
+				other.CustomerAccountID = 6
+				refundRepo.EXPECT().GetPurchaseForRefund(gomock.Any(), 7).Return(other, nil)
+			},
+			want: apperrors.ErrPurchaseNotFound,
+		},
+		{
+			name:  "window expired",
+			input: model.RefundInput{PurchaseID: 7},
+			mock: func(refundRepo *mocks.MockRefund, _ *mocks.MockOperation) {
+				refundRepo.EXPECT().GetPurchaseForRefund(gomock.Any(), 7).
+					Return(purchase(time.Now().Add(-2*time.Hour)), nil)
+			},
+			want: apperrors.ErrRefundWindowExpired,
+		},
+		{
+			name:  "more than not yet refunded",
+			input: model.RefundInput{PurchaseID: 7, Quantity: 4},
+			mock: func(refundRepo *mocks.MockRefund, _ *mocks.MockOperation) {
+				refundRepo.EXPECT().GetPurchaseForRefund(gomock.Any(), 7).Return(purchase(time.Now()), nil)
+			},
+			want: apperrors.ErrRefundQuantityExceeded,
+		},
+		{
+			name:  "partial refund",
+			input: model.RefundInput{PurchaseID: 7, Quantity: 2},
+			mock: func(refundRepo *mocks.MockRefund, operationRepo *mocks.MockOperation) {
+				refundRepo.EXPECT().GetPurchaseForRefund(gomock.Any(), 7).Return(purchase(time.Now()), nil)
+				refundRepo.EXPECT().CreateRefund(gomock.Any(), &entity.CreateRefundInput{
+					PurchaseID: 7, CustomerAccountID: 5, Quantity: 2, Amount: 20,
+				}).Return(3, nil)
+				operationRepo.EXPECT().ExecRefundOperation(gomock.Any(), entity.RefundOperation{
+					RefundID: 3, CustomerAccountID: 5, Status: "requested",
+				}).Return(nil)
+				refundRepo.EXPECT().GetRefundByID(gomock.Any(), 3).
+					Return(&entity.Refund{ID: 3, Quantity: 2, Amount: 20, Status: "requested"}, nil)
+			},
+		},
+		{
+			name:  "everything not yet refunded by default",
+			input: model.RefundInput{PurchaseID: 7},
+			mock: func(refundRepo *mocks.MockRefund, operationRepo *mocks.MockOperation) {
+				refundRepo.EXPECT().GetPurchaseForRefund(gomock.Any(), 7).Return(purchase(time.Now()), nil)
+				refundRepo.EXPECT().CreateRefund(gomock.Any(), &entity.CreateRefundInput{
+					PurchaseID: 7, CustomerAccountID: 5, Quantity: 3, Amount: 30,
+				}).Return(3, nil)
+				operationRepo.EXPECT().ExecRefundOperation(gomock.Any(), gomock.Any()).Return(nil)
+				refundRepo.EXPECT().GetRefundByID(gomock.Any(), 3).
+					Return(&entity.Refund{ID: 3, Quantity: 3, Amount: 30, Status: "requested"}, nil)
+			},
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			accountRepo, operationRepo := mocks.NewMockAccount(ctrl), mocks.NewMockOperation(ctrl)
+			refundRepo, txManager := mocks.NewMockRefund(ctrl), mocks.NewMockTxManager(ctrl)
+
+			accountRepo.EXPECT().GetIDByUserID(gomock.Any(), 111).Return(5, nil)
+			testCase.mock(refundRepo, operationRepo)
+			txMock(txManager)
+
+			uc := NewRefundUsecase(accountRepo, operationRepo, nil, refundRepo, txManager, Config{Window: time.Hour})
+			_, err := uc.RequestRefund(context.Background(), model.Claims{UserID: 111}, testCase.input)
+
+			require.ErrorIs(t, err, testCase.want)
+		})
+	}
+}
+
+func TestRequestRefund_NegativeQuantity(t *testing.T) {
+	uc := NewRefundUsecase(nil, nil, nil, nil, nil, Config{Window: time.Hour})
+	_, err := uc.RequestRefund(context.Background(), model.Claims{UserID: 111},
+		model.RefundInput{PurchaseID: 7, Quantity: -1})
+
+	require.ErrorIs(t, err, apperrors.ErrInvalidQuantity)
+}
+
+func TestApproveRefund(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	accountRepo, operationRepo := mocks.NewMockAccount(ctrl), mocks.NewMockOperation(ctrl)
+	refundRepo, txManager := mocks.NewMockRefund(ctrl), mocks.NewMockTxManager(ctrl)
+	productRepo := mocks.NewMockProduct(ctrl)
+
+	refundRepo.EXPECT().GetRefundByID(gomock.Any(), 3).Return(&entity.Refund{
+		ID: 3, CustomerAccountID: 5, ProductID: 4, Quantity: 2, Amount: 20, Status: "requested",
+	}, nil)
+	refundRepo.EXPECT().DecideRefund(gomock.Any(), 3, "approved", 1).Return(nil)
+	accountRepo.EXPECT().Deposit(gomock.Any(), 5, 20).Return(nil)
+	productRepo.EXPECT().AddStock(gomock.Any(), 4, 2).Return(12, nil)
+	operationRepo.EXPECT().ExecRefundOperation(gomock.Any(), entity.RefundOperation{
+		RefundID: 3, CustomerAccountID: 5, Status: "approved",
+	}).Return(nil)
+	refundRepo.EXPECT().GetRefundByID(gomock.Any(), 3).
+		Return(&entity.Refund{ID: 3, CustomerAccountID: 5, Amount: 20, Status: "approved"}, nil)
+	txMock(txManager)
+
+	uc := NewRefundUsecase(accountRepo, operationRepo, productRepo, refundRepo, txManager, Config{Window: time.Hour})
+	refund, err := uc.ApproveRefund(context.Background(), model.Claims{UserID: 1}, 3)
+
+	require.NoError(t, err)
+	require.Equal(t, model.RefundApproved, refund.Status)
+}
+
+func TestApproveRefund_VariantWithOwnStock(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	accountRepo, operationRepo := mocks.NewMockAccount(ctrl), mocks.NewMockOperation(ctrl)
+	refundRepo, txManager := mocks.NewMockRefund(ctrl), mocks.NewMockTxManager(ctrl)
+	productRepo := mocks.NewMockProduct(ctrl)
+
+	variantID := 9
+	refundRepo.EXPECT().GetRefundByID(gomock.Any(), 3).Return(&entity.Refund{
+		ID: 3, CustomerAccountID: 5, ProductID: 4, VariantID: &variantID, VariantHasStock: true, Quantity: 2,
+		Amount: 20, Status: "requested",
+	}, nil)
+	refundRepo.EXPECT().DecideRefund(gomock.Any(), 3, "approved", 1).Return(nil)
+	accountRepo.EXPECT().Deposit(gomock.Any(), 5, 20).Return(nil)
+	// Единицы возвращаются на остаток варианта, а не товара.
+	productRepo.EXPECT().AddVariantStock(gomock.Any(), variantID, 2).Return(3, nil)
+	operationRepo.EXPECT().ExecRefundOperation(gomock.Any(), gomock.Any()).Return(nil)
+	refundRepo.EXPECT().GetRefundByID(gomock.Any(), 3).
+		Return(&entity.Refund{ID: 3, CustomerAccountID: 5, Amount: 20, Status: "approved"}, nil)
+	txMock(txManager)
+
+	uc := NewRefundUsecase(accountRepo, operationRepo, productRepo, refundRepo, txManager, Config{Window: time.Hour})
+	_, err := uc.ApproveRefund(context.Background(), model.Claims{UserID: 1}, 3)
+
+	require.NoError(t, err)
+}
+
+func TestRejectRefund(t *testing.T) {
+	tests := []struct {
+		name string
+		mock func(refundRepo *mocks.MockRefund, operationRepo *mocks.MockOperation)
+		want error
+	}{
+		{
+			name: "unknown refund",
+			mock: func(refundRepo *mocks.MockRefund, _ *mocks.MockOperation) {
+				refundRepo.EXPECT().GetRefundByID(gomock.Any(), 3).Return(nil, repoerrors.ErrNotFound)
+			},
+			want: apperrors.ErrRefundNotFound,
+		},
+		{
+			name: "already decided",
+			mock: func(refundRepo *mocks.MockRefund, _ *mocks.MockOperation) {
+				refundRepo.EXPECT().GetRefundByID(gomock.Any(), 3).
+					Return(&entity.Refund{ID: 3, CustomerAccountID: 5, Amount: 20, Status: "approved"}, nil)
+				refundRepo.EXPECT().DecideRefund(gomock.Any(), 3, "rejected", 1).Return(repoerrors.ErrNotFound)
+			},
+			want: apperrors.ErrRefundAlreadyDecided,
+		},
+		{
+			name: "rejected without deposit",
+			mock: func(refundRepo *mocks.MockRefund, operationRepo *mocks.MockOperation) {
+				refundRepo.EXPECT().GetRefundByID(gomock.Any(), 3).
+					Return(&entity.Refund{ID: 3, CustomerAccountID: 5, Amount: 20, Status: "requested"}, nil)
+				refundRepo.EXPECT().DecideRefund(gomock.Any(), 3, "rejected", 1).Return(nil)
+				operationRepo.EXPECT().ExecRefundOperation(gomock.Any(), entity.RefundOperation{
+					RefundID: 3, CustomerAccountID: 5, Status: "rejected",
+				}).Return(nil)
+				refundRepo.EXPECT().GetRefundByID(gomock.Any(), 3).
+					Return(&entity.Refund{ID: 3, CustomerAccountID: 5, Amount: 20, Status: "rejected"}, nil)
+			},
+		},
+	}
+
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			accountRepo, operationRepo := mocks.NewMockAccount(ctrl), mocks.NewMockOperation(ctrl)
+			refundRepo, txManager := mocks.NewMockRefund(ctrl), mocks.NewMockTxManager(ctrl)
+			testCase.mock(refundRepo, operationRepo)
+			txMock(txManager)
+
+			uc := NewRefundUsecase(accountRepo, operationRepo, nil, refundRepo, txManager, Config{Window: time.Hour})
+			_, err := uc.RejectRefund(context.Background(), model.Claims{UserID: 1}, 3)
+
+			require.ErrorIs(t, err, testCase.want)
+		})
+	}
+}
+
+func TestGetRefundsByStatus_Invalid(t *testing.T) {
+	uc := NewRefundUsecase(nil, nil, nil, nil, nil, Config{})
+	_, err := uc.GetRefundsByStatus(context.Background(), "pending")
+
+	require.ErrorIs(t, err, apperrors.ErrInvalidRefundStatus)
+}
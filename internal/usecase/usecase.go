@@ -2,48 +2,137 @@ package usecase
 
 import (
 	"context"
-	"time"
 
 	"github.com/resueman/merch-store/internal/model"
 	"github.com/resueman/merch-store/internal/repo"
 	"github.com/resueman/merch-store/internal/usecase/account"
 	"github.com/resueman/merch-store/internal/usecase/auth"
+	"github.com/resueman/merch-store/internal/usecase/idempotency"
 	"github.com/resueman/merch-store/internal/usecase/operation"
+	"github.com/resueman/merch-store/internal/usecase/product"
+	"github.com/resueman/merch-store/internal/usecase/refund"
 	"github.com/resueman/merch-store/pkg/db"
 )
 
 type Auth interface {
-	GenerateToken(ctx context.Context, input model.AuthRequestInput) (string, error)
+	GenerateToken(ctx context.Context, input model.AuthRequestInput) (model.AuthTokens, error)
+	Register(ctx context.Context, input model.AuthRequestInput) (model.AuthTokens, error)
+	SetUserRole(ctx context.Context, claims model.Claims, input model.SetUserRoleInput) error
+	DeactivateUser(ctx context.Context, claims model.Claims, input model.DeactivateUserInput) (int, error)
+	ReactivateUser(ctx context.Context, username string) error
+	BootstrapAdmin(ctx context.Context, username string) error
+	UnlockUser(ctx context.Context, username string) error
+	ChangePassword(ctx context.Context, claims model.Claims, input model.ChangePasswordInput) (model.AuthTokens, error)
+	CreatePasswordReset(ctx context.Context, claims model.Claims, username string) (model.PasswordResetToken, error)
+	ResetPassword(ctx context.Context, input model.ResetPasswordInput) error
+	CreateServiceAccount(ctx context.Context, username string) error
+	CreateAPIKey(ctx context.Context, claims model.Claims, input model.CreateAPIKeyInput) (model.APIKey, error)
+	GetAPIKeys(ctx context.Context, username string) ([]model.APIKey, error)
+	RevokeAPIKey(ctx context.Context, keyID int) error
+	AuthenticateAPIKey(ctx context.Context, key string) (model.Claims, error)
+	EnrollTOTP(ctx context.Context, claims model.Claims) (model.TOTPEnrollment, error)
+	ConfirmTOTP(ctx context.Context, claims model.Claims, input model.ConfirmTOTPInput) ([]string, error)
+	DisableTOTP(ctx context.Context, claims model.Claims, code string) error
+	SetTOTPThreshold(ctx context.Context, claims model.Claims, input model.SetTOTPThresholdInput) error
+	VerifyOTP(ctx context.Context, userID int, amount int, code string) error
+	RecordOTPFailure(ctx context.Context, userID int) error
+	RefreshTokens(ctx context.Context, refreshToken string) (model.AuthTokens, error)
+	PublicKeys() []model.PublicKey
 	ParseToken(ctx context.Context, tokenString string) (model.Claims, error)
+	Logout(ctx context.Context, claims model.Claims, refreshToken string) error
+	LogoutAll(ctx context.Context, claims model.Claims) error
 }
 
 type Account interface {
 	GetInfo(ctx context.Context, claims model.Claims) (*model.AccountInfo, error)
+	GetHistory(ctx context.Context, claims model.Claims, filter model.HistoryFilter) (model.HistoryPage, error)
 }
 
 type Operation interface {
-	BuyItem(ctx context.Context, claims model.Claims, itemID string) error
-	SendCoin(ctx context.Context, claims model.Claims, receiverUsername string, amount int) error
+	BuyItem(ctx context.Context, claims model.Claims, itemID string, variant string, quantity int,
+		otpCode string) error
+	SendCoin(ctx context.Context, claims model.Claims, receiverUsername string, amount int, otpCode string) error
+	GetOperation(ctx context.Context, claims model.Claims, operationID int) (model.Operation, error)
+}
+
+type Cart interface {
+	AddToCart(ctx context.Context, claims model.Claims, input model.CartItemInput) error
+	RemoveFromCart(ctx context.Context, claims model.Claims, itemName string, variant string) error
+	GetCart(ctx context.Context, claims model.Claims) (model.Cart, error)
+	Checkout(ctx context.Context, claims model.Claims, otpCode string) (model.Cart, error)
+}
+
+type Product interface {
+	GetProducts(ctx context.Context, filter model.ProductFilter) (model.ProductPage, error)
+	GetProduct(ctx context.Context, name string) (model.Product, error)
+	CreateProduct(ctx context.Context, claims model.Claims, input model.CreateProductInput) (model.Product, error)
+	UpdateProduct(ctx context.Context, claims model.Claims, name string, input model.UpdateProductInput) (
+		model.Product, error)
+	RetireProduct(ctx context.Context, claims model.Claims, name string) error
+	GetProductAudit(ctx context.Context, name string) ([]model.ProductAuditEntry, error)
+	CreateProductVariant(ctx context.Context, claims model.Claims, itemName string,
+		input model.CreateProductVariantInput) (model.ProductVariant, error)
+	Restock(ctx context.Context, claims model.Claims, itemName string, variant string, quantity int) (
+		model.Product, error)
+	GetLowStockProducts(ctx context.Context) ([]model.Product, error)
+}
+
+type Refund interface {
+	RequestRefund(ctx context.Context, claims model.Claims, input model.RefundInput) (model.Refund, error)
+	GetRefunds(ctx context.Context, claims model.Claims) ([]model.Refund, error)
+	GetRefundablePurchases(ctx context.Context, claims model.Claims) ([]model.RefundablePurchase, error)
+	GetRefundsByStatus(ctx context.Context, status model.RefundStatus) ([]model.Refund, error)
+	ApproveRefund(ctx context.Context, claims model.Claims, refundID int) (model.Refund, error)
+	RejectRefund(ctx context.Context, claims model.Claims, refundID int) (model.Refund, error)
+}
+
+type Idempotency interface {
+	Execute(
+		ctx context.Context,
+		claims model.Claims,
+		request model.IdempotentRequest,
+		handle func(ctx context.Context) (model.IdempotentResponse, error),
+	) (model.IdempotentResponse, error)
 }
 
 type Usecase struct {
 	Auth
 	Account
 	Operation
+	Cart
+	Product
+	Refund
+	Idempotency
 	db.TxManager
 }
 
 type PasswordManager interface {
-	HashPassword(password string) string
+	HashPassword(password string) (string, error)
 	ComparePassword(password, hash string) bool
+	NeedsRehash(hash string) bool
 }
 
-func NewUsecase(repo *repo.Repositories, txManager db.TxManager,
-	passwordManager PasswordManager, secretKey string, tokenTTL time.Duration) *Usecase {
+func NewUsecase(repo *repo.Repositories, txManager db.TxManager, passwordManager PasswordManager,
+	authConfig auth.Config, operationConfig operation.Config, productConfig product.Config,
+	refundConfig refund.Config, idempotencyConfig idempotency.Config) *Usecase {
+	accountUsecase := account.NewAccountUsecase(repo.Account, repo.Operation, repo.Product, txManager)
+	authUsecase := auth.NewAuthUsecase(repo.User, repo.RefreshToken, repo.Denylist, repo.LoginAttempt,
+		repo.PasswordReset, repo.APIKey, repo.TOTP, accountUsecase, passwordManager, txManager, authConfig)
+	operationUsecase := operation.NewOperationUsecase(repo.Account, repo.Operation, repo.Product, repo.Cart,
+		authUsecase, txManager, operationConfig)
+	productUsecase := product.NewProductUsecase(repo.Product, txManager, productConfig)
+	refundUsecase := refund.NewRefundUsecase(repo.Account, repo.Operation, repo.Product, repo.Refund, txManager,
+		refundConfig)
+	idempotencyUsecase := idempotency.NewIdempotencyUsecase(repo.Idempotency, txManager, idempotencyConfig)
+
 	return &Usecase{
-		Auth:      auth.NewAuthUsecase(repo.User, passwordManager, secretKey, tokenTTL),
-		Account:   account.NewAccountUsecase(repo.Account, repo.Operation, repo.Product, txManager),
-		Operation: operation.NewOperationUsecase(repo.Account, repo.Operation, repo.Product, txManager),
-		TxManager: txManager,
+		Auth:        authUsecase,
+		Account:     accountUsecase,
+		Operation:   operationUsecase,
+		Cart:        operationUsecase,
+		Product:     productUsecase,
+		Refund:      refundUsecase,
+		Idempotency: idempotencyUsecase,
+		TxManager:   txManager,
 	}
 }
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE revoked_tokens (
+    jti VARCHAR(64) PRIMARY KEY,
+    user_id INT NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    revoked_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX revoked_tokens_expires_at_idx ON revoked_tokens (expires_at);
+
+-- Версия токенов пользователя: "выход со всех устройств" увеличивает ее,
+-- и все access-токены с меньшей версией считаются отозванными.
+ALTER TABLE users
+    ADD COLUMN token_version INT NOT NULL DEFAULT 0,
+    ADD COLUMN tokens_revoked_at TIMESTAMPTZ;
+
+CREATE INDEX users_tokens_revoked_at_idx ON users (tokens_revoked_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS users_tokens_revoked_at_idx;
+ALTER TABLE users
+    DROP COLUMN IF EXISTS tokens_revoked_at,
+    DROP COLUMN IF EXISTS token_version;
+DROP TABLE IF EXISTS revoked_tokens CASCADE;
+-- +goose StatementEnd
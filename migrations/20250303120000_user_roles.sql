@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE user_role AS ENUM ('user', 'admin');
+
+ALTER TABLE users
+    ADD COLUMN role user_role NOT NULL DEFAULT 'user';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users
+    DROP COLUMN IF EXISTS role;
+DROP TYPE IF EXISTS user_role;
+-- +goose StatementEnd
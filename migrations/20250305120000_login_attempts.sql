@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Счетчики неудачных попыток входа по имени пользователя и по IP-адресу клиента.
+CREATE TABLE login_attempts (
+    key_type VARCHAR(16) NOT NULL,
+    key VARCHAR(255) NOT NULL,
+    failures INT NOT NULL DEFAULT 0,
+    last_failure_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    locked_until TIMESTAMPTZ,
+    PRIMARY KEY (key_type, key)
+);
+
+CREATE INDEX login_attempts_last_failure_at_idx ON login_attempts (last_failure_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS login_attempts CASCADE;
+-- +goose StatementEnd
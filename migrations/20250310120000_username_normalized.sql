@@ -0,0 +1,55 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Преобразование повторяет pkg/username.Normalize: обрезка пробелов, NFKC, нижний регистр, снова NFKC.
+-- btrim получает тот же набор пробельных символов, что и unicode.IsSpace в strings.TrimSpace, а lower
+-- с правилом сортировки pg_c_utf8 переводит в нижний регистр по тем же простым правилам Unicode,
+-- что и strings.ToLower, независимо от локали базы. Совпадение с Go проверяет интеграционный тест
+-- TestUsernameNormalizationMatchesMigration, он читает выражение прямо из этой миграции.
+ALTER TABLE users
+    ADD COLUMN username_normalized VARCHAR(255);
+
+UPDATE users
+SET username_normalized = normalize(
+    lower(
+        normalize(
+            btrim(username, U&' \0009\000A\000B\000C\000D\0085\00A0\1680\2000\2001\2002\2003\2004\2005\2006\2007\2008\2009\200A\2028\2029\202F\205F\3000'),
+            NFKC
+        ) COLLATE pg_c_utf8
+    ),
+    NFKC
+);
+
+-- Имена, отличающиеся только регистром или формой записи, нельзя объединить автоматически:
+-- это разные люди со своими балансами. Миграция останавливается и перечисляет их,
+-- чтобы администратор переименовал лишние аккаунты вручную.
+DO $$
+DECLARE
+    collisions TEXT;
+BEGIN
+    SELECT string_agg(names, '; ')
+    INTO collisions
+    FROM (
+        SELECT string_agg(username, ', ' ORDER BY id) AS names
+        FROM users
+        GROUP BY username_normalized
+        HAVING COUNT(*) > 1
+    ) c;
+
+    IF collisions IS NOT NULL THEN
+        RAISE EXCEPTION 'usernames collide after normalization, rename them and retry: %', collisions;
+    END IF;
+END $$;
+
+ALTER TABLE users
+    ALTER COLUMN username_normalized SET NOT NULL;
+
+CREATE UNIQUE INDEX users_username_normalized_idx ON users (username_normalized);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS users_username_normalized_idx;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS username_normalized;
+-- +goose StatementEnd
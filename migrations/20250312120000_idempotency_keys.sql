@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Результаты запросов с заголовком Idempotency-Key. Ключ сохраняется в той же транзакции, что и операция,
+-- поэтому запись есть только у успешно выполненных запросов. fingerprint - хеш метода, пути и тела запроса:
+-- повтор ключа с другим запросом отклоняется. После expires_at ключ можно использовать снова.
+CREATE TABLE idempotency_keys (
+    user_id INT NOT NULL,
+    idempotency_key VARCHAR(255) NOT NULL,
+    fingerprint VARCHAR(64) NOT NULL,
+    status_code INT NOT NULL DEFAULT 0,
+    content_type VARCHAR(255) NOT NULL DEFAULT '',
+    response_body BYTEA NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (user_id, idempotency_key),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS idempotency_keys CASCADE;
+-- +goose StatementEnd
@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Остаток товара на складе. Уменьшается в транзакции покупки, поэтому не может стать отрицательным.
+-- Новый товар появляется без остатка: склад пополняет администратор.
+ALTER TABLE products
+    ADD COLUMN stock INT NOT NULL DEFAULT 0 CHECK (stock >= 0);
+
+-- Журнал пополнений склада администраторами.
+CREATE TABLE product_restocks (
+    id SERIAL PRIMARY KEY,
+    product_id INT NOT NULL,
+    quantity INT NOT NULL CHECK (quantity > 0),
+    restocked_by INT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE,
+    FOREIGN KEY (restocked_by) REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_product_restocks_product_id ON product_restocks(product_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS product_restocks CASCADE;
+
+ALTER TABLE products
+    DROP COLUMN IF EXISTS stock;
+-- +goose StatementEnd
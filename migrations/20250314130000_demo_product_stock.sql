@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Миграция данных, а не схемы: начальный остаток демонстрационного каталога из 20250213220716_default_tables.sql,
+-- чтобы его товары можно было купить сразу после развертывания. Количества условные; настоящий остаток
+-- задается через POST /api/admin/products/{item}/restock. Задается только отсутствующий остаток.
+UPDATE products
+SET stock = CASE WHEN name = 'pink-hoody' THEN 20 ELSE 100 END
+WHERE stock = 0
+  AND name IN ('t-shirt', 'cup', 'book', 'pen', 'powerbank', 'hoody', 'umbrella', 'socks', 'wallet', 'pink-hoody');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+UPDATE products
+SET stock = 0
+WHERE name IN ('t-shirt', 'cup', 'book', 'pen', 'powerbank', 'hoody', 'umbrella', 'socks', 'wallet', 'pink-hoody');
+-- +goose StatementEnd
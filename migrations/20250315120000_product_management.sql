@@ -0,0 +1,37 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Снятый с продажи товар не удаляется, чтобы не потерять историю покупок,
+-- а только скрывается из каталога и перестает продаваться.
+ALTER TABLE products
+    ADD COLUMN description TEXT NOT NULL DEFAULT '',
+    ADD COLUMN retired_at TIMESTAMPTZ;
+
+CREATE TYPE product_audit_action AS ENUM ('create', 'update', 'retire');
+
+-- Журнал изменений каталога: по строке на каждое измененное поле.
+-- При снятии с продажи field, old_value и new_value пустые.
+CREATE TABLE product_audit (
+    id SERIAL PRIMARY KEY,
+    product_id INT NOT NULL,
+    changed_by INT,
+    action product_audit_action NOT NULL,
+    field VARCHAR(32),
+    old_value TEXT,
+    new_value TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE,
+    FOREIGN KEY (changed_by) REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_product_audit_product_id ON product_audit(product_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS product_audit CASCADE;
+DROP TYPE IF EXISTS product_audit_action;
+
+ALTER TABLE products
+    DROP COLUMN IF EXISTS retired_at,
+    DROP COLUMN IF EXISTS description;
+-- +goose StatementEnd
@@ -26,6 +26,7 @@ type TxManager interface {
 	ReadCommitted(ctx context.Context, mode Mode, f func(ctx context.Context) error) func() error
 	RepeatableRead(ctx context.Context, mode Mode, f func(ctx context.Context) error) func() error
 	Serializable(ctx context.Context, mode Mode, f func(ctx context.Context) error) func() error
+	Snapshot(ctx context.Context, f func(ctx context.Context) error) func() error
 }
 
 // DB.
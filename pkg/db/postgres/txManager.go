@@ -49,6 +49,19 @@ func (m *TxManager) Serializable(ctx context.Context, mode db.Mode, f func(ctx c
 	}
 }
 
+// Читающая транзакция, все запросы которой видят один и тот же снимок БД: изменения каждой
+// зафиксированной транзакции видны либо целиком, либо не видны вовсе. В Postgres это дает
+// REPEATABLE READ, а READ ONLY транзакция на этом уровне не получает ошибок сериализации.
+// SERIALIZABLE READ ONLY DEFERRABLE не используется: он может ждать безопасного снимка
+// и не поддерживается на репликах в режиме hot standby.
+func (m *TxManager) Snapshot(ctx context.Context, f func(ctx context.Context) error) func() error {
+	txOpts := pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly}
+
+	return func() error {
+		return m.transaction(ctx, txOpts, db.Read, f)
+	}
+}
+
 func (m *TxManager) WithRetry(f func() error) error {
 	var err error
 	for range m.maxRetries {
@@ -76,7 +89,7 @@ func (m *TxManager) transaction(
 ) (err error) {
 	tx, ok := ctx.Value(TxKey).(pgx.Tx)
 	if ok {
-		return f(ctx)
+		return m.savepoint(ctx, tx, f)
 	}
 
 	var database db.DB
@@ -118,3 +131,36 @@ func (m *TxManager) transaction(
 
 	return err
 }
+
+// Вложенная транзакция выполняется в точке сохранения: при ошибке откатывается только ее работа,
+// а внешняя транзакция может продолжиться, например чтобы сохранить запись о неудачной попытке.
+func (m *TxManager) savepoint(ctx context.Context, tx pgx.Tx, f func(ctx context.Context) error) (err error) {
+	nested, err := tx.Begin(ctx)
+	if err != nil {
+		return errors.Wrap(err, "error creating savepoint")
+	}
+
+	database, _ := ctx.Value(db.DBKey).(db.DB)
+	ctx = ContextWithTx(ctx, nested, database)
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("recover from panic: %v", r)
+		}
+
+		if err != nil {
+			if errRollback := nested.Rollback(ctx); errRollback != nil {
+				err = fmt.Errorf("rollback to savepoint error: %w", errRollback)
+			}
+
+			return
+		}
+
+		err = nested.Commit(ctx)
+		if err != nil {
+			err = fmt.Errorf("error releasing savepoint: %w", err)
+		}
+	}()
+
+	return f(ctx)
+}
@@ -0,0 +1,110 @@
+package jwtkeys
+
+import (
+	"crypto"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+var (
+	ErrUnknownKey        = errors.New("unknown signing key")
+	ErrMethodMismatch    = errors.New("token signing method does not match key")
+	ErrNoSigningKey      = errors.New("signing key is not configured")
+	ErrUnsupportedMethod = errors.New("unsupported signing method")
+)
+
+// Key описывает один ключ набора. Ключ без PrivateKey используется только для проверки подписи:
+// так после ротации старый ключ продолжает принимать выданные им токены, пока они не истекут.
+type Key struct {
+	ID         string
+	Method     jwt.SigningMethod
+	PrivateKey crypto.PrivateKey
+	PublicKey  crypto.PublicKey
+}
+
+type PublicKey struct {
+	ID        string
+	Algorithm string
+	Key       crypto.PublicKey
+}
+
+// KeySet подписывает токены одним активным ключом и проверяет их любым ключом набора по заголовку kid.
+type KeySet struct {
+	signing *Key
+	keys    map[string]*Key
+}
+
+func NewKeySet(signingKeyID string, keys ...Key) (*KeySet, error) {
+	set := &KeySet{keys: make(map[string]*Key, len(keys))}
+	for i := range keys {
+		key := keys[i]
+		if _, ok := set.keys[key.ID]; ok {
+			return nil, fmt.Errorf("duplicate key id %q", key.ID)
+		}
+
+		set.keys[key.ID] = &key
+	}
+
+	signing, ok := set.keys[signingKeyID]
+	if !ok || signing.PrivateKey == nil {
+		return nil, fmt.Errorf("%w: %q", ErrNoSigningKey, signingKeyID)
+	}
+
+	set.signing = signing
+
+	return set, nil
+}
+
+// Набор из одного симметричного ключа HS256. Секрет нельзя публиковать,
+// поэтому такие токены могут проверять только сервисы, которым он известен.
+func NewHMACKeySet(secret string) *KeySet {
+	key := &Key{Method: jwt.SigningMethodHS256, PrivateKey: []byte(secret), PublicKey: []byte(secret)}
+
+	return &KeySet{signing: key, keys: map[string]*Key{"": key}}
+}
+
+func (s *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(s.signing.Method, claims)
+	if s.signing.ID != "" {
+		token.Header["kid"] = s.signing.ID
+	}
+
+	return token.SignedString(s.signing.PrivateKey)
+}
+
+// Keyfunc выбирает ключ проверки по kid. Алгоритм токена обязан совпадать с алгоритмом ключа,
+// иначе открытый RSA-ключ можно было бы использовать как секрет HS256.
+func (s *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	key, ok := s.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, kid)
+	}
+
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, ErrMethodMismatch
+	}
+
+	return key.PublicKey, nil
+}
+
+// Открытые ключи для публикации в JWKS. Симметричные ключи не публикуются.
+func (s *KeySet) PublicKeys() []PublicKey {
+	keys := make([]PublicKey, 0, len(s.keys))
+	for _, key := range s.keys {
+		if _, ok := key.Method.(*jwt.SigningMethodHMAC); ok {
+			continue
+		}
+
+		keys = append(keys, PublicKey{ID: key.ID, Algorithm: key.Method.Alg(), Key: key.PublicKey})
+	}
+
+	slices.SortFunc(keys, func(a, b PublicKey) int { return strings.Compare(a.ID, b.ID) })
+
+	return keys
+}
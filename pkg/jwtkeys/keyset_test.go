@@ -0,0 +1,138 @@
+package jwtkeys
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func newEdKey(t *testing.T, id string) Key {
+	t.Helper()
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	return Key{ID: id, Method: jwt.SigningMethodEdDSA, PrivateKey: private, PublicKey: public}
+}
+
+func newRSAKey(t *testing.T, id string) Key {
+	t.Helper()
+	private, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	return Key{ID: id, Method: jwt.SigningMethodRS256, PrivateKey: private, PublicKey: &private.PublicKey}
+}
+
+func parse(t *testing.T, set *KeySet, token string) error {
+	t.Helper()
+	_, err := jwt.ParseWithClaims(token, &jwt.RegisteredClaims{}, set.Keyfunc)
+
+	return err
+}
+
+func TestKeySetRotation(t *testing.T) {
+	t.Parallel()
+	oldKey, newKey := newRSAKey(t, "old"), newEdKey(t, "new")
+
+	oldSet, err := NewKeySet("old", oldKey)
+	require.NoError(t, err)
+
+	oldToken, err := oldSet.Sign(jwt.RegisteredClaims{Subject: "1"})
+	require.NoError(t, err)
+
+	// После ротации старый ключ остается только для проверки подписи.
+	oldKey.PrivateKey = nil
+	rotated, err := NewKeySet("new", newKey, oldKey)
+	require.NoError(t, err)
+
+	newToken, err := rotated.Sign(jwt.RegisteredClaims{Subject: "1"})
+	require.NoError(t, err)
+
+	require.NoError(t, parse(t, rotated, oldToken))
+	require.NoError(t, parse(t, rotated, newToken))
+	require.Error(t, parse(t, oldSet, newToken))
+
+	keys := rotated.PublicKeys()
+	require.Len(t, keys, 2)
+	require.Equal(t, "new", keys[0].ID)
+	require.Equal(t, "EdDSA", keys[0].Algorithm)
+	require.Equal(t, "old", keys[1].ID)
+	require.Equal(t, "RS256", keys[1].Algorithm)
+}
+
+func TestKeySetRejectsAlgorithmMismatch(t *testing.T) {
+	t.Parallel()
+	key := newRSAKey(t, "rsa")
+	set, err := NewKeySet("rsa", key)
+	require.NoError(t, err)
+
+	// Токен, подписанный открытым ключом как секретом HS256, не должен проходить проверку.
+	publicDER, err := x509.MarshalPKIXPublicKey(key.PublicKey)
+	require.NoError(t, err)
+
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{Subject: "1"})
+	forged.Header["kid"] = "rsa"
+	forgedString, err := forged.SignedString(publicDER)
+	require.NoError(t, err)
+
+	require.ErrorIs(t, parse(t, set, forgedString), ErrMethodMismatch)
+}
+
+func TestNewKeySetRequiresPrivateSigningKey(t *testing.T) {
+	t.Parallel()
+	key := newEdKey(t, "ed")
+	key.PrivateKey = nil
+
+	_, err := NewKeySet("ed", key)
+	require.ErrorIs(t, err, ErrNoSigningKey)
+}
+
+func TestHMACKeySetIsNotPublished(t *testing.T) {
+	t.Parallel()
+	set := NewHMACKeySet("secret")
+
+	token, err := set.Sign(jwt.RegisteredClaims{Subject: "1"})
+	require.NoError(t, err)
+	require.NoError(t, parse(t, set, token))
+	require.Empty(t, set.PublicKeys())
+}
+
+func TestLoadKeySet(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+
+	edKey := newEdKey(t, "ed")
+	privateDER, err := x509.MarshalPKCS8PrivateKey(edKey.PrivateKey)
+	require.NoError(t, err)
+
+	privateFile := filepath.Join(dir, "ed.pem")
+	require.NoError(t, os.WriteFile(privateFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER}), 0o600))
+
+	rsaKey := newRSAKey(t, "rsa")
+	publicDER, err := x509.MarshalPKIXPublicKey(rsaKey.PublicKey)
+	require.NoError(t, err)
+
+	publicFile := filepath.Join(dir, "rsa.pub.pem")
+	require.NoError(t, os.WriteFile(publicFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}), 0o600))
+
+	set, err := LoadKeySet("ed", []KeyFile{
+		{ID: "ed", Algorithm: "EdDSA", PrivateKeyFile: privateFile},
+		{ID: "rsa", Algorithm: "RS256", PublicKeyFile: publicFile},
+	})
+	require.NoError(t, err)
+	require.Len(t, set.PublicKeys(), 2)
+
+	token, err := set.Sign(jwt.RegisteredClaims{Subject: "1"})
+	require.NoError(t, err)
+	require.NoError(t, parse(t, set, token))
+
+	_, err = LoadKeySet("ed", []KeyFile{{ID: "ed", Algorithm: "HS512", PrivateKeyFile: privateFile}})
+	require.ErrorIs(t, err, ErrUnsupportedMethod)
+}
@@ -0,0 +1,103 @@
+package jwtkeys
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"fmt"
+	"os"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+type KeyFile struct {
+	ID             string
+	Algorithm      string
+	PrivateKeyFile string
+	PublicKeyFile  string
+}
+
+// LoadKeySet читает PEM-ключи из файлов. Для ключа подписи нужен закрытый ключ,
+// для ключей, оставленных только для проверки, достаточно открытого.
+func LoadKeySet(signingKeyID string, files []KeyFile) (*KeySet, error) {
+	keys := make([]Key, 0, len(files))
+	for _, f := range files {
+		key, err := loadKey(f)
+		if err != nil {
+			return nil, fmt.Errorf("error loading key %q: %w", f.ID, err)
+		}
+
+		keys = append(keys, key)
+	}
+
+	return NewKeySet(signingKeyID, keys...)
+}
+
+func loadKey(f KeyFile) (Key, error) {
+	key := Key{ID: f.ID}
+
+	switch f.Algorithm {
+	case jwt.SigningMethodRS256.Alg():
+		key.Method = jwt.SigningMethodRS256
+	case jwt.SigningMethodEdDSA.Alg():
+		key.Method = jwt.SigningMethodEdDSA
+	default:
+		return key, fmt.Errorf("%w: %q", ErrUnsupportedMethod, f.Algorithm)
+	}
+
+	if f.PrivateKeyFile != "" {
+		data, err := os.ReadFile(f.PrivateKeyFile)
+		if err != nil {
+			return key, err
+		}
+
+		if key.PrivateKey, key.PublicKey, err = parsePrivateKey(key.Method, data); err != nil {
+			return key, err
+		}
+
+		return key, nil
+	}
+
+	if f.PublicKeyFile == "" {
+		return key, fmt.Errorf("either private or public key file is required")
+	}
+
+	data, err := os.ReadFile(f.PublicKeyFile)
+	if err != nil {
+		return key, err
+	}
+
+	key.PublicKey, err = parsePublicKey(key.Method, data)
+
+	return key, err
+}
+
+func parsePrivateKey(method jwt.SigningMethod, data []byte) (crypto.PrivateKey, crypto.PublicKey, error) {
+	if method == jwt.SigningMethodRS256 {
+		private, err := jwt.ParseRSAPrivateKeyFromPEM(data)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return private, &private.PublicKey, nil
+	}
+
+	private, err := jwt.ParseEdPrivateKeyFromPEM(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	edPrivate, ok := private.(ed25519.PrivateKey)
+	if !ok {
+		return nil, nil, jwt.ErrNotEdPrivateKey
+	}
+
+	return edPrivate, edPrivate.Public(), nil
+}
+
+func parsePublicKey(method jwt.SigningMethod, data []byte) (crypto.PublicKey, error) {
+	if method == jwt.SigningMethodRS256 {
+		return jwt.ParseRSAPublicKeyFromPEM(data)
+	}
+
+	return jwt.ParseEdPublicKeyFromPEM(data)
+}
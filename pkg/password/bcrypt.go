@@ -0,0 +1,54 @@
+package password
+
+import (
+	"golang.org/x/crypto/bcrypt"
+)
+
+// BcryptHasher проверяет и создает bcrypt-хеши. Соль bcrypt хранит в самом хеше,
+// а pepper нужен для совместимости с хешами, созданными до перехода на per-user соли,
+// когда ко всем паролям дописывалась одна глобальная соль.
+type BcryptHasher struct {
+	cost   int
+	pepper string
+}
+
+func NewBcryptHasher(cost int, pepper string) *BcryptHasher {
+	if cost == 0 {
+		cost = bcrypt.DefaultCost
+	}
+
+	return &BcryptHasher{cost: cost, pepper: pepper}
+}
+
+func (h *BcryptHasher) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password+h.pepper), h.cost)
+	if err != nil {
+		return "", err
+	}
+
+	return string(hash), nil
+}
+
+func (h *BcryptHasher) Compare(password, hash string) bool {
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password+h.pepper))
+
+	return err == nil
+}
+
+func (h *BcryptHasher) Supports(hash string) bool {
+	switch algorithmID(hash) {
+	case "2a", "2b", "2y":
+		return true
+	default:
+		return false
+	}
+}
+
+func (h *BcryptHasher) Outdated(hash string) bool {
+	cost, err := bcrypt.Cost([]byte(hash))
+	if err != nil {
+		return true
+	}
+
+	return cost != h.cost
+}
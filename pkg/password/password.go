@@ -0,0 +1,81 @@
+package password
+
+import (
+	"errors"
+	"strings"
+)
+
+var ErrUnknownAlgorithm = errors.New("unknown password hashing algorithm")
+
+type PasswordManager interface {
+	HashPassword(password string) (string, error)
+	ComparePassword(password, hash string) bool
+	NeedsRehash(hash string) bool
+}
+
+// Hasher реализует один алгоритм хеширования. Алгоритм и его параметры
+// хранятся в самой строке хеша, поэтому по ней можно определить, каким хешером ее проверять.
+type Hasher interface {
+	Hash(password string) (string, error)
+	Compare(password, hash string) bool
+	// Создан ли хеш этим алгоритмом.
+	Supports(hash string) bool
+	// Хеш создан этим алгоритмом, но с параметрами, отличающимися от текущих.
+	Outdated(hash string) bool
+}
+
+// Manager хеширует новые пароли предпочтительным алгоритмом, но умеет проверять
+// хеши всех зарегистрированных алгоритмов. Это позволяет переходить с одного алгоритма
+// на другой без сброса паролей: старые хеши перехешируются при следующем входе пользователя.
+type Manager struct {
+	preferred Hasher
+	hashers   []Hasher
+}
+
+func NewPasswordManager(preferred Hasher, legacy ...Hasher) *Manager {
+	return &Manager{
+		preferred: preferred,
+		hashers:   append([]Hasher{preferred}, legacy...),
+	}
+}
+
+func (m *Manager) HashPassword(password string) (string, error) {
+	return m.preferred.Hash(password)
+}
+
+func (m *Manager) ComparePassword(password, hash string) bool {
+	hasher := m.hasherFor(hash)
+	if hasher == nil {
+		return false
+	}
+
+	return hasher.Compare(password, hash)
+}
+
+func (m *Manager) NeedsRehash(hash string) bool {
+	if !m.preferred.Supports(hash) {
+		return true
+	}
+
+	return m.preferred.Outdated(hash)
+}
+
+func (m *Manager) hasherFor(hash string) Hasher {
+	for _, h := range m.hashers {
+		if h.Supports(hash) {
+			return h
+		}
+	}
+
+	return nil
+}
+
+// Возвращает идентификатор алгоритма из строки хеша в формате $<id>$...
+func algorithmID(hash string) string {
+	parts := strings.SplitN(hash, "$", 3)
+	if len(parts) < 3 || parts[0] != "" {
+		return ""
+	}
+
+	return parts[1]
+}
@@ -0,0 +1,97 @@
+package password
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var testArgon2Params = Argon2Params{Memory: 1024, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+
+func TestArgon2HashAndCompare(t *testing.T) {
+	t.Parallel()
+	hasher := NewArgon2Hasher(testArgon2Params)
+
+	hash, err := hasher.Hash("password")
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=1$"))
+
+	require.True(t, hasher.Compare("password", hash))
+	require.False(t, hasher.Compare("wrong", hash))
+	require.False(t, hasher.Outdated(hash))
+
+	other, err := hasher.Hash("password")
+	require.NoError(t, err)
+	require.NotEqual(t, hash, other, "each hash must use its own salt")
+}
+
+func TestArgon2OutdatedParams(t *testing.T) {
+	t.Parallel()
+	hash, err := NewArgon2Hasher(testArgon2Params).Hash("password")
+	require.NoError(t, err)
+
+	stronger := testArgon2Params
+	stronger.Iterations = 2
+	hasher := NewArgon2Hasher(stronger)
+
+	require.True(t, hasher.Compare("password", hash))
+	require.True(t, hasher.Outdated(hash))
+}
+
+func TestManagerVerifiesLegacyBcryptAndRequestsRehash(t *testing.T) {
+	t.Parallel()
+	legacy := NewBcryptHasher(4, "pepper")
+	legacyHash, err := legacy.Hash("password")
+	require.NoError(t, err)
+
+	manager := NewPasswordManager(NewArgon2Hasher(testArgon2Params), legacy)
+
+	require.True(t, manager.ComparePassword("password", legacyHash))
+	require.False(t, manager.ComparePassword("wrong", legacyHash))
+	require.True(t, manager.NeedsRehash(legacyHash))
+
+	newHash, err := manager.HashPassword("password")
+	require.NoError(t, err)
+	require.True(t, manager.ComparePassword("password", newHash))
+	require.False(t, manager.NeedsRehash(newHash))
+}
+
+func TestManagerRejectsUnknownHash(t *testing.T) {
+	t.Parallel()
+	manager := NewPasswordManager(NewArgon2Hasher(testArgon2Params))
+
+	require.False(t, manager.ComparePassword("password", "plain"))
+	require.True(t, manager.NeedsRehash("plain"))
+}
+
+func TestPolicyValidate(t *testing.T) {
+	t.Parallel()
+	policy := Policy{MinLength: 8, MaxLength: 16, RequireMixedCase: true, RequireDigit: true, RequireSpecial: true}
+
+	tests := []struct {
+		name     string
+		password string
+		valid    bool
+	}{
+		{"valid", "Correct-h0rse", true},
+		{"too short", "Sh0rt!", false},
+		{"too long", "Much-t00-long-password", false},
+		{"contains username", "Alice-2024!", false},
+		{"no upper case", "correct-h0rse", false},
+		{"no digit", "Correct-horse", false},
+		{"no special", "CorrectH0rse", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := policy.Validate("alice", tt.password)
+			if tt.valid {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, ErrPolicyViolation)
+			}
+		})
+	}
+}
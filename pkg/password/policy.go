@@ -0,0 +1,65 @@
+package password
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var ErrPolicyViolation = errors.New("password does not meet the policy")
+
+// Policy задает требования к новым паролям. Нулевые значения отключают соответствующую проверку.
+type Policy struct {
+	MinLength        int
+	MaxLength        int
+	RequireMixedCase bool
+	RequireDigit     bool
+	RequireSpecial   bool
+}
+
+// Проверяет пароль и возвращает ErrPolicyViolation с описанием первого нарушенного требования.
+// Пароль не должен совпадать с именем пользователя или содержать его.
+func (p Policy) Validate(username, password string) error {
+	length := utf8.RuneCountInString(password)
+	if length < p.MinLength {
+		return fmt.Errorf("%w: must be at least %d characters long", ErrPolicyViolation, p.MinLength)
+	}
+
+	if p.MaxLength > 0 && length > p.MaxLength {
+		return fmt.Errorf("%w: must be at most %d characters long", ErrPolicyViolation, p.MaxLength)
+	}
+
+	if username != "" && strings.Contains(strings.ToLower(password), strings.ToLower(username)) {
+		return fmt.Errorf("%w: must not contain the username", ErrPolicyViolation)
+	}
+
+	var upper, lower, digit, special bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			special = true
+		}
+	}
+
+	if p.RequireMixedCase && (!upper || !lower) {
+		return fmt.Errorf("%w: must contain both upper and lower case letters", ErrPolicyViolation)
+	}
+
+	if p.RequireDigit && !digit {
+		return fmt.Errorf("%w: must contain a digit", ErrPolicyViolation)
+	}
+
+	if p.RequireSpecial && !special {
+		return fmt.Errorf("%w: must contain a special character", ErrPolicyViolation)
+	}
+
+	return nil
+}
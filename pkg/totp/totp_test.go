@@ -0,0 +1,68 @@
+package totp
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Секрет "12345678901234567890" из приложения B RFC 6238.
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCodeRFCVectors(t *testing.T) {
+	t.Parallel()
+
+	// Последние шесть цифр восьмизначных кодов из RFC 6238 для HMAC-SHA1.
+	vectors := map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	}
+
+	for unix, expected := range vectors {
+		code, err := Code(rfcSecret, Step(time.Unix(unix, 0)))
+		require.NoError(t, err)
+		require.Equal(t, expected, code, "time %d", unix)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	secret, err := GenerateSecret()
+	require.NoError(t, err)
+
+	now := time.Now()
+	code, err := Code(secret, Step(now.Add(-Period)))
+	require.NoError(t, err)
+
+	step, ok := Validate(secret, code, now, 1)
+	require.True(t, ok)
+	require.Equal(t, Step(now)-1, step)
+
+	_, ok = Validate(secret, code, now, 0)
+	require.False(t, ok, "previous step must be rejected without skew")
+
+	_, ok = Validate(secret, "12345", now, 1)
+	require.False(t, ok)
+
+	_, ok = Validate("not base32!", code, now, 1)
+	require.False(t, ok)
+}
+
+func TestURI(t *testing.T) {
+	t.Parallel()
+
+	parsed, err := url.Parse(URI("Merch Store", "alice", rfcSecret))
+	require.NoError(t, err)
+	require.Equal(t, "otpauth", parsed.Scheme)
+	require.Equal(t, "totp", parsed.Host)
+	require.Equal(t, "/Merch Store:alice", parsed.Path)
+	require.Equal(t, rfcSecret, parsed.Query().Get("secret"))
+	require.Equal(t, "Merch Store", parsed.Query().Get("issuer"))
+}
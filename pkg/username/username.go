@@ -0,0 +1,19 @@
+package username
+
+import (
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Приводит имя пользователя к виду, по которому имена сравниваются: "Alice", "alice"
+// и "ａｌｉｃｅ" (полноширинные символы) считаются одним именем. Совместимая нормализация
+// NFKC применяется до и после перевода в нижний регистр, так как смена регистра
+// может дать ненормализованную строку. Миграция users.username_normalized повторяет
+// это преобразование в SQL, поэтому менять его можно только вместе с пересчетом колонки;
+// совпадение проверяет интеграционный тест TestUsernameNormalizationMatchesMigration.
+func Normalize(name string) string {
+	name = norm.NFKC.String(strings.TrimSpace(name))
+
+	return norm.NFKC.String(strings.ToLower(name))
+}
@@ -0,0 +1,32 @@
+package username
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalize(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "case", input: "Alice", expected: "alice"},
+		{name: "surrounding spaces", input: "  bob ", expected: "bob"},
+		{name: "fullwidth", input: "ａｌｉｃｅ", expected: "alice"},
+		{name: "decomposed accent", input: "José", expected: "josé"},
+		{name: "cyrillic", input: "Иван", expected: "иван"},
+		{name: "ligature", input: "ﬁona", expected: "fiona"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tt.expected, Normalize(tt.input))
+		})
+	}
+}
@@ -78,3 +78,33 @@ func TestBuyItem(t *testing.T) {
 	// GetInfo: after not existing item -> expect same balance and inventory, no transfers
 	getUserInfo(t, token, http.StatusOK, &expected)
 }
+
+func TestBuyItem_Quantity(t *testing.T) {
+	defer cleanup()
+
+	setup()
+
+	token := authUser(t, "user", "password", http.StatusOK)
+
+	// BuyItem: 12 pairs of socks at once -> success, cost = 12 * cost(socks) = 120
+	buyItems(t, token, "socks", 12, http.StatusOK)
+
+	accountInfo := &model.AccountInfo{
+		Balance:           190 - 120,
+		Inventory:         []model.Inventory{{Name: "socks", Quantity: 12}},
+		IncomingTransfers: []model.IncomingTransfer{},
+		OutgoingTransfers: []model.OutgoingTransfer{},
+	}
+	expected := converter.ConvertAccountInfoToInfoResponse(accountInfo)
+
+	getUserInfo(t, token, http.StatusOK, &expected)
+
+	// BuyItem: 8 pens cost 80, but only 70 coins left -> error, nothing is withdrawn
+	buyItems(t, token, "pen", 8, http.StatusBadRequest)
+
+	// BuyItem: zero and too many units -> error
+	buyItems(t, token, "pen", 0, http.StatusBadRequest)
+	buyItems(t, token, "pen", 11, http.StatusBadRequest)
+
+	getUserInfo(t, token, http.StatusOK, &expected)
+}
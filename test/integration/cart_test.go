@@ -0,0 +1,112 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	v1 "github.com/resueman/merch-store/internal/api/v1"
+	"github.com/resueman/merch-store/internal/delivery/handlers/http/v1/converter"
+	"github.com/resueman/merch-store/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckout(t *testing.T) {
+	defer cleanup()
+
+	setup()
+
+	token := authUser(t, "user", "password", http.StatusOK)
+
+	// Checkout: empty cart -> error
+	checkout(t, token, http.StatusBadRequest)
+
+	// AddCartItem: 5 pens + 1 book = 100, then 2 more pens -> 7 pens + 1 book = 120
+	addCartItem(t, token, "pen", 5, http.StatusOK)
+	addCartItem(t, token, "book", 1, http.StatusOK)
+	addCartItem(t, token, "pen", 2, http.StatusOK)
+
+	// AddCartItem: not existing item and quantity over the limit -> error
+	addCartItem(t, token, "jujuju", 1, http.StatusBadRequest)
+	addCartItem(t, token, "pen", 4, http.StatusBadRequest)
+
+	// Checkout: everything is bought at once
+	recorder := checkout(t, token, http.StatusOK)
+
+	var cart v1.CartResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &cart); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, 120, cart.TotalPrice)
+
+	accountInfo := &model.AccountInfo{
+		Balance:           190 - 120,
+		Inventory:         []model.Inventory{{Name: "pen", Quantity: 7}, {Name: "book", Quantity: 1}},
+		IncomingTransfers: []model.IncomingTransfer{},
+		OutgoingTransfers: []model.OutgoingTransfer{},
+	}
+	expected := converter.ConvertAccountInfoToInfoResponse(accountInfo)
+
+	getUserInfo(t, token, http.StatusOK, &expected)
+
+	// Checkout: cart is emptied after a successful checkout
+	checkout(t, token, http.StatusBadRequest)
+
+	// Checkout: 80 coins for a t-shirt, only 70 left -> nothing is bought, cart is kept
+	addCartItem(t, token, "t-shirt", 1, http.StatusOK)
+	checkout(t, token, http.StatusBadRequest)
+
+	getUserInfo(t, token, http.StatusOK, &expected)
+}
+
+func TestCheckout_Variants(t *testing.T) {
+	defer cleanup()
+
+	setup()
+
+	token := authUser(t, "user", "password", http.StatusOK)
+	adminToken := authUser(t, "manager", "password", http.StatusOK)
+
+	// XL has its own price and a single unit in stock, M uses the t-shirt price and stock
+	price, stock := 90, 1
+	createProductVariant(t, adminToken, "t-shirt", v1.CreateProductVariantRequest{Name: "XL", Sku: "TSHIRT-XL",
+		Price: &price, Stock: &stock}, http.StatusOK)
+	createProductVariant(t, adminToken, "t-shirt", v1.CreateProductVariantRequest{Name: "M", Sku: "TSHIRT-M"},
+		http.StatusOK)
+
+	// AddCartItem: unknown variant -> error
+	addCartVariant(t, token, "t-shirt", "XXS", 1, http.StatusBadRequest)
+
+	// AddCartItem: variants of the same product are separate cart lines
+	addCartVariant(t, token, "t-shirt", "XL", 1, http.StatusOK)
+	addCartVariant(t, token, "t-shirt", "M", 1, http.StatusOK)
+
+	// Checkout: XL costs 90, M costs 80 like the t-shirt itself
+	recorder := checkout(t, token, http.StatusOK)
+
+	var cart v1.CartResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &cart); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, 170, cart.TotalPrice)
+	assert.Equal(t, map[string]int{"t-shirt (XL)": 1, "t-shirt (M)": 1}, getInventory(t, token))
+
+	// Checkout: the only XL is sold out -> the line is reported with its variant
+	addCartVariant(t, token, "t-shirt", "XL", 1, http.StatusOK)
+	recorder = checkout(t, token, http.StatusBadRequest)
+
+	var checkoutErr v1.CheckoutErrorResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &checkoutErr); err != nil {
+		t.Fatal(err)
+	}
+
+	if assert.NotNil(t, checkoutErr.Lines) && assert.Len(t, *checkoutErr.Lines, 1) {
+		line := (*checkoutErr.Lines)[0]
+		assert.Equal(t, "t-shirt", line.Item)
+		if assert.NotNil(t, line.Variant) {
+			assert.Equal(t, "XL", *line.Variant)
+		}
+	}
+}
@@ -6,7 +6,9 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"reflect"
+	"strconv"
 	"testing"
 	"time"
 
@@ -14,11 +16,20 @@ import (
 	"github.com/labstack/gommon/log"
 	v1 "github.com/resueman/merch-store/internal/api/v1"
 	"github.com/resueman/merch-store/internal/delivery/handlers/http/v1/account"
+	"github.com/resueman/merch-store/internal/delivery/handlers/http/v1/admin"
 	"github.com/resueman/merch-store/internal/delivery/handlers/http/v1/auth"
+	"github.com/resueman/merch-store/internal/delivery/handlers/http/v1/cart"
 	"github.com/resueman/merch-store/internal/delivery/handlers/http/v1/operation"
+	"github.com/resueman/merch-store/internal/delivery/handlers/http/v1/product"
+	"github.com/resueman/merch-store/internal/delivery/handlers/http/v1/refund"
 	"github.com/resueman/merch-store/internal/delivery/middleware"
 	"github.com/resueman/merch-store/internal/repo"
 	"github.com/resueman/merch-store/internal/usecase"
+	authUsecase "github.com/resueman/merch-store/internal/usecase/auth"
+	idempotencyUsecase "github.com/resueman/merch-store/internal/usecase/idempotency"
+	operationUsecase "github.com/resueman/merch-store/internal/usecase/operation"
+	productUsecase "github.com/resueman/merch-store/internal/usecase/product"
+	refundUsecase "github.com/resueman/merch-store/internal/usecase/refund"
 	"github.com/resueman/merch-store/pkg/db"
 	"github.com/resueman/merch-store/pkg/db/postgres"
 	"github.com/resueman/merch-store/pkg/password"
@@ -29,9 +40,14 @@ var (
 	router           *echo.Echo
 	authHandler      *auth.AuthHandler
 	operationHandler *operation.OperationHandler
+	cartHandler      *cart.CartHandler
 	accountHandler   *account.AccountHandler
+	refundHandler    *refund.RefundHandler
+	productHandler   *product.ProductHandler
+	adminHandler     *admin.AdminHandler
 	dbClient         db.Client
 	authMiddleware   *middleware.AuthMiddleware
+	idempotency      *middleware.IdempotencyMiddleware
 )
 
 func setup() {
@@ -42,24 +58,62 @@ func setup() {
 		log.Fatal(err)
 	}
 
+	// Тестовые миграции не задают остаток каталога, поэтому каждый тест начинает с одного и того же склада.
+	_, err = dbClient.Primary().Exec(context.Background(), db.Query{
+		QueryRaw: "UPDATE products SET stock = CASE WHEN name = 'pink-hoody' THEN 20 ELSE 100 END"})
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	txManager := postgres.NewTxManager(dbClient, time.Second*10, 3)
 	repositories := repo.NewRepositories(dbClient)
-	passwordManager := password.NewPasswordManager("1234567890")
-	tokenTTL := time.Minute * 15
-	usecases := usecase.NewUsecase(repositories, txManager, passwordManager, "secret", tokenTTL)
+	passwordManager := password.NewPasswordManager(password.NewArgon2Hasher(password.DefaultArgon2Params))
+	authConfig := authUsecase.Config{
+		SecretKey:            "secret",
+		AccessTokenTTL:       time.Minute * 15,
+		RefreshTokenTTL:      time.Hour,
+		DenylistSyncInterval: time.Second,
+		AutoRegister:         true,
+	}
+	operationConfig := operationUsecase.Config{MaxPurchaseQuantity: 10}
+	productConfig := productUsecase.Config{LowStockThreshold: 5}
+	refundConfig := refundUsecase.Config{Window: time.Hour}
+	idempotencyConfig := idempotencyUsecase.Config{KeyTTL: time.Hour}
+	usecases := usecase.NewUsecase(repositories, txManager, passwordManager, authConfig, operationConfig,
+		productConfig, refundConfig, idempotencyConfig)
 
 	router = echo.New()
 	authMiddleware = middleware.NewAuthMiddleware(usecases)
-	authHandler = auth.NewAuthHandler(router, usecases)
-	operationHandler = operation.NewOperationHandler(router, usecases)
+	idempotency = middleware.NewIdempotencyMiddleware(usecases)
+	authHandler = auth.NewAuthHandler(router, usecases, nil)
+	operationHandler = operation.NewOperationHandler(router, usecases, nil)
+	cartHandler = cart.NewCartHandler(router, usecases, nil)
 	accountHandler = account.NewAccountHandler(router, usecases)
+	refundHandler = refund.NewRefundHandler(router, usecases, nil)
+	productHandler = product.NewProductHandler(router, usecases)
+	adminHandler = admin.NewAdminHandler(router, usecases, usecases, usecases, nil)
 }
 
 func cleanup() {
+	dbClient.Primary().Exec(context.Background(), db.Query{QueryRaw: "DELETE FROM idempotency_keys"})
+	dbClient.Primary().Exec(context.Background(), db.Query{QueryRaw: "DELETE FROM cart_items"})
+	dbClient.Primary().Exec(context.Background(), db.Query{QueryRaw: "DELETE FROM refund_operations"})
+	dbClient.Primary().Exec(context.Background(), db.Query{QueryRaw: "DELETE FROM refunds"})
 	dbClient.Primary().Exec(context.Background(), db.Query{QueryRaw: "DELETE FROM purchase_operations"})
+	dbClient.Primary().Exec(context.Background(), db.Query{QueryRaw: "DELETE FROM product_restocks"})
+	dbClient.Primary().Exec(context.Background(), db.Query{QueryRaw: "DELETE FROM product_audit"})
+	dbClient.Primary().Exec(context.Background(), db.Query{QueryRaw: "DELETE FROM product_variants"})
+	// Товары, созданные тестами; первые 10 добавлены миграцией
+	dbClient.Primary().Exec(context.Background(), db.Query{QueryRaw: "DELETE FROM products WHERE id > 10"})
 	dbClient.Primary().Exec(context.Background(), db.Query{QueryRaw: "DELETE FROM transfer_operations"})
 	dbClient.Primary().Exec(context.Background(), db.Query{QueryRaw: "DELETE FROM operations"})
 	dbClient.Primary().Exec(context.Background(), db.Query{QueryRaw: "DELETE FROM accounts"})
+	dbClient.Primary().Exec(context.Background(), db.Query{QueryRaw: "DELETE FROM refresh_tokens"})
+	dbClient.Primary().Exec(context.Background(), db.Query{QueryRaw: "DELETE FROM revoked_tokens"})
+	dbClient.Primary().Exec(context.Background(), db.Query{QueryRaw: "DELETE FROM login_attempts"})
+	dbClient.Primary().Exec(context.Background(), db.Query{QueryRaw: "DELETE FROM password_reset_tokens"})
+	dbClient.Primary().Exec(context.Background(), db.Query{QueryRaw: "DELETE FROM totp_recovery_codes"})
+	dbClient.Primary().Exec(context.Background(), db.Query{QueryRaw: "DELETE FROM user_totp"})
 	dbClient.Primary().Exec(context.Background(), db.Query{QueryRaw: "DELETE FROM users"})
 
 	dbClient.Close()
@@ -98,7 +152,13 @@ func authUser(t *testing.T, username, password string, expectedStatus int) strin
 func buyItem(t *testing.T, token string, item string, expectedStatus int) {
 	t.Helper()
 
-	request := httptest.NewRequest(http.MethodPost, "/api/buy/"+item, nil)
+	buyItems(t, token, item, 1, expectedStatus)
+}
+
+func buyItems(t *testing.T, token string, item string, quantity int, expectedStatus int) {
+	t.Helper()
+
+	request := httptest.NewRequest(http.MethodPost, "/api/buy/"+item+"?quantity="+strconv.Itoa(quantity), nil)
 	request.Header.Set("Authorization", "Bearer "+token)
 	request.Header.Set("Content-Type", "application/json")
 
@@ -115,6 +175,25 @@ func buyItem(t *testing.T, token string, item string, expectedStatus int) {
 	}
 }
 
+func buyVariant(t *testing.T, token string, item string, variant string, expectedStatus int) {
+	t.Helper()
+
+	request := httptest.NewRequest(http.MethodPost, "/api/buy/"+item+"?variant="+url.QueryEscape(variant), nil)
+	request.Header.Set("Authorization", "Bearer "+token)
+
+	recorder := httptest.NewRecorder()
+
+	ctx := router.NewContext(request, recorder)
+	ctx.SetParamNames("item")
+	ctx.SetParamValues(item)
+
+	err := authMiddleware.AuthMiddleware(operationHandler.BuyItem)(ctx)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, expectedStatus, recorder.Code)
+	}
+}
+
 func sendCoin(t *testing.T, token string, toUser string, amount int, expectedStatus int) {
 	t.Helper()
 
@@ -142,6 +221,98 @@ func sendCoin(t *testing.T, token string, toUser string, amount int, expectedSta
 	}
 }
 
+func sendCoinIdempotent(t *testing.T, token, key, toUser string, amount int, expectedStatus int,
+) *httptest.ResponseRecorder {
+	t.Helper()
+
+	body, err := json.Marshal(v1.SendCoinRequest{ToUser: toUser, Amount: amount})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	request := httptest.NewRequest(http.MethodPost, "/api/sendCoin", bytes.NewReader(body))
+	request.Header.Set("Authorization", "Bearer "+token)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(middleware.IdempotencyKeyHeader, key)
+
+	recorder := httptest.NewRecorder()
+
+	ctx := router.NewContext(request, recorder)
+
+	err = authMiddleware.AuthMiddleware(idempotency.IdempotencyMiddleware(operationHandler.SendCoin))(ctx)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, expectedStatus, recorder.Code)
+	}
+
+	return recorder
+}
+
+func addCartItem(t *testing.T, token string, item string, quantity int, expectedStatus int) {
+	t.Helper()
+
+	body, err := json.Marshal(v1.AddCartItemRequest{Item: item, Quantity: &quantity})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	request := httptest.NewRequest(http.MethodPost, "/api/cart/items", bytes.NewReader(body))
+	request.Header.Set("Authorization", "Bearer "+token)
+	request.Header.Set("Content-Type", "application/json")
+
+	recorder := httptest.NewRecorder()
+
+	ctx := router.NewContext(request, recorder)
+
+	err = authMiddleware.AuthMiddleware(cartHandler.AddCartItem)(ctx)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, expectedStatus, recorder.Code)
+	}
+}
+
+func addCartVariant(t *testing.T, token string, item string, variant string, quantity int, expectedStatus int) {
+	t.Helper()
+
+	body, err := json.Marshal(v1.AddCartItemRequest{Item: item, Variant: &variant, Quantity: &quantity})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	request := httptest.NewRequest(http.MethodPost, "/api/cart/items", bytes.NewReader(body))
+	request.Header.Set("Authorization", "Bearer "+token)
+	request.Header.Set("Content-Type", "application/json")
+
+	recorder := httptest.NewRecorder()
+
+	ctx := router.NewContext(request, recorder)
+
+	err = authMiddleware.AuthMiddleware(cartHandler.AddCartItem)(ctx)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, expectedStatus, recorder.Code)
+	}
+}
+
+func checkout(t *testing.T, token string, expectedStatus int) *httptest.ResponseRecorder {
+	t.Helper()
+
+	request := httptest.NewRequest(http.MethodPost, "/api/checkout", nil)
+	request.Header.Set("Authorization", "Bearer "+token)
+
+	recorder := httptest.NewRecorder()
+
+	ctx := router.NewContext(request, recorder)
+
+	err := authMiddleware.AuthMiddleware(cartHandler.Checkout)(ctx)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, expectedStatus, recorder.Code)
+	}
+
+	return recorder
+}
+
 func getUserInfo(t *testing.T, token string, expectedStatus int, expected *v1.InfoResponse) {
 	t.Helper()
 
@@ -160,5 +331,329 @@ func getUserInfo(t *testing.T, token string, expectedStatus int, expected *v1.In
 	}
 
 	assert.Equal(t, expectedStatus, recorder.Code)
+
+	// Отметка снимка зависит от времени и id операций, поэтому проверяется только ее наличие.
+	assert.NotNil(t, actual.SnapshotAt)
+	assert.NotNil(t, actual.LastOperationId)
+	actual.SnapshotAt, actual.LastOperationId = expected.SnapshotAt, expected.LastOperationId
+
 	assert.True(t, reflect.DeepEqual(expected, actual))
 }
+
+func getInfo(t *testing.T, token string) v1.InfoResponse {
+	t.Helper()
+
+	request := httptest.NewRequest(http.MethodGet, "/api/account", nil)
+	request.Header.Set("Authorization", "Bearer "+token)
+	recorder := httptest.NewRecorder()
+
+	ctx := router.NewContext(request, recorder)
+
+	err := authMiddleware.AuthMiddleware(accountHandler.GetInfo)(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	info := v1.InfoResponse{}
+	if err := json.Unmarshal(recorder.Body.Bytes(), &info); err != nil {
+		t.Fatal(err)
+	}
+
+	return info
+}
+
+func getInventory(t *testing.T, token string) map[string]int {
+	t.Helper()
+
+	info := getInfo(t, token)
+
+	inventory := map[string]int{}
+	if info.Inventory != nil {
+		for _, item := range *info.Inventory {
+			inventory[*item.Type] = *item.Quantity
+		}
+	}
+
+	return inventory
+}
+
+func getHistory(t *testing.T, token string, query string, expectedStatus int) v1.HistoryResponse {
+	t.Helper()
+
+	request := httptest.NewRequest(http.MethodGet, "/api/history?"+query, nil)
+	request.Header.Set("Authorization", "Bearer "+token)
+
+	recorder := httptest.NewRecorder()
+
+	ctx := router.NewContext(request, recorder)
+
+	err := authMiddleware.AuthMiddleware(accountHandler.GetHistory)(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedStatus, recorder.Code)
+
+	var page v1.HistoryResponse
+	if recorder.Code == http.StatusOK {
+		if err := json.Unmarshal(recorder.Body.Bytes(), &page); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return page
+}
+
+func getOperation(t *testing.T, token string, operationID int, expectedStatus int) v1.Operation {
+	t.Helper()
+
+	id := strconv.Itoa(operationID)
+	request := httptest.NewRequest(http.MethodGet, "/api/operations/"+id, nil)
+	request.Header.Set("Authorization", "Bearer "+token)
+
+	recorder := httptest.NewRecorder()
+
+	ctx := router.NewContext(request, recorder)
+	ctx.SetParamNames("id")
+	ctx.SetParamValues(id)
+
+	err := authMiddleware.AuthMiddleware(operationHandler.GetOperation)(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedStatus, recorder.Code)
+
+	var operation v1.Operation
+	if recorder.Code == http.StatusOK {
+		if err := json.Unmarshal(recorder.Body.Bytes(), &operation); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return operation
+}
+
+func getRefundablePurchases(t *testing.T, token string, expectedStatus int) []v1.RefundablePurchase {
+	t.Helper()
+
+	request := httptest.NewRequest(http.MethodGet, "/api/purchases/refundable", nil)
+	request.Header.Set("Authorization", "Bearer "+token)
+
+	recorder := httptest.NewRecorder()
+
+	ctx := router.NewContext(request, recorder)
+
+	err := authMiddleware.AuthMiddleware(refundHandler.GetRefundablePurchases)(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedStatus, recorder.Code)
+
+	var purchases []v1.RefundablePurchase
+	if err := json.Unmarshal(recorder.Body.Bytes(), &purchases); err != nil {
+		t.Fatal(err)
+	}
+
+	return purchases
+}
+
+func requestRefund(t *testing.T, token string, purchaseID, quantity int, expectedStatus int) v1.Refund {
+	t.Helper()
+
+	body, err := json.Marshal(v1.RefundRequest{PurchaseId: purchaseID, Quantity: &quantity})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	request := httptest.NewRequest(http.MethodPost, "/api/refunds", bytes.NewReader(body))
+	request.Header.Set("Authorization", "Bearer "+token)
+	request.Header.Set("Content-Type", "application/json")
+
+	recorder := httptest.NewRecorder()
+
+	ctx := router.NewContext(request, recorder)
+
+	err = authMiddleware.AuthMiddleware(refundHandler.RequestRefund)(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedStatus, recorder.Code)
+
+	var refund v1.Refund
+	if recorder.Code == http.StatusOK {
+		if err := json.Unmarshal(recorder.Body.Bytes(), &refund); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return refund
+}
+
+func approveRefund(t *testing.T, token string, refundID int, expectedStatus int) {
+	t.Helper()
+
+	request := httptest.NewRequest(http.MethodPost, "/api/admin/refunds/"+strconv.Itoa(refundID)+"/approve", nil)
+	request.Header.Set("Authorization", "Bearer "+token)
+
+	recorder := httptest.NewRecorder()
+
+	ctx := router.NewContext(request, recorder)
+	ctx.SetParamNames("id")
+	ctx.SetParamValues(strconv.Itoa(refundID))
+
+	err := authMiddleware.AuthMiddleware(adminHandler.ApproveRefund)(ctx)
+
+	if assert.NoError(t, err) {
+		assert.Equal(t, expectedStatus, recorder.Code)
+	}
+}
+
+func getProducts(t *testing.T, token string, query string, expectedStatus int) v1.ProductListResponse {
+	t.Helper()
+
+	request := httptest.NewRequest(http.MethodGet, "/api/products?"+query, nil)
+	request.Header.Set("Authorization", "Bearer "+token)
+
+	recorder := httptest.NewRecorder()
+
+	ctx := router.NewContext(request, recorder)
+
+	err := authMiddleware.AuthMiddleware(productHandler.GetProducts)(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedStatus, recorder.Code)
+
+	var page v1.ProductListResponse
+	if recorder.Code == http.StatusOK {
+		if err := json.Unmarshal(recorder.Body.Bytes(), &page); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return page
+}
+
+func createProduct(t *testing.T, token string, input v1.CreateProductRequest, expectedStatus int) {
+	t.Helper()
+
+	body, err := json.Marshal(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	request := httptest.NewRequest(http.MethodPost, "/api/admin/products", bytes.NewReader(body))
+	request.Header.Set("Authorization", "Bearer "+token)
+	request.Header.Set("Content-Type", "application/json")
+
+	recorder := httptest.NewRecorder()
+
+	ctx := router.NewContext(request, recorder)
+
+	err = authMiddleware.AuthMiddleware(adminHandler.CreateProduct)(ctx)
+	if assert.NoError(t, err) {
+		assert.Equal(t, expectedStatus, recorder.Code)
+	}
+}
+
+func updateProduct(t *testing.T, token string, item string, input v1.UpdateProductRequest, expectedStatus int) {
+	t.Helper()
+
+	body, err := json.Marshal(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	request := httptest.NewRequest(http.MethodPatch, "/api/admin/products/"+item, bytes.NewReader(body))
+	request.Header.Set("Authorization", "Bearer "+token)
+	request.Header.Set("Content-Type", "application/json")
+
+	recorder := httptest.NewRecorder()
+
+	ctx := router.NewContext(request, recorder)
+	ctx.SetParamNames("item")
+	ctx.SetParamValues(item)
+
+	err = authMiddleware.AuthMiddleware(adminHandler.UpdateProduct)(ctx)
+	if assert.NoError(t, err) {
+		assert.Equal(t, expectedStatus, recorder.Code)
+	}
+}
+
+func retireProduct(t *testing.T, token string, item string, expectedStatus int) {
+	t.Helper()
+
+	request := httptest.NewRequest(http.MethodDelete, "/api/admin/products/"+item, nil)
+	request.Header.Set("Authorization", "Bearer "+token)
+
+	recorder := httptest.NewRecorder()
+
+	ctx := router.NewContext(request, recorder)
+	ctx.SetParamNames("item")
+	ctx.SetParamValues(item)
+
+	err := authMiddleware.AuthMiddleware(adminHandler.RetireProduct)(ctx)
+	if assert.NoError(t, err) {
+		assert.Equal(t, expectedStatus, recorder.Code)
+	}
+}
+
+func getProductAudit(t *testing.T, token string, item string) []v1.ProductAuditEntry {
+	t.Helper()
+
+	request := httptest.NewRequest(http.MethodGet, "/api/admin/products/"+item+"/audit", nil)
+	request.Header.Set("Authorization", "Bearer "+token)
+
+	recorder := httptest.NewRecorder()
+
+	ctx := router.NewContext(request, recorder)
+	ctx.SetParamNames("item")
+	ctx.SetParamValues(item)
+
+	err := authMiddleware.AuthMiddleware(adminHandler.GetProductAudit)(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	var entries []v1.ProductAuditEntry
+	if err := json.Unmarshal(recorder.Body.Bytes(), &entries); err != nil {
+		t.Fatal(err)
+	}
+
+	return entries
+}
+
+func createProductVariant(t *testing.T, token string, item string, input v1.CreateProductVariantRequest,
+	expectedStatus int,
+) {
+	t.Helper()
+
+	body, err := json.Marshal(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	request := httptest.NewRequest(http.MethodPost, "/api/admin/products/"+item+"/variants", bytes.NewReader(body))
+	request.Header.Set("Authorization", "Bearer "+token)
+	request.Header.Set("Content-Type", "application/json")
+
+	recorder := httptest.NewRecorder()
+
+	ctx := router.NewContext(request, recorder)
+	ctx.SetParamNames("item")
+	ctx.SetParamValues(item)
+
+	err = authMiddleware.AuthMiddleware(adminHandler.CreateProductVariant)(ctx)
+	if assert.NoError(t, err) {
+		assert.Equal(t, expectedStatus, recorder.Code)
+	}
+}
+
+func getLowStockProducts(t *testing.T, token string) []v1.Product {
+	t.Helper()
+
+	request := httptest.NewRequest(http.MethodGet, "/api/admin/products/low-stock", nil)
+	request.Header.Set("Authorization", "Bearer "+token)
+
+	recorder := httptest.NewRecorder()
+
+	ctx := router.NewContext(request, recorder)
+
+	err := authMiddleware.AuthMiddleware(adminHandler.GetLowStockProducts)(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	var products []v1.Product
+	if err := json.Unmarshal(recorder.Body.Bytes(), &products); err != nil {
+		t.Fatal(err)
+	}
+
+	return products
+}
@@ -0,0 +1,140 @@
+package integration
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	v1 "github.com/resueman/merch-store/internal/api/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistory(t *testing.T) {
+	defer cleanup()
+
+	setup()
+
+	tokenA := authUser(t, "A", "password_A", http.StatusOK)
+	tokenB := authUser(t, "B", "password_B", http.StatusOK)
+
+	buyItem(t, tokenA, "cup", http.StatusOK)
+	sendCoin(t, tokenA, "B", 10, http.StatusOK)
+	sendCoin(t, tokenB, "A", 5, http.StatusOK)
+
+	// Первая страница: самые новые операции, курсор на следующую.
+	page := getHistory(t, tokenA, "limit=2", http.StatusOK)
+	require.Len(t, page.Items, 2)
+	require.NotNil(t, page.NextCursor)
+
+	assert.Equal(t, v1.HistoryEntryTypeTransfer, page.Items[0].Type)
+	assert.Equal(t, v1.HistoryEntryDirectionIncoming, page.Items[0].Direction)
+	assert.Equal(t, 5, page.Items[0].Amount)
+	assert.Equal(t, "B", *page.Items[0].Counterparty)
+
+	assert.Equal(t, v1.HistoryEntryTypeTransfer, page.Items[1].Type)
+	assert.Equal(t, v1.HistoryEntryDirectionOutgoing, page.Items[1].Direction)
+	assert.Equal(t, 10, page.Items[1].Amount)
+	assert.Equal(t, "B", *page.Items[1].Counterparty)
+	assert.False(t, page.Items[0].CreatedAt.Before(page.Items[1].CreatedAt))
+
+	// Вторая страница продолжает первую и оказывается последней.
+	page = getHistory(t, tokenA, "limit=2&cursor="+url.QueryEscape(*page.NextCursor), http.StatusOK)
+	require.Len(t, page.Items, 1)
+	assert.Nil(t, page.NextCursor)
+	assert.Equal(t, v1.HistoryEntryTypePurchase, page.Items[0].Type)
+	assert.Equal(t, v1.HistoryEntryDirectionOutgoing, page.Items[0].Direction)
+	assert.Equal(t, "cup", *page.Items[0].Item)
+	assert.Equal(t, 1, *page.Items[0].Quantity)
+	assert.Equal(t, 20, page.Items[0].Amount)
+
+	// Фильтры.
+	page = getHistory(t, tokenA, "direction=incoming", http.StatusOK)
+	require.Len(t, page.Items, 1)
+	assert.Equal(t, 5, page.Items[0].Amount)
+
+	page = getHistory(t, tokenA, "type=purchase", http.StatusOK)
+	require.Len(t, page.Items, 1)
+	assert.Equal(t, "cup", *page.Items[0].Item)
+
+	page = getHistory(t, tokenA, "counterparty=B", http.StatusOK)
+	assert.Len(t, page.Items, 2)
+
+	future := url.QueryEscape(time.Now().Add(time.Hour).Format(time.RFC3339))
+	page = getHistory(t, tokenA, "from="+future, http.StatusOK)
+	assert.Empty(t, page.Items)
+
+	// У получателя тот же перевод входящий.
+	page = getHistory(t, tokenB, "type=transfer&direction=incoming", http.StatusOK)
+	require.Len(t, page.Items, 1)
+	assert.Equal(t, 10, page.Items[0].Amount)
+	assert.Equal(t, "A", *page.Items[0].Counterparty)
+
+	getHistory(t, tokenA, "cursor=broken", http.StatusBadRequest)
+	getHistory(t, tokenA, "type=deposit", http.StatusBadRequest)
+	getHistory(t, tokenA, "counterparty=nobody", http.StatusBadRequest)
+}
+
+func TestGetOperation(t *testing.T) {
+	defer cleanup()
+
+	setup()
+
+	tokenA := authUser(t, "A", "password_A", http.StatusOK)
+	tokenB := authUser(t, "B", "password_B", http.StatusOK)
+	tokenC := authUser(t, "C", "password_C", http.StatusOK)
+	adminToken := authUser(t, "manager", "password", http.StatusOK)
+
+	sendCoin(t, tokenA, "B", 50, http.StatusOK)
+	buyItems(t, tokenA, "cup", 3, http.StatusOK)
+
+	page := getHistory(t, tokenA, "", http.StatusOK)
+	require.Len(t, page.Items, 2)
+	purchaseID, transferID := page.Items[0].Id, page.Items[1].Id
+
+	// Перевод видят отправитель, получатель и администратор.
+	for _, token := range []string{tokenA, tokenB, adminToken} {
+		operation := getOperation(t, token, transferID, http.StatusOK)
+		assert.Equal(t, v1.OperationTypeTransfer, operation.Type)
+		assert.Equal(t, "A", operation.User)
+		assert.Equal(t, "B", *operation.Recipient)
+		assert.Equal(t, 50, *operation.Amount)
+		assert.Equal(t, page.Items[1].CreatedAt, operation.CreatedAt)
+	}
+
+	// Для постороннего операция не существует.
+	getOperation(t, tokenC, transferID, http.StatusBadRequest)
+
+	operation := getOperation(t, tokenA, purchaseID, http.StatusOK)
+	assert.Equal(t, v1.OperationTypePurchase, operation.Type)
+	assert.Equal(t, "cup", *operation.Item)
+	assert.Equal(t, 3, *operation.Quantity)
+	assert.Equal(t, 60, *operation.TotalPrice)
+
+	// Покупка касается только покупателя.
+	getOperation(t, tokenB, purchaseID, http.StatusBadRequest)
+	getOperation(t, tokenA, purchaseID+1000, http.StatusBadRequest)
+}
+
+func TestInfoSnapshotMark(t *testing.T) {
+	defer cleanup()
+
+	setup()
+
+	tokenA := authUser(t, "A", "password_A", http.StatusOK)
+	tokenB := authUser(t, "B", "password_B", http.StatusOK)
+
+	info := getInfo(t, tokenB)
+	require.NotNil(t, info.SnapshotAt)
+	assert.Equal(t, 0, *info.LastOperationId)
+
+	// Полученный перевод записан на счет отправителя, но входит в снимок получателя.
+	sendCoin(t, tokenA, "B", 10, http.StatusOK)
+
+	page := getHistory(t, tokenB, "limit=1", http.StatusOK)
+	require.Len(t, page.Items, 1)
+
+	info = getInfo(t, tokenB)
+	assert.Equal(t, page.Items[0].Id, *info.LastOperationId)
+}
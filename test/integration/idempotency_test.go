@@ -0,0 +1,51 @@
+package integration
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/resueman/merch-store/internal/delivery/handlers/http/v1/converter"
+	"github.com/resueman/merch-store/internal/delivery/middleware"
+	"github.com/resueman/merch-store/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSendCoinIdempotency(t *testing.T) {
+	defer cleanup()
+
+	setup()
+
+	tokenA := authUser(t, "A", "password_A", http.StatusOK)
+	tokenB := authUser(t, "B", "password_B", http.StatusOK)
+
+	// SendCoin: A -> B: 10 with a new key -> success
+	recorder := sendCoinIdempotent(t, tokenA, "key-1", "B", 10, http.StatusOK)
+	assert.Empty(t, recorder.Header().Get(middleware.IdempotentReplayedHeader))
+
+	// SendCoin: the same request with the same key -> stored response, no second transfer
+	recorder = sendCoinIdempotent(t, tokenA, "key-1", "B", 10, http.StatusOK)
+	assert.Equal(t, "true", recorder.Header().Get(middleware.IdempotentReplayedHeader))
+
+	// SendCoin: the same key with another body -> error
+	sendCoinIdempotent(t, tokenA, "key-1", "B", 20, http.StatusUnprocessableEntity)
+
+	// SendCoin: failed request does not occupy the key
+	sendCoinIdempotent(t, tokenA, "key-2", "B", 1000, http.StatusBadRequest)
+	sendCoinIdempotent(t, tokenA, "key-2", "B", 20, http.StatusOK)
+
+	// Keys are scoped to the user
+	sendCoinIdempotent(t, tokenB, "key-1", "A", 5, http.StatusOK)
+
+	accountA := &model.AccountInfo{
+		Balance:           190 - 10 - 20 + 5,
+		Inventory:         []model.Inventory{},
+		IncomingTransfers: []model.IncomingTransfer{{SenderUsername: "B", Amount: 5}},
+		OutgoingTransfers: []model.OutgoingTransfer{
+			{RecipientUsername: "B", Amount: 20},
+			{RecipientUsername: "B", Amount: 10},
+		},
+	}
+	expected := converter.ConvertAccountInfoToInfoResponse(accountA)
+
+	getUserInfo(t, tokenA, http.StatusOK, &expected)
+}
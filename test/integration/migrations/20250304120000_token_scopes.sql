@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Области доступа, с которыми была открыта сессия, через пробел.
+-- Пустая строка означает все области, доступные роли пользователя.
+ALTER TABLE refresh_tokens
+    ADD COLUMN scopes TEXT NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE refresh_tokens
+    DROP COLUMN IF EXISTS scopes;
+-- +goose StatementEnd
@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Сервисные аккаунты (боты) не входят по паролю и аутентифицируются только API-ключами.
+ALTER TABLE users
+    ADD COLUMN is_service BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Хранится только хеш ключа, prefix позволяет узнать ключ в списке, не раскрывая его.
+CREATE TABLE api_keys (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    prefix VARCHAR(16) NOT NULL,
+    key_hash VARCHAR(64) NOT NULL UNIQUE,
+    scopes TEXT NOT NULL DEFAULT '',
+    created_by INT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_used_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE INDEX api_keys_user_id_idx ON api_keys (user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS api_keys CASCADE;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS is_service;
+-- +goose StatementEnd
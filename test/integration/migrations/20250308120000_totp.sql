@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Второй фактор пользователя. Пока enabled_at не заполнен, подключение не подтверждено кодом и не действует.
+-- last_used_step хранит интервал последнего принятого кода, чтобы один код нельзя было использовать дважды.
+-- Переводы и покупки дороже threshold монет требуют свежего кода.
+CREATE TABLE user_totp (
+    user_id INT PRIMARY KEY,
+    secret VARCHAR(64) NOT NULL,
+    threshold INT NOT NULL DEFAULT 0 CHECK (threshold >= 0),
+    last_used_step BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    enabled_at TIMESTAMPTZ,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Одноразовые коды восстановления на случай потери устройства, хранятся только их хеши.
+CREATE TABLE totp_recovery_codes (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL,
+    code_hash VARCHAR(64) NOT NULL,
+    used_at TIMESTAMPTZ,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX totp_recovery_codes_user_id_idx ON totp_recovery_codes (user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS totp_recovery_codes CASCADE;
+DROP TABLE IF EXISTS user_totp CASCADE;
+-- +goose StatementEnd
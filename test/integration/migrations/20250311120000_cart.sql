@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Корзина пользователя: по одной строке на товар, повторное добавление увеличивает quantity.
+-- Цена не хранится, при оформлении заказа берется текущая цена товара.
+CREATE TABLE cart_items (
+    user_id INT NOT NULL,
+    product_id INT NOT NULL,
+    quantity INT NOT NULL CHECK (quantity > 0),
+    added_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, product_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS cart_items CASCADE;
+-- +goose StatementEnd
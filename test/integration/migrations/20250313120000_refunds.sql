@@ -0,0 +1,51 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TYPE operation_type ADD VALUE IF NOT EXISTS 'refund';
+
+CREATE TYPE refund_status AS ENUM (
+    'requested',
+    'approved',
+    'rejected'
+);
+
+-- Заявка на возврат части или всей покупки. Сумма возврата пропорциональна количеству единиц
+-- и начисляется покупателю только после одобрения администратором.
+CREATE TABLE refunds (
+    id SERIAL PRIMARY KEY,
+    purchase_operation_id INT NOT NULL,
+    customer_account_id INT NOT NULL,
+    quantity INT NOT NULL,
+    amount INT NOT NULL,
+    status refund_status NOT NULL DEFAULT 'requested',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    decided_at TIMESTAMP,
+    decided_by INT,
+    FOREIGN KEY (purchase_operation_id) REFERENCES purchase_operations(id) ON DELETE CASCADE,
+    FOREIGN KEY (customer_account_id) REFERENCES accounts(id) ON DELETE CASCADE,
+    FOREIGN KEY (decided_by) REFERENCES users(id) ON DELETE SET NULL,
+    CHECK (quantity > 0),
+    CHECK (amount >= 0)
+);
+
+CREATE INDEX refunds_purchase_operation_id_idx ON refunds (purchase_operation_id);
+CREATE INDEX refunds_status_idx ON refunds (status);
+
+-- Каждый шаг возврата (заявка, одобрение, отказ) - отдельная операция с типом 'refund' в истории покупателя.
+CREATE TABLE refund_operations (
+    id SERIAL PRIMARY KEY,
+    operation_id INT NOT NULL,
+    refund_id INT NOT NULL,
+    status refund_status NOT NULL,
+    FOREIGN KEY (operation_id) REFERENCES operations(id) ON DELETE CASCADE,
+    FOREIGN KEY (refund_id) REFERENCES refunds(id) ON DELETE CASCADE
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS refund_operations CASCADE;
+DROP TABLE IF EXISTS refunds CASCADE;
+DROP TYPE IF EXISTS refund_status CASCADE;
+-- Значение 'refund' нельзя удалить из типа operation_type, оно остается неиспользуемым.
+DELETE FROM operations WHERE operation_type = 'refund';
+-- +goose StatementEnd
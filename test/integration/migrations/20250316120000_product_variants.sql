@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Варианты товара, например размеры. Если цена или остаток не заданы, используются цена и остаток товара.
+CREATE TABLE product_variants (
+    id SERIAL PRIMARY KEY,
+    product_id INT NOT NULL,
+    name VARCHAR(32) NOT NULL,
+    sku VARCHAR(64) NOT NULL UNIQUE,
+    price INT CHECK (price >= 0),
+    stock INT CHECK (stock >= 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (product_id, name),
+    FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE
+);
+
+-- Купленный вариант. Для покупок без варианта и покупок, сделанных до появления вариантов, пусто.
+ALTER TABLE purchase_operations
+    ADD COLUMN variant_id INT REFERENCES product_variants(id);
+
+-- Пополненный вариант, если у него собственный остаток.
+ALTER TABLE product_restocks
+    ADD COLUMN variant_id INT REFERENCES product_variants(id) ON DELETE CASCADE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE product_restocks
+    DROP COLUMN IF EXISTS variant_id;
+
+ALTER TABLE purchase_operations
+    DROP COLUMN IF EXISTS variant_id;
+
+DROP TABLE IF EXISTS product_variants CASCADE;
+-- +goose StatementEnd
@@ -0,0 +1,60 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Товары не удаляются, а снимаются с продажи (products.retired_at). Каскадное удаление товара
+-- стирало бы покупки, инвентарь и журналы, поэтому удаление товара, на который они ссылаются, запрещено.
+-- Строки корзины историей не являются и по-прежнему удаляются вместе с товаром.
+ALTER TABLE purchase_operations
+    DROP CONSTRAINT purchase_operations_product_id_fkey,
+    ADD CONSTRAINT purchase_operations_product_id_fkey
+        FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE RESTRICT,
+    DROP CONSTRAINT purchase_operations_variant_id_fkey,
+    ADD CONSTRAINT purchase_operations_variant_id_fkey
+        FOREIGN KEY (variant_id) REFERENCES product_variants(id) ON DELETE RESTRICT;
+
+ALTER TABLE product_variants
+    DROP CONSTRAINT product_variants_product_id_fkey,
+    ADD CONSTRAINT product_variants_product_id_fkey
+        FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE RESTRICT;
+
+ALTER TABLE product_restocks
+    DROP CONSTRAINT product_restocks_product_id_fkey,
+    ADD CONSTRAINT product_restocks_product_id_fkey
+        FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE RESTRICT,
+    DROP CONSTRAINT product_restocks_variant_id_fkey,
+    ADD CONSTRAINT product_restocks_variant_id_fkey
+        FOREIGN KEY (variant_id) REFERENCES product_variants(id) ON DELETE RESTRICT;
+
+ALTER TABLE product_audit
+    DROP CONSTRAINT product_audit_product_id_fkey,
+    ADD CONSTRAINT product_audit_product_id_fkey
+        FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE RESTRICT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE product_audit
+    DROP CONSTRAINT product_audit_product_id_fkey,
+    ADD CONSTRAINT product_audit_product_id_fkey
+        FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE;
+
+ALTER TABLE product_restocks
+    DROP CONSTRAINT product_restocks_variant_id_fkey,
+    ADD CONSTRAINT product_restocks_variant_id_fkey
+        FOREIGN KEY (variant_id) REFERENCES product_variants(id) ON DELETE CASCADE,
+    DROP CONSTRAINT product_restocks_product_id_fkey,
+    ADD CONSTRAINT product_restocks_product_id_fkey
+        FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE;
+
+ALTER TABLE product_variants
+    DROP CONSTRAINT product_variants_product_id_fkey,
+    ADD CONSTRAINT product_variants_product_id_fkey
+        FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE;
+
+ALTER TABLE purchase_operations
+    DROP CONSTRAINT purchase_operations_variant_id_fkey,
+    ADD CONSTRAINT purchase_operations_variant_id_fkey
+        FOREIGN KEY (variant_id) REFERENCES product_variants(id),
+    DROP CONSTRAINT purchase_operations_product_id_fkey,
+    ADD CONSTRAINT purchase_operations_product_id_fkey
+        FOREIGN KEY (product_id) REFERENCES products(id) ON DELETE CASCADE;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+-- История операций читается постранично от новых к старым, поэтому индекс по счету
+-- покрывает и сортировку по (created_at, id).
+CREATE INDEX operations_account_id_created_at_idx ON operations (account_id, created_at DESC, id DESC);
+
+-- Полученные переводы записаны на счет отправителя и ищутся через transfer_operations.
+CREATE INDEX transfer_operations_recipient_account_id_idx ON transfer_operations (recipient_account_id);
+CREATE INDEX transfer_operations_operation_id_idx ON transfer_operations (operation_id);
+CREATE INDEX purchase_operations_operation_id_idx ON purchase_operations (operation_id);
+CREATE INDEX refund_operations_operation_id_idx ON refund_operations (operation_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS refund_operations_operation_id_idx;
+DROP INDEX IF EXISTS purchase_operations_operation_id_idx;
+DROP INDEX IF EXISTS transfer_operations_operation_id_idx;
+DROP INDEX IF EXISTS transfer_operations_recipient_account_id_idx;
+DROP INDEX IF EXISTS operations_account_id_created_at_idx;
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Строка корзины может относиться к варианту товара: разные варианты одного товара лежат в корзине
+-- отдельными строками, строка без варианта - это сам товар. Первичный ключ не может включать
+-- необязательный variant_id, поэтому уникальность строки обеспечивает индекс.
+ALTER TABLE cart_items
+    DROP CONSTRAINT cart_items_pkey,
+    ADD COLUMN variant_id INT REFERENCES product_variants(id) ON DELETE CASCADE;
+
+CREATE UNIQUE INDEX cart_items_user_product_variant_idx
+    ON cart_items (user_id, product_id, COALESCE(variant_id, 0));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS cart_items_user_product_variant_idx;
+
+DELETE FROM cart_items WHERE variant_id IS NOT NULL;
+
+ALTER TABLE cart_items
+    DROP COLUMN IF EXISTS variant_id,
+    ADD PRIMARY KEY (user_id, product_id);
+-- +goose StatementEnd
@@ -0,0 +1,200 @@
+package integration
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	v1 "github.com/resueman/merch-store/internal/api/v1"
+	"github.com/resueman/merch-store/pkg/db"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetProducts(t *testing.T) {
+	defer cleanup()
+
+	setup()
+
+	token := authUser(t, "user", "password", http.StatusOK)
+
+	// GetProducts: whole catalog sorted by name, first page only
+	page := getProducts(t, token, "limit=3", http.StatusOK)
+	assert.Equal(t, 10, page.Total)
+	if assert.Len(t, page.Items, 3) {
+		assert.Equal(t, "book", page.Items[0].Name)
+		assert.Equal(t, "cup", page.Items[1].Name)
+		assert.Equal(t, "hoody", page.Items[2].Name)
+	}
+
+	// GetProducts: name substring ignores case, most expensive first
+	page = getProducts(t, token, "q=HOODY&sort=price&order=desc", http.StatusOK)
+	assert.Equal(t, 2, page.Total)
+	if assert.Len(t, page.Items, 2) {
+		assert.Equal(t, "pink-hoody", page.Items[0].Name)
+		assert.Equal(t, "hoody", page.Items[1].Name)
+	}
+
+	// GetProducts: price range, cheapest first; pen and socks cost the same, so the order is stable by id
+	page = getProducts(t, token, "minPrice=10&maxPrice=20&sort=price", http.StatusOK)
+	assert.Equal(t, 3, page.Total)
+	if assert.Len(t, page.Items, 3) {
+		assert.Equal(t, "pen", page.Items[0].Name)
+		assert.Equal(t, "socks", page.Items[1].Name)
+		assert.Equal(t, "cup", page.Items[2].Name)
+	}
+
+	// GetProducts: inverted price range -> error
+	getProducts(t, token, "minPrice=100&maxPrice=10", http.StatusBadRequest)
+}
+
+func TestProductManagement(t *testing.T) {
+	defer cleanup()
+
+	setup()
+
+	token := authUser(t, "user", "password", http.StatusOK)
+	adminToken := authUser(t, "manager", "password", http.StatusOK)
+
+	// CreateProduct: name of a seeded product -> conflict
+	createProduct(t, adminToken, v1.CreateProductRequest{Name: "cup", Price: 30}, http.StatusConflict)
+
+	// CreateProduct: new product appears in the catalog
+	description, stock, price := "white", 5, 60
+	createProduct(t, adminToken, v1.CreateProductRequest{Name: "mug", Price: 40, Description: &description,
+		Stock: &stock}, http.StatusOK)
+	page := getProducts(t, token, "q=mug", http.StatusOK)
+	if assert.Len(t, page.Items, 1) {
+		assert.Equal(t, 40, page.Items[0].Price)
+		assert.Equal(t, "white", page.Items[0].Description)
+	}
+
+	// UpdateProduct: the next purchase is charged at the new price
+	updateProduct(t, adminToken, "mug", v1.UpdateProductRequest{Price: &price}, http.StatusOK)
+	buyItem(t, token, "mug", http.StatusOK)
+	purchases := getRefundablePurchases(t, token, http.StatusOK)
+	if assert.Len(t, purchases, 1) {
+		assert.Equal(t, 60, purchases[0].TotalPrice)
+	}
+
+	// RetireProduct: the product can no longer be bought and disappears from the catalog
+	retireProduct(t, adminToken, "mug", http.StatusOK)
+	buyItem(t, token, "mug", http.StatusBadRequest)
+	assert.Equal(t, 0, getProducts(t, token, "q=mug", http.StatusOK).Total)
+	retireProduct(t, adminToken, "mug", http.StatusBadRequest)
+
+	// GetProductAudit: newest entries first, every change is attributed to the admin
+	entries := getProductAudit(t, adminToken, "mug")
+	if assert.Len(t, entries, 5) {
+		assert.Equal(t, v1.ProductAuditEntryActionRetire, entries[0].Action)
+		assert.Equal(t, v1.ProductAuditEntryActionUpdate, entries[1].Action)
+		assert.Equal(t, "40", *entries[1].OldValue)
+		assert.Equal(t, "60", *entries[1].NewValue)
+		for _, entry := range entries {
+			assert.Equal(t, "manager", entry.ChangedBy)
+		}
+	}
+}
+
+func TestProductVariants(t *testing.T) {
+	defer cleanup()
+
+	setup()
+
+	token := authUser(t, "user", "password", http.StatusOK)
+	adminToken := authUser(t, "manager", "password", http.StatusOK)
+
+	// CreateProductVariant: XL has its own price and a single unit in stock, M uses the t-shirt price and stock
+	price, stock := 90, 1
+	createProductVariant(t, adminToken, "t-shirt", v1.CreateProductVariantRequest{Name: "XL", Sku: "TSHIRT-XL",
+		Price: &price, Stock: &stock}, http.StatusOK)
+	createProductVariant(t, adminToken, "t-shirt", v1.CreateProductVariantRequest{Name: "M", Sku: "TSHIRT-M"},
+		http.StatusOK)
+
+	// CreateProductVariant: SKU is already taken -> conflict
+	createProductVariant(t, adminToken, "hoody", v1.CreateProductVariantRequest{Name: "XL", Sku: "TSHIRT-XL"},
+		http.StatusConflict)
+
+	// BuyItem: unknown variant -> error
+	buyVariant(t, token, "t-shirt", "XXS", http.StatusBadRequest)
+
+	// BuyItem: XL costs 90, M costs 80 like the t-shirt itself
+	buyVariant(t, token, "t-shirt", "XL", http.StatusOK)
+	buyVariant(t, token, "t-shirt", "M", http.StatusOK)
+
+	// BuyItem: the only XL is sold out, M still comes from the t-shirt stock
+	buyVariant(t, token, "t-shirt", "XL", http.StatusBadRequest)
+
+	purchases := getRefundablePurchases(t, token, http.StatusOK)
+	if assert.Len(t, purchases, 2) {
+		assert.ElementsMatch(t, []string{"t-shirt (XL)", "t-shirt (M)"},
+			[]string{purchases[0].Item, purchases[1].Item})
+		assert.ElementsMatch(t, []int{90, 80}, []int{purchases[0].TotalPrice, purchases[1].TotalPrice})
+	}
+
+	// GetInfo: variants are listed separately in the inventory
+	assert.Equal(t, map[string]int{"t-shirt (XL)": 1, "t-shirt (M)": 1}, getInventory(t, token))
+}
+
+func TestRetiredProductKeepsHistory(t *testing.T) {
+	defer cleanup()
+
+	setup()
+
+	token := authUser(t, "user", "password", http.StatusOK)
+	adminToken := authUser(t, "manager", "password", http.StatusOK)
+
+	stock := 5
+	createProduct(t, adminToken, v1.CreateProductRequest{Name: "mug", Price: 40, Stock: &stock}, http.StatusOK)
+	buyItem(t, token, "mug", http.StatusOK)
+
+	// RetireProduct: the product can't be bought anymore, but stays in the inventory and purchases
+	retireProduct(t, adminToken, "mug", http.StatusOK)
+	buyItem(t, token, "mug", http.StatusBadRequest)
+	assert.Equal(t, map[string]int{"mug": 1}, getInventory(t, token))
+	assert.Len(t, getRefundablePurchases(t, token, http.StatusOK), 1)
+
+	// Deleting a product with purchases is rejected by the database instead of cascading to the history
+	_, err := dbClient.Primary().Exec(context.Background(),
+		db.Query{Name: "DeleteProduct", QueryRaw: "DELETE FROM products WHERE name = 'mug'"})
+	assert.Error(t, err)
+	assert.Equal(t, map[string]int{"mug": 1}, getInventory(t, token))
+}
+
+func TestLowStockProducts(t *testing.T) {
+	defer cleanup()
+
+	setup()
+
+	adminToken := authUser(t, "manager", "password", http.StatusOK)
+
+	// Seeded products are well stocked: the report is empty
+	assert.Empty(t, getLowStockProducts(t, adminToken))
+
+	// mug runs low itself, the t-shirt only through its XL variant; M shares the t-shirt stock
+	mugStock, xlStock := 2, 1
+	createProduct(t, adminToken, v1.CreateProductRequest{Name: "mug", Price: 40, Stock: &mugStock}, http.StatusOK)
+	createProductVariant(t, adminToken, "t-shirt", v1.CreateProductVariantRequest{Name: "XL", Sku: "TSHIRT-XL",
+		Stock: &xlStock}, http.StatusOK)
+	createProductVariant(t, adminToken, "t-shirt", v1.CreateProductVariantRequest{Name: "M", Sku: "TSHIRT-M"},
+		http.StatusOK)
+
+	// Retired products are not reported
+	retiredStock := 0
+	createProduct(t, adminToken, v1.CreateProductRequest{Name: "pin", Price: 5, Stock: &retiredStock},
+		http.StatusOK)
+	retireProduct(t, adminToken, "pin", http.StatusOK)
+
+	// GetLowStockProducts: the scarcest first, low variants are listed under their product
+	products := getLowStockProducts(t, adminToken)
+	if assert.Len(t, products, 2) {
+		assert.Equal(t, "t-shirt", products[0].Name)
+		if assert.NotNil(t, products[0].Variants) && assert.Len(t, *products[0].Variants, 1) {
+			assert.Equal(t, "XL", (*products[0].Variants)[0].Name)
+			assert.Equal(t, 1, (*products[0].Variants)[0].Stock)
+		}
+
+		assert.Equal(t, "mug", products[1].Name)
+		assert.Equal(t, 2, products[1].Stock)
+		assert.Nil(t, products[1].Variants)
+	}
+}
@@ -0,0 +1,66 @@
+package integration
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/resueman/merch-store/internal/delivery/handlers/http/v1/converter"
+	"github.com/resueman/merch-store/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRefund(t *testing.T) {
+	defer cleanup()
+
+	setup()
+
+	token := authUser(t, "user", "password", http.StatusOK)
+	adminToken := authUser(t, "manager", "password", http.StatusOK)
+
+	// BuyItem: 3 pens for 30 coins
+	buyItems(t, token, "pen", 3, http.StatusOK)
+
+	purchases := getRefundablePurchases(t, token, http.StatusOK)
+	if !assert.Len(t, purchases, 1) {
+		return
+	}
+
+	purchase := purchases[0]
+	assert.Equal(t, 3, purchase.RefundableQuantity)
+
+	// RequestRefund: more than was bought -> error
+	requestRefund(t, token, purchase.Id, 4, http.StatusBadRequest)
+
+	// RequestRefund: someone else's purchase -> error
+	requestRefund(t, adminToken, purchase.Id, 1, http.StatusBadRequest)
+
+	// RequestRefund: 2 of 3 pens, the refund waits for approval
+	refund := requestRefund(t, token, purchase.Id, 2, http.StatusOK)
+	assert.Equal(t, 20, refund.Amount)
+
+	// RequestRefund: only 1 pen is left to refund
+	requestRefund(t, token, purchase.Id, 2, http.StatusBadRequest)
+
+	// GetInfo: nothing changes until the refund is approved
+	accountInfo := &model.AccountInfo{
+		Balance:           190 - 30,
+		Inventory:         []model.Inventory{{Name: "pen", Quantity: 3}},
+		IncomingTransfers: []model.IncomingTransfer{},
+		OutgoingTransfers: []model.OutgoingTransfer{},
+	}
+	expected := converter.ConvertAccountInfoToInfoResponse(accountInfo)
+
+	getUserInfo(t, token, http.StatusOK, &expected)
+
+	// ApproveRefund: coins are returned, refunded pens leave the inventory
+	approveRefund(t, adminToken, refund.Id, http.StatusOK)
+
+	accountInfo.Balance += 20
+	accountInfo.Inventory = []model.Inventory{{Name: "pen", Quantity: 1}}
+	expected = converter.ConvertAccountInfoToInfoResponse(accountInfo)
+
+	getUserInfo(t, token, http.StatusOK, &expected)
+
+	// ApproveRefund: the refund is already decided -> error
+	approveRefund(t, adminToken, refund.Id, http.StatusConflict)
+}
@@ -0,0 +1,62 @@
+package integration
+
+import (
+	"context"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/resueman/merch-store/pkg/db"
+	"github.com/resueman/merch-store/pkg/username"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUsernameNormalizationMatchesMigration(t *testing.T) {
+	defer cleanup()
+
+	setup()
+
+	// The expression is taken from the migration itself, so the test checks the SQL that filled the column
+	migration, err := os.ReadFile("../../migrations/20250310120000_username_normalized.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	match := regexp.MustCompile(`(?s)SET username_normalized = (.*?);`).FindSubmatch(migration)
+	if match == nil {
+		t.Fatal("backfill expression not found in the migration")
+	}
+
+	query := db.Query{
+		Name:     "NormalizeUsername",
+		QueryRaw: "SELECT " + string(match[1]) + " FROM (VALUES ($1::text)) AS users(username)",
+	}
+
+	names := []string{
+		"Alice",
+		"  bob ",
+		"\tcarol\r\n",
+		"\u00a0dave\u3000",
+		"\u2003eve\u205f",
+		"ａｌｉｃｅ",
+		"Jose\u0301",
+		"Иван",
+		"ﬁona",
+		"İstanbul",
+		"ΣΟΦΙΑ",
+		"Straße",
+		"ẞ",
+		"ǅemal",
+		"\u212aelvin",
+		"Ⅻ①",
+	}
+
+	for _, name := range names {
+		var normalized string
+		if err := dbClient.Primary().QueryRow(context.Background(), query, name).Scan(&normalized); err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, username.Normalize(name), normalized, "username %q", name)
+	}
+}
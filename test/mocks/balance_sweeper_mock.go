@@ -0,0 +1,50 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/usecase/auth/balanceSweeper.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockBalanceSweeper is a mock of BalanceSweeper interface.
+type MockBalanceSweeper struct {
+	ctrl     *gomock.Controller
+	recorder *MockBalanceSweeperMockRecorder
+}
+
+// MockBalanceSweeperMockRecorder is the mock recorder for MockBalanceSweeper.
+type MockBalanceSweeperMockRecorder struct {
+	mock *MockBalanceSweeper
+}
+
+// NewMockBalanceSweeper creates a new mock instance.
+func NewMockBalanceSweeper(ctrl *gomock.Controller) *MockBalanceSweeper {
+	mock := &MockBalanceSweeper{ctrl: ctrl}
+	mock.recorder = &MockBalanceSweeperMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBalanceSweeper) EXPECT() *MockBalanceSweeperMockRecorder {
+	return m.recorder
+}
+
+// SweepBalance mocks base method.
+func (m *MockBalanceSweeper) SweepBalance(ctx context.Context, userID int, recipientUsername string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SweepBalance", ctx, userID, recipientUsername)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SweepBalance indicates an expected call of SweepBalance.
+func (mr *MockBalanceSweeperMockRecorder) SweepBalance(ctx, userID, recipientUsername interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SweepBalance", reflect.TypeOf((*MockBalanceSweeper)(nil).SweepBalance), ctx, userID, recipientUsername)
+}
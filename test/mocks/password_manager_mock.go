@@ -1,5 +1,5 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: ./pkg/password/bryptManager.go
+// Source: ./pkg/password/password.go
 
 // Package mocks is a generated GoMock package.
 package mocks
@@ -48,11 +48,12 @@ func (mr *MockPasswordManagerMockRecorder) ComparePassword(password, hash interf
 }
 
 // HashPassword mocks base method.
-func (m *MockPasswordManager) HashPassword(password string) string {
+func (m *MockPasswordManager) HashPassword(password string) (string, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "HashPassword", password)
 	ret0, _ := ret[0].(string)
-	return ret0
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // HashPassword indicates an expected call of HashPassword.
@@ -60,3 +61,97 @@ func (mr *MockPasswordManagerMockRecorder) HashPassword(password interface{}) *g
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HashPassword", reflect.TypeOf((*MockPasswordManager)(nil).HashPassword), password)
 }
+
+// NeedsRehash mocks base method.
+func (m *MockPasswordManager) NeedsRehash(hash string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NeedsRehash", hash)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// NeedsRehash indicates an expected call of NeedsRehash.
+func (mr *MockPasswordManagerMockRecorder) NeedsRehash(hash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NeedsRehash", reflect.TypeOf((*MockPasswordManager)(nil).NeedsRehash), hash)
+}
+
+// MockHasher is a mock of Hasher interface.
+type MockHasher struct {
+	ctrl     *gomock.Controller
+	recorder *MockHasherMockRecorder
+}
+
+// MockHasherMockRecorder is the mock recorder for MockHasher.
+type MockHasherMockRecorder struct {
+	mock *MockHasher
+}
+
+// NewMockHasher creates a new mock instance.
+func NewMockHasher(ctrl *gomock.Controller) *MockHasher {
+	mock := &MockHasher{ctrl: ctrl}
+	mock.recorder = &MockHasherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockHasher) EXPECT() *MockHasherMockRecorder {
+	return m.recorder
+}
+
+// Compare mocks base method.
+func (m *MockHasher) Compare(password, hash string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Compare", password, hash)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// Compare indicates an expected call of Compare.
+func (mr *MockHasherMockRecorder) Compare(password, hash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Compare", reflect.TypeOf((*MockHasher)(nil).Compare), password, hash)
+}
+
+// Hash mocks base method.
+func (m *MockHasher) Hash(password string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Hash", password)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Hash indicates an expected call of Hash.
+func (mr *MockHasherMockRecorder) Hash(password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Hash", reflect.TypeOf((*MockHasher)(nil).Hash), password)
+}
+
+// Outdated mocks base method.
+func (m *MockHasher) Outdated(hash string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Outdated", hash)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// Outdated indicates an expected call of Outdated.
+func (mr *MockHasherMockRecorder) Outdated(hash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Outdated", reflect.TypeOf((*MockHasher)(nil).Outdated), hash)
+}
+
+// Supports mocks base method.
+func (m *MockHasher) Supports(hash string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Supports", hash)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// Supports indicates an expected call of Supports.
+func (mr *MockHasherMockRecorder) Supports(hash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Supports", reflect.TypeOf((*MockHasher)(nil).Supports), hash)
+}
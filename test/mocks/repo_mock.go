@@ -7,6 +7,7 @@ package mocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	entity "github.com/resueman/merch-store/internal/entity"
@@ -50,6 +51,35 @@ func (mr *MockUserMockRecorder) CreateUser(ctx, user interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockUser)(nil).CreateUser), ctx, user)
 }
 
+// DeactivateUser mocks base method.
+func (m *MockUser) DeactivateUser(ctx context.Context, userID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeactivateUser", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeactivateUser indicates an expected call of DeactivateUser.
+func (mr *MockUserMockRecorder) DeactivateUser(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeactivateUser", reflect.TypeOf((*MockUser)(nil).DeactivateUser), ctx, userID)
+}
+
+// GetUserByID mocks base method.
+func (m *MockUser) GetUserByID(ctx context.Context, userID int) (*entity.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByID", ctx, userID)
+	ret0, _ := ret[0].(*entity.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByID indicates an expected call of GetUserByID.
+func (mr *MockUserMockRecorder) GetUserByID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByID", reflect.TypeOf((*MockUser)(nil).GetUserByID), ctx, userID)
+}
+
 // GetUserByUsername mocks base method.
 func (m *MockUser) GetUserByUsername(ctx context.Context, username string) (*entity.User, error) {
 	m.ctrl.T.Helper()
@@ -65,232 +95,1467 @@ func (mr *MockUserMockRecorder) GetUserByUsername(ctx, username interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByUsername", reflect.TypeOf((*MockUser)(nil).GetUserByUsername), ctx, username)
 }
 
-// MockAccount is a mock of Account interface.
-type MockAccount struct {
+// HasUsersWithRole mocks base method.
+func (m *MockUser) HasUsersWithRole(ctx context.Context, role string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasUsersWithRole", ctx, role)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HasUsersWithRole indicates an expected call of HasUsersWithRole.
+func (mr *MockUserMockRecorder) HasUsersWithRole(ctx, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasUsersWithRole", reflect.TypeOf((*MockUser)(nil).HasUsersWithRole), ctx, role)
+}
+
+// IncrementTokenVersion mocks base method.
+func (m *MockUser) IncrementTokenVersion(ctx context.Context, userID int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncrementTokenVersion", ctx, userID)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IncrementTokenVersion indicates an expected call of IncrementTokenVersion.
+func (mr *MockUserMockRecorder) IncrementTokenVersion(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrementTokenVersion", reflect.TypeOf((*MockUser)(nil).IncrementTokenVersion), ctx, userID)
+}
+
+// ReactivateUser mocks base method.
+func (m *MockUser) ReactivateUser(ctx context.Context, userID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReactivateUser", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReactivateUser indicates an expected call of ReactivateUser.
+func (mr *MockUserMockRecorder) ReactivateUser(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReactivateUser", reflect.TypeOf((*MockUser)(nil).ReactivateUser), ctx, userID)
+}
+
+// SetUserRole mocks base method.
+func (m *MockUser) SetUserRole(ctx context.Context, userID int, role string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetUserRole", ctx, userID, role)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetUserRole indicates an expected call of SetUserRole.
+func (mr *MockUserMockRecorder) SetUserRole(ctx, userID, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserRole", reflect.TypeOf((*MockUser)(nil).SetUserRole), ctx, userID, role)
+}
+
+// UpdatePasswordHash mocks base method.
+func (m *MockUser) UpdatePasswordHash(ctx context.Context, input *entity.UpdatePasswordHashInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePasswordHash", ctx, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePasswordHash indicates an expected call of UpdatePasswordHash.
+func (mr *MockUserMockRecorder) UpdatePasswordHash(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePasswordHash", reflect.TypeOf((*MockUser)(nil).UpdatePasswordHash), ctx, input)
+}
+
+// MockRefreshToken is a mock of RefreshToken interface.
+type MockRefreshToken struct {
 	ctrl     *gomock.Controller
-	recorder *MockAccountMockRecorder
+	recorder *MockRefreshTokenMockRecorder
 }
 
-// MockAccountMockRecorder is the mock recorder for MockAccount.
-type MockAccountMockRecorder struct {
-	mock *MockAccount
+// MockRefreshTokenMockRecorder is the mock recorder for MockRefreshToken.
+type MockRefreshTokenMockRecorder struct {
+	mock *MockRefreshToken
 }
 
-// NewMockAccount creates a new mock instance.
-func NewMockAccount(ctrl *gomock.Controller) *MockAccount {
-	mock := &MockAccount{ctrl: ctrl}
-	mock.recorder = &MockAccountMockRecorder{mock}
+// NewMockRefreshToken creates a new mock instance.
+func NewMockRefreshToken(ctrl *gomock.Controller) *MockRefreshToken {
+	mock := &MockRefreshToken{ctrl: ctrl}
+	mock.recorder = &MockRefreshTokenMockRecorder{mock}
 	return mock
 }
 
 // EXPECT returns an object that allows the caller to indicate expected use.
-func (m *MockAccount) EXPECT() *MockAccountMockRecorder {
+func (m *MockRefreshToken) EXPECT() *MockRefreshTokenMockRecorder {
 	return m.recorder
 }
 
-// Deposit mocks base method.
-func (m *MockAccount) Deposit(ctx context.Context, accountID, amount int) error {
+// CreateRefreshToken mocks base method.
+func (m *MockRefreshToken) CreateRefreshToken(ctx context.Context, input *entity.CreateRefreshTokenInput) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Deposit", ctx, accountID, amount)
+	ret := m.ctrl.Call(m, "CreateRefreshToken", ctx, input)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// Deposit indicates an expected call of Deposit.
-func (mr *MockAccountMockRecorder) Deposit(ctx, accountID, amount interface{}) *gomock.Call {
+// CreateRefreshToken indicates an expected call of CreateRefreshToken.
+func (mr *MockRefreshTokenMockRecorder) CreateRefreshToken(ctx, input interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Deposit", reflect.TypeOf((*MockAccount)(nil).Deposit), ctx, accountID, amount)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRefreshToken", reflect.TypeOf((*MockRefreshToken)(nil).CreateRefreshToken), ctx, input)
 }
 
-// GetBalanceByAccountID mocks base method.
-func (m *MockAccount) GetBalanceByAccountID(ctx context.Context, accountID int) (int, error) {
+// GetRefreshTokenByHash mocks base method.
+func (m *MockRefreshToken) GetRefreshTokenByHash(ctx context.Context, tokenHash string) (*entity.RefreshToken, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetBalanceByAccountID", ctx, accountID)
-	ret0, _ := ret[0].(int)
+	ret := m.ctrl.Call(m, "GetRefreshTokenByHash", ctx, tokenHash)
+	ret0, _ := ret[0].(*entity.RefreshToken)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetBalanceByAccountID indicates an expected call of GetBalanceByAccountID.
-func (mr *MockAccountMockRecorder) GetBalanceByAccountID(ctx, accountID interface{}) *gomock.Call {
+// GetRefreshTokenByHash indicates an expected call of GetRefreshTokenByHash.
+func (mr *MockRefreshTokenMockRecorder) GetRefreshTokenByHash(ctx, tokenHash interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalanceByAccountID", reflect.TypeOf((*MockAccount)(nil).GetBalanceByAccountID), ctx, accountID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRefreshTokenByHash", reflect.TypeOf((*MockRefreshToken)(nil).GetRefreshTokenByHash), ctx, tokenHash)
 }
 
-// GetIDByUserID mocks base method.
-func (m *MockAccount) GetIDByUserID(ctx context.Context, userID int) (int, error) {
+// MarkRefreshTokenUsed mocks base method.
+func (m *MockRefreshToken) MarkRefreshTokenUsed(ctx context.Context, tokenID int) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetIDByUserID", ctx, userID)
-	ret0, _ := ret[0].(int)
+	ret := m.ctrl.Call(m, "MarkRefreshTokenUsed", ctx, tokenID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkRefreshTokenUsed indicates an expected call of MarkRefreshTokenUsed.
+func (mr *MockRefreshTokenMockRecorder) MarkRefreshTokenUsed(ctx, tokenID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkRefreshTokenUsed", reflect.TypeOf((*MockRefreshToken)(nil).MarkRefreshTokenUsed), ctx, tokenID)
+}
+
+// RevokeRefreshTokenFamily mocks base method.
+func (m *MockRefreshToken) RevokeRefreshTokenFamily(ctx context.Context, familyID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeRefreshTokenFamily", ctx, familyID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeRefreshTokenFamily indicates an expected call of RevokeRefreshTokenFamily.
+func (mr *MockRefreshTokenMockRecorder) RevokeRefreshTokenFamily(ctx, familyID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeRefreshTokenFamily", reflect.TypeOf((*MockRefreshToken)(nil).RevokeRefreshTokenFamily), ctx, familyID)
+}
+
+// RevokeUserRefreshTokens mocks base method.
+func (m *MockRefreshToken) RevokeUserRefreshTokens(ctx context.Context, userID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeUserRefreshTokens", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeUserRefreshTokens indicates an expected call of RevokeUserRefreshTokens.
+func (mr *MockRefreshTokenMockRecorder) RevokeUserRefreshTokens(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeUserRefreshTokens", reflect.TypeOf((*MockRefreshToken)(nil).RevokeUserRefreshTokens), ctx, userID)
+}
+
+// MockDenylist is a mock of Denylist interface.
+type MockDenylist struct {
+	ctrl     *gomock.Controller
+	recorder *MockDenylistMockRecorder
+}
+
+// MockDenylistMockRecorder is the mock recorder for MockDenylist.
+type MockDenylistMockRecorder struct {
+	mock *MockDenylist
+}
+
+// NewMockDenylist creates a new mock instance.
+func NewMockDenylist(ctrl *gomock.Controller) *MockDenylist {
+	mock := &MockDenylist{ctrl: ctrl}
+	mock.recorder = &MockDenylistMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDenylist) EXPECT() *MockDenylistMockRecorder {
+	return m.recorder
+}
+
+// GetRevokedTokens mocks base method.
+func (m *MockDenylist) GetRevokedTokens(ctx context.Context) ([]entity.RevokedToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRevokedTokens", ctx)
+	ret0, _ := ret[0].([]entity.RevokedToken)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetIDByUserID indicates an expected call of GetIDByUserID.
-func (mr *MockAccountMockRecorder) GetIDByUserID(ctx, userID interface{}) *gomock.Call {
+// GetRevokedTokens indicates an expected call of GetRevokedTokens.
+func (mr *MockDenylistMockRecorder) GetRevokedTokens(ctx interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIDByUserID", reflect.TypeOf((*MockAccount)(nil).GetIDByUserID), ctx, userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRevokedTokens", reflect.TypeOf((*MockDenylist)(nil).GetRevokedTokens), ctx)
 }
 
-// GetIDByUsername mocks base method.
-func (m *MockAccount) GetIDByUsername(ctx context.Context, username string) (int, error) {
+// GetTokenVersions mocks base method.
+func (m *MockDenylist) GetTokenVersions(ctx context.Context, revokedSince time.Time) ([]entity.TokenVersion, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetIDByUsername", ctx, username)
-	ret0, _ := ret[0].(int)
+	ret := m.ctrl.Call(m, "GetTokenVersions", ctx, revokedSince)
+	ret0, _ := ret[0].([]entity.TokenVersion)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetIDByUsername indicates an expected call of GetIDByUsername.
-func (mr *MockAccountMockRecorder) GetIDByUsername(ctx, username interface{}) *gomock.Call {
+// GetTokenVersions indicates an expected call of GetTokenVersions.
+func (mr *MockDenylistMockRecorder) GetTokenVersions(ctx, revokedSince interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIDByUsername", reflect.TypeOf((*MockAccount)(nil).GetIDByUsername), ctx, username)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTokenVersions", reflect.TypeOf((*MockDenylist)(nil).GetTokenVersions), ctx, revokedSince)
 }
 
-// GetPurchasesByAccountID mocks base method.
-func (m *MockAccount) GetPurchasesByAccountID(ctx context.Context, accountID int) ([]entity.Purchase, error) {
+// RevokeToken mocks base method.
+func (m *MockDenylist) RevokeToken(ctx context.Context, input *entity.RevokedToken) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetPurchasesByAccountID", ctx, accountID)
-	ret0, _ := ret[0].([]entity.Purchase)
+	ret := m.ctrl.Call(m, "RevokeToken", ctx, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeToken indicates an expected call of RevokeToken.
+func (mr *MockDenylistMockRecorder) RevokeToken(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeToken", reflect.TypeOf((*MockDenylist)(nil).RevokeToken), ctx, input)
+}
+
+// MockLoginAttempt is a mock of LoginAttempt interface.
+type MockLoginAttempt struct {
+	ctrl     *gomock.Controller
+	recorder *MockLoginAttemptMockRecorder
+}
+
+// MockLoginAttemptMockRecorder is the mock recorder for MockLoginAttempt.
+type MockLoginAttemptMockRecorder struct {
+	mock *MockLoginAttempt
+}
+
+// NewMockLoginAttempt creates a new mock instance.
+func NewMockLoginAttempt(ctrl *gomock.Controller) *MockLoginAttempt {
+	mock := &MockLoginAttempt{ctrl: ctrl}
+	mock.recorder = &MockLoginAttemptMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLoginAttempt) EXPECT() *MockLoginAttemptMockRecorder {
+	return m.recorder
+}
+
+// GetLoginLockedUntil mocks base method.
+func (m *MockLoginAttempt) GetLoginLockedUntil(ctx context.Context, keys []entity.LoginAttemptKey) (*time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLoginLockedUntil", ctx, keys)
+	ret0, _ := ret[0].(*time.Time)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetPurchasesByAccountID indicates an expected call of GetPurchasesByAccountID.
-func (mr *MockAccountMockRecorder) GetPurchasesByAccountID(ctx, accountID interface{}) *gomock.Call {
+// GetLoginLockedUntil indicates an expected call of GetLoginLockedUntil.
+func (mr *MockLoginAttemptMockRecorder) GetLoginLockedUntil(ctx, keys interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPurchasesByAccountID", reflect.TypeOf((*MockAccount)(nil).GetPurchasesByAccountID), ctx, accountID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLoginLockedUntil", reflect.TypeOf((*MockLoginAttempt)(nil).GetLoginLockedUntil), ctx, keys)
 }
 
-// Withdraw mocks base method.
-func (m *MockAccount) Withdraw(ctx context.Context, accountID, amount int) error {
+// LockLogin mocks base method.
+func (m *MockLoginAttempt) LockLogin(ctx context.Context, key entity.LoginAttemptKey, until time.Time) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Withdraw", ctx, accountID, amount)
+	ret := m.ctrl.Call(m, "LockLogin", ctx, key, until)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// Withdraw indicates an expected call of Withdraw.
-func (mr *MockAccountMockRecorder) Withdraw(ctx, accountID, amount interface{}) *gomock.Call {
+// LockLogin indicates an expected call of LockLogin.
+func (mr *MockLoginAttemptMockRecorder) LockLogin(ctx, key, until interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Withdraw", reflect.TypeOf((*MockAccount)(nil).Withdraw), ctx, accountID, amount)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockLogin", reflect.TypeOf((*MockLoginAttempt)(nil).LockLogin), ctx, key, until)
 }
 
-// MockOperation is a mock of Operation interface.
-type MockOperation struct {
+// RecordLoginFailure mocks base method.
+func (m *MockLoginAttempt) RecordLoginFailure(ctx context.Context, input *entity.LoginFailure) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordLoginFailure", ctx, input)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecordLoginFailure indicates an expected call of RecordLoginFailure.
+func (mr *MockLoginAttemptMockRecorder) RecordLoginFailure(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordLoginFailure", reflect.TypeOf((*MockLoginAttempt)(nil).RecordLoginFailure), ctx, input)
+}
+
+// ResetLoginFailures mocks base method.
+func (m *MockLoginAttempt) ResetLoginFailures(ctx context.Context, key entity.LoginAttemptKey) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetLoginFailures", ctx, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetLoginFailures indicates an expected call of ResetLoginFailures.
+func (mr *MockLoginAttemptMockRecorder) ResetLoginFailures(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetLoginFailures", reflect.TypeOf((*MockLoginAttempt)(nil).ResetLoginFailures), ctx, key)
+}
+
+// MockPasswordReset is a mock of PasswordReset interface.
+type MockPasswordReset struct {
 	ctrl     *gomock.Controller
-	recorder *MockOperationMockRecorder
+	recorder *MockPasswordResetMockRecorder
 }
 
-// MockOperationMockRecorder is the mock recorder for MockOperation.
-type MockOperationMockRecorder struct {
-	mock *MockOperation
+// MockPasswordResetMockRecorder is the mock recorder for MockPasswordReset.
+type MockPasswordResetMockRecorder struct {
+	mock *MockPasswordReset
 }
 
-// NewMockOperation creates a new mock instance.
-func NewMockOperation(ctrl *gomock.Controller) *MockOperation {
-	mock := &MockOperation{ctrl: ctrl}
-	mock.recorder = &MockOperationMockRecorder{mock}
+// NewMockPasswordReset creates a new mock instance.
+func NewMockPasswordReset(ctrl *gomock.Controller) *MockPasswordReset {
+	mock := &MockPasswordReset{ctrl: ctrl}
+	mock.recorder = &MockPasswordResetMockRecorder{mock}
 	return mock
 }
 
 // EXPECT returns an object that allows the caller to indicate expected use.
-func (m *MockOperation) EXPECT() *MockOperationMockRecorder {
+func (m *MockPasswordReset) EXPECT() *MockPasswordResetMockRecorder {
 	return m.recorder
 }
 
-// ExecPurchaseOperation mocks base method.
-func (m *MockOperation) ExecPurchaseOperation(ctx context.Context, input entity.PurchaseOperation) error {
+// CreatePasswordResetToken mocks base method.
+func (m *MockPasswordReset) CreatePasswordResetToken(ctx context.Context, input *entity.CreatePasswordResetTokenInput) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ExecPurchaseOperation", ctx, input)
+	ret := m.ctrl.Call(m, "CreatePasswordResetToken", ctx, input)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// ExecPurchaseOperation indicates an expected call of ExecPurchaseOperation.
-func (mr *MockOperationMockRecorder) ExecPurchaseOperation(ctx, input interface{}) *gomock.Call {
+// CreatePasswordResetToken indicates an expected call of CreatePasswordResetToken.
+func (mr *MockPasswordResetMockRecorder) CreatePasswordResetToken(ctx, input interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecPurchaseOperation", reflect.TypeOf((*MockOperation)(nil).ExecPurchaseOperation), ctx, input)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePasswordResetToken", reflect.TypeOf((*MockPasswordReset)(nil).CreatePasswordResetToken), ctx, input)
 }
 
-// ExecTransferOperation mocks base method.
-func (m *MockOperation) ExecTransferOperation(ctx context.Context, input entity.TransferOperation) error {
+// DeleteUnusedPasswordResetTokens mocks base method.
+func (m *MockPasswordReset) DeleteUnusedPasswordResetTokens(ctx context.Context, userID int) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ExecTransferOperation", ctx, input)
+	ret := m.ctrl.Call(m, "DeleteUnusedPasswordResetTokens", ctx, userID)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// ExecTransferOperation indicates an expected call of ExecTransferOperation.
-func (mr *MockOperationMockRecorder) ExecTransferOperation(ctx, input interface{}) *gomock.Call {
+// DeleteUnusedPasswordResetTokens indicates an expected call of DeleteUnusedPasswordResetTokens.
+func (mr *MockPasswordResetMockRecorder) DeleteUnusedPasswordResetTokens(ctx, userID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecTransferOperation", reflect.TypeOf((*MockOperation)(nil).ExecTransferOperation), ctx, input)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUnusedPasswordResetTokens", reflect.TypeOf((*MockPasswordReset)(nil).DeleteUnusedPasswordResetTokens), ctx, userID)
 }
 
-// GetIncomingTransfers mocks base method.
-func (m *MockOperation) GetIncomingTransfers(ctx context.Context, accountID int) ([]entity.Transfer, error) {
+// GetPasswordResetTokenByHash mocks base method.
+func (m *MockPasswordReset) GetPasswordResetTokenByHash(ctx context.Context, tokenHash string) (*entity.PasswordResetToken, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetIncomingTransfers", ctx, accountID)
-	ret0, _ := ret[0].([]entity.Transfer)
+	ret := m.ctrl.Call(m, "GetPasswordResetTokenByHash", ctx, tokenHash)
+	ret0, _ := ret[0].(*entity.PasswordResetToken)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetIncomingTransfers indicates an expected call of GetIncomingTransfers.
-func (mr *MockOperationMockRecorder) GetIncomingTransfers(ctx, accountID interface{}) *gomock.Call {
+// GetPasswordResetTokenByHash indicates an expected call of GetPasswordResetTokenByHash.
+func (mr *MockPasswordResetMockRecorder) GetPasswordResetTokenByHash(ctx, tokenHash interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIncomingTransfers", reflect.TypeOf((*MockOperation)(nil).GetIncomingTransfers), ctx, accountID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPasswordResetTokenByHash", reflect.TypeOf((*MockPasswordReset)(nil).GetPasswordResetTokenByHash), ctx, tokenHash)
 }
 
-// GetOutgoingTransfers mocks base method.
-func (m *MockOperation) GetOutgoingTransfers(ctx context.Context, accountID int) ([]entity.Transfer, error) {
+// MarkPasswordResetTokenUsed mocks base method.
+func (m *MockPasswordReset) MarkPasswordResetTokenUsed(ctx context.Context, tokenID int) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetOutgoingTransfers", ctx, accountID)
-	ret0, _ := ret[0].([]entity.Transfer)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "MarkPasswordResetTokenUsed", ctx, tokenID)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// GetOutgoingTransfers indicates an expected call of GetOutgoingTransfers.
-func (mr *MockOperationMockRecorder) GetOutgoingTransfers(ctx, accountID interface{}) *gomock.Call {
+// MarkPasswordResetTokenUsed indicates an expected call of MarkPasswordResetTokenUsed.
+func (mr *MockPasswordResetMockRecorder) MarkPasswordResetTokenUsed(ctx, tokenID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOutgoingTransfers", reflect.TypeOf((*MockOperation)(nil).GetOutgoingTransfers), ctx, accountID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkPasswordResetTokenUsed", reflect.TypeOf((*MockPasswordReset)(nil).MarkPasswordResetTokenUsed), ctx, tokenID)
 }
 
-// MockProduct is a mock of Product interface.
-type MockProduct struct {
+// MockAPIKey is a mock of APIKey interface.
+type MockAPIKey struct {
 	ctrl     *gomock.Controller
-	recorder *MockProductMockRecorder
+	recorder *MockAPIKeyMockRecorder
 }
 
-// MockProductMockRecorder is the mock recorder for MockProduct.
-type MockProductMockRecorder struct {
-	mock *MockProduct
+// MockAPIKeyMockRecorder is the mock recorder for MockAPIKey.
+type MockAPIKeyMockRecorder struct {
+	mock *MockAPIKey
 }
 
-// NewMockProduct creates a new mock instance.
-func NewMockProduct(ctrl *gomock.Controller) *MockProduct {
-	mock := &MockProduct{ctrl: ctrl}
-	mock.recorder = &MockProductMockRecorder{mock}
+// NewMockAPIKey creates a new mock instance.
+func NewMockAPIKey(ctrl *gomock.Controller) *MockAPIKey {
+	mock := &MockAPIKey{ctrl: ctrl}
+	mock.recorder = &MockAPIKeyMockRecorder{mock}
 	return mock
 }
 
 // EXPECT returns an object that allows the caller to indicate expected use.
-func (m *MockProduct) EXPECT() *MockProductMockRecorder {
+func (m *MockAPIKey) EXPECT() *MockAPIKeyMockRecorder {
 	return m.recorder
 }
 
-// GetProductByName mocks base method.
-func (m *MockProduct) GetProductByName(ctx context.Context, name string) (*entity.Product, error) {
+// CreateAPIKey mocks base method.
+func (m *MockAPIKey) CreateAPIKey(ctx context.Context, input *entity.CreateAPIKeyInput) (int, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetProductByName", ctx, name)
-	ret0, _ := ret[0].(*entity.Product)
+	ret := m.ctrl.Call(m, "CreateAPIKey", ctx, input)
+	ret0, _ := ret[0].(int)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetProductByName indicates an expected call of GetProductByName.
-func (mr *MockProductMockRecorder) GetProductByName(ctx, name interface{}) *gomock.Call {
+// CreateAPIKey indicates an expected call of CreateAPIKey.
+func (mr *MockAPIKeyMockRecorder) CreateAPIKey(ctx, input interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductByName", reflect.TypeOf((*MockProduct)(nil).GetProductByName), ctx, name)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAPIKey", reflect.TypeOf((*MockAPIKey)(nil).CreateAPIKey), ctx, input)
+}
+
+// GetAPIKeyByHash mocks base method.
+func (m *MockAPIKey) GetAPIKeyByHash(ctx context.Context, keyHash string) (*entity.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAPIKeyByHash", ctx, keyHash)
+	ret0, _ := ret[0].(*entity.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAPIKeyByHash indicates an expected call of GetAPIKeyByHash.
+func (mr *MockAPIKeyMockRecorder) GetAPIKeyByHash(ctx, keyHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPIKeyByHash", reflect.TypeOf((*MockAPIKey)(nil).GetAPIKeyByHash), ctx, keyHash)
+}
+
+// GetAPIKeysByUserID mocks base method.
+func (m *MockAPIKey) GetAPIKeysByUserID(ctx context.Context, userID int) ([]entity.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAPIKeysByUserID", ctx, userID)
+	ret0, _ := ret[0].([]entity.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAPIKeysByUserID indicates an expected call of GetAPIKeysByUserID.
+func (mr *MockAPIKeyMockRecorder) GetAPIKeysByUserID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPIKeysByUserID", reflect.TypeOf((*MockAPIKey)(nil).GetAPIKeysByUserID), ctx, userID)
+}
+
+// RevokeAPIKey mocks base method.
+func (m *MockAPIKey) RevokeAPIKey(ctx context.Context, keyID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAPIKey", ctx, keyID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAPIKey indicates an expected call of RevokeAPIKey.
+func (mr *MockAPIKeyMockRecorder) RevokeAPIKey(ctx, keyID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAPIKey", reflect.TypeOf((*MockAPIKey)(nil).RevokeAPIKey), ctx, keyID)
+}
+
+// TouchAPIKey mocks base method.
+func (m *MockAPIKey) TouchAPIKey(ctx context.Context, keyID int, usedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TouchAPIKey", ctx, keyID, usedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TouchAPIKey indicates an expected call of TouchAPIKey.
+func (mr *MockAPIKeyMockRecorder) TouchAPIKey(ctx, keyID, usedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TouchAPIKey", reflect.TypeOf((*MockAPIKey)(nil).TouchAPIKey), ctx, keyID, usedAt)
+}
+
+// MockTOTP is a mock of TOTP interface.
+type MockTOTP struct {
+	ctrl     *gomock.Controller
+	recorder *MockTOTPMockRecorder
+}
+
+// MockTOTPMockRecorder is the mock recorder for MockTOTP.
+type MockTOTPMockRecorder struct {
+	mock *MockTOTP
+}
+
+// NewMockTOTP creates a new mock instance.
+func NewMockTOTP(ctrl *gomock.Controller) *MockTOTP {
+	mock := &MockTOTP{ctrl: ctrl}
+	mock.recorder = &MockTOTPMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTOTP) EXPECT() *MockTOTPMockRecorder {
+	return m.recorder
+}
+
+// CreateRecoveryCodes mocks base method.
+func (m *MockTOTP) CreateRecoveryCodes(ctx context.Context, userID int, codeHashes []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRecoveryCodes", ctx, userID, codeHashes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateRecoveryCodes indicates an expected call of CreateRecoveryCodes.
+func (mr *MockTOTPMockRecorder) CreateRecoveryCodes(ctx, userID, codeHashes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRecoveryCodes", reflect.TypeOf((*MockTOTP)(nil).CreateRecoveryCodes), ctx, userID, codeHashes)
+}
+
+// DeleteRecoveryCodes mocks base method.
+func (m *MockTOTP) DeleteRecoveryCodes(ctx context.Context, userID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRecoveryCodes", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteRecoveryCodes indicates an expected call of DeleteRecoveryCodes.
+func (mr *MockTOTPMockRecorder) DeleteRecoveryCodes(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRecoveryCodes", reflect.TypeOf((*MockTOTP)(nil).DeleteRecoveryCodes), ctx, userID)
+}
+
+// DeleteTOTP mocks base method.
+func (m *MockTOTP) DeleteTOTP(ctx context.Context, userID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteTOTP", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteTOTP indicates an expected call of DeleteTOTP.
+func (mr *MockTOTPMockRecorder) DeleteTOTP(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteTOTP", reflect.TypeOf((*MockTOTP)(nil).DeleteTOTP), ctx, userID)
+}
+
+// EnableTOTP mocks base method.
+func (m *MockTOTP) EnableTOTP(ctx context.Context, userID, threshold int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnableTOTP", ctx, userID, threshold)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EnableTOTP indicates an expected call of EnableTOTP.
+func (mr *MockTOTPMockRecorder) EnableTOTP(ctx, userID, threshold interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableTOTP", reflect.TypeOf((*MockTOTP)(nil).EnableTOTP), ctx, userID, threshold)
+}
+
+// GetTOTP mocks base method.
+func (m *MockTOTP) GetTOTP(ctx context.Context, userID int) (*entity.TOTP, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTOTP", ctx, userID)
+	ret0, _ := ret[0].(*entity.TOTP)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTOTP indicates an expected call of GetTOTP.
+func (mr *MockTOTPMockRecorder) GetTOTP(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTOTP", reflect.TypeOf((*MockTOTP)(nil).GetTOTP), ctx, userID)
+}
+
+// SaveTOTPSecret mocks base method.
+func (m *MockTOTP) SaveTOTPSecret(ctx context.Context, userID int, secret string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveTOTPSecret", ctx, userID, secret)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveTOTPSecret indicates an expected call of SaveTOTPSecret.
+func (mr *MockTOTPMockRecorder) SaveTOTPSecret(ctx, userID, secret interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveTOTPSecret", reflect.TypeOf((*MockTOTP)(nil).SaveTOTPSecret), ctx, userID, secret)
+}
+
+// SetTOTPThreshold mocks base method.
+func (m *MockTOTP) SetTOTPThreshold(ctx context.Context, userID, threshold int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTOTPThreshold", ctx, userID, threshold)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetTOTPThreshold indicates an expected call of SetTOTPThreshold.
+func (mr *MockTOTPMockRecorder) SetTOTPThreshold(ctx, userID, threshold interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTOTPThreshold", reflect.TypeOf((*MockTOTP)(nil).SetTOTPThreshold), ctx, userID, threshold)
+}
+
+// UseRecoveryCode mocks base method.
+func (m *MockTOTP) UseRecoveryCode(ctx context.Context, userID int, codeHash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseRecoveryCode", ctx, userID, codeHash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UseRecoveryCode indicates an expected call of UseRecoveryCode.
+func (mr *MockTOTPMockRecorder) UseRecoveryCode(ctx, userID, codeHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseRecoveryCode", reflect.TypeOf((*MockTOTP)(nil).UseRecoveryCode), ctx, userID, codeHash)
+}
+
+// UseTOTPStep mocks base method.
+func (m *MockTOTP) UseTOTPStep(ctx context.Context, userID int, step int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseTOTPStep", ctx, userID, step)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UseTOTPStep indicates an expected call of UseTOTPStep.
+func (mr *MockTOTPMockRecorder) UseTOTPStep(ctx, userID, step interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseTOTPStep", reflect.TypeOf((*MockTOTP)(nil).UseTOTPStep), ctx, userID, step)
+}
+
+// MockAccount is a mock of Account interface.
+type MockAccount struct {
+	ctrl     *gomock.Controller
+	recorder *MockAccountMockRecorder
+}
+
+// MockAccountMockRecorder is the mock recorder for MockAccount.
+type MockAccountMockRecorder struct {
+	mock *MockAccount
+}
+
+// NewMockAccount creates a new mock instance.
+func NewMockAccount(ctrl *gomock.Controller) *MockAccount {
+	mock := &MockAccount{ctrl: ctrl}
+	mock.recorder = &MockAccountMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAccount) EXPECT() *MockAccountMockRecorder {
+	return m.recorder
+}
+
+// Deposit mocks base method.
+func (m *MockAccount) Deposit(ctx context.Context, accountID, amount int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Deposit", ctx, accountID, amount)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Deposit indicates an expected call of Deposit.
+func (mr *MockAccountMockRecorder) Deposit(ctx, accountID, amount interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Deposit", reflect.TypeOf((*MockAccount)(nil).Deposit), ctx, accountID, amount)
+}
+
+// GetBalanceByAccountID mocks base method.
+func (m *MockAccount) GetBalanceByAccountID(ctx context.Context, accountID int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBalanceByAccountID", ctx, accountID)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBalanceByAccountID indicates an expected call of GetBalanceByAccountID.
+func (mr *MockAccountMockRecorder) GetBalanceByAccountID(ctx, accountID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalanceByAccountID", reflect.TypeOf((*MockAccount)(nil).GetBalanceByAccountID), ctx, accountID)
+}
+
+// GetIDByUserID mocks base method.
+func (m *MockAccount) GetIDByUserID(ctx context.Context, userID int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetIDByUserID", ctx, userID)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetIDByUserID indicates an expected call of GetIDByUserID.
+func (mr *MockAccountMockRecorder) GetIDByUserID(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIDByUserID", reflect.TypeOf((*MockAccount)(nil).GetIDByUserID), ctx, userID)
+}
+
+// GetIDByUsername mocks base method.
+func (m *MockAccount) GetIDByUsername(ctx context.Context, username string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetIDByUsername", ctx, username)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetIDByUsername indicates an expected call of GetIDByUsername.
+func (mr *MockAccountMockRecorder) GetIDByUsername(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIDByUsername", reflect.TypeOf((*MockAccount)(nil).GetIDByUsername), ctx, username)
+}
+
+// GetPurchasesByAccountID mocks base method.
+func (m *MockAccount) GetPurchasesByAccountID(ctx context.Context, accountID int) ([]entity.Purchase, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPurchasesByAccountID", ctx, accountID)
+	ret0, _ := ret[0].([]entity.Purchase)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPurchasesByAccountID indicates an expected call of GetPurchasesByAccountID.
+func (mr *MockAccountMockRecorder) GetPurchasesByAccountID(ctx, accountID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPurchasesByAccountID", reflect.TypeOf((*MockAccount)(nil).GetPurchasesByAccountID), ctx, accountID)
+}
+
+// Withdraw mocks base method.
+func (m *MockAccount) Withdraw(ctx context.Context, accountID, amount int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Withdraw", ctx, accountID, amount)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Withdraw indicates an expected call of Withdraw.
+func (mr *MockAccountMockRecorder) Withdraw(ctx, accountID, amount interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Withdraw", reflect.TypeOf((*MockAccount)(nil).Withdraw), ctx, accountID, amount)
+}
+
+// MockOperation is a mock of Operation interface.
+type MockOperation struct {
+	ctrl     *gomock.Controller
+	recorder *MockOperationMockRecorder
+}
+
+// MockOperationMockRecorder is the mock recorder for MockOperation.
+type MockOperationMockRecorder struct {
+	mock *MockOperation
+}
+
+// NewMockOperation creates a new mock instance.
+func NewMockOperation(ctrl *gomock.Controller) *MockOperation {
+	mock := &MockOperation{ctrl: ctrl}
+	mock.recorder = &MockOperationMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOperation) EXPECT() *MockOperationMockRecorder {
+	return m.recorder
+}
+
+// ExecPurchaseOperation mocks base method.
+func (m *MockOperation) ExecPurchaseOperation(ctx context.Context, input entity.PurchaseOperation) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExecPurchaseOperation", ctx, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExecPurchaseOperation indicates an expected call of ExecPurchaseOperation.
+func (mr *MockOperationMockRecorder) ExecPurchaseOperation(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecPurchaseOperation", reflect.TypeOf((*MockOperation)(nil).ExecPurchaseOperation), ctx, input)
+}
+
+// ExecRefundOperation mocks base method.
+func (m *MockOperation) ExecRefundOperation(ctx context.Context, input entity.RefundOperation) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExecRefundOperation", ctx, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExecRefundOperation indicates an expected call of ExecRefundOperation.
+func (mr *MockOperationMockRecorder) ExecRefundOperation(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecRefundOperation", reflect.TypeOf((*MockOperation)(nil).ExecRefundOperation), ctx, input)
+}
+
+// ExecTransferOperation mocks base method.
+func (m *MockOperation) ExecTransferOperation(ctx context.Context, input entity.TransferOperation) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExecTransferOperation", ctx, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExecTransferOperation indicates an expected call of ExecTransferOperation.
+func (mr *MockOperationMockRecorder) ExecTransferOperation(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecTransferOperation", reflect.TypeOf((*MockOperation)(nil).ExecTransferOperation), ctx, input)
+}
+
+// GetHistory mocks base method.
+func (m *MockOperation) GetHistory(ctx context.Context, filter entity.HistoryFilter) ([]entity.HistoryEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHistory", ctx, filter)
+	ret0, _ := ret[0].([]entity.HistoryEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHistory indicates an expected call of GetHistory.
+func (mr *MockOperationMockRecorder) GetHistory(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHistory", reflect.TypeOf((*MockOperation)(nil).GetHistory), ctx, filter)
+}
+
+// GetHistoryMark mocks base method.
+func (m *MockOperation) GetHistoryMark(ctx context.Context, accountID int) (*entity.HistoryMark, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHistoryMark", ctx, accountID)
+	ret0, _ := ret[0].(*entity.HistoryMark)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHistoryMark indicates an expected call of GetHistoryMark.
+func (mr *MockOperationMockRecorder) GetHistoryMark(ctx, accountID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHistoryMark", reflect.TypeOf((*MockOperation)(nil).GetHistoryMark), ctx, accountID)
+}
+
+// GetIncomingTransfers mocks base method.
+func (m *MockOperation) GetIncomingTransfers(ctx context.Context, accountID int) ([]entity.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetIncomingTransfers", ctx, accountID)
+	ret0, _ := ret[0].([]entity.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetIncomingTransfers indicates an expected call of GetIncomingTransfers.
+func (mr *MockOperationMockRecorder) GetIncomingTransfers(ctx, accountID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIncomingTransfers", reflect.TypeOf((*MockOperation)(nil).GetIncomingTransfers), ctx, accountID)
+}
+
+// GetOperationByID mocks base method.
+func (m *MockOperation) GetOperationByID(ctx context.Context, operationID int) (*entity.OperationDetails, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOperationByID", ctx, operationID)
+	ret0, _ := ret[0].(*entity.OperationDetails)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOperationByID indicates an expected call of GetOperationByID.
+func (mr *MockOperationMockRecorder) GetOperationByID(ctx, operationID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOperationByID", reflect.TypeOf((*MockOperation)(nil).GetOperationByID), ctx, operationID)
+}
+
+// GetOutgoingTransfers mocks base method.
+func (m *MockOperation) GetOutgoingTransfers(ctx context.Context, accountID int) ([]entity.Transfer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOutgoingTransfers", ctx, accountID)
+	ret0, _ := ret[0].([]entity.Transfer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOutgoingTransfers indicates an expected call of GetOutgoingTransfers.
+func (mr *MockOperationMockRecorder) GetOutgoingTransfers(ctx, accountID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOutgoingTransfers", reflect.TypeOf((*MockOperation)(nil).GetOutgoingTransfers), ctx, accountID)
+}
+
+// MockProduct is a mock of Product interface.
+type MockProduct struct {
+	ctrl     *gomock.Controller
+	recorder *MockProductMockRecorder
+}
+
+// MockProductMockRecorder is the mock recorder for MockProduct.
+type MockProductMockRecorder struct {
+	mock *MockProduct
+}
+
+// NewMockProduct creates a new mock instance.
+func NewMockProduct(ctrl *gomock.Controller) *MockProduct {
+	mock := &MockProduct{ctrl: ctrl}
+	mock.recorder = &MockProductMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockProduct) EXPECT() *MockProductMockRecorder {
+	return m.recorder
+}
+
+// AddStock mocks base method.
+func (m *MockProduct) AddStock(ctx context.Context, productID, quantity int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddStock", ctx, productID, quantity)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddStock indicates an expected call of AddStock.
+func (mr *MockProductMockRecorder) AddStock(ctx, productID, quantity interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddStock", reflect.TypeOf((*MockProduct)(nil).AddStock), ctx, productID, quantity)
+}
+
+// AddVariantStock mocks base method.
+func (m *MockProduct) AddVariantStock(ctx context.Context, variantID, quantity int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddVariantStock", ctx, variantID, quantity)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddVariantStock indicates an expected call of AddVariantStock.
+func (mr *MockProductMockRecorder) AddVariantStock(ctx, variantID, quantity interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddVariantStock", reflect.TypeOf((*MockProduct)(nil).AddVariantStock), ctx, variantID, quantity)
+}
+
+// CountProducts mocks base method.
+func (m *MockProduct) CountProducts(ctx context.Context, filter entity.ProductFilter) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountProducts", ctx, filter)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountProducts indicates an expected call of CountProducts.
+func (mr *MockProductMockRecorder) CountProducts(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountProducts", reflect.TypeOf((*MockProduct)(nil).CountProducts), ctx, filter)
+}
+
+// CreateProduct mocks base method.
+func (m *MockProduct) CreateProduct(ctx context.Context, input entity.CreateProductInput) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateProduct", ctx, input)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateProduct indicates an expected call of CreateProduct.
+func (mr *MockProductMockRecorder) CreateProduct(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateProduct", reflect.TypeOf((*MockProduct)(nil).CreateProduct), ctx, input)
+}
+
+// CreateProductAudit mocks base method.
+func (m *MockProduct) CreateProductAudit(ctx context.Context, entries []entity.ProductAudit) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateProductAudit", ctx, entries)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateProductAudit indicates an expected call of CreateProductAudit.
+func (mr *MockProductMockRecorder) CreateProductAudit(ctx, entries interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateProductAudit", reflect.TypeOf((*MockProduct)(nil).CreateProductAudit), ctx, entries)
+}
+
+// CreateProductVariant mocks base method.
+func (m *MockProduct) CreateProductVariant(ctx context.Context, input entity.CreateProductVariantInput) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateProductVariant", ctx, input)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateProductVariant indicates an expected call of CreateProductVariant.
+func (mr *MockProductMockRecorder) CreateProductVariant(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateProductVariant", reflect.TypeOf((*MockProduct)(nil).CreateProductVariant), ctx, input)
+}
+
+// CreateRestock mocks base method.
+func (m *MockProduct) CreateRestock(ctx context.Context, restock entity.Restock) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRestock", ctx, restock)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateRestock indicates an expected call of CreateRestock.
+func (mr *MockProductMockRecorder) CreateRestock(ctx, restock interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRestock", reflect.TypeOf((*MockProduct)(nil).CreateRestock), ctx, restock)
+}
+
+// GetLowStockProducts mocks base method.
+func (m *MockProduct) GetLowStockProducts(ctx context.Context, threshold int) ([]entity.LowStockProduct, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLowStockProducts", ctx, threshold)
+	ret0, _ := ret[0].([]entity.LowStockProduct)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLowStockProducts indicates an expected call of GetLowStockProducts.
+func (mr *MockProductMockRecorder) GetLowStockProducts(ctx, threshold interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLowStockProducts", reflect.TypeOf((*MockProduct)(nil).GetLowStockProducts), ctx, threshold)
+}
+
+// GetProductAudit mocks base method.
+func (m *MockProduct) GetProductAudit(ctx context.Context, name string) ([]entity.ProductAudit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProductAudit", ctx, name)
+	ret0, _ := ret[0].([]entity.ProductAudit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProductAudit indicates an expected call of GetProductAudit.
+func (mr *MockProductMockRecorder) GetProductAudit(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductAudit", reflect.TypeOf((*MockProduct)(nil).GetProductAudit), ctx, name)
+}
+
+// GetProductByName mocks base method.
+func (m *MockProduct) GetProductByName(ctx context.Context, name string) (*entity.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProductByName", ctx, name)
+	ret0, _ := ret[0].(*entity.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProductByName indicates an expected call of GetProductByName.
+func (mr *MockProductMockRecorder) GetProductByName(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductByName", reflect.TypeOf((*MockProduct)(nil).GetProductByName), ctx, name)
+}
+
+// GetProductVariant mocks base method.
+func (m *MockProduct) GetProductVariant(ctx context.Context, productID int, name string) (*entity.ProductVariant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProductVariant", ctx, productID, name)
+	ret0, _ := ret[0].(*entity.ProductVariant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProductVariant indicates an expected call of GetProductVariant.
+func (mr *MockProductMockRecorder) GetProductVariant(ctx, productID, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductVariant", reflect.TypeOf((*MockProduct)(nil).GetProductVariant), ctx, productID, name)
+}
+
+// GetProductVariants mocks base method.
+func (m *MockProduct) GetProductVariants(ctx context.Context, productID int) ([]entity.ProductVariant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProductVariants", ctx, productID)
+	ret0, _ := ret[0].([]entity.ProductVariant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProductVariants indicates an expected call of GetProductVariants.
+func (mr *MockProductMockRecorder) GetProductVariants(ctx, productID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductVariants", reflect.TypeOf((*MockProduct)(nil).GetProductVariants), ctx, productID)
+}
+
+// GetProducts mocks base method.
+func (m *MockProduct) GetProducts(ctx context.Context, filter entity.ProductFilter) ([]entity.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProducts", ctx, filter)
+	ret0, _ := ret[0].([]entity.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProducts indicates an expected call of GetProducts.
+func (mr *MockProductMockRecorder) GetProducts(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProducts", reflect.TypeOf((*MockProduct)(nil).GetProducts), ctx, filter)
+}
+
+// ReserveStock mocks base method.
+func (m *MockProduct) ReserveStock(ctx context.Context, productID, quantity int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReserveStock", ctx, productID, quantity)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReserveStock indicates an expected call of ReserveStock.
+func (mr *MockProductMockRecorder) ReserveStock(ctx, productID, quantity interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReserveStock", reflect.TypeOf((*MockProduct)(nil).ReserveStock), ctx, productID, quantity)
+}
+
+// ReserveVariantStock mocks base method.
+func (m *MockProduct) ReserveVariantStock(ctx context.Context, variantID, quantity int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReserveVariantStock", ctx, variantID, quantity)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReserveVariantStock indicates an expected call of ReserveVariantStock.
+func (mr *MockProductMockRecorder) ReserveVariantStock(ctx, variantID, quantity interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReserveVariantStock", reflect.TypeOf((*MockProduct)(nil).ReserveVariantStock), ctx, variantID, quantity)
+}
+
+// RetireProduct mocks base method.
+func (m *MockProduct) RetireProduct(ctx context.Context, productID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RetireProduct", ctx, productID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RetireProduct indicates an expected call of RetireProduct.
+func (mr *MockProductMockRecorder) RetireProduct(ctx, productID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetireProduct", reflect.TypeOf((*MockProduct)(nil).RetireProduct), ctx, productID)
+}
+
+// UpdateProduct mocks base method.
+func (m *MockProduct) UpdateProduct(ctx context.Context, productID int, input entity.UpdateProductInput) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateProduct", ctx, productID, input)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateProduct indicates an expected call of UpdateProduct.
+func (mr *MockProductMockRecorder) UpdateProduct(ctx, productID, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProduct", reflect.TypeOf((*MockProduct)(nil).UpdateProduct), ctx, productID, input)
+}
+
+// MockCart is a mock of Cart interface.
+type MockCart struct {
+	ctrl     *gomock.Controller
+	recorder *MockCartMockRecorder
+}
+
+// MockCartMockRecorder is the mock recorder for MockCart.
+type MockCartMockRecorder struct {
+	mock *MockCart
+}
+
+// NewMockCart creates a new mock instance.
+func NewMockCart(ctrl *gomock.Controller) *MockCart {
+	mock := &MockCart{ctrl: ctrl}
+	mock.recorder = &MockCartMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCart) EXPECT() *MockCartMockRecorder {
+	return m.recorder
+}
+
+// AddCartItem mocks base method.
+func (m *MockCart) AddCartItem(ctx context.Context, userID, productID int, variantID *int, quantity int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddCartItem", ctx, userID, productID, variantID, quantity)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddCartItem indicates an expected call of AddCartItem.
+func (mr *MockCartMockRecorder) AddCartItem(ctx, userID, productID, variantID, quantity interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddCartItem", reflect.TypeOf((*MockCart)(nil).AddCartItem), ctx, userID, productID, variantID, quantity)
+}
+
+// GetCartItems mocks base method.
+func (m *MockCart) GetCartItems(ctx context.Context, userID int) ([]entity.CartItem, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCartItems", ctx, userID)
+	ret0, _ := ret[0].([]entity.CartItem)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCartItems indicates an expected call of GetCartItems.
+func (mr *MockCartMockRecorder) GetCartItems(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCartItems", reflect.TypeOf((*MockCart)(nil).GetCartItems), ctx, userID)
+}
+
+// RemoveCartItem mocks base method.
+func (m *MockCart) RemoveCartItem(ctx context.Context, userID int, productName, variantName string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveCartItem", ctx, userID, productName, variantName)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveCartItem indicates an expected call of RemoveCartItem.
+func (mr *MockCartMockRecorder) RemoveCartItem(ctx, userID, productName, variantName interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveCartItem", reflect.TypeOf((*MockCart)(nil).RemoveCartItem), ctx, userID, productName, variantName)
+}
+
+// RemoveCartItems mocks base method.
+func (m *MockCart) RemoveCartItems(ctx context.Context, userID int, items []entity.CartItem) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveCartItems", ctx, userID, items)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveCartItems indicates an expected call of RemoveCartItems.
+func (mr *MockCartMockRecorder) RemoveCartItems(ctx, userID, items interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveCartItems", reflect.TypeOf((*MockCart)(nil).RemoveCartItems), ctx, userID, items)
+}
+
+// MockRefund is a mock of Refund interface.
+type MockRefund struct {
+	ctrl     *gomock.Controller
+	recorder *MockRefundMockRecorder
+}
+
+// MockRefundMockRecorder is the mock recorder for MockRefund.
+type MockRefundMockRecorder struct {
+	mock *MockRefund
+}
+
+// NewMockRefund creates a new mock instance.
+func NewMockRefund(ctrl *gomock.Controller) *MockRefund {
+	mock := &MockRefund{ctrl: ctrl}
+	mock.recorder = &MockRefundMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRefund) EXPECT() *MockRefundMockRecorder {
+	return m.recorder
+}
+
+// CreateRefund mocks base method.
+func (m *MockRefund) CreateRefund(ctx context.Context, input *entity.CreateRefundInput) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRefund", ctx, input)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateRefund indicates an expected call of CreateRefund.
+func (mr *MockRefundMockRecorder) CreateRefund(ctx, input interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRefund", reflect.TypeOf((*MockRefund)(nil).CreateRefund), ctx, input)
+}
+
+// DecideRefund mocks base method.
+func (m *MockRefund) DecideRefund(ctx context.Context, refundID int, status string, decidedBy int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DecideRefund", ctx, refundID, status, decidedBy)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DecideRefund indicates an expected call of DecideRefund.
+func (mr *MockRefundMockRecorder) DecideRefund(ctx, refundID, status, decidedBy interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DecideRefund", reflect.TypeOf((*MockRefund)(nil).DecideRefund), ctx, refundID, status, decidedBy)
+}
+
+// GetPurchaseForRefund mocks base method.
+func (m *MockRefund) GetPurchaseForRefund(ctx context.Context, purchaseID int) (*entity.RefundablePurchase, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPurchaseForRefund", ctx, purchaseID)
+	ret0, _ := ret[0].(*entity.RefundablePurchase)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPurchaseForRefund indicates an expected call of GetPurchaseForRefund.
+func (mr *MockRefundMockRecorder) GetPurchaseForRefund(ctx, purchaseID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPurchaseForRefund", reflect.TypeOf((*MockRefund)(nil).GetPurchaseForRefund), ctx, purchaseID)
+}
+
+// GetRefundByID mocks base method.
+func (m *MockRefund) GetRefundByID(ctx context.Context, refundID int) (*entity.Refund, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRefundByID", ctx, refundID)
+	ret0, _ := ret[0].(*entity.Refund)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRefundByID indicates an expected call of GetRefundByID.
+func (mr *MockRefundMockRecorder) GetRefundByID(ctx, refundID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRefundByID", reflect.TypeOf((*MockRefund)(nil).GetRefundByID), ctx, refundID)
+}
+
+// GetRefundablePurchases mocks base method.
+func (m *MockRefund) GetRefundablePurchases(ctx context.Context, accountID int, since time.Time) ([]entity.RefundablePurchase, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRefundablePurchases", ctx, accountID, since)
+	ret0, _ := ret[0].([]entity.RefundablePurchase)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRefundablePurchases indicates an expected call of GetRefundablePurchases.
+func (mr *MockRefundMockRecorder) GetRefundablePurchases(ctx, accountID, since interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRefundablePurchases", reflect.TypeOf((*MockRefund)(nil).GetRefundablePurchases), ctx, accountID, since)
+}
+
+// GetRefundsByAccountID mocks base method.
+func (m *MockRefund) GetRefundsByAccountID(ctx context.Context, accountID int) ([]entity.Refund, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRefundsByAccountID", ctx, accountID)
+	ret0, _ := ret[0].([]entity.Refund)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRefundsByAccountID indicates an expected call of GetRefundsByAccountID.
+func (mr *MockRefundMockRecorder) GetRefundsByAccountID(ctx, accountID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRefundsByAccountID", reflect.TypeOf((*MockRefund)(nil).GetRefundsByAccountID), ctx, accountID)
+}
+
+// GetRefundsByStatus mocks base method.
+func (m *MockRefund) GetRefundsByStatus(ctx context.Context, status string) ([]entity.Refund, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRefundsByStatus", ctx, status)
+	ret0, _ := ret[0].([]entity.Refund)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRefundsByStatus indicates an expected call of GetRefundsByStatus.
+func (mr *MockRefundMockRecorder) GetRefundsByStatus(ctx, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRefundsByStatus", reflect.TypeOf((*MockRefund)(nil).GetRefundsByStatus), ctx, status)
+}
+
+// MockIdempotency is a mock of Idempotency interface.
+type MockIdempotency struct {
+	ctrl     *gomock.Controller
+	recorder *MockIdempotencyMockRecorder
+}
+
+// MockIdempotencyMockRecorder is the mock recorder for MockIdempotency.
+type MockIdempotencyMockRecorder struct {
+	mock *MockIdempotency
+}
+
+// NewMockIdempotency creates a new mock instance.
+func NewMockIdempotency(ctrl *gomock.Controller) *MockIdempotency {
+	mock := &MockIdempotency{ctrl: ctrl}
+	mock.recorder = &MockIdempotencyMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIdempotency) EXPECT() *MockIdempotencyMockRecorder {
+	return m.recorder
+}
+
+// CreateIdempotencyKey mocks base method.
+func (m *MockIdempotency) CreateIdempotencyKey(ctx context.Context, key entity.IdempotencyKey) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateIdempotencyKey", ctx, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateIdempotencyKey indicates an expected call of CreateIdempotencyKey.
+func (mr *MockIdempotencyMockRecorder) CreateIdempotencyKey(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateIdempotencyKey", reflect.TypeOf((*MockIdempotency)(nil).CreateIdempotencyKey), ctx, key)
+}
+
+// DeleteExpiredIdempotencyKeys mocks base method.
+func (m *MockIdempotency) DeleteExpiredIdempotencyKeys(ctx context.Context, userID int, now time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredIdempotencyKeys", ctx, userID, now)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteExpiredIdempotencyKeys indicates an expected call of DeleteExpiredIdempotencyKeys.
+func (mr *MockIdempotencyMockRecorder) DeleteExpiredIdempotencyKeys(ctx, userID, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredIdempotencyKeys", reflect.TypeOf((*MockIdempotency)(nil).DeleteExpiredIdempotencyKeys), ctx, userID, now)
+}
+
+// DeleteIdempotencyKey mocks base method.
+func (m *MockIdempotency) DeleteIdempotencyKey(ctx context.Context, userID int, key string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteIdempotencyKey", ctx, userID, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteIdempotencyKey indicates an expected call of DeleteIdempotencyKey.
+func (mr *MockIdempotencyMockRecorder) DeleteIdempotencyKey(ctx, userID, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteIdempotencyKey", reflect.TypeOf((*MockIdempotency)(nil).DeleteIdempotencyKey), ctx, userID, key)
+}
+
+// GetIdempotencyKey mocks base method.
+func (m *MockIdempotency) GetIdempotencyKey(ctx context.Context, userID int, key string) (*entity.IdempotencyKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetIdempotencyKey", ctx, userID, key)
+	ret0, _ := ret[0].(*entity.IdempotencyKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetIdempotencyKey indicates an expected call of GetIdempotencyKey.
+func (mr *MockIdempotencyMockRecorder) GetIdempotencyKey(ctx, userID, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIdempotencyKey", reflect.TypeOf((*MockIdempotency)(nil).GetIdempotencyKey), ctx, userID, key)
+}
+
+// SaveIdempotencyResponse mocks base method.
+func (m *MockIdempotency) SaveIdempotencyResponse(ctx context.Context, key entity.IdempotencyKey) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveIdempotencyResponse", ctx, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveIdempotencyResponse indicates an expected call of SaveIdempotencyResponse.
+func (mr *MockIdempotencyMockRecorder) SaveIdempotencyResponse(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveIdempotencyResponse", reflect.TypeOf((*MockIdempotency)(nil).SaveIdempotencyResponse), ctx, key)
 }
@@ -0,0 +1,63 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./internal/usecase/operation/secondFactor.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockSecondFactor is a mock of SecondFactor interface.
+type MockSecondFactor struct {
+	ctrl     *gomock.Controller
+	recorder *MockSecondFactorMockRecorder
+}
+
+// MockSecondFactorMockRecorder is the mock recorder for MockSecondFactor.
+type MockSecondFactorMockRecorder struct {
+	mock *MockSecondFactor
+}
+
+// NewMockSecondFactor creates a new mock instance.
+func NewMockSecondFactor(ctrl *gomock.Controller) *MockSecondFactor {
+	mock := &MockSecondFactor{ctrl: ctrl}
+	mock.recorder = &MockSecondFactorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSecondFactor) EXPECT() *MockSecondFactorMockRecorder {
+	return m.recorder
+}
+
+// RecordOTPFailure mocks base method.
+func (m *MockSecondFactor) RecordOTPFailure(ctx context.Context, userID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordOTPFailure", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordOTPFailure indicates an expected call of RecordOTPFailure.
+func (mr *MockSecondFactorMockRecorder) RecordOTPFailure(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordOTPFailure", reflect.TypeOf((*MockSecondFactor)(nil).RecordOTPFailure), ctx, userID)
+}
+
+// VerifyOTP mocks base method.
+func (m *MockSecondFactor) VerifyOTP(ctx context.Context, userID, amount int, code string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyOTP", ctx, userID, amount, code)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// VerifyOTP indicates an expected call of VerifyOTP.
+func (mr *MockSecondFactorMockRecorder) VerifyOTP(ctx, userID, amount, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyOTP", reflect.TypeOf((*MockSecondFactor)(nil).VerifyOTP), ctx, userID, amount, code)
+}
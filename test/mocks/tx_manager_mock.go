@@ -117,6 +117,20 @@ func (mr *MockTxManagerMockRecorder) Serializable(ctx, mode, f interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Serializable", reflect.TypeOf((*MockTxManager)(nil).Serializable), ctx, mode, f)
 }
 
+// Snapshot mocks base method.
+func (m *MockTxManager) Snapshot(ctx context.Context, f func(context.Context) error) func() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Snapshot", ctx, f)
+	ret0, _ := ret[0].(func() error)
+	return ret0
+}
+
+// Snapshot indicates an expected call of Snapshot.
+func (mr *MockTxManagerMockRecorder) Snapshot(ctx, f interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Snapshot", reflect.TypeOf((*MockTxManager)(nil).Snapshot), ctx, f)
+}
+
 // WithRetry mocks base method.
 func (m *MockTxManager) WithRetry(f func() error) error {
 	m.ctrl.T.Helper()